@@ -0,0 +1,362 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// APIError is returned when the service answers with an error status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Client calls the Lunar Rockets HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	// Credentials signing the messages sent, when the service requires them
+	producerID     string
+	producerSecret string
+
+	// Bearer token sent with every request, when the service requires one
+	token string
+}
+
+// Option customizes a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithProducerCredentials signs every request with a body using the
+// producer's secret, as required by services that authenticate producers
+func WithProducerCredentials(producerID string, secret string) Option {
+	return func(c *Client) {
+		c.producerID = producerID
+		c.producerSecret = secret
+	}
+}
+
+// WithBearerToken sends the JWT with every request, as required by services
+// that authorize callers by scope
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New creates a client for the service listening at baseURL, e.g.
+// "http://localhost:8088"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// GetRocket returns a rocket by channel, or domain.ErrRocketNotFound
+func (c *Client) GetRocket(ctx context.Context, channel string) (*domain.Rocket, error) {
+	var rocket domain.Rocket
+	_, err := c.do(ctx, http.MethodGet, "/rockets/"+url.PathEscape(channel), nil, &rocket)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, domain.ErrRocketNotFound
+		}
+		return nil, err
+	}
+
+	return &rocket, nil
+}
+
+// ListRockets returns every rocket, sorted by sortBy in the given order. Empty
+// values use the server defaults.
+func (c *Client) ListRockets(ctx context.Context, sortBy string, order string) ([]*domain.Rocket, error) {
+	params := url.Values{}
+	if sortBy != "" {
+		params.Set("sort", sortBy)
+	}
+	if order != "" {
+		params.Set("order", order)
+	}
+
+	var rockets []*domain.Rocket
+	if _, err := c.do(ctx, http.MethodGet, withQuery("/rockets", params), nil, &rockets); err != nil {
+		return nil, err
+	}
+
+	return rockets, nil
+}
+
+// SearchRockets returns a page of the rockets matching the query
+func (c *Client) SearchRockets(ctx context.Context, query domain.RocketQuery) (*domain.RocketPage, error) {
+	params := url.Values{}
+	setIfNotEmpty(params, "status", query.Status)
+	setIfNotEmpty(params, "type", query.Type)
+	setIfNotEmpty(params, "mission", query.Mission)
+	setIfNotEmpty(params, "sort", query.SortBy)
+	setIfNotEmpty(params, "order", query.Order)
+	// Always paginate so the server reports the total
+	params.Set("limit", strconv.Itoa(query.Limit))
+	params.Set("offset", strconv.Itoa(query.Offset))
+
+	var rockets []*domain.Rocket
+	header, err := c.do(ctx, http.MethodGet, withQuery("/rockets", params), nil, &rockets)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := strconv.Atoi(header.Get("X-Total-Count"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid X-Total-Count header: %w", err)
+	}
+
+	return &domain.RocketPage{Rockets: rockets, Total: total}, nil
+}
+
+// SendMessage posts a rocket message to the service
+func (c *Client) SendMessage(ctx context.Context, message *domain.RocketMessage) error {
+	_, err := c.do(ctx, http.MethodPost, "/messages", message, nil)
+	return err
+}
+
+// SubmitMessage posts a rocket message to the service and returns the ID of
+// its receipt, or an empty ID when the service processed it in the request
+func (c *Client) SubmitMessage(ctx context.Context, message *domain.RocketMessage) (string, error) {
+	var accepted struct {
+		ReceiptID string `json:"receiptId"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/messages", message, &accepted); err != nil {
+		return "", err
+	}
+
+	return accepted.ReceiptID, nil
+}
+
+// SendBatch posts rocket messages to the service in one request, and returns
+// what became of each of them in the same order
+func (c *Client) SendBatch(ctx context.Context, messages []*domain.RocketMessage) ([]*domain.MessageResult, error) {
+	var response struct {
+		Results []*domain.MessageResult `json:"results"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/messages/batch", messages, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
+
+// GetReceipt returns what became of a submitted message
+func (c *Client) GetReceipt(ctx context.Context, id string) (*domain.Receipt, error) {
+	var receipt domain.Receipt
+	if _, err := c.do(ctx, http.MethodGet, "/messages/receipts/"+url.PathEscape(id), nil, &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// ListChannels returns the status of every channel with processed or buffered
+// messages
+func (c *Client) ListChannels(ctx context.Context) ([]*domain.ChannelStatus, error) {
+	var statuses []*domain.ChannelStatus
+	if _, err := c.do(ctx, http.MethodGet, "/channels", nil, &statuses); err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// GetChannel returns the status of a channel
+func (c *Client) GetChannel(ctx context.Context, channel string) (*domain.ChannelStatus, error) {
+	var status domain.ChannelStatus
+	if _, err := c.do(ctx, http.MethodGet, "/channels/"+url.PathEscape(channel), nil, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// ListChannelGaps returns the channels waiting for missing messages
+func (c *Client) ListChannelGaps(ctx context.Context) ([]*domain.ChannelStatus, error) {
+	statuses, err := c.ListChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var gaps []*domain.ChannelStatus
+	for _, status := range statuses {
+		if len(status.Buffered) > 0 {
+			gaps = append(gaps, status)
+		}
+	}
+
+	return gaps, nil
+}
+
+// WatchRockets polls the watched channels, or every rocket when none is
+// given, and calls onChange with the current state of each rocket and then
+// with every change. It returns when ctx is done or a request fails.
+func (c *Client) WatchRockets(ctx context.Context, interval time.Duration, channels []string, onChange func(*domain.Rocket)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Last update time seen per channel, so that only changes are reported
+	seen := make(map[string]time.Time)
+	for {
+		rockets, err := c.watchedRockets(ctx, channels)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		for _, rocket := range rockets {
+			if last, ok := seen[rocket.Channel]; ok && last.Equal(rocket.LastUpdated) {
+				continue
+			}
+			onChange(rocket)
+			seen[rocket.Channel] = rocket.LastUpdated
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) watchedRockets(ctx context.Context, channels []string) ([]*domain.Rocket, error) {
+	if len(channels) == 0 {
+		return c.ListRockets(ctx, "channel", "asc")
+	}
+
+	var rockets []*domain.Rocket
+	for _, channel := range channels {
+		rocket, err := c.GetRocket(ctx, channel)
+		if err != nil {
+			if errors.Is(err, domain.ErrRocketNotFound) {
+				continue // Not launched yet
+			}
+			return nil, err
+		}
+		rockets = append(rockets, rocket)
+	}
+
+	return rockets, nil
+}
+
+// do sends a request with body encoded as JSON, and decodes the response into
+// out when it is not nil
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, out interface{}) (http.Header, error) {
+	var encoded []byte
+	var reader io.Reader
+	if body != nil {
+		var err error
+		encoded, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		if c.producerID != "" {
+			if err := c.sign(req, encoded); err != nil {
+				return nil, err
+			}
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(message)),
+		}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return resp.Header, nil
+}
+
+// sign adds the producer's signature of the body, with a fresh timestamp and
+// nonce, to the request
+func (c *Client) sign(req *http.Request, body []byte) error {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	nonce := hex.EncodeToString(random)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("X-Producer-ID", c.producerID)
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", domain.SignMessage(c.producerSecret, timestamp, nonce, body))
+	return nil
+}
+
+func withQuery(path string, params url.Values) string {
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
+
+func setIfNotEmpty(params url.Values, key string, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
@@ -0,0 +1,408 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	httproute "lunar-rockets/http"
+	"lunar-rockets/metrics"
+	"lunar-rockets/test/helper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedTime = time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC)
+
+func testRocket(channel string) *domain.Rocket {
+	return &domain.Rocket{
+		Channel:     channel,
+		Type:        "Falcon-9",
+		Speed:       1000,
+		Mission:     "ARTEMIS",
+		LaunchTime:  fixedTime,
+		Status:      domain.RocketStatusLaunched,
+		LastUpdated: fixedTime,
+		LastMessage: 1,
+	}
+}
+
+func TestClient_GetRocket(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         int
+		body           string
+		expectedRocket *domain.Rocket
+		expectedError  error
+	}{
+		{
+			name:           "found",
+			status:         http.StatusOK,
+			body:           `{"channel":"channel-1","type":"Falcon-9","speed":1000,"mission":"ARTEMIS","launchTime":"2024-03-21T00:00:00Z","status":"Launched","lastUpdated":"2024-03-21T00:00:00Z","lastMessage":1}`,
+			expectedRocket: testRocket("channel-1"),
+		},
+		{
+			name:          "not_found",
+			status:        http.StatusNotFound,
+			body:          "Rocket not found\n",
+			expectedError: domain.ErrRocketNotFound,
+		},
+		{
+			name:          "server_error",
+			status:        http.StatusInternalServerError,
+			body:          "Failed to get rocket state\n",
+			expectedError: &APIError{StatusCode: http.StatusInternalServerError, Message: "Failed to get rocket state"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/rockets/channel-1", r.URL.Path)
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			rocket, err := New(server.URL).GetRocket(context.Background(), "channel-1")
+
+			if tc.expectedError != nil {
+				assert.Equal(t, tc.expectedError, err)
+				assert.Nil(t, rocket)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRocket, rocket)
+			}
+		})
+	}
+}
+
+func TestClient_ListRockets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rockets", r.URL.Path)
+		assert.Equal(t, "order=desc&sort=speed", r.URL.RawQuery)
+		json.NewEncoder(w).Encode([]*domain.Rocket{testRocket("channel-1")})
+	}))
+	defer server.Close()
+
+	rockets, err := New(server.URL).ListRockets(context.Background(), "speed", "desc")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.Rocket{testRocket("channel-1")}, rockets)
+}
+
+func TestClient_SearchRockets(t *testing.T) {
+	testCases := []struct {
+		name          string
+		totalHeader   string
+		expectedPage  *domain.RocketPage
+		expectedError string
+	}{
+		{
+			name:         "page",
+			totalHeader:  "3",
+			expectedPage: &domain.RocketPage{Rockets: []*domain.Rocket{testRocket("channel-1")}, Total: 3},
+		},
+		{
+			name:          "missing_total",
+			expectedError: "invalid X-Total-Count header: strconv.Atoi: parsing \"\": invalid syntax",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/rockets", r.URL.Path)
+				assert.Equal(t, "limit=1&mission=ARTEMIS&offset=2&order=asc&sort=speed&status=Launched", r.URL.RawQuery)
+				if tc.totalHeader != "" {
+					w.Header().Set("X-Total-Count", tc.totalHeader)
+				}
+				json.NewEncoder(w).Encode([]*domain.Rocket{testRocket("channel-1")})
+			}))
+			defer server.Close()
+
+			page, err := New(server.URL).SearchRockets(context.Background(), domain.RocketQuery{
+				Status:  domain.RocketStatusLaunched,
+				Mission: "ARTEMIS",
+				SortBy:  "speed",
+				Order:   "asc",
+				Limit:   1,
+				Offset:  2,
+			})
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedPage, page)
+			}
+		})
+	}
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	message := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{
+			Channel:       "channel-1",
+			MessageNumber: 1,
+			MessageTime:   fixedTime,
+			MessageType:   domain.TypeRocketLaunched,
+		},
+		Message: helper.EncodePayload(map[string]interface{}{"type": "Falcon-9", "launchSpeed": float64(500), "mission": "ARTEMIS"}),
+	}
+
+	testCases := []struct {
+		name          string
+		status        int
+		expectedError string
+	}{
+		{
+			name:   "accepted",
+			status: http.StatusAccepted,
+		},
+		{
+			name:          "rejected",
+			status:        http.StatusBadRequest,
+			expectedError: "server returned 400: Missing channel ID",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/messages", r.URL.Path)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				var received domain.RocketMessage
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				assert.Equal(t, message, &received)
+
+				if tc.status != http.StatusAccepted {
+					http.Error(w, "Missing channel ID", tc.status)
+					return
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(`{"status":"accepted"}`))
+			}))
+			defer server.Close()
+
+			err := New(server.URL).SendMessage(context.Background(), message)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_SendMessageSigned(t *testing.T) {
+	message := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{
+			Channel:       "lunar-1",
+			MessageNumber: 1,
+			MessageTime:   fixedTime,
+			MessageType:   domain.TypeRocketLaunched,
+		},
+		Message: helper.EncodePayload(map[string]interface{}{"type": "Falcon-9", "launchSpeed": float64(500), "mission": "ARTEMIS"}),
+	}
+
+	verifier, err := domain.NewSignatureVerifier([]domain.Producer{{ID: "lunar", Secret: "lunar-secret", Channels: []string{"lunar-*"}}}, time.Minute)
+	require.NoError(t, err)
+	failures := metrics.NewRegistry().Counter("auth_failures_total", "Rejected messages", "reason")
+	server := httptest.NewServer(httproute.NewSignatureMiddleware(verifier, failures, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})))
+	defer server.Close()
+
+	// Every message gets its own nonce, so sending twice is not a replay
+	signed := New(server.URL, WithProducerCredentials("lunar", "lunar-secret"))
+	assert.NoError(t, signed.SendMessage(context.Background(), message))
+	assert.NoError(t, signed.SendMessage(context.Background(), message))
+
+	err = New(server.URL).SendMessage(context.Background(), message)
+	assert.EqualError(t, err, "server returned 401: Invalid signature")
+
+	err = New(server.URL, WithProducerCredentials("lunar", "guessed")).SendMessage(context.Background(), message)
+	assert.EqualError(t, err, "server returned 401: Invalid signature")
+}
+
+func TestClient_BearerToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, WithBearerToken("token-1")).ListRockets(context.Background(), "", "")
+	assert.NoError(t, err)
+
+	_, err = New(server.URL).ListRockets(context.Background(), "", "")
+	assert.EqualError(t, err, "server returned 401: Missing bearer token")
+}
+
+func TestClient_SubmitMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/messages":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"status":"accepted","receiptId":"r-1"}`))
+		case "/messages/receipts/r-1":
+			w.Write([]byte(`{"id":"r-1","channel":"channel-1","messageNumber":1,"messageType":"RocketLaunched","status":"applied","createdAt":"2024-03-21T00:00:00Z","updatedAt":"2024-03-21T00:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+
+	id, err := c.SubmitMessage(context.Background(), &domain.RocketMessage{Metadata: domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, "r-1", id)
+
+	receipt, err := c.GetReceipt(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.Receipt{
+		ID:            "r-1",
+		Channel:       "channel-1",
+		MessageNumber: 1,
+		MessageType:   domain.TypeRocketLaunched,
+		Status:        domain.ReceiptStatusApplied,
+		CreatedAt:     fixedTime,
+		UpdatedAt:     fixedTime,
+	}, receipt)
+
+	_, err = c.GetReceipt(context.Background(), "r-2")
+	assert.EqualError(t, err, "server returned 404: 404 page not found")
+}
+
+func TestClient_SendBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/messages/batch", r.URL.Path)
+
+		var messages []*domain.RocketMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&messages))
+		assert.Len(t, messages, 2)
+
+		w.Write([]byte(`{"results":[{"index":0,"channel":"channel-1","messageNumber":1,"status":"applied"},{"index":1,"channel":"channel-1","messageNumber":3,"status":"buffered"}]}`))
+	}))
+	defer server.Close()
+
+	results, err := New(server.URL).SendBatch(context.Background(), []*domain.RocketMessage{
+		{Metadata: domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1}},
+		{Metadata: domain.MessageMetadata{Channel: "channel-1", MessageNumber: 3}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.MessageResult{
+		{Index: 0, Channel: "channel-1", MessageNumber: 1, Status: domain.ReceiptStatusApplied},
+		{Index: 1, Channel: "channel-1", MessageNumber: 3, Status: domain.ReceiptStatusBuffered},
+	}, results)
+}
+
+func TestClient_ListChannelGaps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/channels", r.URL.Path)
+		w.Write([]byte(`[{"channel":"channel-1","lastProcessed":1,"buffered":[3],"missing":[{"from":2,"to":2}]},` +
+			`{"channel":"channel-2","lastProcessed":4,"buffered":[],"missing":[]}]`))
+	}))
+	defer server.Close()
+
+	statuses, err := New(server.URL).ListChannelGaps(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.ChannelStatus{
+		{Channel: "channel-1", LastProcessed: 1, Buffered: []int64{3}, Missing: []domain.MessageRange{{From: 2, To: 2}}},
+	}, statuses)
+}
+
+func TestClient_GetChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/channels/channel-1", r.URL.Path)
+		w.Write([]byte(`{"channel":"channel-1","lastProcessed":4,"buffered":[],"missing":[],"sinceLastMessageSeconds":90}`))
+	}))
+	defer server.Close()
+
+	status, err := New(server.URL).GetChannel(context.Background(), "channel-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ChannelStatus{
+		Channel:                 "channel-1",
+		LastProcessed:           4,
+		Buffered:                []int64{},
+		Missing:                 []domain.MessageRange{},
+		SinceLastMessageSeconds: 90,
+	}, status)
+}
+
+func TestClient_WatchRockets(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+
+		switch r.URL.Path {
+		case "/rockets/channel-1":
+			rocket := testRocket("channel-1")
+			if polls >= 3 {
+				rocket.Speed = 2000
+				rocket.LastUpdated = fixedTime.Add(time.Minute)
+			}
+			json.NewEncoder(w).Encode(rocket)
+		default:
+			http.Error(w, "Rocket not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var updates []*domain.Rocket
+	err := New(server.URL).WatchRockets(ctx, 10*time.Millisecond, []string{"channel-1", "channel-2"}, func(rocket *domain.Rocket) {
+		updates = append(updates, rocket)
+		if len(updates) == 2 {
+			cancel()
+		}
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, updates, 2) {
+		assert.Equal(t, 1000, updates[0].Speed)
+		assert.Equal(t, 2000, updates[1].Speed)
+	}
+}
+
+func TestClient_WatchRocketsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Failed to get rockets", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := New(server.URL).WatchRockets(context.Background(), time.Millisecond, nil, func(*domain.Rocket) {})
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
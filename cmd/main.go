@@ -2,19 +2,34 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"lunar-rockets/configs"
 	"lunar-rockets/db/sqlite"
+	"lunar-rockets/domain"
+	"lunar-rockets/graphql"
+	grpcserver "lunar-rockets/grpc"
 	httproute "lunar-rockets/http"
 	"lunar-rockets/http/controller"
+	"lunar-rockets/jwt"
+	"lunar-rockets/metrics"
+	"lunar-rockets/outbox"
+	"lunar-rockets/ratelimit"
 	"lunar-rockets/repository"
+	"lunar-rockets/retry"
+	"lunar-rockets/source"
 	"lunar-rockets/usecase"
+
+	"github.com/nats-io/nats.go"
 )
 
 // @title Lunar Rockets API
@@ -25,13 +40,18 @@ import (
 // @BasePath /
 // @schemes http
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer JWT, when the service requires one: "Bearer <token>"
+
 func main() {
 	cfg, err := configs.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	db, err := sqlite.NewDB(cfg.DBPath)
+	db, err := sqlite.NewDB(cfg.DBPath, cfg.SQLiteBusyTimeout)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -39,19 +59,135 @@ func main() {
 
 	rocketRepo := repository.NewRocketRepository(db)
 	messageRepo := repository.NewMessageRepository(db)
+	offsetRepo := repository.NewOffsetRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	telemetryRepo := repository.NewTelemetryRepository(db)
+	rejectionRepo := repository.NewRejectionRepository(db)
+	deadLetterRepo := repository.NewDeadLetterRepository(db)
+	conflictRepo := repository.NewConflictRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	receiptRepo := repository.NewReceiptRepository(db)
+
+	var nc *nats.Conn
+	if cfg.NATSURL != "" {
+		nc, err = nats.Connect(cfg.NATSURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to NATS: %v", err)
+		}
+		defer nc.Close()
+	}
+
+	messageHandlers, err := domain.NewMessageRegistry(usecase.MessageHandlers()...)
+	if err != nil {
+		log.Fatalf("Failed to register message handlers: %v", err)
+	}
+	for _, upcaster := range usecase.MessageUpcasters() {
+		if err := messageHandlers.RegisterUpcaster(upcaster); err != nil {
+			log.Fatalf("Failed to register message upcasters: %v", err)
+		}
+	}
+
+	stateMachine := domain.NewStateMachine(domain.RocketTransitions...)
+
+	registry := metrics.NewRegistry()
+
+	retryPolicy, err := buildRetryPolicy(cfg, registry)
+	if err != nil {
+		log.Fatalf("Failed to configure database retries: %v", err)
+	}
 
-	rocketStateUsecase := usecase.NewRocketStateUsecase(rocketRepo, messageRepo)
-	messageProcessor := usecase.NewRocketMessageUsecase(rocketRepo, messageRepo, rocketStateUsecase)
-	rocketUseCase := usecase.NewRocketUseCase(rocketRepo)
+	conflicts := registry.Counter("lunar_rockets_message_conflicts_total", "Messages reusing the number of a processed message with a different content, by message type", "type")
 
-	messageController := controller.NewMessageController(messageProcessor)
+	rocketStateUsecase := usecase.NewRocketStateUsecase(rocketRepo, messageRepo, outboxRepo, telemetryRepo, rejectionRepo, messageHandlers, stateMachine, retryPolicy)
+	bufferLimits := usecase.BufferLimits{
+		MaxMessages:        cfg.BufferMaxMessages,
+		MaxBytes:           int64(cfg.BufferMaxBytes),
+		MaxChannelMessages: cfg.BufferMaxChannelMessages,
+		MaxChannelBytes:    int64(cfg.BufferMaxChannelBytes),
+		Policy:             usecase.BufferPolicy(cfg.BufferPolicy),
+	}
+	if err := bufferLimits.Validate(); err != nil {
+		log.Fatalf("Failed to configure the message buffer: %v", err)
+	}
+	messageProcessor := usecase.NewRocketMessageUsecase(rocketRepo, messageRepo, deadLetterRepo, conflictRepo, rocketStateUsecase, messageHandlers, bufferLimits, retryPolicy, conflicts)
+	rocketUseCase := usecase.NewRocketUseCase(rocketRepo, telemetryRepo, rejectionRepo)
+	deadLetterUsecase := usecase.NewDeadLetterUsecase(deadLetterRepo, messageProcessor)
+	channelAdminUsecase := usecase.NewChannelAdminUsecase(rocketRepo, messageRepo, telemetryRepo, deadLetterRepo, conflictRepo, rejectionRepo, outboxRepo, auditRepo, messageProcessor, retryPolicy)
+
+	// Messages posted over HTTP are queued unless no worker processes them
+	var ingestionUsecase usecase.IngestionUsecase
+	if cfg.IngestionWorkers > 0 {
+		ingestionCfg := usecase.DefaultIngestionConfig()
+		ingestionCfg.Workers = cfg.IngestionWorkers
+		ingestionCfg.Retention = cfg.IngestionRetention
+		ingestionCfg.Retry, err = retry.NewPolicy(cfg.IngestionRetryMaxAttempts, cfg.IngestionRetryBaseDelay, cfg.IngestionRetryMaxDelay, usecase.IsTransientIngestionError)
+		if err != nil {
+			log.Fatalf("Failed to configure ingestion retries: %v", err)
+		}
+		ingestionUsecase = usecase.NewIngestionUsecase(receiptRepo, messageProcessor, messageHandlers, ingestionCfg)
+	}
+
+	messageController := controller.NewMessageController(messageProcessor, ingestionUsecase)
 	rocketController := controller.NewRocketController(rocketUseCase)
+	channelController := controller.NewChannelController(messageProcessor)
+	rejectionController := controller.NewRejectionController(rocketUseCase)
+	messageTypeController := controller.NewMessageTypeController(messageHandlers)
+	deadLetterController := controller.NewDeadLetterController(deadLetterUsecase)
+	conflictController := controller.NewConflictController(messageProcessor)
+	channelAdminController := controller.NewChannelAdminController(channelAdminUsecase)
+
+	graphqlService, err := graphql.NewService(rocketUseCase, messageRepo, cfg.GraphQLMaxComplexity)
+	if err != nil {
+		log.Fatalf("Failed to create GraphQL service: %v", err)
+	}
+	graphqlController := controller.NewGraphQLController(graphqlService)
+
+	tokens, err := buildTokenValidator(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure JWT validation: %v", err)
+	}
+
+	authFailures := registry.Counter("lunar_rockets_ingestion_auth_failures_total", "Messages rejected by signature verification, by reason", "reason")
+	throttled := registry.Counter("lunar_rockets_ingestion_throttled_total", "Messages rejected by rate limits, by limit", "limit")
+
+	clientLimits, channelLimits, err := buildRateLimits(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure rate limits: %v", err)
+	}
+	var limiter *httproute.RateLimiter
+	if clientLimits != nil || channelLimits != nil {
+		limiter = httproute.NewRateLimiter(clientLimits, channelLimits, throttled)
+	}
 
-	router := httproute.NewRouter(messageController, rocketController)
+	router := httproute.NewRouter(messageController, rocketController, channelController, rejectionController, messageTypeController, graphqlController, deadLetterController, conflictController, channelAdminController, tokens, limiter)
+
+	tenantResolver, err := domain.NewTenantResolver(cfg.TenantAPIKeys)
+	if err != nil {
+		log.Fatalf("Failed to configure tenant API keys: %v", err)
+	}
+
+	var handler http.Handler = router
+	if len(cfg.ProducerSecrets) > 0 {
+		producers, err := buildProducers(cfg)
+		if err != nil {
+			log.Fatalf("Failed to configure producer credentials: %v", err)
+		}
+		verifier, err := domain.NewSignatureVerifier(producers, cfg.SignatureMaxSkew)
+		if err != nil {
+			log.Fatalf("Failed to configure producer credentials: %v", err)
+		}
+		handler = httproute.NewSignatureMiddleware(verifier, authFailures, handler)
+		log.Printf("Requiring signed messages from %d producers", len(cfg.ProducerSecrets))
+	}
+
+	// Metrics are scraped for the whole service, outside of any tenant
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	mux.Handle("/", httproute.NewTenantMiddleware(tenantResolver, handler))
 
 	server := &http.Server{
 		Addr:    cfg.ServerAddress,
-		Handler: router,
+		Handler: mux,
 	}
 
 	go func() {
@@ -61,11 +197,107 @@ func main() {
 		}
 	}()
 
+	// The gRPC API applies the checks of the HTTP API in the same order
+	grpcOptions := grpcserver.TenantServerOptions(tenantResolver)
+	if len(cfg.ProducerSecrets) > 0 {
+		grpcOptions = append(grpcOptions, grpcserver.SignatureServerOptions(authFailures)...)
+	}
+	if tokens != nil {
+		grpcOptions = append(grpcOptions, grpcserver.AuthServerOptions(tokens)...)
+	}
+	if clientLimits != nil || channelLimits != nil {
+		grpcOptions = append(grpcOptions, grpcserver.RateLimitServerOptions(clientLimits, channelLimits, throttled)...)
+	}
+
+	grpcServer := grpcserver.NewServer(grpcserver.NewRocketServer(rocketUseCase, messageProcessor, cfg.GRPCWatchInterval), grpcOptions...)
+	grpcListener, err := net.Listen("tcp", cfg.GRPCAddress)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
+	}
+
+	go func() {
+		log.Printf("Starting gRPC server on %s", cfg.GRPCAddress)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
+	sources := buildSources(cfg, offsetRepo)
+
+	if nc != nil {
+		jsCfg := source.DefaultJetStreamConfig()
+		jsCfg.Stream = cfg.NATSStream
+		jsCfg.Subject = cfg.NATSSubject
+		jsCfg.Durable = cfg.NATSDurable
+
+		jsSource, err := source.NewJetStreamSource(nc, jsCfg)
+		if err != nil {
+			log.Fatalf("Failed to create JetStream source: %v", err)
+		}
+		sources = append(sources, jsSource)
+	}
+
+	if err := domain.ValidateTenantID(cfg.SourceTenant); err != nil {
+		log.Fatalf("Failed to configure source tenant: %v", err)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	sourceCtx := domain.ContextWithTenant(workerCtx, cfg.SourceTenant)
+	var workersWG sync.WaitGroup
+	for _, src := range sources {
+		workersWG.Add(1)
+		go func(src source.MessageSource) {
+			defer workersWG.Done()
+			log.Printf("Starting message source %s for tenant %s", src.Name(), cfg.SourceTenant)
+			if err := src.Start(sourceCtx, messageProcessor.ApplyMessage); err != nil {
+				log.Printf("Message source %s stopped: %v", src.Name(), err)
+			}
+		}(src)
+	}
+
+	if ingestionUsecase != nil {
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			log.Printf("Starting %d ingestion workers", cfg.IngestionWorkers)
+			if err := ingestionUsecase.Start(workerCtx); err != nil {
+				log.Printf("Ingestion workers stopped: %v", err)
+			}
+		}()
+	}
+
+	publisher, err := buildEventPublisher(workerCtx, cfg, nc)
+	if err != nil {
+		log.Fatalf("Failed to create event publisher: %v", err)
+	}
+
+	if publisher != nil {
+		relayCfg := outbox.DefaultRelayConfig()
+		relayCfg.Interval = cfg.OutboxInterval
+		relayCfg.Retention = cfg.OutboxRetention
+		relay := outbox.NewRelay(outboxRepo, publisher, relayCfg)
+
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			log.Printf("Starting outbox relay with %s publisher", cfg.OutboxPublisher)
+			if err := relay.Start(workerCtx); err != nil {
+				log.Printf("Outbox relay stopped: %v", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	log.Println("Stopping background workers...")
+	stopWorkers()
+	workersWG.Wait()
+
 	log.Println("Shutting down server...")
+	grpcServer.GracefulStop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -75,3 +307,153 @@ func main() {
 
 	log.Println("Server exited properly")
 }
+
+// buildSources creates the message sources enabled in the configuration
+func buildSources(cfg *configs.Config, offsetRepo *repository.OffsetRepository) []source.MessageSource {
+	opts := source.DefaultOptions()
+	opts.PollInterval = cfg.SourcePollInterval
+
+	var sources []source.MessageSource
+	if cfg.SourceStdin {
+		sources = append(sources, source.NewStdinSource(os.Stdin, offsetRepo, opts))
+	}
+	if cfg.SourceFile != "" {
+		sources = append(sources, source.NewFileSource(cfg.SourceFile, offsetRepo, opts))
+	}
+	if cfg.SourceDir != "" {
+		sources = append(sources, source.NewDirSource(cfg.SourceDir, offsetRepo, opts))
+	}
+	if cfg.SourceSocket != "" {
+		sources = append(sources, source.NewUnixSocketSource(cfg.SourceSocket))
+	}
+
+	return sources
+}
+
+// buildTokenValidator creates the validator of the API's bearer tokens, or
+// nil when the API is left open
+func buildTokenValidator(cfg *configs.Config) (domain.TokenValidator, error) {
+	if cfg.JWTSecret == "" && cfg.JWTJWKSFile == "" {
+		return nil, nil
+	}
+
+	jwtCfg := jwt.Config{
+		Secret:   []byte(cfg.JWTSecret),
+		Issuer:   cfg.JWTIssuer,
+		Audience: cfg.JWTAudience,
+		Leeway:   cfg.JWTLeeway,
+	}
+	if cfg.JWTJWKSFile != "" {
+		keys, err := jwt.LoadJWKS(cfg.JWTJWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		jwtCfg.Keys = keys
+	}
+
+	validator, err := jwt.NewValidator(jwtCfg)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Requiring bearer tokens on the API")
+	return validator, nil
+}
+
+// buildRetryPolicy creates the retries of the transactions applying messages
+// when SQLite reports a lock held by another connection, counted by operation
+func buildRetryPolicy(cfg *configs.Config, registry *metrics.Registry) (*retry.Policy, error) {
+	policy, err := retry.NewPolicy(cfg.DBRetryMaxAttempts, cfg.DBRetryBaseDelay, cfg.DBRetryMaxDelay, sqlite.IsTransient)
+	if err != nil {
+		return nil, err
+	}
+
+	retries := registry.Counter("lunar_rockets_db_retries_total", "Transactions attempted again after a transient database error, by operation", "operation")
+	exhausted := registry.Counter("lunar_rockets_db_retries_exhausted_total", "Transactions that still failed with a transient database error after the last attempt, by operation", "operation")
+	policy.OnRetry = func(operation string, attempt int, err error) {
+		log.Printf("Retrying %s after attempt %d: %v", operation, attempt, err)
+		retries.Inc(operation)
+	}
+	policy.OnExhausted = func(operation string, err error) {
+		exhausted.Inc(operation)
+	}
+
+	return policy, nil
+}
+
+// buildRateLimits creates the rate limits of the messages of each client and
+// of each channel, nil when their quota is not configured
+func buildRateLimits(cfg *configs.Config) (*ratelimit.Limiter, *ratelimit.Limiter, error) {
+	if len(cfg.RateLimitClientOverrides) > 0 && cfg.RateLimitClient == "" {
+		return nil, nil, fmt.Errorf("client quota overrides require RATE_LIMIT_CLIENT")
+	}
+
+	var clients, channels *ratelimit.Limiter
+	if cfg.RateLimitClient != "" {
+		quota, err := ratelimit.ParseQuota(cfg.RateLimitClient)
+		if err != nil {
+			return nil, nil, err
+		}
+		overrides := make(map[string]ratelimit.Quota, len(cfg.RateLimitClientOverrides))
+		for client, value := range cfg.RateLimitClientOverrides {
+			if overrides[client], err = ratelimit.ParseQuota(value); err != nil {
+				return nil, nil, err
+			}
+		}
+		if clients, err = ratelimit.NewLimiter(quota, overrides); err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Limiting the messages of each client to %s", quota)
+	}
+
+	if cfg.RateLimitChannel != "" {
+		quota, err := ratelimit.ParseQuota(cfg.RateLimitChannel)
+		if err != nil {
+			return nil, nil, err
+		}
+		if channels, err = ratelimit.NewLimiter(quota, nil); err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Limiting the messages of each channel to %s", quota)
+	}
+
+	return clients, channels, nil
+}
+
+// buildProducers creates the producers allowed to post messages from their
+// configured secrets and channel patterns
+func buildProducers(cfg *configs.Config) ([]domain.Producer, error) {
+	for id := range cfg.ProducerChannels {
+		if _, ok := cfg.ProducerSecrets[id]; !ok {
+			return nil, fmt.Errorf("channels configured for producer %s without a secret", id)
+		}
+	}
+
+	producers := make([]domain.Producer, 0, len(cfg.ProducerSecrets))
+	for id, secret := range cfg.ProducerSecrets {
+		producer := domain.Producer{ID: id, Secret: secret}
+		if patterns := cfg.ProducerChannels[id]; patterns != "" {
+			producer.Channels = strings.Split(patterns, "|")
+		}
+		producers = append(producers, producer)
+	}
+	return producers, nil
+}
+
+// buildEventPublisher creates the publisher used by the outbox relay, or nil
+// when publishing is disabled
+func buildEventPublisher(ctx context.Context, cfg *configs.Config, nc *nats.Conn) (domain.EventPublisher, error) {
+	switch cfg.OutboxPublisher {
+	case "", "none":
+		log.Println("Outbox publishing disabled, events will accumulate in the outbox table")
+		return nil, nil
+	case "file":
+		return outbox.NewFilePublisher(cfg.OutboxFile)
+	case "nats":
+		if nc == nil {
+			return nil, fmt.Errorf("outbox publisher nats requires NATS_URL")
+		}
+		return outbox.NewNATSPublisher(ctx, nc, cfg.OutboxStream, cfg.OutboxSubject)
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher: %s", cfg.OutboxPublisher)
+	}
+}
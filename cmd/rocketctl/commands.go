@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// maxLineSize is the longest NDJSON line accepted by send
+const maxLineSize = 1024 * 1024
+
+func runGet(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	if err := parseFlags(flags, env, args, "get <channel>"); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError(env, "get expects a single channel")
+	}
+
+	rocket, err := env.client.GetRocket(ctx, flags.Arg(0))
+	if err != nil {
+		if errors.Is(err, domain.ErrRocketNotFound) {
+			return fmt.Errorf("rocket %s not found", flags.Arg(0))
+		}
+		return err
+	}
+
+	return env.printer.rocket(rocket)
+}
+
+func runList(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	sortBy := flags.String("sort", "", "sort field: channel, type, speed, mission or status")
+	order := flags.String("order", "", "sort order: asc or desc")
+	if err := parseFlags(flags, env, args, "list [-sort field] [-order asc|desc]"); err != nil {
+		return err
+	}
+
+	rockets, err := env.client.ListRockets(ctx, *sortBy, *order)
+	if err != nil {
+		return err
+	}
+
+	return env.printer.rockets(rockets)
+}
+
+func runSearch(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	status := flags.String("status", "", "only rockets with this status (Launched, Exploded, Landed, Aborted or Docked)")
+	rocketType := flags.String("type", "", "only rockets of this type")
+	mission := flags.String("mission", "", "only rockets on this mission")
+	sortBy := flags.String("sort", "", "sort field: channel, type, speed, mission or status")
+	order := flags.String("order", "", "sort order: asc or desc")
+	limit := flags.Int("limit", 20, "maximum number of rockets, 0 for all")
+	offset := flags.Int("offset", 0, "number of rockets to skip")
+	if err := parseFlags(flags, env, args, "search [flags]"); err != nil {
+		return err
+	}
+
+	if *limit < 0 || *offset < 0 {
+		return usageError(env, "limit and offset must not be negative")
+	}
+
+	page, err := env.client.SearchRockets(ctx, domain.RocketQuery{
+		Status:  *status,
+		Type:    *rocketType,
+		Mission: *mission,
+		SortBy:  *sortBy,
+		Order:   *order,
+		Limit:   *limit,
+		Offset:  *offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	return env.printer.rocketPage(page, *offset)
+}
+
+func runWatch(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", 2*time.Second, "how often to check for changes")
+	if err := parseFlags(flags, env, args, "watch [-interval duration] [channel...]"); err != nil {
+		return err
+	}
+
+	if *interval <= 0 {
+		return usageError(env, "interval must be positive")
+	}
+
+	w := env.printer.watcher()
+	var printErr error
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	err := env.client.WatchRockets(watchCtx, *interval, flags.Args(), func(rocket *domain.Rocket) {
+		if err := w.update(rocket); err != nil {
+			printErr = err
+			cancel()
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return printErr
+}
+
+func runSend(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	file := flags.String("f", "", "NDJSON file of messages to send, - for standard input")
+	keepGoing := flags.Bool("continue", false, "keep sending after a message fails")
+	if err := parseFlags(flags, env, args, "send [-f file] [-continue] [message]"); err != nil {
+		return err
+	}
+
+	var input io.Reader
+	switch {
+	case *file != "" && flags.NArg() > 0:
+		return usageError(env, "send expects either a message or -f, not both")
+	case *file == "-":
+		input = env.stdin
+	case *file != "":
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("failed to open messages file: %w", err)
+		}
+		defer f.Close()
+		input = f
+	case flags.NArg() == 1:
+		input = strings.NewReader(flags.Arg(0))
+	default:
+		return usageError(env, "send expects a message or -f")
+	}
+
+	result, err := sendMessages(ctx, env, input, *keepGoing)
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if printErr := env.printer.sendResult(result); printErr != nil {
+		return printErr
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d messages failed", result.Failed, result.Sent+result.Failed)
+	}
+	return err
+}
+
+// sendMessages sends one message per non-empty line of input, in order.
+// Failures are reported on stderr; sending stops at the first one unless
+// keepGoing is set.
+func sendMessages(ctx context.Context, env *environment, input io.Reader, keepGoing bool) (sendResult, error) {
+	var result sendResult
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if err := sendLine(ctx, env, text); err != nil {
+			result.Failed++
+			fmt.Fprintf(env.stderr, "line %d: %v\n", line, err)
+			if !keepGoing {
+				return result, nil
+			}
+			continue
+		}
+		result.Sent++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read messages: %w", err)
+	}
+
+	return result, nil
+}
+
+func sendLine(ctx context.Context, env *environment, text string) error {
+	var message domain.RocketMessage
+	if err := json.Unmarshal([]byte(text), &message); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	return env.client.SendMessage(ctx, &message)
+}
+
+func runGaps(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("gaps", flag.ContinueOnError)
+	if err := parseFlags(flags, env, args, "gaps"); err != nil {
+		return err
+	}
+
+	statuses, err := env.client.ListChannelGaps(ctx)
+	if err != nil {
+		return err
+	}
+
+	return env.printer.channelGaps(statuses)
+}
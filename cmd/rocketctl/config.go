@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the rocketctl settings. Values are read from the config file,
+// then overridden by ROCKETCTL_* environment variables and finally by flags.
+type Config struct {
+	Server  string        `yaml:"server"`
+	Output  string        `yaml:"output"`
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Credentials signing the messages sent, when the service requires them
+	ProducerID     string `yaml:"producerId"`
+	ProducerSecret string `yaml:"producerSecret"`
+
+	// Bearer JWT sent with every request, when the service requires one
+	Token string `yaml:"token"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Server:  "http://localhost:8088",
+		Output:  formatTable,
+		Timeout: 10 * time.Second,
+	}
+}
+
+// defaultConfigPath returns the config file used when none is given
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "rocketctl", "config.yaml")
+}
+
+// loadConfig reads the config file at path and applies the environment. A
+// missing file is only an error when the path was given explicitly.
+func loadConfig(path string, explicit bool) (Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		case errors.Is(err, fs.ErrNotExist) && !explicit:
+			// No config file, use the defaults
+		default:
+			return cfg, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	if value := os.Getenv("ROCKETCTL_SERVER"); value != "" {
+		cfg.Server = value
+	}
+
+	if value := os.Getenv("ROCKETCTL_OUTPUT"); value != "" {
+		cfg.Output = value
+	}
+
+	if value := os.Getenv("ROCKETCTL_PRODUCER_ID"); value != "" {
+		cfg.ProducerID = value
+	}
+
+	if value := os.Getenv("ROCKETCTL_PRODUCER_SECRET"); value != "" {
+		cfg.ProducerSecret = value
+	}
+
+	if value := os.Getenv("ROCKETCTL_TOKEN"); value != "" {
+		cfg.Token = value
+	}
+
+	if value := os.Getenv("ROCKETCTL_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid value for ROCKETCTL_TIMEOUT: %w", err)
+		}
+		cfg.Timeout = timeout
+	}
+
+	return cfg, nil
+}
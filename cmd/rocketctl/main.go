@@ -0,0 +1,193 @@
+// Command rocketctl is a command-line client for the Lunar Rockets API.
+//
+// Usage:
+//
+//	rocketctl [global flags] <command> [flags] [arguments]
+//
+// Run "rocketctl help" for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"lunar-rockets/client"
+)
+
+const usage = `Usage: rocketctl [global flags] <command> [flags] [arguments]
+
+Commands:
+  get <channel>         Show a rocket
+  list                  List every rocket
+  search                Search rockets by status, type and mission
+  watch [channel...]    Print rockets as they change
+  send [message]        Send a JSON message, or an NDJSON file with -f
+  gaps                  Show channels waiting for missing messages
+
+Global flags:
+  -config string        Config file (default: $ROCKETCTL_CONFIG or <user config dir>/rocketctl/config.yaml)
+  -server string        Service URL (default: $ROCKETCTL_SERVER or http://localhost:8088)
+  -o string             Output format: table, json or yaml (default: $ROCKETCTL_OUTPUT or table)
+  -timeout duration     Request timeout (default: $ROCKETCTL_TIMEOUT or 10s)
+
+Run "rocketctl <command> -h" for the flags of a command.
+`
+
+// errUsage reports an invalid command line, after its usage has been printed
+var errUsage = errors.New("invalid usage")
+
+// command is a rocketctl subcommand
+type command func(ctx context.Context, env *environment, args []string) error
+
+var commands = map[string]command{
+	"get":    runGet,
+	"list":   runList,
+	"search": runSearch,
+	"watch":  runWatch,
+	"send":   runSend,
+	"gaps":   runGaps,
+}
+
+// environment is what commands run with
+type environment struct {
+	client  *client.Client
+	printer *printer
+	stdin   io.Reader
+	stderr  io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the process exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rocketctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+
+	configPath := flags.String("config", "", "config file")
+	server := flags.String("server", "", "service URL")
+	output := flags.String("o", "", "output format")
+	timeout := flags.Duration("timeout", 0, "request timeout")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		fmt.Fprint(stderr, usage)
+		if flags.NArg() == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	explicit := *configPath != ""
+	if !explicit {
+		*configPath = os.Getenv("ROCKETCTL_CONFIG")
+		explicit = *configPath != ""
+	}
+	if !explicit {
+		*configPath = defaultConfigPath()
+	}
+
+	cfg, err := loadConfig(*configPath, explicit)
+	if err != nil {
+		fmt.Fprintf(stderr, "rocketctl: %v\n", err)
+		return 1
+	}
+
+	if *server != "" {
+		cfg.Server = *server
+	}
+	if *output != "" {
+		cfg.Output = *output
+	}
+	if *timeout > 0 {
+		cfg.Timeout = *timeout
+	}
+
+	p, err := newPrinter(stdout, cfg.Output)
+	if err != nil {
+		fmt.Fprintf(stderr, "rocketctl: %v\n", err)
+		return 2
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "rocketctl: unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	env := &environment{
+		client:  newClient(cfg),
+		printer: p,
+		stdin:   stdin,
+		stderr:  stderr,
+	}
+
+	if err := cmd(ctx, env, flags.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "rocketctl: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// newClient creates an API client. The timeout applies to each request, so
+// long-running commands like watch are not cut short.
+func newClient(cfg Config) *client.Client {
+	opts := []client.Option{client.WithHTTPClient(&http.Client{Timeout: cfg.Timeout})}
+	if cfg.ProducerID != "" {
+		opts = append(opts, client.WithProducerCredentials(cfg.ProducerID, cfg.ProducerSecret))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, client.WithBearerToken(cfg.Token))
+	}
+	return client.New(cfg.Server, opts...)
+}
+
+// parseFlags parses the flags of a command, printing its usage on error
+func parseFlags(flags *flag.FlagSet, env *environment, args []string, usageLine string) error {
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(env.stderr, "Usage: rocketctl %s\n", usageLine)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	return nil
+}
+
+// usageError reports an invalid command line and returns errUsage
+func usageError(env *environment, format string, args ...interface{}) error {
+	fmt.Fprintf(env.stderr, "rocketctl: "+format+"\n", args...)
+	return errUsage
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedTime = time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC)
+
+// newTestServer serves a fixed fleet and records the messages it receives
+func newTestServer(t *testing.T, received *[]domain.RocketMessage) *httptest.Server {
+	t.Helper()
+
+	rocket := &domain.Rocket{
+		Channel:     "channel-1",
+		Type:        "Falcon-9",
+		Speed:       1000,
+		Mission:     "ARTEMIS",
+		LaunchTime:  fixedTime,
+		Status:      domain.RocketStatusLaunched,
+		LastUpdated: fixedTime,
+		LastMessage: 2,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rockets/{channel}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("channel") != rocket.Channel {
+			http.Error(w, "Rocket not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(rocket)
+	})
+	mux.HandleFunc("GET /rockets", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("limit") {
+			w.Header().Set("X-Total-Count", "3")
+		}
+		json.NewEncoder(w).Encode([]*domain.Rocket{rocket})
+	})
+	mux.HandleFunc("GET /channels", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*domain.ChannelStatus{
+			{Channel: "channel-2", LastProcessed: 1, Buffered: []int64{3, 5}, Missing: []domain.MessageRange{{From: 2, To: 2}, {From: 4, To: 4}}},
+		})
+	})
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		var message domain.RocketMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		if message.Metadata.Channel == "" {
+			http.Error(w, "Missing channel ID", http.StatusBadRequest)
+			return
+		}
+		*received = append(*received, message)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun(t *testing.T) {
+	messagesFile := filepath.Join(t.TempDir(), "messages.ndjson")
+	require.NoError(t, os.WriteFile(messagesFile, []byte(
+		`{"metadata":{"channel":"channel-1","messageNumber":3,"messageTime":"2024-03-21T00:00:00Z","messageType":"RocketSpeedIncreased"},"message":{"by":100}}`+"\n"+
+			"\n"+
+			`{"metadata":{"messageNumber":4},"message":{}}`+"\n"+
+			`{"metadata":{"channel":"channel-1","messageNumber":5,"messageTime":"2024-03-21T00:00:00Z","messageType":"RocketSpeedDecreased"},"message":{"by":50}}`+"\n",
+	), 0644))
+
+	testCases := []struct {
+		name             string
+		args             []string
+		stdin            string
+		expectedCode     int
+		expectedStdout   string
+		expectedStderr   string
+		expectedMessages []int64
+	}{
+		{
+			name:         "get_table",
+			args:         []string{"get", "channel-1"},
+			expectedCode: 0,
+			expectedStdout: "CHANNEL    TYPE      SPEED  MISSION  STATUS    LAST MESSAGE  LAST UPDATED\n" +
+				"channel-1  Falcon-9  1000   ARTEMIS  Launched  2             2024-03-21T00:00:00Z\n",
+		},
+		{
+			name:           "get_not_found",
+			args:           []string{"get", "channel-9"},
+			expectedCode:   1,
+			expectedStderr: "rocketctl: rocket channel-9 not found\n",
+		},
+		{
+			name:           "get_without_channel",
+			args:           []string{"get"},
+			expectedCode:   2,
+			expectedStderr: "rocketctl: get expects a single channel\n",
+		},
+		{
+			name:         "list_json",
+			args:         []string{"-o", "json", "list", "-sort", "speed"},
+			expectedCode: 0,
+			expectedStdout: `[
+  {
+    "channel": "channel-1",
+    "type": "Falcon-9",
+    "speed": 1000,
+    "mission": "ARTEMIS",
+    "launchTime": "2024-03-21T00:00:00Z",
+    "status": "Launched",
+    "lastUpdated": "2024-03-21T00:00:00Z",
+    "lastMessage": 2
+  }
+]
+`,
+		},
+		{
+			name:         "get_yaml",
+			args:         []string{"-o", "yaml", "get", "channel-1"},
+			expectedCode: 0,
+			expectedStdout: `channel: channel-1
+type: Falcon-9
+speed: 1000
+mission: ARTEMIS
+launchTime: "2024-03-21T00:00:00Z"
+status: Launched
+lastUpdated: "2024-03-21T00:00:00Z"
+lastMessage: 2
+`,
+		},
+		{
+			name:         "search_table",
+			args:         []string{"search", "-status", "Launched", "-limit", "1", "-offset", "1"},
+			expectedCode: 0,
+			expectedStdout: "CHANNEL    TYPE      SPEED  MISSION  STATUS    LAST MESSAGE  LAST UPDATED\n" +
+				"channel-1  Falcon-9  1000   ARTEMIS  Launched  2             2024-03-21T00:00:00Z\n" +
+				"\nShowing 2-2 of 3 rockets\n",
+		},
+		{
+			name:         "gaps_table",
+			args:         []string{"gaps"},
+			expectedCode: 0,
+			expectedStdout: "CHANNEL    LAST PROCESSED  BUFFERED  MISSING\n" +
+				"channel-2  1               2         2, 4\n",
+		},
+		{
+			name:             "send_message",
+			args:             []string{"-o", "json", "send", `{"metadata":{"channel":"channel-1","messageNumber":3,"messageType":"RocketSpeedIncreased"},"message":{"by":1}}`},
+			expectedCode:     0,
+			expectedStdout:   "{\n  \"sent\": 1,\n  \"failed\": 0\n}\n",
+			expectedMessages: []int64{3},
+		},
+		{
+			name:             "send_file_stops_at_failure",
+			args:             []string{"send", "-f", messagesFile},
+			expectedCode:     1,
+			expectedStdout:   "SENT  FAILED\n1     1\n",
+			expectedStderr:   "line 3: server returned 400: Missing channel ID\nrocketctl: 1 of 2 messages failed\n",
+			expectedMessages: []int64{3},
+		},
+		{
+			name:             "send_file_continue",
+			args:             []string{"send", "-continue", "-f", messagesFile},
+			expectedCode:     1,
+			expectedStdout:   "SENT  FAILED\n2     1\n",
+			expectedStderr:   "line 3: server returned 400: Missing channel ID\nrocketctl: 1 of 3 messages failed\n",
+			expectedMessages: []int64{3, 5},
+		},
+		{
+			name:             "send_stdin",
+			args:             []string{"send", "-f", "-"},
+			stdin:            `{"metadata":{"channel":"channel-1","messageNumber":7},"message":{}}`,
+			expectedCode:     0,
+			expectedStdout:   "SENT  FAILED\n1     0\n",
+			expectedMessages: []int64{7},
+		},
+		{
+			name:           "unknown_output",
+			args:           []string{"-o", "xml", "list"},
+			expectedCode:   2,
+			expectedStderr: "rocketctl: unknown output format \"xml\", expected table, json or yaml\n",
+		},
+		{
+			name:           "unknown_command",
+			args:           []string{"launch"},
+			expectedCode:   2,
+			expectedStderr: "rocketctl: unknown command \"launch\"\n\n" + usage,
+		},
+		{
+			name:           "no_command",
+			args:           []string{},
+			expectedCode:   2,
+			expectedStderr: usage,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			var received []domain.RocketMessage
+			server := newTestServer(t, &received)
+			t.Setenv("ROCKETCTL_CONFIG", "")
+			t.Setenv("ROCKETCTL_SERVER", server.URL)
+			t.Setenv("ROCKETCTL_OUTPUT", "")
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+
+			assert.Equal(t, tc.expectedCode, code)
+			assert.Equal(t, tc.expectedStdout, stdout.String())
+			assert.Equal(t, tc.expectedStderr, stderr.String())
+
+			var numbers []int64
+			for _, message := range received {
+				numbers = append(numbers, message.Metadata.MessageNumber)
+			}
+			assert.Equal(t, tc.expectedMessages, numbers)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("server: http://rockets:8088\noutput: yaml\ntimeout: 30s\n"), 0644))
+
+	testCases := []struct {
+		name           string
+		path           string
+		explicit       bool
+		env            map[string]string
+		expectedConfig Config
+		expectedError  string
+	}{
+		{
+			name:           "defaults_without_file",
+			path:           filepath.Join(dir, "missing.yaml"),
+			expectedConfig: defaultConfig(),
+		},
+		{
+			name:           "config_file",
+			path:           configFile,
+			explicit:       true,
+			expectedConfig: Config{Server: "http://rockets:8088", Output: formatYAML, Timeout: 30 * time.Second},
+		},
+		{
+			name:           "env_overrides_file",
+			path:           configFile,
+			env:            map[string]string{"ROCKETCTL_SERVER": "http://other:8088", "ROCKETCTL_TIMEOUT": "5s"},
+			expectedConfig: Config{Server: "http://other:8088", Output: formatYAML, Timeout: 5 * time.Second},
+		},
+		{
+			name:           "credentials",
+			path:           configFile,
+			env:            map[string]string{"ROCKETCTL_PRODUCER_ID": "lunar", "ROCKETCTL_PRODUCER_SECRET": "lunar-secret", "ROCKETCTL_TOKEN": "token-1"},
+			expectedConfig: Config{Server: "http://rockets:8088", Output: formatYAML, Timeout: 30 * time.Second, ProducerID: "lunar", ProducerSecret: "lunar-secret", Token: "token-1"},
+		},
+		{
+			name:          "missing_explicit_file",
+			path:          filepath.Join(dir, "missing.yaml"),
+			explicit:      true,
+			expectedError: "failed to read config file",
+		},
+		{
+			name:          "invalid_timeout",
+			env:           map[string]string{"ROCKETCTL_TIMEOUT": "soon"},
+			expectedError: "invalid value for ROCKETCTL_TIMEOUT",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{"ROCKETCTL_SERVER", "ROCKETCTL_OUTPUT", "ROCKETCTL_TIMEOUT", "ROCKETCTL_PRODUCER_ID", "ROCKETCTL_PRODUCER_SECRET", "ROCKETCTL_TOKEN"} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			cfg, err := loadConfig(tc.path, tc.explicit)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedConfig, cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+var rocketColumns = []string{"CHANNEL", "TYPE", "SPEED", "MISSION", "STATUS", "LAST MESSAGE", "LAST UPDATED"}
+
+// printer writes command results in the selected output format
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+func newPrinter(out io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{out: out, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+	}
+}
+
+// print writes value as JSON or YAML, or calls table in table format
+func (p *printer) print(value interface{}, table func(w *tabwriter.Writer)) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	case formatYAML:
+		return writeYAML(p.out, value)
+	default:
+		w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
+
+func (p *printer) rocket(rocket *domain.Rocket) error {
+	return p.print(rocket, func(w *tabwriter.Writer) {
+		writeRow(w, rocketColumns...)
+		writeRocketRow(w, rocket)
+	})
+}
+
+func (p *printer) rockets(rockets []*domain.Rocket) error {
+	if rockets == nil {
+		rockets = []*domain.Rocket{}
+	}
+
+	return p.print(rockets, func(w *tabwriter.Writer) {
+		writeRow(w, rocketColumns...)
+		for _, rocket := range rockets {
+			writeRocketRow(w, rocket)
+		}
+	})
+}
+
+func (p *printer) rocketPage(page *domain.RocketPage, offset int) error {
+	if page.Rockets == nil {
+		page.Rockets = []*domain.Rocket{}
+	}
+
+	return p.print(page, func(w *tabwriter.Writer) {
+		writeRow(w, rocketColumns...)
+		for _, rocket := range page.Rockets {
+			writeRocketRow(w, rocket)
+		}
+		w.Flush()
+
+		if len(page.Rockets) == 0 {
+			fmt.Fprintf(p.out, "\nNo rockets (%d in total)\n", page.Total)
+		} else {
+			fmt.Fprintf(p.out, "\nShowing %d-%d of %d rockets\n", offset+1, offset+len(page.Rockets), page.Total)
+		}
+	})
+}
+
+func (p *printer) channelGaps(statuses []*domain.ChannelStatus) error {
+	if statuses == nil {
+		statuses = []*domain.ChannelStatus{}
+	}
+
+	return p.print(statuses, func(w *tabwriter.Writer) {
+		writeRow(w, "CHANNEL", "LAST PROCESSED", "BUFFERED", "MISSING")
+		for _, status := range statuses {
+			writeRow(w,
+				status.Channel,
+				strconv.FormatInt(status.LastProcessed, 10),
+				strconv.Itoa(len(status.Buffered)),
+				formatRanges(status.Missing),
+			)
+		}
+	})
+}
+
+// sendResult is the outcome of the send command
+type sendResult struct {
+	Sent   int    `json:"sent"`
+	Failed int    `json:"failed"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (p *printer) sendResult(result sendResult) error {
+	return p.print(result, func(w *tabwriter.Writer) {
+		writeRow(w, "SENT", "FAILED")
+		writeRow(w, strconv.Itoa(result.Sent), strconv.Itoa(result.Failed))
+	})
+}
+
+// watcher prints each rocket update as it arrives: table rows under a single
+// header, one JSON document per line, or a stream of YAML documents
+type watcher struct {
+	p      *printer
+	table  *tabwriter.Writer
+	header bool
+}
+
+func (p *printer) watcher() *watcher {
+	// Rows are flushed one at a time, so a minimum cell width keeps the
+	// columns of typical values aligned
+	return &watcher{
+		p:     p,
+		table: tabwriter.NewWriter(p.out, 14, 0, 2, ' ', 0),
+	}
+}
+
+func (w *watcher) update(rocket *domain.Rocket) error {
+	switch w.p.format {
+	case formatJSON:
+		return json.NewEncoder(w.p.out).Encode(rocket)
+	case formatYAML:
+		if _, err := fmt.Fprintln(w.p.out, "---"); err != nil {
+			return err
+		}
+		return writeYAML(w.p.out, rocket)
+	default:
+		if !w.header {
+			writeRow(w.table, rocketColumns...)
+			w.header = true
+		}
+		writeRocketRow(w.table, rocket)
+		return w.table.Flush()
+	}
+}
+
+func writeRocketRow(w io.Writer, rocket *domain.Rocket) {
+	writeRow(w,
+		rocket.Channel,
+		rocket.Type,
+		strconv.Itoa(rocket.Speed),
+		rocket.Mission,
+		rocket.Status,
+		strconv.FormatInt(rocket.LastMessage, 10),
+		rocket.LastUpdated.Format(time.RFC3339),
+	)
+}
+
+func writeRow(w io.Writer, columns ...string) {
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+}
+
+// formatRanges renders message ranges as "2-3, 6"
+func formatRanges(ranges []domain.MessageRange) string {
+	if len(ranges) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r.From == r.To {
+			parts = append(parts, strconv.FormatInt(r.From, 10))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.From, r.To))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeYAML writes value as YAML using its JSON field names and order
+func writeYAML(out io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	// JSON is valid YAML, so decoding it into a node keeps the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	resetStyle(&node)
+
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return enc.Close()
+}
+
+// resetStyle switches a node decoded from JSON to block style
+func resetStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Style == yaml.DoubleQuotedStyle {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
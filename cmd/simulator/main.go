@@ -0,0 +1,253 @@
+// Command simulator generates rocket message traffic for the Lunar Rockets
+// service, with duplicated, out-of-order and dropped messages.
+//
+// Messages are posted to the service with -target or written as NDJSON with
+// -output. The state the service should reach can be written with -expected,
+// and checked against the running service with -verify.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"lunar-rockets/client"
+	"lunar-rockets/simulator"
+)
+
+// verifyInterval is the wait between two checks of the service state
+const verifyInterval = 500 * time.Millisecond
+
+type options struct {
+	generator     simulator.Config
+	faults        simulator.Faults
+	pacing        simulator.Pacing
+	seed          int64
+	target        string
+	producerID    string
+	secret        string
+	token         string
+	output        string
+	expected      string
+	verify        bool
+	verifyTimeout time.Duration
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	if err := run(ctx, opts); err != nil {
+		log.Printf("simulator: %v", err)
+		os.Exit(1)
+	}
+}
+
+func parseOptions(args []string) (*options, error) {
+	opts := &options{generator: simulator.DefaultConfig()}
+
+	flags := flag.NewFlagSet("simulator", flag.ContinueOnError)
+	flags.IntVar(&opts.generator.Channels, "channels", opts.generator.Channels, "number of rockets")
+	flags.IntVar(&opts.generator.MessagesPerChannel, "messages", opts.generator.MessagesPerChannel, "messages per rocket, launch included")
+	flags.Float64Var(&opts.generator.ExplodeRate, "explode-rate", opts.generator.ExplodeRate, "probability that a rocket explodes")
+	flags.Float64Var(&opts.faults.DuplicateRate, "duplicate-rate", 0, "probability that a message is delivered twice")
+	flags.Float64Var(&opts.faults.OutOfOrderRate, "out-of-order-rate", 0, "probability that a message is delivered late")
+	flags.Float64Var(&opts.faults.DropRate, "drop-rate", 0, "probability that a message is never delivered")
+	flags.IntVar(&opts.faults.ReorderWindow, "reorder-window", 5, "how many deliveries a late message or duplicate can be pushed back by")
+	flags.DurationVar(&opts.pacing.Interval, "interval", 0, "wait between two messages")
+	flags.DurationVar(&opts.pacing.Jitter, "jitter", 0, "maximum random time added to each wait")
+	flags.Int64Var(&opts.seed, "seed", 0, "random seed (default: current time)")
+	flags.StringVar(&opts.target, "target", "", "service URL to post messages to, e.g. http://localhost:8088")
+	flags.StringVar(&opts.producerID, "producer-id", "", "producer ID signing the posted messages, when the service requires signatures")
+	flags.StringVar(&opts.secret, "producer-secret", os.Getenv("SIMULATOR_PRODUCER_SECRET"), "secret of -producer-id (default: $SIMULATOR_PRODUCER_SECRET)")
+	flags.StringVar(&opts.token, "token", os.Getenv("SIMULATOR_TOKEN"), "bearer JWT sent to the service, when it requires one (default: $SIMULATOR_TOKEN)")
+	flags.StringVar(&opts.output, "output", "", "NDJSON file to write messages to, or - for stdout")
+	flags.StringVar(&opts.expected, "expected", "", "JSON file to write the expected final state to, or - for stdout")
+	flags.BoolVar(&opts.verify, "verify", false, "check the service reaches the expected state (requires -target)")
+	flags.DurationVar(&opts.verifyTimeout, "verify-timeout", 30*time.Second, "how long to wait for the expected state")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if opts.target == "" && opts.output == "" {
+		problems = append(problems, "one of -target or -output is required")
+	}
+	if opts.target != "" && opts.output != "" {
+		problems = append(problems, "-target and -output cannot be used together")
+	}
+	if opts.verify && opts.target == "" {
+		problems = append(problems, "-verify requires -target")
+	}
+	if opts.producerID != "" && (opts.target == "" || opts.secret == "") {
+		problems = append(problems, "-producer-id requires -target and -producer-secret")
+	}
+	if opts.output == "-" && opts.expected == "-" {
+		problems = append(problems, "-output and -expected cannot both write to stdout")
+	}
+	if opts.generator.Channels <= 0 || opts.generator.MessagesPerChannel <= 0 {
+		problems = append(problems, "-channels and -messages must be positive")
+	}
+	for name, rate := range map[string]float64{
+		"explode-rate":      opts.generator.ExplodeRate,
+		"duplicate-rate":    opts.faults.DuplicateRate,
+		"out-of-order-rate": opts.faults.OutOfOrderRate,
+		"drop-rate":         opts.faults.DropRate,
+	} {
+		if rate < 0 || rate > 1 {
+			problems = append(problems, fmt.Sprintf("-%s must be between 0 and 1", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintf(flags.Output(), "simulator: %s\n", strings.Join(problems, "; "))
+		flags.Usage()
+		return nil, errors.New("invalid options")
+	}
+
+	if opts.seed == 0 {
+		opts.seed = time.Now().UnixNano()
+	}
+
+	return opts, nil
+}
+
+func run(ctx context.Context, opts *options) error {
+	rng := rand.New(rand.NewSource(opts.seed))
+
+	streams := simulator.Generate(opts.generator, rng)
+	delivery := simulator.Schedule(streams, opts.faults, rng)
+	expected := simulator.ExpectedState(streams, delivery)
+
+	log.Printf("Simulating %d channels with seed %d: %d messages to deliver, %d dropped, %d duplicated, %d out of order",
+		len(streams), opts.seed, len(delivery.Messages), len(delivery.Dropped), delivery.Duplicates, delivery.Reordered)
+
+	if opts.expected != "" {
+		if err := writeExpected(opts.expected, expected); err != nil {
+			return err
+		}
+	}
+
+	var api *client.Client
+	var sink simulator.Sink
+	if opts.target != "" {
+		clientOpts := []client.Option{client.WithHTTPClient(&http.Client{Timeout: 10 * time.Second})}
+		if opts.producerID != "" {
+			clientOpts = append(clientOpts, client.WithProducerCredentials(opts.producerID, opts.secret))
+		}
+		if opts.token != "" {
+			clientOpts = append(clientOpts, client.WithBearerToken(opts.token))
+		}
+		api = client.New(opts.target, clientOpts...)
+		sink = simulator.NewHTTPSink(api)
+	} else {
+		w, closeOutput, err := openOutput(opts.output)
+		if err != nil {
+			return err
+		}
+		defer closeOutput()
+		sink = simulator.NewNDJSONSink(w)
+	}
+
+	sent, err := simulator.Deliver(ctx, sink, delivery.Messages, opts.pacing, rng)
+	if err != nil {
+		return fmt.Errorf("delivered %d of %d messages: %w", sent, len(delivery.Messages), err)
+	}
+	log.Printf("Delivered %d messages", sent)
+
+	if !opts.verify {
+		return nil
+	}
+
+	return verify(ctx, api, expected, opts.verifyTimeout)
+}
+
+// verify polls the service until it reports the expected state or the
+// timeout expires
+func verify(ctx context.Context, api *client.Client, expected *simulator.Expected, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(verifyInterval)
+	defer ticker.Stop()
+
+	var diffs []string
+	for {
+		rockets, err := api.ListRockets(ctx, "", "")
+		if err == nil {
+			gaps, gapsErr := api.ListChannelGaps(ctx)
+			err = gapsErr
+			if err == nil {
+				diffs = simulator.Verify(expected, rockets, gaps)
+				if len(diffs) == 0 {
+					log.Printf("Verified %d rockets and %d channels with gaps", len(expected.Rockets), len(expected.Gaps))
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("failed to verify service state: %w", err)
+			}
+			for _, diff := range diffs {
+				log.Print(diff)
+			}
+			return fmt.Errorf("service state differs from the expected state in %d places", len(diffs))
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeExpected(path string, expected *simulator.Expected) error {
+	w, closeOutput, err := openOutput(path)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(expected); err != nil {
+		return fmt.Errorf("failed to write expected state: %w", err)
+	}
+	return nil
+}
+
+// openOutput opens a file for writing, or stdout for "-"
+func openOutput(path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return os.Stdout, func() {}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return f, func() {
+		if err := f.Close(); err != nil {
+			log.Printf("Failed to close %s: %v", path, err)
+		}
+	}, nil
+}
@@ -1,20 +1,283 @@
 package configs
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	ServerAddress string
 
+	GRPCAddress       string
+	GRPCWatchInterval time.Duration
+
 	DBPath string
+
+	// How long a connection waits for another connection's lock, and how the
+	// transactions applying messages are retried when it still fails: up to
+	// DBRetryMaxAttempts attempts in all, with a backoff doubling from
+	// DBRetryBaseDelay up to DBRetryMaxDelay.
+	SQLiteBusyTimeout  time.Duration
+	DBRetryMaxAttempts int
+	DBRetryBaseDelay   time.Duration
+	DBRetryMaxDelay    time.Duration
+
+	// API keys and the tenant each one belongs to. When empty, requests name
+	// their tenant in a header, and default to the default tenant.
+	TenantAPIKeys map[string]string
+
+	// Bearer JWT validation of the API, disabled when neither a secret nor a
+	// JWKS file is set. Issuer and audience are only checked when set.
+	JWTSecret   string
+	JWTJWKSFile string
+	JWTIssuer   string
+	JWTAudience string
+	JWTLeeway   time.Duration
+
+	// Secrets of the producers allowed to post messages, by producer ID. When
+	// empty, messages posted over HTTP need no signature.
+	ProducerSecrets map[string]string
+	// Channel patterns each producer may send to, separated by "|". Producers
+	// without patterns may send to every channel.
+	ProducerChannels map[string]string
+	// Maximum difference between a signed message's timestamp and the clock
+	SignatureMaxSkew time.Duration
+
+	// Quotas of posted messages as requests/period, e.g. "100/1s", per client
+	// and per channel. Each limit is disabled when left empty.
+	RateLimitClient  string
+	RateLimitChannel string
+	// Quotas of specific clients, by JWT subject, producer ID or address
+	RateLimitClientOverrides map[string]string
+
+	// Workers processing the messages posted over HTTP from a durable queue,
+	// 0 processes them in the request. Receipts of processed messages are
+	// kept for IngestionRetention. A message failing with a transient error
+	// is queued again, up to IngestionRetryMaxAttempts attempts in all, with a
+	// backoff doubling from IngestionRetryBaseDelay up to
+	// IngestionRetryMaxDelay.
+	IngestionWorkers          int
+	IngestionRetention        time.Duration
+	IngestionRetryMaxAttempts int
+	IngestionRetryBaseDelay   time.Duration
+	IngestionRetryMaxDelay    time.Duration
+
+	// Caps on the out-of-order messages buffered in memory, for every channel
+	// of a tenant together and for each channel, 0 disables a cap. When a
+	// message does not fit, BufferPolicy "reject" refuses it, "evict-oldest"
+	// dead-letters the messages buffered first and "evict-newest"
+	// dead-letters the message.
+	BufferMaxMessages        int
+	BufferMaxBytes           int
+	BufferMaxChannelMessages int
+	BufferMaxChannelBytes    int
+	BufferPolicy             string
+
+	// Maximum cost of a GraphQL query, 0 disables the limit
+	GraphQLMaxComplexity int
+
+	// Alternative message sources, each one disabled when left empty
+	SourceStdin        bool
+	SourceFile         string
+	SourceDir          string
+	SourceSocket       string
+	SourcePollInterval time.Duration
+	// Tenant of the messages read from the sources above and from NATS
+	SourceTenant string
+
+	// NATS JetStream consumer, disabled when NATSURL is empty
+	NATSURL     string
+	NATSStream  string
+	NATSSubject string
+	NATSDurable string
+
+	// Outbox relay publishing rocket state changes: "file", "nats" or "none"
+	OutboxPublisher string
+	OutboxFile      string
+	OutboxStream    string
+	OutboxSubject   string
+	OutboxInterval  time.Duration
+	OutboxRetention time.Duration
 }
 
 func LoadConfig() (*Config, error) {
+	sourceStdin, err := getEnvBool("SOURCE_STDIN", false)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePollInterval, err := getEnvDuration("SOURCE_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcWatchInterval, err := getEnvDuration("GRPC_WATCH_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxInterval, err := getEnvDuration("OUTBOX_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxRetention, err := getEnvDuration("OUTBOX_RETENTION", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	graphqlMaxComplexity, err := getEnvInt("GRAPHQL_MAX_COMPLEXITY", 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantAPIKeys, err := getEnvMap("TENANT_API_KEYS")
+	if err != nil {
+		return nil, err
+	}
+
+	producerSecrets, err := getEnvMap("PRODUCER_SECRETS")
+	if err != nil {
+		return nil, err
+	}
+
+	producerChannels, err := getEnvMap("PRODUCER_CHANNELS")
+	if err != nil {
+		return nil, err
+	}
+
+	signatureMaxSkew, err := getEnvDuration("SIGNATURE_MAX_SKEW", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtLeeway, err := getEnvDuration("JWT_LEEWAY", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitClientOverrides, err := getEnvMap("RATE_LIMIT_CLIENT_OVERRIDES")
+	if err != nil {
+		return nil, err
+	}
+
+	sqliteBusyTimeout, err := getEnvDuration("SQLITE_BUSY_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRetryMaxAttempts, err := getEnvInt("DB_RETRY_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRetryBaseDelay, err := getEnvDuration("DB_RETRY_BASE_DELAY", 10*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRetryMaxDelay, err := getEnvDuration("DB_RETRY_MAX_DELAY", 500*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	ingestionWorkers, err := getEnvInt("INGESTION_WORKERS", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	ingestionRetention, err := getEnvDuration("INGESTION_RETENTION", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	ingestionRetryMaxAttempts, err := getEnvInt("INGESTION_RETRY_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	ingestionRetryBaseDelay, err := getEnvDuration("INGESTION_RETRY_BASE_DELAY", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	ingestionRetryMaxDelay, err := getEnvDuration("INGESTION_RETRY_MAX_DELAY", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferMaxMessages, err := getEnvInt("BUFFER_MAX_MESSAGES", 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferMaxBytes, err := getEnvInt("BUFFER_MAX_BYTES", 64<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferMaxChannelMessages, err := getEnvInt("BUFFER_MAX_CHANNEL_MESSAGES", 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferMaxChannelBytes, err := getEnvInt("BUFFER_MAX_CHANNEL_BYTES", 8<<20)
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		ServerAddress: getEnv("SERVER_ADDRESS", ":8088"),
-		DBPath:        getEnv("DB_PATH", filepath.Join("data", "rockets.db")),
+		ServerAddress:             getEnv("SERVER_ADDRESS", ":8088"),
+		GRPCAddress:               getEnv("GRPC_ADDRESS", ":9090"),
+		GRPCWatchInterval:         grpcWatchInterval,
+		DBPath:                    getEnv("DB_PATH", filepath.Join("data", "rockets.db")),
+		SQLiteBusyTimeout:         sqliteBusyTimeout,
+		DBRetryMaxAttempts:        dbRetryMaxAttempts,
+		DBRetryBaseDelay:          dbRetryBaseDelay,
+		DBRetryMaxDelay:           dbRetryMaxDelay,
+		TenantAPIKeys:             tenantAPIKeys,
+		JWTSecret:                 getEnv("JWT_SECRET", ""),
+		JWTJWKSFile:               getEnv("JWT_JWKS_FILE", ""),
+		JWTIssuer:                 getEnv("JWT_ISSUER", ""),
+		JWTAudience:               getEnv("JWT_AUDIENCE", ""),
+		JWTLeeway:                 jwtLeeway,
+		ProducerSecrets:           producerSecrets,
+		RateLimitClient:           getEnv("RATE_LIMIT_CLIENT", ""),
+		RateLimitChannel:          getEnv("RATE_LIMIT_CHANNEL", ""),
+		RateLimitClientOverrides:  rateLimitClientOverrides,
+		ProducerChannels:          producerChannels,
+		SignatureMaxSkew:          signatureMaxSkew,
+		IngestionWorkers:          ingestionWorkers,
+		IngestionRetention:        ingestionRetention,
+		IngestionRetryMaxAttempts: ingestionRetryMaxAttempts,
+		IngestionRetryBaseDelay:   ingestionRetryBaseDelay,
+		IngestionRetryMaxDelay:    ingestionRetryMaxDelay,
+		BufferMaxMessages:         bufferMaxMessages,
+		BufferMaxBytes:            bufferMaxBytes,
+		BufferMaxChannelMessages:  bufferMaxChannelMessages,
+		BufferMaxChannelBytes:     bufferMaxChannelBytes,
+		BufferPolicy:              getEnv("BUFFER_POLICY", "reject"),
+		GraphQLMaxComplexity:      graphqlMaxComplexity,
+		SourceStdin:               sourceStdin,
+		SourceFile:                getEnv("SOURCE_FILE", ""),
+		SourceDir:                 getEnv("SOURCE_DIR", ""),
+		SourceSocket:              getEnv("SOURCE_SOCKET", ""),
+		SourcePollInterval:        sourcePollInterval,
+		SourceTenant:              getEnv("SOURCE_TENANT", "default"),
+		NATSURL:                   getEnv("NATS_URL", ""),
+		NATSStream:                getEnv("NATS_STREAM", "ROCKETS"),
+		NATSSubject:               getEnv("NATS_SUBJECT", "rockets.messages"),
+		NATSDurable:               getEnv("NATS_DURABLE", "lunar-rockets"),
+		OutboxPublisher:           getEnv("OUTBOX_PUBLISHER", "file"),
+		OutboxFile:                getEnv("OUTBOX_FILE", filepath.Join("data", "events.ndjson")),
+		OutboxStream:              getEnv("OUTBOX_STREAM", "ROCKET_EVENTS"),
+		OutboxSubject:             getEnv("OUTBOX_SUBJECT", "rockets.events"),
+		OutboxInterval:            outboxInterval,
+		OutboxRetention:           outboxRetention,
 	}
 
 	return config, nil
@@ -26,3 +289,60 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvMap parses a comma separated list of key:value pairs
+func getEnvMap(key string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid value for %s: %q is not a key:value pair", key, pair)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return parsed, nil
+}
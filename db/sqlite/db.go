@@ -2,19 +2,31 @@ package sqlite
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"lunar-rockets/domain"
+
+	"github.com/mattn/go-sqlite3"
 )
 
-func NewDB(dbPath string) (*sql.DB, error) {
+// NewDB opens the database at dbPath, creating or migrating its schema. Busy
+// connections wait up to busyTimeout for another connection's lock before
+// failing with SQLITE_BUSY.
+func NewDB(dbPath string, busyTimeout time.Duration) (*sql.DB, error) {
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	// Rocket updates read before they write, so take the write lock when the
+	// transaction begins instead of failing on a lock upgrade under contention
+	dsn := fmt.Sprintf("%s?_txlock=immediate&_busy_timeout=%d", dbPath, busyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -32,10 +44,21 @@ func NewDB(dbPath string) (*sql.DB, error) {
 	return db, nil
 }
 
+// IsTransient reports whether err comes from a lock held by another
+// connection, so that the operation may succeed when attempted again
+func IsTransient(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
 func initSchema(db *sql.DB) error {
 	rocketTableSQL := `
 	CREATE TABLE IF NOT EXISTS rockets (
-		channel TEXT PRIMARY KEY,
+		tenant_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
 		type TEXT NOT NULL,
 		speed INTEGER NOT NULL,
 		mission TEXT NOT NULL,
@@ -43,25 +66,377 @@ func initSchema(db *sql.DB) error {
 		status TEXT NOT NULL,
 		exploded_at TIMESTAMP,
 		reason TEXT,
+		landed_at TIMESTAMP,
+		landing_site TEXT,
+		aborted_at TIMESTAMP,
+		docked_at TIMESTAMP,
+		station TEXT,
+		telemetry_time TIMESTAMP,
+		altitude REAL,
+		latitude REAL,
+		longitude REAL,
+		fuel REAL,
+		heading REAL,
 		last_updated TIMESTAMP NOT NULL,
-		last_message INTEGER NOT NULL
+		last_message INTEGER NOT NULL,
+		PRIMARY KEY (tenant_id, channel)
 	);`
 
 	if _, err := db.Exec(rocketTableSQL); err != nil {
 		return fmt.Errorf("failed to create rockets table: %w", err)
 	}
 
+	// Databases created by earlier versions lack the columns added since, for
+	// the landing, abort and docking lifecycle and the latest telemetry
+	addedColumns := []struct{ name, definition string }{
+		{"landed_at", "TIMESTAMP"},
+		{"landing_site", "TEXT"},
+		{"aborted_at", "TIMESTAMP"},
+		{"docked_at", "TIMESTAMP"},
+		{"station", "TEXT"},
+		{"telemetry_time", "TIMESTAMP"},
+		{"altitude", "REAL"},
+		{"latitude", "REAL"},
+		{"longitude", "REAL"},
+		{"fuel", "REAL"},
+		{"heading", "REAL"},
+	}
+	for _, column := range addedColumns {
+		if err := addColumnIfMissing(db, "rockets", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	if err := addTenantKey(db, "rockets", rocketTableSQL); err != nil {
+		return err
+	}
+
 	messagesTableSQL := `
 	CREATE TABLE IF NOT EXISTS processed_messages (
+		tenant_id TEXT NOT NULL,
 		channel TEXT NOT NULL,
 		message_number INTEGER NOT NULL,
 		processed_at TIMESTAMP NOT NULL,
-		PRIMARY KEY (channel, message_number)
+		payload_hash TEXT,
+		PRIMARY KEY (tenant_id, channel, message_number)
 	);`
 
 	if _, err := db.Exec(messagesTableSQL); err != nil {
 		return fmt.Errorf("failed to create processed_messages table: %w", err)
 	}
 
+	if err := addTenantKey(db, "processed_messages", messagesTableSQL); err != nil {
+		return err
+	}
+
+	// Hash of the content of a processed message, NULL for the messages
+	// processed by earlier versions, which are not checked for conflicts
+	if err := addColumnIfMissing(db, "processed_messages", "payload_hash", "TEXT"); err != nil {
+		return err
+	}
+
+	eventsTableSQL := `
+	CREATE TABLE IF NOT EXISTS message_events (
+		tenant_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		message_type TEXT NOT NULL,
+		message_time TIMESTAMP NOT NULL,
+		schema_version INTEGER NOT NULL DEFAULT 1,
+		payload TEXT NOT NULL,
+		processed_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (tenant_id, channel, message_number)
+	);`
+
+	if _, err := db.Exec(eventsTableSQL); err != nil {
+		return fmt.Errorf("failed to create message_events table: %w", err)
+	}
+
+	// Events stored before schema versioning have the first version
+	if err := addColumnIfMissing(db, "message_events", "schema_version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+
+	if err := addTenantKey(db, "message_events", eventsTableSQL); err != nil {
+		return err
+	}
+
+	telemetryTableSQL := `
+	CREATE TABLE IF NOT EXISTS telemetry (
+		tenant_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		reading_time TIMESTAMP NOT NULL,
+		altitude REAL NOT NULL,
+		latitude REAL NOT NULL,
+		longitude REAL NOT NULL,
+		fuel REAL NOT NULL,
+		heading REAL NOT NULL,
+		PRIMARY KEY (tenant_id, channel, message_number)
+	);`
+
+	if _, err := db.Exec(telemetryTableSQL); err != nil {
+		return fmt.Errorf("failed to create telemetry table: %w", err)
+	}
+
+	if err := addTenantKey(db, "telemetry", telemetryTableSQL); err != nil {
+		return err
+	}
+
+	// Created once the table is keyed by tenant, as the index of a table
+	// created before tenants is dropped with it
+	telemetryIndexSQL := `CREATE INDEX IF NOT EXISTS idx_telemetry_time ON telemetry (tenant_id, channel, reading_time);`
+
+	if _, err := db.Exec(telemetryIndexSQL); err != nil {
+		return fmt.Errorf("failed to create telemetry index: %w", err)
+	}
+
+	rejectionsTableSQL := `
+	CREATE TABLE IF NOT EXISTS rejections (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id TEXT NOT NULL DEFAULT 'default',
+		channel TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		message_type TEXT NOT NULL,
+		message_time TIMESTAMP NOT NULL,
+		status TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		rejected_at TIMESTAMP NOT NULL
+	);`
+
+	if _, err := db.Exec(rejectionsTableSQL); err != nil {
+		return fmt.Errorf("failed to create rejections table: %w", err)
+	}
+
+	// Rejections recorded before tenants belong to the default tenant
+	if err := addColumnIfMissing(db, "rejections", "tenant_id", "TEXT NOT NULL DEFAULT 'default'"); err != nil {
+		return err
+	}
+
+	// Authenticated caller that sent the rejected message, empty before
+	// callers were authenticated
+	if err := addColumnIfMissing(db, "rejections", "actor", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	// The channel index of earlier versions is replaced by one per tenant
+	rejectionsIndexSQL := `
+	DROP INDEX IF EXISTS idx_rejections_channel;
+	CREATE INDEX IF NOT EXISTS idx_rejections_tenant_channel ON rejections (tenant_id, channel, id);`
+
+	if _, err := db.Exec(rejectionsIndexSQL); err != nil {
+		return fmt.Errorf("failed to create rejections index: %w", err)
+	}
+
+	deadLettersTableSQL := `
+	CREATE TABLE IF NOT EXISTS dead_letters (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		message_type TEXT NOT NULL,
+		message TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_dead_letters_tenant_channel ON dead_letters (tenant_id, channel, id);`
+
+	if _, err := db.Exec(deadLettersTableSQL); err != nil {
+		return fmt.Errorf("failed to create dead_letters table: %w", err)
+	}
+
+	// Failed attempts at a dead letter, one for the dead letters of earlier
+	// versions
+	if err := addColumnIfMissing(db, "dead_letters", "attempts", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+
+	// Time of the last attempt, the creation time when NULL
+	if err := addColumnIfMissing(db, "dead_letters", "updated_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+
+	conflictsTableSQL := `
+	CREATE TABLE IF NOT EXISTS message_conflicts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		message_type TEXT NOT NULL,
+		message_time TIMESTAMP NOT NULL,
+		processed_hash TEXT NOT NULL,
+		received_hash TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		detected_at TIMESTAMP NOT NULL,
+		actor TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_message_conflicts_tenant_channel ON message_conflicts (tenant_id, channel, id);`
+
+	if _, err := db.Exec(conflictsTableSQL); err != nil {
+		return fmt.Errorf("failed to create message_conflicts table: %w", err)
+	}
+
+	// Admin operations on channels, kept for as long as the database
+	auditTableSQL := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		actor TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT '',
+		details TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_channel ON audit_log (tenant_id, channel, id);`
+
+	if _, err := db.Exec(auditTableSQL); err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	// Messages received over HTTP wait in this table until a worker processes
+	// them, and keep their status afterwards
+	receiptsTableSQL := `
+	CREATE TABLE IF NOT EXISTS receipts (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		id TEXT NOT NULL UNIQUE,
+		tenant_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		message_type TEXT NOT NULL,
+		message TEXT NOT NULL,
+		actor TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_receipts_status ON receipts (status, seq);`
+
+	if _, err := db.Exec(receiptsTableSQL); err != nil {
+		return fmt.Errorf("failed to create receipts table: %w", err)
+	}
+
+	offsetsTableSQL := `
+	CREATE TABLE IF NOT EXISTS source_offsets (
+		source TEXT PRIMARY KEY,
+		offset_value INTEGER NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`
+
+	if _, err := db.Exec(offsetsTableSQL); err != nil {
+		return fmt.Errorf("failed to create source_offsets table: %w", err)
+	}
+
+	outboxTableSQL := `
+	CREATE TABLE IF NOT EXISTS outbox (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id TEXT NOT NULL DEFAULT 'default',
+		channel TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		message_number INTEGER NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		published_at TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox (published_at, id);`
+
+	if _, err := db.Exec(outboxTableSQL); err != nil {
+		return fmt.Errorf("failed to create outbox table: %w", err)
+	}
+
+	if err := addColumnIfMissing(db, "outbox", "tenant_id", "TEXT NOT NULL DEFAULT 'default'"); err != nil {
+		return err
+	}
+
+	if err := addColumnIfMissing(db, "outbox", "actor", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// addColumnIfMissing adds a column, nullable or with a default, to a table
+// created by an earlier version of the schema
+func addColumnIfMissing(db *sql.DB, table string, column string, definition string) error {
+	columns, err := tableColumns(db, table)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(columns, column) {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+
+	return nil
+}
+
+// addTenantKey rebuilds a table created before tenants, whose key lacks the
+// tenant ID, with createSQL. SQLite cannot change a primary key in place, so
+// the rows are copied to the new table and assigned to the default tenant.
+func addTenantKey(db *sql.DB, table string, createSQL string) (err error) {
+	columns, err := tableColumns(db, table)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(columns, "tenant_id") {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin %s migration: %w", table, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	copied := strings.Join(columns, ", ")
+	statements := []string{
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s_old`, table, table),
+		createSQL,
+		fmt.Sprintf(`INSERT INTO %s (tenant_id, %s) SELECT '%s', %s FROM %s_old`, table, copied, domain.DefaultTenantID, copied, table),
+		fmt.Sprintf(`DROP TABLE %s_old`, table),
+	}
+	for _, statement := range statements {
+		if _, err = tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to add tenant to %s table: %w", table, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s migration: %w", table, err)
+	}
+
+	return nil
+}
+
+// tableColumns returns the column names of a table
+func tableColumns(db *sql.DB, table string) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return nil, fmt.Errorf("failed to scan %s column: %w", table, err)
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s columns: %w", table, err)
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaBeforeTenants is the schema of the tables keyed by channel, as
+// created by the versions before tenants
+const schemaBeforeTenants = `
+CREATE TABLE rockets (
+	channel TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	speed INTEGER NOT NULL,
+	mission TEXT NOT NULL,
+	launch_time TIMESTAMP NOT NULL,
+	status TEXT NOT NULL,
+	exploded_at TIMESTAMP,
+	reason TEXT,
+	last_updated TIMESTAMP NOT NULL,
+	last_message INTEGER NOT NULL
+);
+CREATE TABLE processed_messages (
+	channel TEXT NOT NULL,
+	message_number INTEGER NOT NULL,
+	processed_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (channel, message_number)
+);
+CREATE TABLE message_events (
+	channel TEXT NOT NULL,
+	message_number INTEGER NOT NULL,
+	message_type TEXT NOT NULL,
+	message_time TIMESTAMP NOT NULL,
+	payload TEXT NOT NULL,
+	processed_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (channel, message_number)
+);
+CREATE TABLE telemetry (
+	channel TEXT NOT NULL,
+	message_number INTEGER NOT NULL,
+	reading_time TIMESTAMP NOT NULL,
+	altitude REAL NOT NULL,
+	latitude REAL NOT NULL,
+	longitude REAL NOT NULL,
+	fuel REAL NOT NULL,
+	heading REAL NOT NULL,
+	PRIMARY KEY (channel, message_number)
+);
+CREATE INDEX idx_telemetry_time ON telemetry (channel, reading_time);
+CREATE TABLE rejections (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	channel TEXT NOT NULL,
+	message_number INTEGER NOT NULL,
+	message_type TEXT NOT NULL,
+	message_time TIMESTAMP NOT NULL,
+	status TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	rejected_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_rejections_channel ON rejections (channel, id);
+
+INSERT INTO rockets VALUES ('channel-1', 'Falcon-9', 500, 'ARTEMIS', '2024-01-01 00:00:00', 'Launched', NULL, NULL, '2024-01-01 00:00:00', 2);
+INSERT INTO processed_messages VALUES ('channel-1', 1, '2024-01-01 00:00:00'), ('channel-1', 2, '2024-01-01 00:00:00');
+INSERT INTO message_events VALUES ('channel-1', 1, 'RocketLaunched', '2024-01-01 00:00:00', '{}', '2024-01-01 00:00:00');
+INSERT INTO telemetry VALUES ('channel-1', 2, '2024-01-01 00:00:00', 100, 28.5, -80.6, 90, 45);
+INSERT INTO rejections (channel, message_number, message_type, message_time, status, reason, rejected_at)
+VALUES ('channel-1', 3, 'RocketLaunched', '2024-01-01 00:00:00', 'Launched', 'rocket already launched', '2024-01-01 00:00:00');`
+
+func TestNewDB_MigratesDataToDefaultTenant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.db")
+
+	old, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = old.Exec(schemaBeforeTenants)
+	require.NoError(t, err)
+	require.NoError(t, old.Close())
+
+	db, err := NewDB(path, time.Second)
+	require.NoError(t, err)
+
+	for table, expected := range map[string]int{
+		"rockets":            1,
+		"processed_messages": 2,
+		"message_events":     1,
+		"telemetry":          1,
+		"rejections":         1,
+	} {
+		var count int
+		err := db.QueryRow(`SELECT COUNT(*) FROM ` + table + ` WHERE tenant_id = 'default'`).Scan(&count)
+		require.NoError(t, err, table)
+		assert.Equal(t, expected, count, table)
+	}
+
+	// The columns added since are there too
+	var schemaVersion int
+	require.NoError(t, db.QueryRow(`SELECT schema_version FROM message_events`).Scan(&schemaVersion))
+	assert.Equal(t, 1, schemaVersion)
+	var actor string
+	require.NoError(t, db.QueryRow(`SELECT actor FROM rejections`).Scan(&actor))
+	assert.Empty(t, actor)
+	var payloadHash sql.NullString
+	require.NoError(t, db.QueryRow(`SELECT payload_hash FROM processed_messages LIMIT 1`).Scan(&payloadHash))
+	assert.False(t, payloadHash.Valid)
+
+	// The tenant is part of the key, so another tenant can use the same channel
+	_, err = db.Exec(`INSERT INTO processed_messages (tenant_id, channel, message_number, processed_at) VALUES ('tenant-a', 'channel-1', 1, CURRENT_TIMESTAMP)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO processed_messages (tenant_id, channel, message_number, processed_at) VALUES ('default', 'channel-1', 1, CURRENT_TIMESTAMP)`)
+	assert.Error(t, err)
+
+	// Opening the migrated database again leaves it as it is
+	require.NoError(t, db.Close())
+	reopened, err := NewDB(path, time.Second)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	var count int
+	require.NoError(t, reopened.QueryRow(`SELECT COUNT(*) FROM processed_messages`).Scan(&count))
+	assert.Equal(t, 3, count)
+}
+
+func TestIsTransient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.db")
+
+	// Without a busy timeout, a second writer fails at once while the first
+	// holds the lock
+	db, err := NewDB(path, 0)
+	require.NoError(t, err)
+	defer db.Close()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	other, err := NewDB(path, 0)
+	require.NoError(t, err)
+	defer other.Close()
+
+	_, err = other.Begin()
+	require.Error(t, err)
+	assert.True(t, IsTransient(err))
+	assert.True(t, IsTransient(fmt.Errorf("failed to begin transaction: %w", err)))
+
+	_, err = db.Exec(`INSERT INTO no_such_table VALUES (1)`)
+	require.Error(t, err)
+	assert.False(t, IsTransient(err))
+	assert.False(t, IsTransient(errors.New("database is locked")))
+}
@@ -15,9 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/messages": {
-            "post": {
-                "description": "Process and store a new rocket message",
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the admin operations made on channels, newest first, with who made them, why and what they changed",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,27 +30,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "channels"
                 ],
-                "summary": "Receive a message",
+                "summary": "List the audit log",
                 "parameters": [
                     {
-                        "description": "Message to be processed",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.RocketMessage"
-                        }
+                        "type": "string",
+                        "description": "Only operations on this channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only operations of this action: skip_gap, discard_buffer or reset_channel",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries to return (default 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Message accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AuditEntry"
                             }
                         }
                     },
@@ -70,9 +84,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/rockets": {
+        "/channels": {
             "get": {
-                "description": "Retrieve a list of all available rockets with optional sorting",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the channels with processed or out-of-order messages: the last processed message number, the buffered message numbers and the ranges they are waiting for, the time since the last message, and the age of the oldest buffered message",
                 "consumes": [
                     "application/json"
                 ],
@@ -80,39 +99,160 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rockets"
+                    "channels"
                 ],
-                "summary": "List all rockets",
+                "summary": "List channels",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ChannelStatus"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/channels/{channel}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the status of a channel: the last processed message number, the buffered message numbers and the ranges they are waiting for, the time since the last message, and the age of the oldest buffered message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "channels"
+                ],
+                "summary": "Get a channel",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sort field ('channel','type','speed','mission','status')",
-                        "name": "sort",
-                        "in": "query"
+                        "description": "Channel ID",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChannelStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing channel ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Channel not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/channels/{channel}/discard-buffer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Drop the out-of-order messages a channel holds in memory. The operation is audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "channels"
+                ],
+                "summary": "Discard the buffered messages of a channel",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Sort order ('asc' or 'desc')",
-                        "name": "order",
-                        "in": "query"
+                        "description": "Channel ID",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ChannelAdminRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Rocket"
-                            }
+                            "$ref": "#/definitions/domain.AuditEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/rockets/{channel}": {
-            "get": {
-                "description": "Retrieve details of a specific rocket by its channel ID",
+        "/channels/{channel}/reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the rocket, telemetry, message history, processed messages, dead letters, conflicts and rejections of a channel, and drop its buffered messages, so that its messages can be sent again from the first one. A ChannelReset event is published. The operation is audited.",
                 "consumes": [
                     "application/json"
                 ],
@@ -120,23 +260,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rockets"
+                    "channels"
                 ],
-                "summary": "Get a specific rocket",
+                "summary": "Reset a channel",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Rocket Channel ID",
+                        "description": "Channel ID",
                         "name": "channel",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ChannelAdminRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Rocket"
+                            "$ref": "#/definitions/domain.AuditEntry"
                         }
                     },
                     "400": {
@@ -146,49 +294,1448 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Rocket not found",
+                        "description": "Channel not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.MessageMetadata": {
-            "type": "object",
-            "properties": {
-                "channel": {
-                    "type": "string"
-                },
-                "messageNumber": {
-                    "type": "integer"
-                },
-                "messageTime": {
-                    "type": "string"
-                },
-                "messageType": {
-                    "type": "string"
+        },
+        "/channels/{channel}/skip-gap": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Give up on the messages of a channel up to a message number, by default the missing messages before the first buffered one, and apply the buffered messages that follow. The skipped messages are not applied, and are taken as duplicates if they are sent later. The operation is audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "channels"
+                ],
+                "summary": "Skip a gap of a channel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Channel ID",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message number to skip to and reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ChannelAdminRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "No message to skip",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "domain.Rocket": {
-            "type": "object",
-            "properties": {
-                "channel": {
-                    "description": "Unique identifier for the rocket",
-                    "type": "string"
-                },
-                "explodedAt": {
-                    "description": "Time when the rocket exploded, if applicable",
-                    "type": "string"
-                },
-                "lastMessage": {
-                    "description": "Last message number processed",
-                    "type": "integer"
-                },
-                "lastUpdated": {
+        "/conflicts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the messages received with the number of a processed message but a different content, newest first. They are not applied. The hashes are SHA-256 of the message type, schema version, time and payload.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conflicts"
+                ],
+                "summary": "List conflicting messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only conflicts of this channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of conflicts to return (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MessageConflict"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the messages the service gave up on, by channel and message number: messages that could not be applied, and out-of-order messages evicted from the full buffer. Each one has the reason of its last failure and its number of attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dead-letters"
+                ],
+                "summary": "List dead letters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only dead letters of this channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only dead letters created before this RFC 3339 time",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of dead letters to return (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DeadLetter"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the matching dead letters without replaying them, every dead letter when no filter is given",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dead-letters"
+                ],
+                "summary": "Purge dead letters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only dead letters of this channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only dead letters created before this RFC 3339 time",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.PurgeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dead-letters/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replay the matching dead letters by channel and message number. A channel stops at its first failure, and its later dead letters are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dead-letters"
+                ],
+                "summary": "Replay dead letters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only dead letters of this channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only dead letters created before this RFC 3339 time",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of dead letters to replay (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dead-letters/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a dead letter without replaying it",
+                "tags": [
+                    "dead-letters"
+                ],
+                "summary": "Delete a dead letter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Dead letter deleted"
+                    },
+                    "400": {
+                        "description": "Invalid dead letter ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead letter not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dead-letters/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Process the message of a dead letter again. The dead letter is deleted once its message is applied, buffered or found to be a duplicate, and counts one more attempt otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dead-letters"
+                ],
+                "summary": "Replay a dead letter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReplayResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dead letter ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead letter not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Query rockets, their message events and fleet stats. Errors in the query are reported in the \"errors\" field of the response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Run a GraphQL query",
+                "parameters": [
+                    {
+                        "description": "GraphQL request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GraphQL response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/message-types": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the registered message types with the JSON Schema of their payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "List message types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MessageType"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a new rocket message for processing, and return the ID of its receipt. When the service processes messages in the request, no receipt is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Receive a message",
+                "parameters": [
+                    {
+                        "description": "Message to be processed",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RocketMessage"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Message accepted",
+                        "schema": {
+                            "$ref": "#/definitions/controller.AcceptedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Channel not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Message number already processed with a different content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Channel buffer full",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Message buffer full or database busy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Process up to 1000 rocket messages at once, sent as a JSON array or as NDJSON with one message per line. The messages are grouped by channel, and the consecutive messages of a channel are applied in one transaction. Each message gets a result with its index in the batch: applied, buffered, duplicate, dead_lettered or error.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Receive a batch of messages",
+                "parameters": [
+                    {
+                        "description": "Messages to be processed",
+                        "name": "messages",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RocketMessage"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of each message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid batch format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Channel not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Batch too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/receipts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report what became of a queued message: queued, applied, buffered until the messages before it arrive, duplicate, conflict, dead_lettered, failed with an error, or discarded from the buffer by an operator",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a message receipt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Receipt ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Receipt"
+                        }
+                    },
+                    "404": {
+                        "description": "Receipt not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rejections": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the messages the rocket state machine rejected, newest first, such as messages received before the launch or after an explosion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rejections"
+                ],
+                "summary": "List rejected messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only rejections of this channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rejections of this message type",
+                        "name": "messageType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of rejections to return (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Rejection"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rockets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a list of all available rockets with optional sorting. When a filter or pagination parameter is given, the total number of matching rockets is returned in the X-Total-Count header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rockets"
+                ],
+                "summary": "List all rockets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort field ('channel','type','speed','mission','status')",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order ('asc' or 'desc')",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Launched",
+                            "Exploded",
+                            "Landed",
+                            "Aborted",
+                            "Docked"
+                        ],
+                        "type": "string",
+                        "description": "Only rockets with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rockets of this type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rockets on this mission",
+                        "name": "mission",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of rockets to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of rockets to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Rocket"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of matching rockets, when filtering or paginating"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rockets/{channel}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve details of a specific rocket by its channel ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rockets"
+                ],
+                "summary": "Get a specific rocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rocket Channel ID",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Rocket"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Rocket not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rockets/{channel}/telemetry": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the telemetry readings of a rocket, oldest first, optionally within a time range. With an interval, only the last reading of each interval is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rockets"
+                ],
+                "summary": "Get the telemetry of a rocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rocket Channel ID",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only readings at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only readings at or before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Downsampling interval, e.g. 30s or 5m",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Telemetry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Rocket not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.AcceptedResponse": {
+            "type": "object",
+            "properties": {
+                "receiptId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.MessageResult"
+                    }
+                }
+            }
+        },
+        "controller.ChannelAdminRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Why the operation is made, kept in the audit log",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Message number to skip to, by default the one before the first buffered message",
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.PurgeResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.ReplayResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ReplayResult"
+                    }
+                }
+            }
+        },
+        "controller.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                }
+            }
+        },
+        "domain.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Authenticated caller that made the operation, if any",
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "What the operation changed",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Why the operation was made, as given by the caller",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ChannelStatus": {
+            "type": "object",
+            "properties": {
+                "buffered": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "lastMessageAt": {
+                    "description": "When the last message was processed or buffered",
+                    "type": "string"
+                },
+                "lastProcessed": {
+                    "type": "integer"
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.MessageRange"
+                    }
+                },
+                "oldestBufferedAgeSeconds": {
+                    "description": "Time since OldestBufferedAt, 0 without buffered messages",
+                    "type": "integer"
+                },
+                "oldestBufferedAt": {
+                    "description": "When the oldest buffered message was received",
+                    "type": "string"
+                },
+                "sinceLastMessageSeconds": {
+                    "description": "Time since LastMessageAt",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Whole message as received",
+                    "type": "object"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "messageType": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Why the last attempt failed",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Time of the last attempt",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MessageConflict": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Authenticated caller that sent the message, if any",
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "detectedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "messageTime": {
+                    "type": "string"
+                },
+                "messageType": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload of the conflicting message",
+                    "type": "object"
+                },
+                "processedHash": {
+                    "description": "Hash of the processed message",
+                    "type": "string"
+                },
+                "receivedHash": {
+                    "description": "Hash of the conflicting message",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MessageMetadata": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "messageTime": {
+                    "type": "string"
+                },
+                "messageType": {
+                    "type": "string"
+                },
+                "schemaVersion": {
+                    "description": "Version of the payload shape, FirstSchemaVersion when unset",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.MessageRange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.MessageResult": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Invalid fields of an invalid message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "index": {
+                    "description": "Position of the message in the batch",
+                    "type": "integer"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MessageType": {
+            "type": "object",
+            "properties": {
+                "schema": {
+                    "$ref": "#/definitions/domain.PayloadSchema"
+                },
+                "schemaVersion": {
+                    "description": "Current schema version, the one the schema describes",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PayloadSchema": {
+            "type": "object",
+            "properties": {
+                "additionalProperties": {
+                    "type": "boolean"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.PropertySchema"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PropertySchema": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Receipt": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Authenticated caller that sent the message, if any",
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why the message failed, if it did",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "messageType": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Rejection": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Authenticated caller that sent the message, if any",
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "messageTime": {
+                    "type": "string"
+                },
+                "messageType": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rejectedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status of the rocket when the message arrived",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ReplayResult": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "messageNumber": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Rocket": {
+            "type": "object",
+            "properties": {
+                "abortedAt": {
+                    "description": "Time when the mission was aborted, if applicable",
+                    "type": "string"
+                },
+                "channel": {
+                    "description": "Unique identifier for the rocket",
+                    "type": "string"
+                },
+                "dockedAt": {
+                    "description": "Time when the rocket docked, if applicable",
+                    "type": "string"
+                },
+                "explodedAt": {
+                    "description": "Time when the rocket exploded, if applicable",
+                    "type": "string"
+                },
+                "landedAt": {
+                    "description": "Time when the rocket landed, if applicable",
+                    "type": "string"
+                },
+                "landingSite": {
+                    "description": "Where the rocket landed, if applicable",
+                    "type": "string"
+                },
+                "lastMessage": {
+                    "description": "Last message number processed",
+                    "type": "integer"
+                },
+                "lastUpdated": {
                     "description": "Last time the rocket state was updated",
                     "type": "string"
                 },
@@ -201,17 +1748,29 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "reason": {
-                    "description": "Reason for explosion, if applicable",
+                    "description": "Reason for explosion or abort, if applicable",
                     "type": "string"
                 },
                 "speed": {
                     "description": "Current speed of the rocket",
                     "type": "integer"
                 },
+                "station": {
+                    "description": "Station the rocket docked with, if applicable",
+                    "type": "string"
+                },
                 "status": {
-                    "description": "Current status",
+                    "description": "Current status: Launched, Exploded, Landed, Aborted or Docked",
                     "type": "string"
                 },
+                "telemetry": {
+                    "description": "Latest telemetry reading, if any",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Telemetry"
+                        }
+                    ]
+                },
                 "type": {
                     "description": "Type of rocket",
                     "type": "string"
@@ -221,11 +1780,65 @@ const docTemplate = `{
         "domain.RocketMessage": {
             "type": "object",
             "properties": {
-                "message": {},
+                "message": {
+                    "type": "object"
+                },
                 "metadata": {
                     "$ref": "#/definitions/domain.MessageMetadata"
                 }
             }
+        },
+        "domain.Telemetry": {
+            "type": "object",
+            "properties": {
+                "altitude": {
+                    "description": "Meters above the surface",
+                    "type": "number"
+                },
+                "fuel": {
+                    "description": "Percentage of the fuel capacity left",
+                    "type": "number"
+                },
+                "heading": {
+                    "description": "Degrees clockwise from north, from 0 to 360",
+                    "type": "number"
+                },
+                "latitude": {
+                    "description": "Degrees, from -90 to 90",
+                    "type": "number"
+                },
+                "longitude": {
+                    "description": "Degrees, from -180 to 180",
+                    "type": "number"
+                },
+                "time": {
+                    "description": "Message time of the reading",
+                    "type": "string"
+                }
+            }
+        },
+        "graphql.Request": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Bearer JWT, when the service requires one: \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// AuditActionSkipGap marks the messages of a gap as processed without
+	// applying them, and applies the buffered messages that follow
+	AuditActionSkipGap = "skip_gap"
+	// AuditActionDiscardBuffer drops the buffered messages of a channel
+	AuditActionDiscardBuffer = "discard_buffer"
+	// AuditActionResetChannel deletes the rocket and processed messages of a
+	// channel, so that its messages can be sent again from the first one
+	AuditActionResetChannel = "reset_channel"
+)
+
+var (
+	// ErrNothingToSkip is returned when skipping a gap of a channel that does
+	// not wait for any message, or up to a message already processed
+	ErrNothingToSkip = errors.New("no message to skip")
+)
+
+// AuditEntry records an admin operation on a channel: who made it, why, and
+// what it changed
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	Channel   string    `json:"channel"`
+	Actor     string    `json:"actor,omitempty"`  // Authenticated caller that made the operation, if any
+	Reason    string    `json:"reason,omitempty"` // Why the operation was made, as given by the caller
+	Details   string    `json:"details"`          // What the operation changed
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditQuery filters the audit entries, newest first. Empty fields match every
+// entry.
+type AuditQuery struct {
+	Channel string
+	Action  string
+	Limit   int
+}
+
+type AuditRepository interface {
+	Save(ctx context.Context, entry *AuditEntry) error
+	List(ctx context.Context, query AuditQuery) ([]*AuditEntry, error)
+}
+
+// GapSkip is what skipping a gap did to a channel
+type GapSkip struct {
+	From      int64   // Last processed message number before the skip
+	To        int64   // Message number the channel was advanced to
+	Applied   []int64 // Buffered messages applied after the skip
+	Discarded []int64 // Buffered messages skipped along with the gap
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MessageStatusError is the status of a message of a batch that could not be
+// processed. The other messages are applied, buffered, duplicate or
+// dead-lettered, like the messages of a receipt.
+const MessageStatusError = "error"
+
+// MessageResult is what became of one message of a batch
+type MessageResult struct {
+	Index         int          `json:"index"` // Position of the message in the batch
+	Channel       string       `json:"channel,omitempty"`
+	MessageNumber int64        `json:"messageNumber,omitempty"`
+	Status        string       `json:"status"`
+	Error         string       `json:"error,omitempty"`
+	Fields        []FieldError `json:"fields,omitempty"` // Invalid fields of an invalid message
+}
+
+// SplitBatch splits a batch of messages, written as a JSON array or as NDJSON
+// with one message per line, into its messages. The messages themselves are
+// not decoded, so that one invalid message does not fail the whole batch.
+func SplitBatch(data []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("invalid batch: %w", err)
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(trimmed)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(bytes.Clone(line)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid batch: %w", err)
+	}
+
+	return items, nil
+}
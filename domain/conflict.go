@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrMessageConflict is returned for a message whose number was already
+	// processed with a different content
+	ErrMessageConflict = errors.New("message conflicts with the processed one")
+)
+
+// MessageConflict is a message received again with the number of a processed
+// message but a different content. It is not applied, and the conflict is
+// kept so that operators can find the producers reusing message numbers.
+type MessageConflict struct {
+	ID            int64           `json:"id"`
+	Channel       string          `json:"channel"`
+	MessageNumber int64           `json:"messageNumber"`
+	MessageType   string          `json:"messageType"`
+	MessageTime   time.Time       `json:"messageTime"`
+	ProcessedHash string          `json:"processedHash"`                // Hash of the processed message
+	ReceivedHash  string          `json:"receivedHash"`                 // Hash of the conflicting message
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"` // Payload of the conflicting message
+	DetectedAt    time.Time       `json:"detectedAt"`
+	Actor         string          `json:"actor,omitempty"` // Authenticated caller that sent the message, if any
+}
+
+// ConflictQuery filters the conflicts, newest first. An empty channel matches
+// every conflict.
+type ConflictQuery struct {
+	Channel string
+	Limit   int
+}
+
+type ConflictRepository interface {
+	Save(ctx context.Context, conflict *MessageConflict) error
+	List(ctx context.Context, query ConflictQuery) ([]*MessageConflict, error)
+	// DeleteChannel deletes the conflicts of a channel, and returns how many
+	// were deleted
+	DeleteChannel(ctx context.Context, channel string) (int64, error)
+}
+
+// PayloadHash returns the SHA-256 of the content of a message: its type,
+// schema version, time and payload. The payload is hashed in a canonical form,
+// so that a message encoded again with other spacing or key order keeps its
+// hash. Numbers are kept as written, so that integers too large for a float64
+// still tell two payloads apart.
+func PayloadHash(message *RocketMessage) (string, error) {
+	payload := []byte("null")
+	if len(message.Message) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(message.Message))
+		decoder.UseNumber()
+		var decoded interface{}
+		if err := decoder.Decode(&decoded); err != nil {
+			return "", fmt.Errorf("failed to decode payload: %w", err)
+		}
+		var err error
+		if payload, err = json.Marshal(decoded); err != nil {
+			return "", fmt.Errorf("failed to encode payload: %w", err)
+		}
+	}
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%s\n%d\n%s\n", message.Metadata.MessageType, message.Metadata.Version(), message.Metadata.MessageTime.UTC().Format(time.RFC3339Nano))
+	content.Write(payload)
+
+	sum := sha256.Sum256(content.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
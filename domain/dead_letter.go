@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrBufferFull is returned when an out-of-order message cannot be
+	// buffered because the buffer of every channel of its tenant together is
+	// full
+	ErrBufferFull = errors.New("message buffer full")
+	// ErrChannelBufferFull is returned when an out-of-order message cannot be
+	// buffered because the buffer of its channel is full
+	ErrChannelBufferFull = errors.New("channel message buffer full")
+	// ErrDeadLettered is wrapped by the error of a message that could not be
+	// applied and was saved as a dead letter instead
+	ErrDeadLettered       = errors.New("message dead-lettered")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+// DeadLetter is a message the service gave up on, kept so that it can be
+// inspected and sent again. A message dead-lettered again, by a failed replay
+// or because it was sent and failed again, keeps its dead letter and counts
+// one more attempt.
+type DeadLetter struct {
+	ID            int64           `json:"id"`
+	Channel       string          `json:"channel"`
+	MessageNumber int64           `json:"messageNumber"`
+	MessageType   string          `json:"messageType"`
+	Message       json.RawMessage `json:"message" swaggertype:"object"` // Whole message as received
+	Reason        string          `json:"reason"`                       // Why the last attempt failed
+	Attempts      int             `json:"attempts"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"` // Time of the last attempt
+}
+
+// DeadLetterQuery filters dead letters. Zero fields match every dead letter.
+type DeadLetterQuery struct {
+	Channel string
+	Before  time.Time // Only dead letters created before this time
+	Limit   int
+}
+
+// ReplayResult is what became of a replayed dead letter: applied, buffered,
+// duplicate or dead_lettered like the message of a receipt, or error
+type ReplayResult struct {
+	ID            int64  `json:"id"`
+	Channel       string `json:"channel"`
+	MessageNumber int64  `json:"messageNumber"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+type DeadLetterRepository interface {
+	// Save records a failed attempt at a message, in the tenant of ctx. The
+	// dead letter of the same channel and message number is updated if there
+	// is one.
+	Save(ctx context.Context, deadLetter *DeadLetter) error
+	FindByID(ctx context.Context, id int64) (*DeadLetter, error)
+	// List returns the matching dead letters of the tenant, by channel and
+	// message number
+	List(ctx context.Context, query DeadLetterQuery) ([]*DeadLetter, error)
+	Delete(ctx context.Context, id int64) error
+	// DeleteMatching deletes the matching dead letters of the tenant, and
+	// returns how many were deleted
+	DeleteMatching(ctx context.Context, query DeadLetterQuery) (int64, error)
+}
@@ -0,0 +1,333 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MessageHandler defines a message type: its payload, how the payload is
+// validated and how it changes a rocket
+type MessageHandler interface {
+	// MessageType is the metadata.messageType the handler is registered for
+	MessageType() string
+	// NewPayload returns a pointer to an empty payload struct to decode into
+	NewPayload() interface{}
+	// Validate checks a decoded payload and returns its invalid fields, named
+	// by their JSON field name
+	Validate(payload interface{}) []FieldError
+	// Apply returns the rocket after the message, or nil if the message
+	// changes nothing. It is only called for the transitions the state
+	// machine allows, which then sets the status of the returned rocket.
+	// rocket is nil when the channel has no rocket yet.
+	Apply(rocket *Rocket, message *RocketMessage) (*Rocket, error)
+}
+
+// MessageType describes a registered message type and its payload
+type MessageType struct {
+	Type          string        `json:"type"`
+	SchemaVersion int           `json:"schemaVersion"` // Current schema version, the one the schema describes
+	Schema        PayloadSchema `json:"schema"`
+}
+
+// PayloadSchema is the JSON Schema of a message payload
+type PayloadSchema struct {
+	Type                 string                    `json:"type"`
+	Properties           map[string]PropertySchema `json:"properties"`
+	Required             []string                  `json:"required"`
+	AdditionalProperties bool                      `json:"additionalProperties"`
+}
+
+// PropertySchema is the JSON Schema of a payload field
+type PropertySchema struct {
+	Type string `json:"type"`
+}
+
+// payloadField is a field of a payload struct
+type payloadField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+type registeredHandler struct {
+	handler   MessageHandler
+	fields    []payloadField
+	schema    PayloadSchema
+	version   int                                                    // Current schema version
+	upcasters map[int]func(json.RawMessage) (json.RawMessage, error) // By the version they upcast from
+}
+
+// MessageRegistry holds the handler of each message type
+type MessageRegistry struct {
+	handlers map[string]*registeredHandler
+}
+
+// NewMessageRegistry creates a registry with the given handlers
+func NewMessageRegistry(handlers ...MessageHandler) (*MessageRegistry, error) {
+	registry := &MessageRegistry{handlers: make(map[string]*registeredHandler)}
+	for _, handler := range handlers {
+		if err := registry.Register(handler); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Register adds the handler of a message type. The payload must be a pointer
+// to a struct whose fields have JSON tags.
+func (r *MessageRegistry) Register(handler MessageHandler) error {
+	messageType := handler.MessageType()
+	if messageType == "" {
+		return errors.New("message type is required")
+	}
+	if _, exists := r.handlers[messageType]; exists {
+		return fmt.Errorf("message type %s is already registered", messageType)
+	}
+
+	fields, err := payloadFields(handler.NewPayload())
+	if err != nil {
+		return fmt.Errorf("invalid payload for message type %s: %w", messageType, err)
+	}
+
+	r.handlers[messageType] = &registeredHandler{
+		handler: handler,
+		fields:  fields,
+		schema:  payloadSchema(fields),
+		version: FirstSchemaVersion,
+	}
+	return nil
+}
+
+// Handler returns the handler of a message type
+func (r *MessageRegistry) Handler(messageType string) (MessageHandler, bool) {
+	registered, ok := r.handlers[messageType]
+	if !ok {
+		return nil, false
+	}
+	return registered.handler, true
+}
+
+// Types returns the registered message types, sorted by type
+func (r *MessageRegistry) Types() []MessageType {
+	types := make([]MessageType, 0, len(r.handlers))
+	for messageType, registered := range r.handlers {
+		types = append(types, MessageType{Type: messageType, SchemaVersion: registered.version, Schema: registered.schema})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+// Validate checks the metadata and payload of a message and decodes the
+// payload, upcast to the current schema version, into message.Payload. It
+// returns a *ValidationError listing every invalid field.
+func (r *MessageRegistry) Validate(message *RocketMessage) error {
+	fields := validateMetadata(message.Metadata)
+
+	messageType := message.Metadata.MessageType
+	registered, ok := r.handlers[messageType]
+	switch {
+	case messageType == "":
+		fields = append(fields, FieldError{Field: "metadata.messageType", Message: "is required"})
+	case !ok:
+		fields = append(fields, FieldError{Field: "metadata.messageType", Message: fmt.Sprintf("unknown message type %q", messageType)})
+	case message.Metadata.SchemaVersion < 0:
+		// Reported with the metadata, the payload cannot be upcast
+	default:
+		payload, payloadErrors := registered.decode(message.Metadata.Version(), message.Message)
+		fields = append(fields, payloadErrors...)
+		if len(payloadErrors) == 0 {
+			message.Payload = payload
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// Decode decodes the payload of a message, upcast to the current schema
+// version, into message.Payload, unless it has been decoded already. The
+// original payload is left in message.Message.
+func (r *MessageRegistry) Decode(message *RocketMessage) (MessageHandler, error) {
+	registered, ok := r.handlers[message.Metadata.MessageType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type: %s", message.Metadata.MessageType)
+	}
+
+	if message.Payload == nil {
+		payload, fields := registered.decode(message.Metadata.Version(), message.Message)
+		if len(fields) > 0 {
+			return nil, &ValidationError{Fields: fields}
+		}
+		message.Payload = payload
+	}
+
+	return registered.handler, nil
+}
+
+// decode upcasts a payload of the given schema version and decodes it,
+// naming invalid fields from the message root
+func (h *registeredHandler) decode(version int, raw json.RawMessage) (interface{}, []FieldError) {
+	if version > h.version {
+		return nil, []FieldError{{Field: "metadata.schemaVersion", Message: fmt.Sprintf("must not be greater than %d, the current version of %s", h.version, h.handler.MessageType())}}
+	}
+
+	raw, err := h.upcast(version, raw)
+	if err != nil {
+		return nil, []FieldError{{Field: "message", Message: err.Error()}}
+	}
+
+	payload, fields := h.decodePayload(raw)
+	for i := range fields {
+		fields[i].Field = joinField("message", fields[i].Field)
+	}
+	return payload, fields
+}
+
+// decodePayload decodes a payload in a single pass. Only when that fails is
+// the payload inspected field by field, so that every problem is reported.
+func (h *registeredHandler) decodePayload(raw json.RawMessage) (interface{}, []FieldError) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, []FieldError{{Message: "is required"}}
+	}
+
+	payload := h.handler.NewPayload()
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err == nil {
+		return payload, h.handler.Validate(payload)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &values); err != nil || values == nil {
+		return nil, []FieldError{{Message: "must be a JSON object"}}
+	}
+
+	// Decode what can be, so that the other fields are still validated
+	payload = h.handler.NewPayload()
+	_ = json.Unmarshal(trimmed, payload)
+
+	invalid := make(map[string]string)
+	for _, field := range h.handler.Validate(payload) {
+		invalid[field.Field] = field.Message
+	}
+
+	var fields []FieldError
+	known := make(map[string]bool, len(h.fields))
+	for _, field := range h.fields {
+		known[field.name] = true
+
+		value, present := values[field.name]
+		if present && json.Unmarshal(value, reflect.New(field.typ).Interface()) != nil {
+			fields = append(fields, FieldError{Field: field.name, Message: "must be " + article(jsonType(field.typ))})
+		} else if message, ok := invalid[field.name]; ok {
+			fields = append(fields, FieldError{Field: field.name, Message: message})
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fields = append(fields, FieldError{Field: name, Message: "is not a field of " + h.handler.MessageType()})
+	}
+
+	if len(fields) == 0 {
+		fields = append(fields, FieldError{Message: "is not a valid " + h.handler.MessageType() + " payload"})
+	}
+	return nil, fields
+}
+
+// payloadFields lists the JSON fields of a payload struct
+func payloadFields(payload interface{}) ([]payloadField, error) {
+	typ := reflect.TypeOf(payload)
+	if typ == nil || typ.Kind() != reflect.Pointer || typ.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("payload must be a pointer to a struct")
+	}
+	typ = typ.Elem()
+
+	var fields []payloadField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, payloadField{
+			name:     name,
+			typ:      field.Type,
+			required: !strings.Contains(options, "omitempty"),
+		})
+	}
+	return fields, nil
+}
+
+func payloadSchema(fields []payloadField) PayloadSchema {
+	schema := PayloadSchema{
+		Type:       "object",
+		Properties: make(map[string]PropertySchema, len(fields)),
+		Required:   []string{},
+	}
+	for _, field := range fields {
+		schema.Properties[field.name] = PropertySchema{Type: jsonType(field.typ)}
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema
+}
+
+// jsonType returns the JSON Schema type of a Go type
+func jsonType(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Pointer:
+		return jsonType(typ.Elem())
+	default:
+		return "object"
+	}
+}
+
+func article(jsonType string) string {
+	if jsonType == "integer" || jsonType == "object" || jsonType == "array" {
+		return "an " + jsonType
+	}
+	return "a " + jsonType
+}
+
+func joinField(prefix string, field string) string {
+	if field == "" {
+		return prefix
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Scopes granted to callers by their token
+const (
+	ScopeRocketsRead   = "rockets:read"
+	ScopeMessagesWrite = "messages:write"
+	// ScopeAdmin grants every other scope
+	ScopeAdmin = "admin"
+)
+
+var (
+	// ErrInvalidToken is returned when a bearer token is missing, malformed,
+	// expired or not signed by a trusted key
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInsufficientScope is returned when a caller lacks the scope of a
+	// route
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Subject string
+	Scopes  []string
+}
+
+// HasScope reports whether the caller was granted the scope, directly or
+// through the admin scope
+func (i *Identity) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope) || slices.Contains(i.Scopes, ScopeAdmin)
+}
+
+// TokenValidator authenticates callers from their bearer token
+type TokenValidator interface {
+	Validate(token string) (*Identity, error)
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of ctx carrying the caller's identity
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity carried by ctx, nil if none
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// ActorFromContext returns the subject of the identity carried by ctx, empty
+// when the caller is not authenticated, to record who caused a change
+func ActorFromContext(ctx context.Context) string {
+	if identity := IdentityFromContext(ctx); identity != nil {
+		return identity.Subject
+	}
+	return ""
+}
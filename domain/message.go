@@ -2,27 +2,57 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
+var (
+	// ErrChannelNotFound is returned for a channel without processed or
+	// buffered messages
+	ErrChannelNotFound = errors.New("channel not found")
+	// ErrAlreadyProcessed is returned when a message is marked as processed
+	// while another delivery of its number was processed concurrently
+	ErrAlreadyProcessed = errors.New("message already processed")
+)
+
 const (
 	TypeRocketLaunched       = "RocketLaunched"
 	TypeRocketSpeedIncreased = "RocketSpeedIncreased"
 	TypeRocketSpeedDecreased = "RocketSpeedDecreased"
 	TypeRocketExploded       = "RocketExploded"
 	TypeRocketMissionChanged = "RocketMissionChanged"
+	TypeRocketLanded         = "RocketLanded"
+	TypeRocketAborted        = "RocketAborted"
+	TypeRocketDocked         = "RocketDocked"
+	TypeRocketTelemetry      = "RocketTelemetry"
 )
 
+// FirstSchemaVersion is the schema version of messages that do not set one
+const FirstSchemaVersion = 1
+
 type MessageMetadata struct {
 	Channel       string    `json:"channel"`
 	MessageNumber int64     `json:"messageNumber"`
 	MessageTime   time.Time `json:"messageTime"`
 	MessageType   string    `json:"messageType"`
+	SchemaVersion int       `json:"schemaVersion,omitempty"` // Version of the payload shape, FirstSchemaVersion when unset
+}
+
+// Version returns the schema version of the payload
+func (m MessageMetadata) Version() int {
+	if m.SchemaVersion == 0 {
+		return FirstSchemaVersion
+	}
+	return m.SchemaVersion
 }
 
 type RocketMessage struct {
 	Metadata MessageMetadata `json:"metadata"`
-	Message  interface{}     `json:"message"`
+	Message  json.RawMessage `json:"message" swaggertype:"object"`
+	// Payload is the decoded message, set once the message type's handler
+	// has decoded it
+	Payload interface{} `json:"-"`
 }
 
 type RocketLaunchedMessage struct {
@@ -47,7 +77,97 @@ type RocketMissionChangedMessage struct {
 	NewMission string `json:"newMission"`
 }
 
+type RocketLandedMessage struct {
+	Site string `json:"site"`
+}
+
+type RocketAbortedMessage struct {
+	Reason string `json:"reason"`
+}
+
+type RocketDockedMessage struct {
+	Station string `json:"station"`
+}
+
+// RocketTelemetryMessage fields are pointers so that missing values are told
+// apart from zeros
+type RocketTelemetryMessage struct {
+	Altitude  *float64 `json:"altitude"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Fuel      *float64 `json:"fuel"`
+	Heading   *float64 `json:"heading"`
+}
+
+// MessageEvent is an applied message kept as part of a rocket's history
+type MessageEvent struct {
+	Channel       string          `json:"channel"`
+	MessageNumber int64           `json:"messageNumber"`
+	MessageType   string          `json:"messageType"`
+	MessageTime   time.Time       `json:"messageTime"`
+	SchemaVersion int             `json:"schemaVersion"` // Version the payload was received with
+	Payload       json.RawMessage `json:"payload"`       // Payload as received, before upcasting
+	ProcessedAt   time.Time       `json:"processedAt"`
+}
+
+// MessageRange is an inclusive range of message numbers
+type MessageRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// MissingRanges returns the ranges of message numbers after lastProcessed that
+// are neither processed nor buffered. buffered must be sorted.
+func MissingRanges(lastProcessed int64, buffered []int64) []MessageRange {
+	var missing []MessageRange
+
+	next := lastProcessed + 1
+	for _, number := range buffered {
+		if number > next {
+			missing = append(missing, MessageRange{From: next, To: number - 1})
+		}
+		if number >= next {
+			next = number + 1
+		}
+	}
+
+	return missing
+}
+
+// ChannelStatus describes how far the messages of a channel were processed,
+// and the messages waiting for a gap to be filled. A channel is stuck when its
+// oldest buffered message keeps aging, or when it stops receiving messages.
+type ChannelStatus struct {
+	Channel                  string         `json:"channel"`
+	LastProcessed            int64          `json:"lastProcessed"`
+	Buffered                 []int64        `json:"buffered"`
+	Missing                  []MessageRange `json:"missing"`
+	LastMessageAt            *time.Time     `json:"lastMessageAt,omitempty"`    // When the last message was processed or buffered
+	SinceLastMessageSeconds  int64          `json:"sinceLastMessageSeconds"`    // Time since LastMessageAt
+	OldestBufferedAt         *time.Time     `json:"oldestBufferedAt,omitempty"` // When the oldest buffered message was received
+	OldestBufferedAgeSeconds int64          `json:"oldestBufferedAgeSeconds"`   // Time since OldestBufferedAt, 0 without buffered messages
+}
+
+// ChannelProgress is the last processed message of a channel
+type ChannelProgress struct {
+	Channel         string
+	LastProcessed   int64
+	LastProcessedAt time.Time
+}
+
 type MessageRepository interface {
-	MarkAsProcessed(ctx context.Context, channel string, messageNumber int64) error
+	MarkAsProcessed(ctx context.Context, channel string, messageNumber int64, payloadHash string) error
 	FindLastMessageNumber(ctx context.Context, channel string) (int64, error)
+	FindPayloadHash(ctx context.Context, channel string, messageNumber int64) (string, error)
+	// ListChannelProgress returns the last processed message of every channel,
+	// sorted by channel
+	ListChannelProgress(ctx context.Context) ([]*ChannelProgress, error)
+	// FindChannelProgress returns the last processed message of a channel, or
+	// nil when none was processed
+	FindChannelProgress(ctx context.Context, channel string) (*ChannelProgress, error)
+	SaveEvent(ctx context.Context, message *RocketMessage) error
+	ListEvents(ctx context.Context, channel string, afterNumber int64, limit int) ([]*MessageEvent, error)
+	// DeleteChannel deletes the processed messages and events of a channel,
+	// and returns how many messages were processed
+	DeleteChannel(ctx context.Context, channel string) (int64, error)
 }
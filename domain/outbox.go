@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// EventTypeChannelReset is the event type of a channel reset by an operator,
+// whose rocket and history were deleted. Its payload is null.
+const EventTypeChannelReset = "ChannelReset"
+
+// OutboxEvent is a rocket state change waiting to be published. It is written
+// in the same transaction as the change itself, so no committed change is
+// ever lost, and relayed to a broker afterwards. The relay publishes the
+// events of every tenant, each event naming its own.
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	TenantID      string          `json:"tenantId"`
+	Channel       string          `json:"channel"`
+	EventType     string          `json:"eventType"`             // Message type that caused the change
+	MessageNumber int64           `json:"messageNumber"`         // Message number that caused the change
+	Payload       json.RawMessage `json:"payload"`               // Rocket state after the change
+	CreatedAt     time.Time       `json:"createdAt"`             // Time the change was committed
+	PublishedAt   *time.Time      `json:"publishedAt,omitempty"` // Time the event was published, if it was
+	Actor         string          `json:"actor,omitempty"`       // Authenticated caller whose request caused the change, if any
+}
+
+type OutboxRepository interface {
+	Add(ctx context.Context, event *OutboxEvent) error
+	FetchUnpublished(ctx context.Context, limit int) ([]*OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EventPublisher delivers outbox events to other services
+type EventPublisher interface {
+	Publish(ctx context.Context, event *OutboxEvent) error
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMissingSignature is returned when a message comes without the
+	// producer ID, timestamp, nonce or signature
+	ErrMissingSignature = errors.New("missing signature")
+	// ErrUnknownProducer is returned when a message names a producer that has
+	// no credentials
+	ErrUnknownProducer = errors.New("unknown producer")
+	// ErrInvalidSignature is returned when a signature does not match the
+	// message and the producer's secret
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrStaleTimestamp is returned when a message is signed too far from the
+	// current time, or with a timestamp that is not Unix seconds
+	ErrStaleTimestamp = errors.New("timestamp outside the allowed clock skew")
+	// ErrReplayedNonce is returned when a producer uses a nonce again
+	ErrReplayedNonce = errors.New("nonce already used")
+	// ErrChannelForbidden is returned when a producer sends a message to a
+	// channel outside its patterns
+	ErrChannelForbidden = errors.New("channel not allowed for producer")
+)
+
+// Producer is a client allowed to send messages, which signs them with its
+// secret
+type Producer struct {
+	ID     string
+	Secret string
+	// Channel patterns the producer may send to, in path.Match syntax. Empty
+	// allows every channel.
+	Channels []string
+}
+
+// Allows reports whether the producer may send messages to the channel
+func (p Producer) Allows(channel string) bool {
+	if len(p.Channels) == 0 {
+		return true
+	}
+	for _, pattern := range p.Channels {
+		if matched, _ := path.Match(pattern, channel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SignMessage returns the hex encoded HMAC-SHA256 of the timestamp, nonce and
+// body of a message, keyed by the producer's secret
+func SignMessage(secret string, timestamp string, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureVerifier verifies the signature of messages sent by producers.
+// It accepts a timestamp within the allowed clock skew of the current time,
+// and remembers the nonces seen in that window so that a captured message
+// cannot be sent again.
+type SignatureVerifier struct {
+	producers map[string]Producer
+	maxSkew   time.Duration
+	now       func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // Producer ID and nonce to expiry
+	swept  time.Time
+}
+
+// NewSignatureVerifier creates a verifier for the given producers
+func NewSignatureVerifier(producers []Producer, maxSkew time.Duration) (*SignatureVerifier, error) {
+	if maxSkew <= 0 {
+		return nil, errors.New("max clock skew must be positive")
+	}
+
+	byID := make(map[string]Producer, len(producers))
+	for _, producer := range producers {
+		if producer.ID == "" {
+			return nil, errors.New("producer ID is required")
+		}
+		if producer.Secret == "" {
+			return nil, fmt.Errorf("secret of producer %s is required", producer.ID)
+		}
+		for _, pattern := range producer.Channels {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid channel pattern %q of producer %s: %w", pattern, producer.ID, err)
+			}
+		}
+		if _, ok := byID[producer.ID]; ok {
+			return nil, fmt.Errorf("duplicate producer %s", producer.ID)
+		}
+		byID[producer.ID] = producer
+	}
+
+	return &SignatureVerifier{
+		producers: byID,
+		maxSkew:   maxSkew,
+		now:       time.Now,
+		nonces:    make(map[string]time.Time),
+	}, nil
+}
+
+// Verify checks a message's signature and returns its producer. The nonce is
+// only recorded once the rest of the message is verified, so a forged
+// message cannot burn the nonce of a genuine one.
+func (v *SignatureVerifier) Verify(producerID string, timestamp string, nonce string, signature string, body []byte) (Producer, error) {
+	if producerID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return Producer{}, ErrMissingSignature
+	}
+
+	producer, ok := v.producers[producerID]
+	if !ok {
+		return Producer{}, ErrUnknownProducer
+	}
+
+	expected := SignMessage(producer.Secret, timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return Producer{}, ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Producer{}, ErrStaleTimestamp
+	}
+	signedAt := time.Unix(seconds, 0)
+	now := v.now()
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return Producer{}, ErrStaleTimestamp
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.sweep(now)
+
+	key := producerID + "\x00" + nonce
+	if expiry, ok := v.nonces[key]; ok && now.Before(expiry) {
+		return Producer{}, ErrReplayedNonce
+	}
+	// The nonce must be remembered for as long as its timestamp is accepted
+	v.nonces[key] = signedAt.Add(v.maxSkew)
+
+	return producer, nil
+}
+
+// sweep forgets the expired nonces, at most once per clock skew window
+func (v *SignatureVerifier) sweep(now time.Time) {
+	if now.Sub(v.swept) < v.maxSkew {
+		return
+	}
+	for key, expiry := range v.nonces {
+		if !now.Before(expiry) {
+			delete(v.nonces, key)
+		}
+	}
+	v.swept = now
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	ReceiptStatusQueued       = "queued"        // Waiting for a worker
+	ReceiptStatusApplied      = "applied"       // Applied to its rocket
+	ReceiptStatusBuffered     = "buffered"      // Waiting for the messages before it
+	ReceiptStatusDuplicate    = "duplicate"     // Already processed, skipped
+	ReceiptStatusConflict     = "conflict"      // Number already processed with a different content, skipped
+	ReceiptStatusDeadLettered = "dead_lettered" // Given up on, and kept in the dead-letter store
+	ReceiptStatusFailed       = "failed"        // Could not be processed, see the error
+	ReceiptStatusDiscarded    = "discarded"     // Dropped from the buffer by an operator, never applied
+)
+
+var (
+	ErrReceiptNotFound = errors.New("receipt not found")
+)
+
+// Receipt tracks a message accepted for asynchronous processing. The queue of
+// pending messages is durable: receipts are stored with their message, and
+// the queued ones are processed again after a restart.
+type Receipt struct {
+	ID            string          `json:"id"`
+	Channel       string          `json:"channel"`
+	MessageNumber int64           `json:"messageNumber"`
+	MessageType   string          `json:"messageType"`
+	Status        string          `json:"status"`
+	Error         string          `json:"error,omitempty"` // Why the message failed, if it did
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	Actor         string          `json:"actor,omitempty"` // Authenticated caller that sent the message, if any
+	Seq           int64           `json:"-"`               // Position in the queue
+	TenantID      string          `json:"-"`
+	Message       json.RawMessage `json:"-"` // Whole message as received
+}
+
+type ReceiptRepository interface {
+	// Save queues a receipt with its message, in the tenant of ctx
+	Save(ctx context.Context, receipt *Receipt) error
+	FindByID(ctx context.Context, id string) (*Receipt, error)
+	// FetchQueued returns the queued receipts of every tenant after seq, in
+	// queue order
+	FetchQueued(ctx context.Context, afterSeq int64, limit int) ([]*Receipt, error)
+	UpdateStatus(ctx context.Context, id string, status string, reason string) error
+	// DeleteProcessedBefore deletes the receipts processed before a time,
+	// except those of messages still buffered
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SettleFunc is told what finally became of a buffered message when it leaves
+// the buffer: applied, dead-lettered or discarded, and why when it was not
+// applied. It is called once, from whichever goroutine settles the message.
+type SettleFunc func(status string, reason string)
+
+type settleKey struct{}
+
+// ContextWithSettle returns a copy of ctx carrying settle. A message buffered
+// while processed with this context calls settle once it leaves the buffer,
+// so that its sender can wait for it rather than take it as applied.
+func ContextWithSettle(ctx context.Context, settle SettleFunc) context.Context {
+	return context.WithValue(ctx, settleKey{}, settle)
+}
+
+// SettleFromContext returns the SettleFunc carried by ctx, nil if none
+func SettleFromContext(ctx context.Context) SettleFunc {
+	settle, _ := ctx.Value(settleKey{}).(SettleFunc)
+	return settle
+}
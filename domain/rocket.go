@@ -9,28 +9,85 @@ import (
 const (
 	RocketStatusLaunched = "Launched"
 	RocketStatusExploded = "Exploded"
+	RocketStatusLanded   = "Landed"
+	RocketStatusAborted  = "Aborted"
+	RocketStatusDocked   = "Docked"
 )
 
+// RocketStatuses are all the statuses a rocket can have
+var RocketStatuses = []string{
+	RocketStatusLaunched,
+	RocketStatusExploded,
+	RocketStatusLanded,
+	RocketStatusAborted,
+	RocketStatusDocked,
+}
+
 var (
 	ErrRocketNotFound = errors.New("rocket not found")
 )
 
 type Rocket struct {
-	Channel     string     `json:"channel"`              // Unique identifier for the rocket
-	Type        string     `json:"type"`                 // Type of rocket
-	Speed       int        `json:"speed"`                // Current speed of the rocket
-	Mission     string     `json:"mission"`              // Current mission
-	LaunchTime  time.Time  `json:"launchTime"`           // Time when the rocket was launched
-	Status      string     `json:"status"`               // Current status
-	ExplodedAt  *time.Time `json:"explodedAt,omitempty"` // Time when the rocket exploded, if applicable
-	Reason      string     `json:"reason,omitempty"`     // Reason for explosion, if applicable
-	LastUpdated time.Time  `json:"lastUpdated"`          // Last time the rocket state was updated
-	LastMessage int64      `json:"lastMessage"`          // Last message number processed
+	Channel     string     `json:"channel"`               // Unique identifier for the rocket
+	Type        string     `json:"type"`                  // Type of rocket
+	Speed       int        `json:"speed"`                 // Current speed of the rocket
+	Mission     string     `json:"mission"`               // Current mission
+	LaunchTime  time.Time  `json:"launchTime"`            // Time when the rocket was launched
+	Status      string     `json:"status"`                // Current status: Launched, Exploded, Landed, Aborted or Docked
+	ExplodedAt  *time.Time `json:"explodedAt,omitempty"`  // Time when the rocket exploded, if applicable
+	Reason      string     `json:"reason,omitempty"`      // Reason for explosion or abort, if applicable
+	LandedAt    *time.Time `json:"landedAt,omitempty"`    // Time when the rocket landed, if applicable
+	LandingSite string     `json:"landingSite,omitempty"` // Where the rocket landed, if applicable
+	AbortedAt   *time.Time `json:"abortedAt,omitempty"`   // Time when the mission was aborted, if applicable
+	DockedAt    *time.Time `json:"dockedAt,omitempty"`    // Time when the rocket docked, if applicable
+	Station     string     `json:"station,omitempty"`     // Station the rocket docked with, if applicable
+	Telemetry   *Telemetry `json:"telemetry,omitempty"`   // Latest telemetry reading, if any
+	LastUpdated time.Time  `json:"lastUpdated"`           // Last time the rocket state was updated
+	LastMessage int64      `json:"lastMessage"`           // Last message number processed
+}
+
+// IsValidRocketStatus reports whether status is one of the rocket statuses
+func IsValidRocketStatus(status string) bool {
+	for _, s := range RocketStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RocketQuery filters, sorts and paginates a rocket search. Empty filters
+// match every rocket and a zero Limit returns all matches.
+type RocketQuery struct {
+	Status  string
+	Type    string
+	Mission string
+	SortBy  string
+	Order   string
+	Limit   int
+	Offset  int
+}
+
+// RocketPage is one page of a rocket search along with the total match count
+type RocketPage struct {
+	Rockets []*Rocket `json:"rockets"`
+	Total   int       `json:"total"`
+}
+
+// FleetStats aggregates the state of every rocket
+type FleetStats struct {
+	Total        int            `json:"total"`
+	AverageSpeed float64        `json:"averageSpeed"`
+	MaxSpeed     int            `json:"maxSpeed"`
+	ByStatus     map[string]int `json:"byStatus"`
+	ByType       map[string]int `json:"byType"`
 }
 
 type RocketRepository interface {
 	GetByChannel(ctx context.Context, channel string) (*Rocket, error)
 	GetAll(ctx context.Context, sortBy string, order string) ([]*Rocket, error)
+	Search(ctx context.Context, query RocketQuery) ([]*Rocket, int, error)
+	Stats(ctx context.Context) (*FleetStats, error)
 	Save(ctx context.Context, rocket *Rocket) error
 	Update(ctx context.Context, rocket *Rocket) error
 	Delete(ctx context.Context, channel string) error
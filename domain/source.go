@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"context"
+)
+
+// OffsetRepository persists how far each message source has been consumed,
+// so that a restarted source resumes where it left off.
+type OffsetRepository interface {
+	GetOffset(ctx context.Context, source string) (int64, error)
+	SaveOffset(ctx context.Context, source string, offset int64) error
+	DeleteOffset(ctx context.Context, source string) error
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RocketStatusNotLaunched is the status of a channel whose rocket has not
+// been launched yet. Rockets never have it.
+const RocketStatusNotLaunched = "NotLaunched"
+
+// Transition allows a message type to move a rocket from one status to another
+type Transition struct {
+	From        string
+	MessageType string
+	To          string
+}
+
+// RocketTransitions are the transitions of the built-in message types
+var RocketTransitions = []Transition{
+	{RocketStatusNotLaunched, TypeRocketLaunched, RocketStatusLaunched},
+
+	{RocketStatusLaunched, TypeRocketSpeedIncreased, RocketStatusLaunched},
+	{RocketStatusLaunched, TypeRocketSpeedDecreased, RocketStatusLaunched},
+	{RocketStatusLaunched, TypeRocketMissionChanged, RocketStatusLaunched},
+	{RocketStatusLaunched, TypeRocketTelemetry, RocketStatusLaunched},
+	{RocketStatusLaunched, TypeRocketExploded, RocketStatusExploded},
+	{RocketStatusLaunched, TypeRocketLanded, RocketStatusLanded},
+	{RocketStatusLaunched, TypeRocketAborted, RocketStatusAborted},
+	{RocketStatusLaunched, TypeRocketDocked, RocketStatusDocked},
+
+	// An exploded rocket is gone, so it has no transition
+
+	// A landed rocket stays on the ground, but can be assigned a new mission
+	{RocketStatusLanded, TypeRocketMissionChanged, RocketStatusLanded},
+	{RocketStatusLanded, TypeRocketTelemetry, RocketStatusLanded},
+
+	// An aborted mission is over, but the rocket flies until it lands or explodes
+	{RocketStatusAborted, TypeRocketSpeedIncreased, RocketStatusAborted},
+	{RocketStatusAborted, TypeRocketSpeedDecreased, RocketStatusAborted},
+	{RocketStatusAborted, TypeRocketTelemetry, RocketStatusAborted},
+	{RocketStatusAborted, TypeRocketLanded, RocketStatusLanded},
+	{RocketStatusAborted, TypeRocketExploded, RocketStatusExploded},
+
+	// A docked rocket stays at its station, but can be given a new mission
+	{RocketStatusDocked, TypeRocketMissionChanged, RocketStatusDocked},
+	{RocketStatusDocked, TypeRocketTelemetry, RocketStatusDocked},
+	{RocketStatusDocked, TypeRocketExploded, RocketStatusExploded},
+}
+
+// StateMachine holds the allowed transitions between rocket statuses. A
+// message whose type has no transition from the rocket's status is rejected.
+type StateMachine struct {
+	transitions map[string]map[string]string // From status, then message type, to status
+}
+
+// NewStateMachine creates a state machine allowing the given transitions
+func NewStateMachine(transitions ...Transition) *StateMachine {
+	machine := &StateMachine{transitions: make(map[string]map[string]string)}
+	for _, transition := range transitions {
+		machine.Allow(transition)
+	}
+	return machine
+}
+
+// Allow adds a transition, replacing any transition of the same message type
+// from the same status
+func (m *StateMachine) Allow(transition Transition) {
+	if m.transitions[transition.From] == nil {
+		m.transitions[transition.From] = make(map[string]string)
+	}
+	m.transitions[transition.From][transition.MessageType] = transition.To
+}
+
+// Next returns the status a message of the given type moves the rocket to.
+// rocket is nil when the channel has no rocket yet. It fails with a
+// *TransitionError when the transition is not allowed.
+func (m *StateMachine) Next(rocket *Rocket, messageType string) (string, error) {
+	from := RocketStatusNotLaunched
+	if rocket != nil {
+		from = rocket.Status
+	}
+
+	to, ok := m.transitions[from][messageType]
+	if !ok {
+		return "", &TransitionError{Status: from, MessageType: messageType}
+	}
+	return to, nil
+}
+
+// TransitionError reports a message the state machine does not allow
+type TransitionError struct {
+	Status      string
+	MessageType string
+}
+
+func (e *TransitionError) Error() string {
+	switch {
+	case e.Status == RocketStatusNotLaunched:
+		return fmt.Sprintf("%s received before the rocket was launched", e.MessageType)
+	case e.MessageType == TypeRocketLaunched:
+		return "rocket already launched"
+	default:
+		return fmt.Sprintf("%s not allowed in status %s", e.MessageType, e.Status)
+	}
+}
+
+// Rejection is a message the state machine rejected. The message is
+// processed without changing the rocket, and the rejection is kept so that
+// operators can find the producers sending invalid sequences.
+type Rejection struct {
+	ID            int64     `json:"id"`
+	Channel       string    `json:"channel"`
+	MessageNumber int64     `json:"messageNumber"`
+	MessageType   string    `json:"messageType"`
+	MessageTime   time.Time `json:"messageTime"`
+	Status        string    `json:"status"` // Status of the rocket when the message arrived
+	Reason        string    `json:"reason"`
+	RejectedAt    time.Time `json:"rejectedAt"`
+	Actor         string    `json:"actor,omitempty"` // Authenticated caller that sent the message, if any
+}
+
+// RejectionQuery filters the rejections, newest first. Empty filters match
+// every rejection.
+type RejectionQuery struct {
+	Channel     string
+	MessageType string
+	Limit       int
+}
+
+type RejectionRepository interface {
+	Save(ctx context.Context, rejection *Rejection) error
+	List(ctx context.Context, query RejectionQuery) ([]*Rejection, error)
+	// DeleteChannel deletes the rejections of a channel, and returns how many
+	// were deleted
+	DeleteChannel(ctx context.Context, channel string) (int64, error)
+}
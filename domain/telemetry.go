@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Telemetry is a reading of the flight instruments of a rocket
+type Telemetry struct {
+	Time      time.Time `json:"time"`      // Message time of the reading
+	Altitude  float64   `json:"altitude"`  // Meters above the surface
+	Latitude  float64   `json:"latitude"`  // Degrees, from -90 to 90
+	Longitude float64   `json:"longitude"` // Degrees, from -180 to 180
+	Fuel      float64   `json:"fuel"`      // Percentage of the fuel capacity left
+	Heading   float64   `json:"heading"`   // Degrees clockwise from north, from 0 to 360
+}
+
+// TelemetryQuery selects the telemetry history of a rocket. Zero times leave
+// the range open, and a zero Interval returns every reading.
+type TelemetryQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval time.Duration // Keep only the last reading of each interval
+}
+
+type TelemetryRepository interface {
+	Save(ctx context.Context, channel string, messageNumber int64, reading *Telemetry) error
+	List(ctx context.Context, channel string, from time.Time, to time.Time) ([]*Telemetry, error)
+	DeleteChannel(ctx context.Context, channel string) error
+}
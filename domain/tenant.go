@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// DefaultTenantID is the tenant of requests that name none, and of the data
+// stored before tenants were introduced
+const DefaultTenantID = "default"
+
+var (
+	// ErrInvalidAPIKey is returned when API keys are configured and a request
+	// has no key or an unknown one
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrTenantForbidden is returned when a request names a tenant other than
+	// the one of its API key
+	ErrTenantForbidden = errors.New("tenant not allowed for API key")
+)
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateTenantID checks that a tenant ID is 1 to 64 letters, digits,
+// underscores or dashes, starting with a letter or digit
+func ValidateTenantID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return fmt.Errorf("invalid tenant ID %q", tenantID)
+	}
+	return nil
+}
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a copy of ctx carrying the tenant. Repositories
+// that receive this context only read and write the tenant's data.
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant carried by ctx, DefaultTenantID if none
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+// TenantResolver resolves the tenant of a request. When API keys are
+// configured every request needs one, and its key decides the tenant.
+// Otherwise the tenant is the one the request names, DefaultTenantID if none.
+type TenantResolver struct {
+	apiKeys map[string]string // API key to tenant ID
+}
+
+// NewTenantResolver creates a resolver mapping the given API keys to their
+// tenant. An empty map leaves the requests free to name their tenant.
+func NewTenantResolver(apiKeys map[string]string) (*TenantResolver, error) {
+	keys := make(map[string]string, len(apiKeys))
+	for key, tenantID := range apiKeys {
+		if key == "" {
+			return nil, errors.New("API key is required")
+		}
+		if err := ValidateTenantID(tenantID); err != nil {
+			return nil, err
+		}
+		keys[key] = tenantID
+	}
+	return &TenantResolver{apiKeys: keys}, nil
+}
+
+// Resolve returns the tenant of a request from its API key and the tenant ID
+// it names, either of which may be empty
+func (r *TenantResolver) Resolve(apiKey string, tenantID string) (string, error) {
+	if len(r.apiKeys) == 0 {
+		if tenantID == "" {
+			return DefaultTenantID, nil
+		}
+		if err := ValidateTenantID(tenantID); err != nil {
+			return "", err
+		}
+		return tenantID, nil
+	}
+
+	keyTenantID, ok := r.apiKeys[apiKey]
+	if !ok {
+		return "", ErrInvalidAPIKey
+	}
+	if tenantID != "" && tenantID != keyTenantID {
+		return "", ErrTenantForbidden
+	}
+	return keyTenantID, nil
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBusy is returned when a transaction kept failing on locks held by other
+// connections until its retries ran out
+var ErrBusy = errors.New("database busy")
+
+type transactionKey struct{}
+
+// ContextWithTransaction returns a copy of ctx carrying tx. Repositories that
+// receive this context run their statements inside the transaction.
+func ContextWithTransaction(ctx context.Context, tx Transaction) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+// TransactionFromContext returns the transaction carried by ctx, if any
+func TransactionFromContext(ctx context.Context) (Transaction, bool) {
+	tx, ok := ctx.Value(transactionKey{}).(Transaction)
+	return tx, ok
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Upcaster converts the payload of a message type from one schema version to
+// the next. Payloads are upcast one version at a time until they reach the
+// current version, the shape the message type's handler decodes.
+type Upcaster struct {
+	MessageType string
+	FromVersion int
+	Upcast      func(payload json.RawMessage) (json.RawMessage, error)
+}
+
+// RegisterUpcaster adds the upcaster of a message type from a schema
+// version. The current version of the message type becomes the version
+// after the latest upcaster.
+func (r *MessageRegistry) RegisterUpcaster(upcaster Upcaster) error {
+	registered, ok := r.handlers[upcaster.MessageType]
+	if !ok {
+		return fmt.Errorf("unknown message type: %s", upcaster.MessageType)
+	}
+	if upcaster.FromVersion < FirstSchemaVersion {
+		return fmt.Errorf("invalid schema version %d for message type %s", upcaster.FromVersion, upcaster.MessageType)
+	}
+	if upcaster.Upcast == nil {
+		return errors.New("upcast function is required")
+	}
+	if _, exists := registered.upcasters[upcaster.FromVersion]; exists {
+		return fmt.Errorf("upcaster from schema version %d of message type %s is already registered", upcaster.FromVersion, upcaster.MessageType)
+	}
+
+	if registered.upcasters == nil {
+		registered.upcasters = make(map[int]func(json.RawMessage) (json.RawMessage, error))
+	}
+	registered.upcasters[upcaster.FromVersion] = upcaster.Upcast
+	if upcaster.FromVersion+1 > registered.version {
+		registered.version = upcaster.FromVersion + 1
+	}
+	return nil
+}
+
+// upcast converts a payload of the given schema version to the current
+// version of the message type
+func (h *registeredHandler) upcast(version int, payload json.RawMessage) (json.RawMessage, error) {
+	for ; version < h.version; version++ {
+		upcast, ok := h.upcasters[version]
+		if !ok {
+			return nil, fmt.Errorf("no upcaster from schema version %d", version)
+		}
+
+		upcasted, err := upcast(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast from schema version %d: %w", version, err)
+		}
+		payload = upcasted
+	}
+	return payload, nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+)
+
+// FieldError describes an invalid field of a message
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a message
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, field.Field+" "+field.Message)
+	}
+	return "invalid message: " + strings.Join(parts, ", ")
+}
+
+// validateMetadata checks the metadata fields every message needs. The
+// message type is checked against the registered handlers.
+func validateMetadata(metadata MessageMetadata) []FieldError {
+	var fields []FieldError
+	if metadata.Channel == "" {
+		fields = append(fields, FieldError{Field: "metadata.channel", Message: "is required"})
+	}
+	if metadata.MessageNumber <= 0 {
+		fields = append(fields, FieldError{Field: "metadata.messageNumber", Message: "must be greater than zero"})
+	}
+	if metadata.MessageTime.IsZero() {
+		fields = append(fields, FieldError{Field: "metadata.messageTime", Message: "is required"})
+	}
+	if metadata.SchemaVersion < 0 {
+		fields = append(fields, FieldError{Field: "metadata.schemaVersion", Message: "must be greater than zero"})
+	}
+	return fields
+}
@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// connectionFields are the fields returning a page of nodes. The cost of
+// their selection is multiplied by the number of nodes requested.
+var connectionFields = map[string]bool{
+	"rockets": true,
+	"events":  true,
+}
+
+// complexityWalker computes the cost of a query: every field costs 1, and the
+// selection of a connection costs as many times as the page size it asks for
+type complexityWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+// queryComplexity returns the cost of the operation selected by
+// operationName, or of the only operation in the query
+func queryComplexity(query string, operationName string, variables map[string]interface{}) (int, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0, err
+	}
+
+	w := &complexityWalker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		visiting:  make(map[string]bool),
+	}
+
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.OperationDefinition:
+			operations = append(operations, def)
+		case *ast.FragmentDefinition:
+			w.fragments[def.Name.Value] = def
+		}
+	}
+
+	for _, operation := range operations {
+		if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+			return w.selectionSet(operation.SelectionSet), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown operation %q", operationName)
+}
+
+func (w *complexityWalker) selectionSet(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	cost := 0
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			cost += w.field(sel)
+		case *ast.InlineFragment:
+			cost += w.selectionSet(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue // Reported by validation
+			}
+			w.visiting[name] = true
+			cost += w.selectionSet(fragment.SelectionSet)
+			w.visiting[name] = false
+		}
+	}
+
+	return cost
+}
+
+func (w *complexityWalker) field(field *ast.Field) int {
+	children := w.selectionSet(field.SelectionSet)
+	if connectionFields[field.Name.Value] {
+		children *= w.pageSize(field)
+	}
+
+	return 1 + children
+}
+
+// pageSize returns the "first" argument of a connection field, falling back
+// to the default page size
+func (w *complexityWalker) pageSize(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n >= 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := w.variables[value.Name.Value].(type) {
+			case int:
+				if n >= 0 {
+					return n
+				}
+			case float64:
+				if n >= 0 {
+					return int(n)
+				}
+			}
+		}
+	}
+
+	return defaultPageSize
+}
@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryComplexity(t *testing.T) {
+	testCases := []struct {
+		name               string
+		query              string
+		operationName      string
+		variables          map[string]interface{}
+		expectedComplexity int
+		expectedError      string
+	}{
+		{
+			name:               "flat_fields",
+			query:              `{ rocket(channel: "channel-1") { channel type speed } stats { total } }`,
+			expectedComplexity: 6,
+		},
+		{
+			name:               "connection_uses_first",
+			query:              `{ rockets(first: 5) { totalCount edges { node { channel } } } }`,
+			expectedComplexity: 1 + 5*(1+1+1+1),
+		},
+		{
+			name:               "connection_default_page_size",
+			query:              `{ rockets { totalCount } }`,
+			expectedComplexity: 1 + defaultPageSize,
+		},
+		{
+			name:               "nested_connections_multiply",
+			query:              `{ rockets(first: 2) { edges { node { events(first: 3) { edges { node { messageNumber } } } } } } }`,
+			expectedComplexity: 1 + 2*(1+1+(1+3*(1+1+1))),
+		},
+		{
+			name:               "first_from_variable",
+			query:              `query ($n: Int) { rockets(first: $n) { totalCount } }`,
+			variables:          map[string]interface{}{"n": float64(7)},
+			expectedComplexity: 1 + 7,
+		},
+		{
+			name: "fragments",
+			query: `{ rockets(first: 2) { ...page } }
+				fragment page on RocketConnection { totalCount edges { ... on RocketEdge { cursor } } }`,
+			expectedComplexity: 1 + 2*(1+1+1),
+		},
+		{
+			name: "selected_operation",
+			query: `query small { stats { total } }
+				query large { rockets(first: 50) { totalCount } }`,
+			operationName:      "large",
+			expectedComplexity: 1 + 50,
+		},
+		{
+			name:          "unknown_operation",
+			query:         `query small { stats { total } }`,
+			operationName: "large",
+			expectedError: `unknown operation "large"`,
+		},
+		{
+			name:          "syntax_error",
+			query:         `{ rockets(`,
+			expectedError: "Syntax Error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			complexity, err := queryComplexity(tc.query, tc.operationName, tc.variables)
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedComplexity, complexity)
+			}
+		})
+	}
+}
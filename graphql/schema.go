@@ -0,0 +1,407 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	rocketCursorPrefix = "rocket:"
+	eventCursorPrefix  = "event:"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// resolver backs the schema fields with the use cases and repositories
+type resolver struct {
+	rocketUseCase usecase.RocketUseCase
+	messageRepo   domain.MessageRepository
+}
+
+// pageInfo describes a page of a connection
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   *string
+}
+
+type edge struct {
+	Cursor string
+	Node   interface{}
+}
+
+type connection struct {
+	Edges      []edge
+	PageInfo   pageInfo
+	TotalCount *int
+}
+
+// setEndCursor points the page info at the last edge of the page
+func (c *connection) setEndCursor() {
+	if len(c.Edges) > 0 {
+		c.PageInfo.EndCursor = &c.Edges[len(c.Edges)-1].Cursor
+	}
+}
+
+// count is a single entry of an aggregate, such as the number of rockets with
+// a given status
+type count struct {
+	Key   string
+	Count int
+}
+
+// jsonScalar exposes raw JSON payloads as their decoded value
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Arbitrary JSON value",
+	Serialize: func(value interface{}) interface{} {
+		raw, ok := value.(json.RawMessage)
+		if !ok {
+			return value
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil
+		}
+		return decoded
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return value
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return nil
+	},
+})
+
+func newSchema(r *resolver) (graphql.Schema, error) {
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	messageEventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "MessageEvent",
+		Description: "A message applied to a rocket",
+		Fields: graphql.Fields{
+			"messageNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"messageType":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"messageTime":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"schemaVersion": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Schema version the payload was received with"},
+			"payload":       &graphql.Field{Type: jsonScalar},
+			"processedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	messageEventConnectionType := newConnectionType("MessageEvent", messageEventType, pageInfoType)
+
+	telemetryType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Telemetry",
+		Description: "A reading of the flight instruments of a rocket",
+		Fields: graphql.Fields{
+			"time":      &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"altitude":  &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"latitude":  &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"longitude": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"fuel":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"heading":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+
+	rocketType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Rocket",
+		Description: "The current state of a rocket",
+		Fields: graphql.Fields{
+			"channel":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"type":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"speed":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"mission":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"launchTime":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"status":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"explodedAt":  &graphql.Field{Type: graphql.DateTime},
+			"reason":      &graphql.Field{Type: graphql.String},
+			"landedAt":    &graphql.Field{Type: graphql.DateTime},
+			"landingSite": &graphql.Field{Type: graphql.String},
+			"abortedAt":   &graphql.Field{Type: graphql.DateTime},
+			"dockedAt":    &graphql.Field{Type: graphql.DateTime},
+			"station":     &graphql.Field{Type: graphql.String},
+			"telemetry":   &graphql.Field{Type: telemetryType, Description: "Latest telemetry reading"},
+			"lastUpdated": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"lastMessage": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"events": &graphql.Field{
+				Type:        graphql.NewNonNull(messageEventConnectionType),
+				Description: "Messages applied to the rocket, oldest first",
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{Type: graphql.Int},
+					"after": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.events,
+			},
+		},
+	})
+
+	rocketConnectionType := newConnectionType("Rocket", rocketType, pageInfoType)
+
+	countType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Count",
+		Fields: graphql.Fields{
+			"key":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"count": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "FleetStats",
+		Description: "Aggregates over every rocket",
+		Fields: graphql.Fields{
+			"total":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"averageSpeed": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"maxSpeed":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"byStatus": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(countType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return counts(p.Source.(*domain.FleetStats).ByStatus), nil
+				},
+			},
+			"byType": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(countType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return counts(p.Source.(*domain.FleetStats).ByType), nil
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"rocket": &graphql.Field{
+				Type:        rocketType,
+				Description: "A rocket by channel, or null when it has not launched",
+				Args: graphql.FieldConfigArgument{
+					"channel": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.rocket,
+			},
+			"rockets": &graphql.Field{
+				Type:        graphql.NewNonNull(rocketConnectionType),
+				Description: "Rockets matching the filters",
+				Args: graphql.FieldConfigArgument{
+					"status":  &graphql.ArgumentConfig{Type: graphql.String, Description: "Launched, Exploded, Landed, Aborted or Docked"},
+					"type":    &graphql.ArgumentConfig{Type: graphql.String},
+					"mission": &graphql.ArgumentConfig{Type: graphql.String},
+					"sortBy":  &graphql.ArgumentConfig{Type: graphql.String, Description: "channel, type, speed, mission or status"},
+					"order":   &graphql.ArgumentConfig{Type: graphql.String, Description: "ASC or DESC"},
+					"first":   &graphql.ArgumentConfig{Type: graphql.Int},
+					"after":   &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.rockets,
+			},
+			"stats": &graphql.Field{
+				Type:    graphql.NewNonNull(statsType),
+				Resolve: r.stats,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// newConnectionType creates the edge and connection types for a node type
+func newConnectionType(name string, nodeType *graphql.Object, pageInfoType *graphql.Object) *graphql.Object {
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(nodeType)},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.Int},
+		},
+	})
+}
+
+func (r *resolver) rocket(p graphql.ResolveParams) (interface{}, error) {
+	channel, _ := p.Args["channel"].(string)
+
+	rocket, err := r.rocketUseCase.GetRocket(p.Context, channel)
+	if err != nil {
+		if errors.Is(err, domain.ErrRocketNotFound) {
+			return nil, nil
+		}
+		log.Printf("Error getting rocket: %v", err)
+		return nil, errors.New("failed to get rocket state")
+	}
+
+	return rocket, nil
+}
+
+func (r *resolver) rockets(p graphql.ResolveParams) (interface{}, error) {
+	first, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if after, ok := p.Args["after"].(string); ok {
+		last, err := decodeCursor(rocketCursorPrefix, after)
+		if err != nil {
+			return nil, err
+		}
+		offset = int(last) + 1
+	}
+
+	status, _ := p.Args["status"].(string)
+	if status != "" && !domain.IsValidRocketStatus(status) {
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	rocketType, _ := p.Args["type"].(string)
+	mission, _ := p.Args["mission"].(string)
+	sortBy, _ := p.Args["sortBy"].(string)
+	order, _ := p.Args["order"].(string)
+
+	page, err := r.rocketUseCase.SearchRockets(p.Context, domain.RocketQuery{
+		Status:  status,
+		Type:    rocketType,
+		Mission: mission,
+		SortBy:  strings.ToLower(sortBy),
+		Order:   strings.ToUpper(order),
+		Limit:   first,
+		Offset:  offset,
+	})
+	if err != nil {
+		log.Printf("Error listing rockets: %v", err)
+		return nil, errors.New("failed to get rockets")
+	}
+
+	conn := &connection{Edges: []edge{}, TotalCount: &page.Total}
+	for i, rocket := range page.Rockets {
+		conn.Edges = append(conn.Edges, edge{
+			Cursor: encodeCursor(rocketCursorPrefix, int64(offset+i)),
+			Node:   rocket,
+		})
+	}
+	conn.setEndCursor()
+	conn.PageInfo.HasNextPage = offset+len(page.Rockets) < page.Total
+
+	return conn, nil
+}
+
+func (r *resolver) events(p graphql.ResolveParams) (interface{}, error) {
+	rocket := p.Source.(*domain.Rocket)
+
+	first, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	var afterNumber int64
+	if after, ok := p.Args["after"].(string); ok {
+		afterNumber, err = decodeCursor(eventCursorPrefix, after)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra event to know whether there is a next page
+	events, err := r.messageRepo.ListEvents(p.Context, rocket.Channel, afterNumber, first+1)
+	if err != nil {
+		log.Printf("Error listing message events: %v", err)
+		return nil, errors.New("failed to get rocket events")
+	}
+
+	conn := &connection{Edges: []edge{}}
+	if len(events) > first {
+		events = events[:first]
+		conn.PageInfo.HasNextPage = true
+	}
+
+	for _, event := range events {
+		conn.Edges = append(conn.Edges, edge{
+			Cursor: encodeCursor(eventCursorPrefix, event.MessageNumber),
+			Node:   event,
+		})
+	}
+	conn.setEndCursor()
+
+	return conn, nil
+}
+
+func (r *resolver) stats(p graphql.ResolveParams) (interface{}, error) {
+	stats, err := r.rocketUseCase.GetStats(p.Context)
+	if err != nil {
+		log.Printf("Error getting fleet stats: %v", err)
+		return nil, errors.New("failed to get fleet stats")
+	}
+
+	return stats, nil
+}
+
+// pageSize returns the validated "first" argument of a connection field
+func pageSize(args map[string]interface{}) (int, error) {
+	first, ok := args["first"].(int)
+	if !ok {
+		return defaultPageSize, nil
+	}
+
+	if first < 0 || first > maxPageSize {
+		return 0, fmt.Errorf("first must be between 0 and %d", maxPageSize)
+	}
+
+	return first, nil
+}
+
+func encodeCursor(prefix string, value int64) string {
+	return base64.StdEncoding.EncodeToString([]byte(prefix + strconv.FormatInt(value, 10)))
+}
+
+func decodeCursor(prefix string, cursor string) (int64, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), prefix) {
+		return 0, errInvalidCursor
+	}
+
+	value, err := strconv.ParseInt(strings.TrimPrefix(string(decoded), prefix), 10, 64)
+	if err != nil || value < 0 {
+		return 0, errInvalidCursor
+	}
+
+	return value, nil
+}
+
+// counts turns an aggregate map into a list sorted by key
+func counts(values map[string]int) []count {
+	result := make([]count, 0, len(values))
+	for key, value := range values {
+		result = append(result, count{Key: key, Count: value})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
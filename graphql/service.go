@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// DefaultMaxComplexity is the query complexity allowed when none is configured
+const DefaultMaxComplexity = 1000
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Service executes GraphQL queries over the rocket domain
+type Service struct {
+	schema        graphql.Schema
+	maxComplexity int
+}
+
+// NewService builds the schema on top of the rocket use case and the message
+// repository. Queries whose complexity exceeds maxComplexity are rejected
+// before they are executed.
+func NewService(rocketUseCase usecase.RocketUseCase, messageRepo domain.MessageRepository, maxComplexity int) (*Service, error) {
+	schema, err := newSchema(&resolver{
+		rocketUseCase: rocketUseCase,
+		messageRepo:   messageRepo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graphql schema: %w", err)
+	}
+
+	return &Service{
+		schema:        schema,
+		maxComplexity: maxComplexity,
+	}, nil
+}
+
+// Execute validates and runs a request
+func (s *Service) Execute(ctx context.Context, req Request) *graphql.Result {
+	if s.maxComplexity > 0 {
+		complexity, err := queryComplexity(req.Query, req.OperationName, req.Variables)
+		if err == nil && complexity > s.maxComplexity {
+			return &graphql.Result{
+				Errors: []gqlerrors.FormattedError{
+					gqlerrors.NewFormattedError(fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, s.maxComplexity)),
+				},
+			}
+		}
+		// Syntax errors are left to the executor, which reports them with locations
+	}
+
+	return graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
@@ -0,0 +1,288 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func executeJSON(t *testing.T, service *Service, req Request) map[string]interface{} {
+	t.Helper()
+
+	result := service.Execute(context.Background(), req)
+	encoded, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return decoded
+}
+
+func TestService_Rocket(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+	rocket := &domain.Rocket{
+		Channel:     "channel-1",
+		Type:        "Falcon-9",
+		Speed:       100,
+		Mission:     "ARTEMIS",
+		LaunchTime:  fixedTime,
+		Status:      domain.RocketStatusLaunched,
+		LastUpdated: fixedTime,
+		LastMessage: 3,
+		Telemetry:   &domain.Telemetry{Time: fixedTime, Altitude: 1500.5, Latitude: 28.5, Longitude: -80.6, Fuel: 82.5, Heading: 90},
+	}
+
+	testCases := []struct {
+		name         string
+		channel      string
+		setupMock    func(*mocks.MockRocketUseCase)
+		expectedData string
+		expectError  bool
+	}{
+		{
+			name:    "found",
+			channel: "channel-1",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetRocket", mock.Anything, "channel-1").Return(rocket, nil)
+			},
+			expectedData: `{"rocket":{"channel":"channel-1","type":"Falcon-9","speed":100,"status":"Launched","launchTime":"2025-05-20T09:39:15Z","explodedAt":null,"lastMessage":3,"telemetry":{"altitude":1500.5,"fuel":82.5}}}`,
+		},
+		{
+			name:    "not_found",
+			channel: "channel-2",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetRocket", mock.Anything, "channel-2").Return(nil, domain.ErrRocketNotFound)
+			},
+			expectedData: `{"rocket":null}`,
+		},
+		{
+			name:    "usecase_error",
+			channel: "channel-3",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetRocket", mock.Anything, "channel-3").Return(nil, errors.New("database error"))
+			},
+			expectedData: `{"rocket":null}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUseCase := &mocks.MockRocketUseCase{}
+			tc.setupMock(mockUseCase)
+
+			service, err := NewService(mockUseCase, &mocks.MockMessageRepository{}, DefaultMaxComplexity)
+			require.NoError(t, err)
+
+			resp := executeJSON(t, service, Request{
+				Query:     `query ($channel: String!) { rocket(channel: $channel) { channel type speed status launchTime explodedAt lastMessage telemetry { altitude fuel } } }`,
+				Variables: map[string]interface{}{"channel": tc.channel},
+			})
+
+			data, _ := json.Marshal(resp["data"])
+			assert.JSONEq(t, tc.expectedData, string(data))
+			if tc.expectError {
+				if errs, ok := resp["errors"].([]interface{}); assert.True(t, ok) && assert.Len(t, errs, 1) {
+					assert.Equal(t, "failed to get rocket state", errs[0].(map[string]interface{})["message"])
+				}
+			} else {
+				assert.Nil(t, resp["errors"])
+			}
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_Rockets(t *testing.T) {
+	rockets := []*domain.Rocket{
+		{Channel: "channel-3", Type: "Falcon-9", Status: domain.RocketStatusLaunched},
+		{Channel: "channel-4", Type: "Falcon-9", Status: domain.RocketStatusLaunched},
+	}
+
+	mockUseCase := &mocks.MockRocketUseCase{}
+	mockUseCase.On("SearchRockets", mock.Anything, domain.RocketQuery{
+		Status: domain.RocketStatusLaunched,
+		SortBy: "speed",
+		Order:  "DESC",
+		Limit:  2,
+		Offset: 2,
+	}).Return(&domain.RocketPage{Rockets: rockets, Total: 5}, nil)
+
+	service, err := NewService(mockUseCase, &mocks.MockMessageRepository{}, DefaultMaxComplexity)
+	require.NoError(t, err)
+
+	resp := executeJSON(t, service, Request{
+		Query: `{ rockets(status: "Launched", sortBy: "speed", order: "desc", first: 2, after: "` + encodeCursor(rocketCursorPrefix, 1) + `") {
+			totalCount
+			edges { cursor node { channel } }
+			pageInfo { hasNextPage endCursor }
+		} }`,
+	})
+
+	assert.Nil(t, resp["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"rockets": map[string]interface{}{
+			"totalCount": float64(5),
+			"edges": []interface{}{
+				map[string]interface{}{"cursor": encodeCursor(rocketCursorPrefix, 2), "node": map[string]interface{}{"channel": "channel-3"}},
+				map[string]interface{}{"cursor": encodeCursor(rocketCursorPrefix, 3), "node": map[string]interface{}{"channel": "channel-4"}},
+			},
+			"pageInfo": map[string]interface{}{
+				"hasNextPage": true,
+				"endCursor":   encodeCursor(rocketCursorPrefix, 3),
+			},
+		},
+	}, resp["data"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestService_RocketsInvalidArguments(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expectedError string
+	}{
+		{
+			name:          "invalid_cursor",
+			query:         `{ rockets(after: "not-a-cursor") { totalCount } }`,
+			expectedError: "invalid cursor",
+		},
+		{
+			name:          "cursor_of_another_connection",
+			query:         `{ rockets(after: "` + encodeCursor(eventCursorPrefix, 1) + `") { totalCount } }`,
+			expectedError: "invalid cursor",
+		},
+		{
+			name:          "page_too_large",
+			query:         `{ rockets(first: 101) { totalCount } }`,
+			expectedError: "first must be between 0 and 100",
+		},
+		{
+			name:          "invalid_status",
+			query:         `{ rockets(status: "Orbiting") { totalCount } }`,
+			expectedError: `invalid status "Orbiting"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			service, err := NewService(&mocks.MockRocketUseCase{}, &mocks.MockMessageRepository{}, 0)
+			require.NoError(t, err)
+
+			result := service.Execute(context.Background(), Request{Query: tc.query})
+
+			if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, tc.expectedError, result.Errors[0].Message)
+			}
+		})
+	}
+}
+
+func TestService_RocketEvents(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+	rocket := &domain.Rocket{Channel: "channel-1", Type: "Falcon-9", Status: domain.RocketStatusLaunched}
+
+	mockUseCase := &mocks.MockRocketUseCase{}
+	mockUseCase.On("GetRocket", mock.Anything, "channel-1").Return(rocket, nil)
+
+	mockMessageRepo := &mocks.MockMessageRepository{
+		ListEventsFunc: func(ctx context.Context, channel string, afterNumber int64, limit int) ([]*domain.MessageEvent, error) {
+			assert.Equal(t, "channel-1", channel)
+			assert.Equal(t, int64(1), afterNumber)
+			assert.Equal(t, 3, limit)
+			return []*domain.MessageEvent{
+				{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased, MessageTime: fixedTime, SchemaVersion: 1, Payload: json.RawMessage(`{"by":100}`), ProcessedAt: fixedTime},
+				{Channel: "channel-1", MessageNumber: 3, MessageType: domain.TypeRocketSpeedDecreased, MessageTime: fixedTime, SchemaVersion: 2, Payload: json.RawMessage(`{"by":50}`), ProcessedAt: fixedTime},
+				{Channel: "channel-1", MessageNumber: 4, MessageType: domain.TypeRocketSpeedIncreased, MessageTime: fixedTime, Payload: json.RawMessage(`{"by":10}`), ProcessedAt: fixedTime},
+			}, nil
+		},
+	}
+
+	service, err := NewService(mockUseCase, mockMessageRepo, DefaultMaxComplexity)
+	require.NoError(t, err)
+
+	resp := executeJSON(t, service, Request{
+		Query: `{ rocket(channel: "channel-1") { channel events(first: 2, after: "` + encodeCursor(eventCursorPrefix, 1) + `") {
+			edges { node { messageNumber messageType schemaVersion payload } }
+			pageInfo { hasNextPage endCursor }
+		} } }`,
+	})
+
+	assert.Nil(t, resp["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"rocket": map[string]interface{}{
+			"channel": "channel-1",
+			"events": map[string]interface{}{
+				"edges": []interface{}{
+					map[string]interface{}{"node": map[string]interface{}{"messageNumber": float64(2), "messageType": domain.TypeRocketSpeedIncreased, "schemaVersion": float64(1), "payload": map[string]interface{}{"by": float64(100)}}},
+					map[string]interface{}{"node": map[string]interface{}{"messageNumber": float64(3), "messageType": domain.TypeRocketSpeedDecreased, "schemaVersion": float64(2), "payload": map[string]interface{}{"by": float64(50)}}},
+				},
+				"pageInfo": map[string]interface{}{
+					"hasNextPage": true,
+					"endCursor":   encodeCursor(eventCursorPrefix, 3),
+				},
+			},
+		},
+	}, resp["data"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestService_Stats(t *testing.T) {
+	mockUseCase := &mocks.MockRocketUseCase{}
+	mockUseCase.On("GetStats", mock.Anything).Return(&domain.FleetStats{
+		Total:        3,
+		AverageSpeed: 150.5,
+		MaxSpeed:     300,
+		ByStatus:     map[string]int{domain.RocketStatusLaunched: 2, domain.RocketStatusExploded: 1},
+		ByType:       map[string]int{"Falcon-9": 3},
+	}, nil)
+
+	service, err := NewService(mockUseCase, &mocks.MockMessageRepository{}, DefaultMaxComplexity)
+	require.NoError(t, err)
+
+	resp := executeJSON(t, service, Request{
+		Query: `{ stats { total averageSpeed maxSpeed byStatus { key count } byType { key count } } }`,
+	})
+
+	assert.Nil(t, resp["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"stats": map[string]interface{}{
+			"total":        float64(3),
+			"averageSpeed": 150.5,
+			"maxSpeed":     float64(300),
+			"byStatus": []interface{}{
+				map[string]interface{}{"key": domain.RocketStatusExploded, "count": float64(1)},
+				map[string]interface{}{"key": domain.RocketStatusLaunched, "count": float64(2)},
+			},
+			"byType": []interface{}{
+				map[string]interface{}{"key": "Falcon-9", "count": float64(3)},
+			},
+		},
+	}, resp["data"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestService_ComplexityLimit(t *testing.T) {
+	service, err := NewService(&mocks.MockRocketUseCase{}, &mocks.MockMessageRepository{}, 100)
+	require.NoError(t, err)
+
+	result := service.Execute(context.Background(), Request{
+		Query: `{ rockets(first: 10) { edges { node { channel events(first: 10) { edges { node { messageNumber } } } } } } }`,
+	})
+
+	assert.Nil(t, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query complexity 341 exceeds the maximum of 100", result.Errors[0].Message)
+	}
+}
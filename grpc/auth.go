@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationMetadata carries the bearer token of a call, the gRPC
+// counterpart of the Authorization header
+const AuthorizationMetadata = "authorization"
+
+// methodScopes are the scopes granted access to each method, the same as the
+// matching HTTP routes. Other methods require the admin scope.
+var methodScopes = map[string]string{
+	rocketpb.RocketService_GetRocket_FullMethodName:     domain.ScopeRocketsRead,
+	rocketpb.RocketService_ListRockets_FullMethodName:   domain.ScopeRocketsRead,
+	rocketpb.RocketService_WatchRockets_FullMethodName:  domain.ScopeRocketsRead,
+	rocketpb.RocketService_IngestMessage_FullMethodName: domain.ScopeMessagesWrite,
+}
+
+// AuthServerOptions returns the interceptors that authenticate the caller of
+// every call from its bearer token, check that it was granted the scope of
+// the method, and carry its identity in the call context
+func AuthServerOptions(tokens domain.TokenValidator) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := identityContext(ctx, tokens, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := identityContext(stream.Context(), tokens, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+// identityContext authenticates the caller from the call metadata and checks
+// its scope for the method
+func identityContext(ctx context.Context, tokens domain.TokenValidator, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	token, ok := strings.CutPrefix(firstValue(md, AuthorizationMetadata), "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	identity, err := tokens.Validate(strings.TrimSpace(token))
+	if err != nil {
+		log.Printf("Rejected gRPC call to %s: %v", method, err)
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = domain.ScopeAdmin
+	}
+	if !identity.HasScope(scope) {
+		log.Printf("Rejected gRPC call to %s by %s: %v %s", method, identity.Subject, domain.ErrInsufficientScope, scope)
+		return nil, status.Errorf(codes.PermissionDenied, "insufficient scope: %s required", scope)
+	}
+
+	log.Printf("Authorized gRPC call to %s for %s", method, identity.Subject)
+	return domain.ContextWithIdentity(ctx, identity), nil
+}
+
+// SignatureServerOptions returns the interceptor refusing the messages
+// ingested over gRPC when producers must sign their messages. A signature
+// covers the exact JSON body posted to the HTTP API, which a gRPC request does
+// not carry, so signed producers post their messages over HTTP. Refused
+// messages are counted in failures as missing a signature.
+func SignatureServerOptions(failures *metrics.CounterVec) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if info.FullMethod != rocketpb.RocketService_IngestMessage_FullMethodName {
+				return handler(ctx, req)
+			}
+
+			log.Printf("Rejected gRPC message from %q: %v", domain.ActorFromContext(ctx), domain.ErrMissingSignature)
+			failures.Inc("missing_signature")
+			return nil, status.Error(codes.Unauthenticated, "signed messages must be posted to the HTTP API")
+		}),
+	}
+}
@@ -0,0 +1,208 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/metrics"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// stubTokens accepts the tokens it knows, as the identity they map to
+type stubTokens map[string]*domain.Identity
+
+func (s stubTokens) Validate(token string) (*domain.Identity, error) {
+	if identity, ok := s[token]; ok {
+		return identity, nil
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+var testTokens = stubTokens{
+	"reader":   {Subject: "dashboard", Scopes: []string{domain.ScopeRocketsRead}},
+	"producer": {Subject: "ground-station", Scopes: []string{domain.ScopeMessagesWrite}},
+	"admin":    {Subject: "operator", Scopes: []string{domain.ScopeAdmin}},
+}
+
+// testIngestRequest is a valid message to ingest
+var testIngestRequest = &rocketpb.IngestMessageRequest{
+	Metadata: &rocketpb.MessageMetadata{
+		Channel:       "channel-1",
+		MessageNumber: 1,
+		MessageTime:   timestamppb.New(fixedTime),
+		MessageType:   domain.TypeRocketLaunched,
+	},
+}
+
+func TestAuthServerOptions_Unary(t *testing.T) {
+	testCases := []struct {
+		name            string
+		method          string
+		authorization   string
+		expectedCode    codes.Code
+		expectedSubject string
+	}{
+		{
+			name:            "ingest_with_write_scope",
+			method:          "IngestMessage",
+			authorization:   "Bearer producer",
+			expectedCode:    codes.OK,
+			expectedSubject: "ground-station",
+		},
+		{
+			name:         "ingest_without_token",
+			method:       "IngestMessage",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:          "ingest_with_invalid_token",
+			method:        "IngestMessage",
+			authorization: "Bearer forged",
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "ingest_with_read_scope",
+			method:        "IngestMessage",
+			authorization: "Bearer reader",
+			expectedCode:  codes.PermissionDenied,
+		},
+		{
+			name:            "read_with_read_scope",
+			method:          "GetRocket",
+			authorization:   "Bearer reader",
+			expectedCode:    codes.OK,
+			expectedSubject: "dashboard",
+		},
+		{
+			name:          "read_with_write_scope",
+			method:        "GetRocket",
+			authorization: "Bearer producer",
+			expectedCode:  codes.PermissionDenied,
+		},
+		{
+			name:            "read_with_admin_scope",
+			method:          "GetRocket",
+			authorization:   "Bearer admin",
+			expectedCode:    codes.OK,
+			expectedSubject: "operator",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var subject string
+			recordSubject := func(ctx context.Context) bool {
+				subject = domain.ActorFromContext(ctx)
+				return true
+			}
+
+			rocketUseCase := &mocks.MockRocketUseCase{}
+			rocketUseCase.On("GetRocket", mock.MatchedBy(recordSubject), "channel-1").
+				Return(&domain.Rocket{Channel: "channel-1", LaunchTime: fixedTime, LastUpdated: fixedTime}, nil).Maybe()
+			messageUsecase := &mocks.MockRocketMessageUsecase{}
+			messageUsecase.On("ProcessMessage", mock.MatchedBy(recordSubject), mock.Anything).Return(nil).Maybe()
+
+			client := newTestClient(t, rocketUseCase, messageUsecase, AuthServerOptions(testTokens)...)
+
+			ctx := context.Background()
+			if tc.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationMetadata, tc.authorization)
+			}
+
+			var err error
+			if tc.method == "IngestMessage" {
+				_, err = client.IngestMessage(ctx, testIngestRequest)
+			} else {
+				_, err = client.GetRocket(ctx, &rocketpb.GetRocketRequest{Channel: "channel-1"})
+			}
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			assert.Equal(t, tc.expectedSubject, subject)
+		})
+	}
+}
+
+func TestAuthServerOptions_Stream(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authorization string
+		expectedCode  codes.Code
+	}{
+		{
+			name:          "read_scope",
+			authorization: "Bearer reader",
+			expectedCode:  codes.OK,
+		},
+		{
+			name:         "missing_token",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:          "write_scope",
+			authorization: "Bearer producer",
+			expectedCode:  codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rocketUseCase := &mocks.MockRocketUseCase{}
+			rocketUseCase.On("GetRocket", mock.MatchedBy(func(ctx context.Context) bool {
+				return domain.ActorFromContext(ctx) == "dashboard"
+			}), "channel-1").Return(&domain.Rocket{Channel: "channel-1", LaunchTime: fixedTime, LastUpdated: fixedTime}, nil).Maybe()
+
+			client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{}, AuthServerOptions(testTokens)...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationMetadata, tc.authorization)
+			}
+
+			stream, err := client.WatchRockets(ctx, &rocketpb.WatchRocketsRequest{Channels: []string{"channel-1"}})
+			require.NoError(t, err)
+
+			update, err := stream.Recv()
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, "channel-1", update.GetRocket().GetChannel())
+			}
+		})
+	}
+}
+
+func TestSignatureServerOptions(t *testing.T) {
+	failures := metrics.NewRegistry().Counter("auth_failures_total", "Rejected messages", "reason")
+
+	rocketUseCase := &mocks.MockRocketUseCase{}
+	rocketUseCase.On("GetRocket", mock.Anything, "channel-1").
+		Return(&domain.Rocket{Channel: "channel-1", LaunchTime: fixedTime, LastUpdated: fixedTime}, nil)
+	messageUsecase := &mocks.MockRocketMessageUsecase{}
+
+	client := newTestClient(t, rocketUseCase, messageUsecase, SignatureServerOptions(failures)...)
+
+	_, err := client.IngestMessage(context.Background(), testIngestRequest)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, float64(1), failures.Value("missing_signature"))
+	messageUsecase.AssertNotCalled(t, "ProcessMessage", mock.Anything, mock.Anything)
+
+	// Only the messages need a signature
+	_, err = client.GetRocket(context.Background(), &rocketpb.GetRocketRequest{Channel: "channel-1"})
+	assert.NoError(t, err)
+}
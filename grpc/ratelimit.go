@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/metrics"
+	"lunar-rockets/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimitServerOptions returns the interceptor throttling the messages
+// ingested over gRPC with the limiters of the HTTP API, so that a client has
+// one quota whichever API it uses. Each message takes a token from its client
+// and from its channel, and throttled messages are counted in throttled by
+// limit. A nil limiter applies no limit.
+func RateLimitServerOptions(clients *ratelimit.Limiter, channels *ratelimit.Limiter, throttled *metrics.CounterVec) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ingest, ok := req.(*rocketpb.IngestMessageRequest)
+			if !ok {
+				return handler(ctx, req)
+			}
+
+			if clients != nil {
+				client := clientKey(ctx)
+				if decision := clients.Allow(client); !decision.Allowed {
+					log.Printf("Throttled gRPC messages of client %s", client)
+					throttled.Inc("client")
+					return nil, throttledStatus(decision)
+				}
+			}
+
+			// A message without a channel is left to validation to reject
+			if channel := ingest.GetMetadata().GetChannel(); channels != nil && channel != "" {
+				if decision := channels.Allow(domain.TenantFromContext(ctx) + "/" + channel); !decision.Allowed {
+					log.Printf("Throttled gRPC messages of channel %s", channel)
+					throttled.Inc("channel")
+					return nil, throttledStatus(decision)
+				}
+			}
+
+			return handler(ctx, req)
+		}),
+	}
+}
+
+// clientKey identifies the client of a call by its authenticated identity, or
+// by its address when it has none, like the HTTP API does
+func clientKey(ctx context.Context) string {
+	if identity := domain.IdentityFromContext(ctx); identity != nil {
+		return identity.Subject
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// throttledStatus reports a throttled message, with the wait before the next
+// one is allowed as RetryInfo
+func throttledStatus(decision ratelimit.Decision) error {
+	st := status.New(codes.ResourceExhausted, "too many requests")
+
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(decision.RetryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
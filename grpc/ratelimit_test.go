@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/metrics"
+	"lunar-rockets/ratelimit"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRateLimitServerOptions(t *testing.T) {
+	oncePerHour := ratelimit.Quota{Requests: 1, Period: time.Hour}
+
+	testCases := []struct {
+		name              string
+		clientLimit       bool
+		channelLimit      bool
+		channels          []string
+		expectedCodes     []codes.Code
+		expectedThrottled string
+	}{
+		{
+			name:              "client_over_quota",
+			clientLimit:       true,
+			channels:          []string{"channel-1", "channel-2"},
+			expectedCodes:     []codes.Code{codes.OK, codes.ResourceExhausted},
+			expectedThrottled: "client",
+		},
+		{
+			name:              "channel_over_quota",
+			channelLimit:      true,
+			channels:          []string{"channel-1", "channel-2", "channel-1"},
+			expectedCodes:     []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+			expectedThrottled: "channel",
+		},
+		{
+			name:          "no_limits",
+			channels:      []string{"channel-1", "channel-1"},
+			expectedCodes: []codes.Code{codes.OK, codes.OK},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var clients, channels *ratelimit.Limiter
+			var err error
+			if tc.clientLimit {
+				clients, err = ratelimit.NewLimiter(oncePerHour, nil)
+				require.NoError(t, err)
+			}
+			if tc.channelLimit {
+				channels, err = ratelimit.NewLimiter(oncePerHour, nil)
+				require.NoError(t, err)
+			}
+			throttled := metrics.NewRegistry().Counter("throttled_total", "Throttled messages", "limit")
+
+			messageUsecase := &mocks.MockRocketMessageUsecase{}
+			messageUsecase.On("ProcessMessage", mock.Anything, mock.Anything).Return(nil)
+
+			client := newTestClient(t, &mocks.MockRocketUseCase{}, messageUsecase, RateLimitServerOptions(clients, channels, throttled)...)
+
+			for i, channel := range tc.channels {
+				req := proto.Clone(testIngestRequest).(*rocketpb.IngestMessageRequest)
+				req.Metadata.Channel = channel
+				req.Metadata.MessageNumber = int64(i + 1)
+
+				_, err := client.IngestMessage(context.Background(), req)
+				assert.Equal(t, tc.expectedCodes[i], status.Code(err), "message %d", i+1)
+
+				if status.Code(err) == codes.ResourceExhausted {
+					details := status.Convert(err).Details()
+					require.Len(t, details, 1)
+					retryInfo, ok := details[0].(*errdetails.RetryInfo)
+					require.True(t, ok)
+					assert.Greater(t, retryInfo.GetRetryDelay().AsDuration(), time.Duration(0))
+				}
+			}
+
+			if tc.expectedThrottled != "" {
+				assert.Equal(t, float64(1), throttled.Value(tc.expectedThrottled))
+			}
+		})
+	}
+}
+
+func TestRateLimitServerOptions_ReadsNotLimited(t *testing.T) {
+	clients, err := ratelimit.NewLimiter(ratelimit.Quota{Requests: 1, Period: time.Hour}, nil)
+	require.NoError(t, err)
+	throttled := metrics.NewRegistry().Counter("throttled_total", "Throttled messages", "limit")
+
+	rocketUseCase := &mocks.MockRocketUseCase{}
+	rocketUseCase.On("GetRocket", mock.Anything, "channel-1").
+		Return(&domain.Rocket{Channel: "channel-1", LaunchTime: fixedTime, LastUpdated: fixedTime}, nil)
+
+	client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{}, RateLimitServerOptions(clients, nil, throttled)...)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetRocket(context.Background(), &rocketpb.GetRocketRequest{Channel: "channel-1"})
+		assert.NoError(t, err)
+	}
+}
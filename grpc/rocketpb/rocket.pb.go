@@ -0,0 +1,893 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: rocket.proto
+
+package rocketpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Rocket struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Channel    string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Type       string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Speed      int64                  `protobuf:"varint,3,opt,name=speed,proto3" json:"speed,omitempty"`
+	Mission    string                 `protobuf:"bytes,4,opt,name=mission,proto3" json:"mission,omitempty"`
+	LaunchTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=launch_time,json=launchTime,proto3" json:"launch_time,omitempty"`
+	// One of Launched, Exploded, Landed, Aborted or Docked.
+	Status     string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	ExplodedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=exploded_at,json=explodedAt,proto3" json:"exploded_at,omitempty"`
+	// Reason for the explosion or abort.
+	Reason      string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	LastUpdated *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	LastMessage int64                  `protobuf:"varint,10,opt,name=last_message,json=lastMessage,proto3" json:"last_message,omitempty"`
+	LandedAt    *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=landed_at,json=landedAt,proto3" json:"landed_at,omitempty"`
+	LandingSite string                 `protobuf:"bytes,12,opt,name=landing_site,json=landingSite,proto3" json:"landing_site,omitempty"`
+	AbortedAt   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=aborted_at,json=abortedAt,proto3" json:"aborted_at,omitempty"`
+	DockedAt    *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=docked_at,json=dockedAt,proto3" json:"docked_at,omitempty"`
+	Station     string                 `protobuf:"bytes,15,opt,name=station,proto3" json:"station,omitempty"`
+	// Latest telemetry reading, unset before the first one.
+	Telemetry     *Telemetry `protobuf:"bytes,16,opt,name=telemetry,proto3" json:"telemetry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rocket) Reset() {
+	*x = Rocket{}
+	mi := &file_rocket_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rocket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rocket) ProtoMessage() {}
+
+func (x *Rocket) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rocket.ProtoReflect.Descriptor instead.
+func (*Rocket) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Rocket) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *Rocket) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Rocket) GetSpeed() int64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *Rocket) GetMission() string {
+	if x != nil {
+		return x.Mission
+	}
+	return ""
+}
+
+func (x *Rocket) GetLaunchTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LaunchTime
+	}
+	return nil
+}
+
+func (x *Rocket) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Rocket) GetExplodedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExplodedAt
+	}
+	return nil
+}
+
+func (x *Rocket) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Rocket) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+func (x *Rocket) GetLastMessage() int64 {
+	if x != nil {
+		return x.LastMessage
+	}
+	return 0
+}
+
+func (x *Rocket) GetLandedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LandedAt
+	}
+	return nil
+}
+
+func (x *Rocket) GetLandingSite() string {
+	if x != nil {
+		return x.LandingSite
+	}
+	return ""
+}
+
+func (x *Rocket) GetAbortedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AbortedAt
+	}
+	return nil
+}
+
+func (x *Rocket) GetDockedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DockedAt
+	}
+	return nil
+}
+
+func (x *Rocket) GetStation() string {
+	if x != nil {
+		return x.Station
+	}
+	return ""
+}
+
+func (x *Rocket) GetTelemetry() *Telemetry {
+	if x != nil {
+		return x.Telemetry
+	}
+	return nil
+}
+
+type Telemetry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// Meters above the surface.
+	Altitude  float64 `protobuf:"fixed64,2,opt,name=altitude,proto3" json:"altitude,omitempty"`
+	Latitude  float64 `protobuf:"fixed64,3,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,4,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Percentage of the fuel capacity left.
+	Fuel float64 `protobuf:"fixed64,5,opt,name=fuel,proto3" json:"fuel,omitempty"`
+	// Degrees clockwise from north.
+	Heading       float64 `protobuf:"fixed64,6,opt,name=heading,proto3" json:"heading,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Telemetry) Reset() {
+	*x = Telemetry{}
+	mi := &file_rocket_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Telemetry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Telemetry) ProtoMessage() {}
+
+func (x *Telemetry) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Telemetry.ProtoReflect.Descriptor instead.
+func (*Telemetry) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Telemetry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Telemetry) GetAltitude() float64 {
+	if x != nil {
+		return x.Altitude
+	}
+	return 0
+}
+
+func (x *Telemetry) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Telemetry) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Telemetry) GetFuel() float64 {
+	if x != nil {
+		return x.Fuel
+	}
+	return 0
+}
+
+func (x *Telemetry) GetHeading() float64 {
+	if x != nil {
+		return x.Heading
+	}
+	return 0
+}
+
+type GetRocketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRocketRequest) Reset() {
+	*x = GetRocketRequest{}
+	mi := &file_rocket_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRocketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRocketRequest) ProtoMessage() {}
+
+func (x *GetRocketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRocketRequest.ProtoReflect.Descriptor instead.
+func (*GetRocketRequest) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRocketRequest) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+type ListRocketsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exact-match filters, ignored when empty. The status must be a rocket status.
+	Status  string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Type    string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Mission string `protobuf:"bytes,3,opt,name=mission,proto3" json:"mission,omitempty"`
+	// One of channel, type, speed, mission or status. Defaults to type.
+	SortBy string `protobuf:"bytes,4,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	// ASC or DESC. Defaults to DESC.
+	Order string `protobuf:"bytes,5,opt,name=order,proto3" json:"order,omitempty"`
+	// Maximum number of rockets per page. Defaults to 50, capped at 500.
+	PageSize int32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Token returned by a previous call to fetch the next page.
+	PageToken     string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRocketsRequest) Reset() {
+	*x = ListRocketsRequest{}
+	mi := &file_rocket_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRocketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRocketsRequest) ProtoMessage() {}
+
+func (x *ListRocketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRocketsRequest.ProtoReflect.Descriptor instead.
+func (*ListRocketsRequest) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListRocketsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListRocketsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListRocketsRequest) GetMission() string {
+	if x != nil {
+		return x.Mission
+	}
+	return ""
+}
+
+func (x *ListRocketsRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListRocketsRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ListRocketsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRocketsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListRocketsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Rockets []*Rocket              `protobuf:"bytes,1,rep,name=rockets,proto3" json:"rockets,omitempty"`
+	// Empty when there are no more pages.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int32  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRocketsResponse) Reset() {
+	*x = ListRocketsResponse{}
+	mi := &file_rocket_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRocketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRocketsResponse) ProtoMessage() {}
+
+func (x *ListRocketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRocketsResponse.ProtoReflect.Descriptor instead.
+func (*ListRocketsResponse) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListRocketsResponse) GetRockets() []*Rocket {
+	if x != nil {
+		return x.Rockets
+	}
+	return nil
+}
+
+func (x *ListRocketsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListRocketsResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+type MessageMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	MessageNumber int64                  `protobuf:"varint,2,opt,name=message_number,json=messageNumber,proto3" json:"message_number,omitempty"`
+	MessageTime   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=message_time,json=messageTime,proto3" json:"message_time,omitempty"`
+	MessageType   string                 `protobuf:"bytes,4,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	// Version of the payload shape. Unset means the first version.
+	SchemaVersion int32 `protobuf:"varint,5,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageMetadata) Reset() {
+	*x = MessageMetadata{}
+	mi := &file_rocket_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageMetadata) ProtoMessage() {}
+
+func (x *MessageMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageMetadata.ProtoReflect.Descriptor instead.
+func (*MessageMetadata) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MessageMetadata) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *MessageMetadata) GetMessageNumber() int64 {
+	if x != nil {
+		return x.MessageNumber
+	}
+	return 0
+}
+
+func (x *MessageMetadata) GetMessageTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MessageTime
+	}
+	return nil
+}
+
+func (x *MessageMetadata) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+func (x *MessageMetadata) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+type IngestMessageRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Metadata *MessageMetadata       `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Payload with the same fields as the JSON message body, e.g. {"by": 3000}.
+	Message       *structpb.Struct `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestMessageRequest) Reset() {
+	*x = IngestMessageRequest{}
+	mi := &file_rocket_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestMessageRequest) ProtoMessage() {}
+
+func (x *IngestMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestMessageRequest.ProtoReflect.Descriptor instead.
+func (*IngestMessageRequest) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *IngestMessageRequest) GetMetadata() *MessageMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *IngestMessageRequest) GetMessage() *structpb.Struct {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type IngestMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestMessageResponse) Reset() {
+	*x = IngestMessageResponse{}
+	mi := &file_rocket_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestMessageResponse) ProtoMessage() {}
+
+func (x *IngestMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestMessageResponse.ProtoReflect.Descriptor instead.
+func (*IngestMessageResponse) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *IngestMessageResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type WatchRocketsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only watch these channels. Every rocket is watched when empty.
+	Channels      []string `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRocketsRequest) Reset() {
+	*x = WatchRocketsRequest{}
+	mi := &file_rocket_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRocketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRocketsRequest) ProtoMessage() {}
+
+func (x *WatchRocketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRocketsRequest.ProtoReflect.Descriptor instead.
+func (*WatchRocketsRequest) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchRocketsRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type RocketUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rocket        *Rocket                `protobuf:"bytes,1,opt,name=rocket,proto3" json:"rocket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RocketUpdate) Reset() {
+	*x = RocketUpdate{}
+	mi := &file_rocket_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RocketUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RocketUpdate) ProtoMessage() {}
+
+func (x *RocketUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_rocket_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RocketUpdate.ProtoReflect.Descriptor instead.
+func (*RocketUpdate) Descriptor() ([]byte, []int) {
+	return file_rocket_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RocketUpdate) GetRocket() *Rocket {
+	if x != nil {
+		return x.Rocket
+	}
+	return nil
+}
+
+var File_rocket_proto protoreflect.FileDescriptor
+
+const file_rocket_proto_rawDesc = "" +
+	"\n" +
+	"\frocket.proto\x12\x0flunarrockets.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x96\x05\n" +
+	"\x06Rocket\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05speed\x18\x03 \x01(\x03R\x05speed\x12\x18\n" +
+	"\amission\x18\x04 \x01(\tR\amission\x12;\n" +
+	"\vlaunch_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"launchTime\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12;\n" +
+	"\vexploded_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"explodedAt\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12=\n" +
+	"\flast_updated\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x12!\n" +
+	"\flast_message\x18\n" +
+	" \x01(\x03R\vlastMessage\x127\n" +
+	"\tlanded_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\blandedAt\x12!\n" +
+	"\flanding_site\x18\f \x01(\tR\vlandingSite\x129\n" +
+	"\n" +
+	"aborted_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tabortedAt\x127\n" +
+	"\tdocked_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\bdockedAt\x12\x18\n" +
+	"\astation\x18\x0f \x01(\tR\astation\x128\n" +
+	"\ttelemetry\x18\x10 \x01(\v2\x1a.lunarrockets.v1.TelemetryR\ttelemetry\"\xbf\x01\n" +
+	"\tTelemetry\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1a\n" +
+	"\baltitude\x18\x02 \x01(\x01R\baltitude\x12\x1a\n" +
+	"\blatitude\x18\x03 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x04 \x01(\x01R\tlongitude\x12\x12\n" +
+	"\x04fuel\x18\x05 \x01(\x01R\x04fuel\x12\x18\n" +
+	"\aheading\x18\x06 \x01(\x01R\aheading\",\n" +
+	"\x10GetRocketRequest\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\"\xc5\x01\n" +
+	"\x12ListRocketsRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\amission\x18\x03 \x01(\tR\amission\x12\x17\n" +
+	"\asort_by\x18\x04 \x01(\tR\x06sortBy\x12\x14\n" +
+	"\x05order\x18\x05 \x01(\tR\x05order\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageToken\"\x8f\x01\n" +
+	"\x13ListRocketsResponse\x121\n" +
+	"\arockets\x18\x01 \x03(\v2\x17.lunarrockets.v1.RocketR\arockets\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"\xdb\x01\n" +
+	"\x0fMessageMetadata\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12%\n" +
+	"\x0emessage_number\x18\x02 \x01(\x03R\rmessageNumber\x12=\n" +
+	"\fmessage_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vmessageTime\x12!\n" +
+	"\fmessage_type\x18\x04 \x01(\tR\vmessageType\x12%\n" +
+	"\x0eschema_version\x18\x05 \x01(\x05R\rschemaVersion\"\x87\x01\n" +
+	"\x14IngestMessageRequest\x12<\n" +
+	"\bmetadata\x18\x01 \x01(\v2 .lunarrockets.v1.MessageMetadataR\bmetadata\x121\n" +
+	"\amessage\x18\x02 \x01(\v2\x17.google.protobuf.StructR\amessage\"/\n" +
+	"\x15IngestMessageResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"1\n" +
+	"\x13WatchRocketsRequest\x12\x1a\n" +
+	"\bchannels\x18\x01 \x03(\tR\bchannels\"?\n" +
+	"\fRocketUpdate\x12/\n" +
+	"\x06rocket\x18\x01 \x01(\v2\x17.lunarrockets.v1.RocketR\x06rocket2\xe9\x02\n" +
+	"\rRocketService\x12G\n" +
+	"\tGetRocket\x12!.lunarrockets.v1.GetRocketRequest\x1a\x17.lunarrockets.v1.Rocket\x12X\n" +
+	"\vListRockets\x12#.lunarrockets.v1.ListRocketsRequest\x1a$.lunarrockets.v1.ListRocketsResponse\x12^\n" +
+	"\rIngestMessage\x12%.lunarrockets.v1.IngestMessageRequest\x1a&.lunarrockets.v1.IngestMessageResponse\x12U\n" +
+	"\fWatchRockets\x12$.lunarrockets.v1.WatchRocketsRequest\x1a\x1d.lunarrockets.v1.RocketUpdate0\x01B\x1dZ\x1blunar-rockets/grpc/rocketpbb\x06proto3"
+
+var (
+	file_rocket_proto_rawDescOnce sync.Once
+	file_rocket_proto_rawDescData []byte
+)
+
+func file_rocket_proto_rawDescGZIP() []byte {
+	file_rocket_proto_rawDescOnce.Do(func() {
+		file_rocket_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rocket_proto_rawDesc), len(file_rocket_proto_rawDesc)))
+	})
+	return file_rocket_proto_rawDescData
+}
+
+var file_rocket_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_rocket_proto_goTypes = []any{
+	(*Rocket)(nil),                // 0: lunarrockets.v1.Rocket
+	(*Telemetry)(nil),             // 1: lunarrockets.v1.Telemetry
+	(*GetRocketRequest)(nil),      // 2: lunarrockets.v1.GetRocketRequest
+	(*ListRocketsRequest)(nil),    // 3: lunarrockets.v1.ListRocketsRequest
+	(*ListRocketsResponse)(nil),   // 4: lunarrockets.v1.ListRocketsResponse
+	(*MessageMetadata)(nil),       // 5: lunarrockets.v1.MessageMetadata
+	(*IngestMessageRequest)(nil),  // 6: lunarrockets.v1.IngestMessageRequest
+	(*IngestMessageResponse)(nil), // 7: lunarrockets.v1.IngestMessageResponse
+	(*WatchRocketsRequest)(nil),   // 8: lunarrockets.v1.WatchRocketsRequest
+	(*RocketUpdate)(nil),          // 9: lunarrockets.v1.RocketUpdate
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 11: google.protobuf.Struct
+}
+var file_rocket_proto_depIdxs = []int32{
+	10, // 0: lunarrockets.v1.Rocket.launch_time:type_name -> google.protobuf.Timestamp
+	10, // 1: lunarrockets.v1.Rocket.exploded_at:type_name -> google.protobuf.Timestamp
+	10, // 2: lunarrockets.v1.Rocket.last_updated:type_name -> google.protobuf.Timestamp
+	10, // 3: lunarrockets.v1.Rocket.landed_at:type_name -> google.protobuf.Timestamp
+	10, // 4: lunarrockets.v1.Rocket.aborted_at:type_name -> google.protobuf.Timestamp
+	10, // 5: lunarrockets.v1.Rocket.docked_at:type_name -> google.protobuf.Timestamp
+	1,  // 6: lunarrockets.v1.Rocket.telemetry:type_name -> lunarrockets.v1.Telemetry
+	10, // 7: lunarrockets.v1.Telemetry.time:type_name -> google.protobuf.Timestamp
+	0,  // 8: lunarrockets.v1.ListRocketsResponse.rockets:type_name -> lunarrockets.v1.Rocket
+	10, // 9: lunarrockets.v1.MessageMetadata.message_time:type_name -> google.protobuf.Timestamp
+	5,  // 10: lunarrockets.v1.IngestMessageRequest.metadata:type_name -> lunarrockets.v1.MessageMetadata
+	11, // 11: lunarrockets.v1.IngestMessageRequest.message:type_name -> google.protobuf.Struct
+	0,  // 12: lunarrockets.v1.RocketUpdate.rocket:type_name -> lunarrockets.v1.Rocket
+	2,  // 13: lunarrockets.v1.RocketService.GetRocket:input_type -> lunarrockets.v1.GetRocketRequest
+	3,  // 14: lunarrockets.v1.RocketService.ListRockets:input_type -> lunarrockets.v1.ListRocketsRequest
+	6,  // 15: lunarrockets.v1.RocketService.IngestMessage:input_type -> lunarrockets.v1.IngestMessageRequest
+	8,  // 16: lunarrockets.v1.RocketService.WatchRockets:input_type -> lunarrockets.v1.WatchRocketsRequest
+	0,  // 17: lunarrockets.v1.RocketService.GetRocket:output_type -> lunarrockets.v1.Rocket
+	4,  // 18: lunarrockets.v1.RocketService.ListRockets:output_type -> lunarrockets.v1.ListRocketsResponse
+	7,  // 19: lunarrockets.v1.RocketService.IngestMessage:output_type -> lunarrockets.v1.IngestMessageResponse
+	9,  // 20: lunarrockets.v1.RocketService.WatchRockets:output_type -> lunarrockets.v1.RocketUpdate
+	17, // [17:21] is the sub-list for method output_type
+	13, // [13:17] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_rocket_proto_init() }
+func file_rocket_proto_init() {
+	if File_rocket_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rocket_proto_rawDesc), len(file_rocket_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rocket_proto_goTypes,
+		DependencyIndexes: file_rocket_proto_depIdxs,
+		MessageInfos:      file_rocket_proto_msgTypes,
+	}.Build()
+	File_rocket_proto = out.File
+	file_rocket_proto_goTypes = nil
+	file_rocket_proto_depIdxs = nil
+}
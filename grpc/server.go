@@ -0,0 +1,283 @@
+package grpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/usecase"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// RocketServer implements the RocketService gRPC API on top of the use cases
+// used by the HTTP controllers
+type RocketServer struct {
+	rocketpb.UnimplementedRocketServiceServer
+	rocketUseCase        usecase.RocketUseCase
+	rocketMessageUsecase usecase.RocketMessageUsecase
+	watchInterval        time.Duration
+}
+
+// NewRocketServer creates a RocketService implementation. WatchRockets polls
+// for changes every watchInterval.
+func NewRocketServer(rocketUseCase usecase.RocketUseCase, rocketMessageUsecase usecase.RocketMessageUsecase, watchInterval time.Duration) *RocketServer {
+	return &RocketServer{
+		rocketUseCase:        rocketUseCase,
+		rocketMessageUsecase: rocketMessageUsecase,
+		watchInterval:        watchInterval,
+	}
+}
+
+// NewServer creates a gRPC server with the rocket service registered
+func NewServer(rocketServer *RocketServer, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	rocketpb.RegisterRocketServiceServer(server, rocketServer)
+	return server
+}
+
+func (s *RocketServer) GetRocket(ctx context.Context, req *rocketpb.GetRocketRequest) (*rocketpb.Rocket, error) {
+	if req.GetChannel() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing channel ID")
+	}
+
+	rocket, err := s.rocketUseCase.GetRocket(ctx, req.GetChannel())
+	if err != nil {
+		log.Printf("Error getting rocket: %v", err)
+		if errors.Is(err, domain.ErrRocketNotFound) {
+			return nil, status.Error(codes.NotFound, "rocket not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get rocket state")
+	}
+
+	return toProtoRocket(rocket), nil
+}
+
+func (s *RocketServer) ListRockets(ctx context.Context, req *rocketpb.ListRocketsRequest) (*rocketpb.ListRocketsResponse, error) {
+	if req.GetStatus() != "" && !domain.IsValidRocketStatus(req.GetStatus()) {
+		return nil, status.Error(codes.InvalidArgument, "invalid status")
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	offset := 0
+	if req.GetPageToken() != "" {
+		parsed, err := strconv.Atoi(req.GetPageToken())
+		if err != nil || parsed < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		offset = parsed
+	}
+
+	page, err := s.rocketUseCase.SearchRockets(ctx, domain.RocketQuery{
+		Status:  req.GetStatus(),
+		Type:    req.GetType(),
+		Mission: req.GetMission(),
+		SortBy:  strings.ToLower(req.GetSortBy()),
+		Order:   strings.ToUpper(req.GetOrder()),
+		Limit:   pageSize,
+		Offset:  offset,
+	})
+	if err != nil {
+		log.Printf("Error listing rockets: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get rockets")
+	}
+
+	resp := &rocketpb.ListRocketsResponse{TotalSize: int32(page.Total)}
+	for _, rocket := range page.Rockets {
+		resp.Rockets = append(resp.Rockets, toProtoRocket(rocket))
+	}
+
+	if next := offset + len(page.Rockets); len(page.Rockets) > 0 && next < page.Total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+
+	return resp, nil
+}
+
+func (s *RocketServer) IngestMessage(ctx context.Context, req *rocketpb.IngestMessageRequest) (*rocketpb.IngestMessageResponse, error) {
+	metadata := req.GetMetadata()
+	message := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{
+			Channel:       metadata.GetChannel(),
+			MessageNumber: metadata.GetMessageNumber(),
+			MessageType:   metadata.GetMessageType(),
+			SchemaVersion: int(metadata.GetSchemaVersion()),
+		},
+	}
+	// A missing timestamp converts to the Unix epoch, so it is left zero for
+	// validation to reject
+	if metadata.GetMessageTime() != nil {
+		message.Metadata.MessageTime = metadata.GetMessageTime().AsTime()
+	}
+	if req.GetMessage() != nil {
+		payload, err := req.GetMessage().MarshalJSON()
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid message payload")
+		}
+		message.Message = payload
+	}
+
+	if err := s.rocketMessageUsecase.ProcessMessage(ctx, message); err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, invalidMessageStatus(validationErr)
+		}
+		if errors.Is(err, domain.ErrChannelBufferFull) || errors.Is(err, domain.ErrBufferFull) {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		if errors.Is(err, domain.ErrMessageConflict) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if errors.Is(err, domain.ErrBusy) {
+			log.Printf("Error processing message: %v", err)
+			return nil, status.Error(codes.Unavailable, "database busy")
+		}
+		log.Printf("Error processing message: %v", err)
+		return nil, status.Error(codes.Internal, "failed to process message")
+	}
+
+	return &rocketpb.IngestMessageResponse{Status: "accepted"}, nil
+}
+
+func (s *RocketServer) WatchRockets(req *rocketpb.WatchRocketsRequest, stream grpc.ServerStreamingServer[rocketpb.RocketUpdate]) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	// Last update time sent per channel, so that only changes are streamed
+	sent := make(map[string]time.Time)
+	for {
+		rockets, err := s.watchedRockets(ctx, req.GetChannels())
+		if err != nil {
+			log.Printf("Error watching rockets: %v", err)
+			return status.Error(codes.Internal, "failed to get rockets")
+		}
+
+		for _, rocket := range rockets {
+			if last, ok := sent[rocket.Channel]; ok && last.Equal(rocket.LastUpdated) {
+				continue
+			}
+
+			if err := stream.Send(&rocketpb.RocketUpdate{Rocket: toProtoRocket(rocket)}); err != nil {
+				return err
+			}
+			sent[rocket.Channel] = rocket.LastUpdated
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchedRockets returns the current state of the watched channels, or of
+// every rocket when no channel is given
+func (s *RocketServer) watchedRockets(ctx context.Context, channels []string) ([]*domain.Rocket, error) {
+	if len(channels) == 0 {
+		return s.rocketUseCase.ListRockets(ctx, "channel", "ASC")
+	}
+
+	var rockets []*domain.Rocket
+	for _, channel := range channels {
+		rocket, err := s.rocketUseCase.GetRocket(ctx, channel)
+		if err != nil {
+			if errors.Is(err, domain.ErrRocketNotFound) {
+				continue // Not launched yet
+			}
+			return nil, err
+		}
+		rockets = append(rockets, rocket)
+	}
+
+	return rockets, nil
+}
+
+func toProtoRocket(rocket *domain.Rocket) *rocketpb.Rocket {
+	pb := &rocketpb.Rocket{
+		Channel:     rocket.Channel,
+		Type:        rocket.Type,
+		Speed:       int64(rocket.Speed),
+		Mission:     rocket.Mission,
+		LaunchTime:  timestamppb.New(rocket.LaunchTime),
+		Status:      rocket.Status,
+		Reason:      rocket.Reason,
+		LandingSite: rocket.LandingSite,
+		Station:     rocket.Station,
+		LastUpdated: timestamppb.New(rocket.LastUpdated),
+		LastMessage: rocket.LastMessage,
+	}
+
+	if rocket.ExplodedAt != nil {
+		pb.ExplodedAt = timestamppb.New(*rocket.ExplodedAt)
+	}
+
+	if rocket.LandedAt != nil {
+		pb.LandedAt = timestamppb.New(*rocket.LandedAt)
+	}
+
+	if rocket.AbortedAt != nil {
+		pb.AbortedAt = timestamppb.New(*rocket.AbortedAt)
+	}
+
+	if rocket.DockedAt != nil {
+		pb.DockedAt = timestamppb.New(*rocket.DockedAt)
+	}
+
+	if rocket.Telemetry != nil {
+		pb.Telemetry = &rocketpb.Telemetry{
+			Time:      timestamppb.New(rocket.Telemetry.Time),
+			Altitude:  rocket.Telemetry.Altitude,
+			Latitude:  rocket.Telemetry.Latitude,
+			Longitude: rocket.Telemetry.Longitude,
+			Fuel:      rocket.Telemetry.Fuel,
+			Heading:   rocket.Telemetry.Heading,
+		}
+	}
+
+	return pb
+}
+
+// invalidMessageStatus reports the invalid fields of a message as
+// BadRequest field violations
+func invalidMessageStatus(err *domain.ValidationError) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range err.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+
+	detailed, detailsErr := st.WithDetails(badRequest)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
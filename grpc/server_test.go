@@ -0,0 +1,373 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var fixedTime = time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC)
+
+// newTestClient serves the rocket service over an in-memory bufconn listener
+func newTestClient(t *testing.T, rocketUseCase *mocks.MockRocketUseCase, messageUsecase *mocks.MockRocketMessageUsecase, opts ...grpc.ServerOption) rocketpb.RocketServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(NewRocketServer(rocketUseCase, messageUsecase, 10*time.Millisecond), opts...)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return rocketpb.NewRocketServiceClient(conn)
+}
+
+func TestRocketServer_GetRocket(t *testing.T) {
+	testCases := []struct {
+		name         string
+		channel      string
+		setupMock    func(*mocks.MockRocketUseCase)
+		expectedCode codes.Code
+	}{
+		{
+			name:    "valid_rocket",
+			channel: "channel-1",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetRocket", mock.Anything, "channel-1").Return(&domain.Rocket{
+					Channel:     "channel-1",
+					Type:        "Falcon-9",
+					Speed:       1000,
+					Mission:     "ARTEMIS",
+					Status:      domain.RocketStatusLaunched,
+					LaunchTime:  fixedTime,
+					LastUpdated: fixedTime,
+				}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "missing_channel",
+			channel:      "",
+			setupMock:    func(m *mocks.MockRocketUseCase) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:    "rocket_not_found",
+			channel: "channel-2",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetRocket", mock.Anything, "channel-2").Return(nil, domain.ErrRocketNotFound)
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:    "internal_error",
+			channel: "channel-3",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetRocket", mock.Anything, "channel-3").Return(nil, errors.New("database error"))
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			rocketUseCase := &mocks.MockRocketUseCase{}
+			tc.setupMock(rocketUseCase)
+			client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{})
+
+			rocket, err := client.GetRocket(context.Background(), &rocketpb.GetRocketRequest{Channel: tc.channel})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, "Falcon-9", rocket.GetType())
+				assert.Equal(t, int64(1000), rocket.GetSpeed())
+				assert.Equal(t, fixedTime, rocket.GetLaunchTime().AsTime())
+				assert.Nil(t, rocket.GetExplodedAt())
+			}
+			rocketUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestToProtoRocket(t *testing.T) {
+	abortedAt := fixedTime.Add(time.Hour)
+	landedAt := fixedTime.Add(2 * time.Hour)
+
+	rocket := toProtoRocket(&domain.Rocket{
+		Channel:     "channel-1",
+		Type:        "Falcon-9",
+		Mission:     "ARTEMIS",
+		Status:      domain.RocketStatusLanded,
+		LaunchTime:  fixedTime,
+		Reason:      "GUIDANCE_FAILURE",
+		AbortedAt:   &abortedAt,
+		LandedAt:    &landedAt,
+		LandingSite: "CAPE_CANAVERAL",
+		Telemetry:   &domain.Telemetry{Time: landedAt, Altitude: 0, Latitude: 28.5, Longitude: -80.6, Fuel: 12.5, Heading: 90},
+		LastUpdated: landedAt,
+	})
+
+	assert.Equal(t, domain.RocketStatusLanded, rocket.GetStatus())
+	assert.Equal(t, "GUIDANCE_FAILURE", rocket.GetReason())
+	assert.Equal(t, abortedAt, rocket.GetAbortedAt().AsTime())
+	assert.Equal(t, landedAt, rocket.GetLandedAt().AsTime())
+	assert.Equal(t, "CAPE_CANAVERAL", rocket.GetLandingSite())
+	assert.Nil(t, rocket.GetExplodedAt())
+	assert.Nil(t, rocket.GetDockedAt())
+	assert.Empty(t, rocket.GetStation())
+	assert.Equal(t, landedAt, rocket.GetTelemetry().GetTime().AsTime())
+	assert.Equal(t, 28.5, rocket.GetTelemetry().GetLatitude())
+	assert.Equal(t, 12.5, rocket.GetTelemetry().GetFuel())
+	assert.Nil(t, toProtoRocket(&domain.Rocket{LaunchTime: fixedTime, LastUpdated: fixedTime}).GetTelemetry())
+}
+
+func TestRocketServer_ListRockets(t *testing.T) {
+	rockets := []*domain.Rocket{
+		{Channel: "channel-1", Type: "Falcon-9", Status: domain.RocketStatusLaunched, LaunchTime: fixedTime, LastUpdated: fixedTime},
+		{Channel: "channel-2", Type: "Falcon-9", Status: domain.RocketStatusLaunched, LaunchTime: fixedTime, LastUpdated: fixedTime},
+	}
+
+	testCases := []struct {
+		name              string
+		request           *rocketpb.ListRocketsRequest
+		expectedQuery     domain.RocketQuery
+		page              *domain.RocketPage
+		expectedCode      codes.Code
+		expectedNextToken string
+	}{
+		{
+			name:              "first_page_with_filters",
+			request:           &rocketpb.ListRocketsRequest{Status: domain.RocketStatusLaunched, SortBy: "Speed", Order: "asc", PageSize: 2},
+			expectedQuery:     domain.RocketQuery{Status: domain.RocketStatusLaunched, SortBy: "speed", Order: "ASC", Limit: 2},
+			page:              &domain.RocketPage{Rockets: rockets, Total: 5},
+			expectedCode:      codes.OK,
+			expectedNextToken: "2",
+		},
+		{
+			name:              "last_page",
+			request:           &rocketpb.ListRocketsRequest{PageToken: "4"},
+			expectedQuery:     domain.RocketQuery{Limit: defaultPageSize, Offset: 4},
+			page:              &domain.RocketPage{Rockets: rockets[:1], Total: 5},
+			expectedCode:      codes.OK,
+			expectedNextToken: "",
+		},
+		{
+			name:         "invalid_page_token",
+			request:      &rocketpb.ListRocketsRequest{PageToken: "abc"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "invalid_status",
+			request:      &rocketpb.ListRocketsRequest{Status: "Orbiting"},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			rocketUseCase := &mocks.MockRocketUseCase{}
+			if tc.page != nil {
+				rocketUseCase.On("SearchRockets", mock.Anything, tc.expectedQuery).Return(tc.page, nil)
+			}
+			client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{})
+
+			resp, err := client.ListRockets(context.Background(), tc.request)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Len(t, resp.GetRockets(), len(tc.page.Rockets))
+				assert.Equal(t, int32(tc.page.Total), resp.GetTotalSize())
+				assert.Equal(t, tc.expectedNextToken, resp.GetNextPageToken())
+			}
+			rocketUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRocketServer_IngestMessage(t *testing.T) {
+	payload, err := structpb.NewStruct(map[string]interface{}{"by": 3000})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		request      *rocketpb.IngestMessageRequest
+		processError error
+		expectCall   bool
+		expectedCode codes.Code
+	}{
+		{
+			name: "valid_message",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{
+					Channel:       "channel-1",
+					MessageNumber: 2,
+					MessageTime:   timestamppb.New(fixedTime),
+					MessageType:   domain.TypeRocketSpeedIncreased,
+				},
+				Message: payload,
+			},
+			expectCall:   true,
+			expectedCode: codes.OK,
+		},
+		{
+			name: "versioned_message",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{
+					Channel:       "channel-1",
+					MessageNumber: 2,
+					MessageTime:   timestamppb.New(fixedTime),
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					SchemaVersion: 2,
+				},
+				Message: payload,
+			},
+			expectCall:   true,
+			expectedCode: codes.OK,
+		},
+		{
+			name: "invalid_message",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{Channel: "channel-1", MessageNumber: 2},
+			},
+			processError: &domain.ValidationError{Fields: []domain.FieldError{
+				{Field: "metadata.messageTime", Message: "is required"},
+				{Field: "metadata.messageType", Message: "is required"},
+			}},
+			expectCall:   true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "processing_error",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased},
+				Message:  payload,
+			},
+			processError: errors.New("database error"),
+			expectCall:   true,
+			expectedCode: codes.Internal,
+		},
+		{
+			name: "buffer_full",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased},
+				Message:  payload,
+			},
+			processError: domain.ErrChannelBufferFull,
+			expectCall:   true,
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name: "conflicting_duplicate",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased},
+				Message:  payload,
+			},
+			processError: fmt.Errorf("message 2 of channel channel-1: %w", domain.ErrMessageConflict),
+			expectCall:   true,
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name: "database_busy",
+			request: &rocketpb.IngestMessageRequest{
+				Metadata: &rocketpb.MessageMetadata{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased},
+				Message:  payload,
+			},
+			processError: fmt.Errorf("failed to execute rocket state usecase: %w", domain.ErrBusy),
+			expectCall:   true,
+			expectedCode: codes.Unavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			messageUsecase := &mocks.MockRocketMessageUsecase{}
+			if tc.expectCall {
+				messageUsecase.On("ProcessMessage", mock.Anything, mock.MatchedBy(func(message *domain.RocketMessage) bool {
+					if message.Message == nil {
+						return message.Metadata.MessageTime.IsZero()
+					}
+					return message.Metadata.Channel == "channel-1" &&
+						message.Metadata.MessageNumber == 2 &&
+						message.Metadata.SchemaVersion == int(tc.request.GetMetadata().GetSchemaVersion()) &&
+						string(message.Message) == `{"by":3000}`
+				})).Return(tc.processError)
+			}
+			client := newTestClient(t, &mocks.MockRocketUseCase{}, messageUsecase)
+
+			resp, err := client.IngestMessage(context.Background(), tc.request)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, "accepted", resp.GetStatus())
+			}
+			if tc.expectedCode == codes.InvalidArgument {
+				details := status.Convert(err).Details()
+				require.Len(t, details, 1)
+				assert.Len(t, details[0].(*errdetails.BadRequest).GetFieldViolations(), 2)
+			}
+			messageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRocketServer_WatchRockets(t *testing.T) {
+	launched := &domain.Rocket{Channel: "channel-1", Speed: 100, LaunchTime: fixedTime, LastUpdated: fixedTime}
+	accelerated := &domain.Rocket{Channel: "channel-1", Speed: 200, LaunchTime: fixedTime, LastUpdated: fixedTime.Add(time.Second)}
+
+	rocketUseCase := &mocks.MockRocketUseCase{}
+	// The first polls see the launch, later ones the acceleration; unchanged
+	// states must not be streamed twice
+	rocketUseCase.On("GetRocket", mock.Anything, "channel-1").Return(launched, nil).Times(3)
+	rocketUseCase.On("GetRocket", mock.Anything, "channel-1").Return(accelerated, nil)
+	rocketUseCase.On("GetRocket", mock.Anything, "channel-2").Return(nil, domain.ErrRocketNotFound)
+
+	client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchRockets(ctx, &rocketpb.WatchRocketsRequest{Channels: []string{"channel-1", "channel-2"}})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), first.GetRocket().GetSpeed())
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), second.GetRocket().GetSpeed())
+
+	cancel()
+	_, err = stream.Recv()
+	assert.True(t, err == io.EOF || status.Code(err) == codes.Canceled)
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"lunar-rockets/domain"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys naming the API key and tenant of a call, the gRPC
+// counterparts of the X-API-Key and X-Tenant-ID headers
+const (
+	APIKeyMetadata   = "x-api-key"
+	TenantIDMetadata = "x-tenant-id"
+)
+
+// TenantServerOptions returns the interceptors that resolve the tenant of
+// every call and carry it in the call context
+func TenantServerOptions(resolver *domain.TenantResolver) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := tenantContext(ctx, resolver)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := tenantContext(stream.Context(), resolver)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+// tenantContext resolves the tenant from the call metadata
+func tenantContext(ctx context.Context, resolver *domain.TenantResolver) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	tenantID, err := resolver.Resolve(firstValue(md, APIKeyMetadata), firstValue(md, TenantIDMetadata))
+	if err != nil {
+		log.Printf("Rejected gRPC call: %v", err)
+		switch {
+		case errors.Is(err, domain.ErrInvalidAPIKey):
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		case errors.Is(err, domain.ErrTenantForbidden):
+			return nil, status.Error(codes.PermissionDenied, "tenant not allowed")
+		default:
+			return nil, status.Error(codes.InvalidArgument, "invalid tenant ID")
+		}
+	}
+
+	return domain.ContextWithTenant(ctx, tenantID), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream is a server stream whose context carries the tenant or the
+// identity of the call
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/grpc/rocketpb"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenantServerOptions_Unary(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apiKeys        map[string]string
+		metadata       []string
+		expectedCode   codes.Code
+		expectedTenant string
+	}{
+		{
+			name:           "default_tenant",
+			expectedCode:   codes.OK,
+			expectedTenant: domain.DefaultTenantID,
+		},
+		{
+			name:           "tenant_metadata",
+			metadata:       []string{TenantIDMetadata, "tenant-a"},
+			expectedCode:   codes.OK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:         "invalid_tenant",
+			metadata:     []string{TenantIDMetadata, "tenant a"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:           "api_key",
+			apiKeys:        map[string]string{"key-a": "tenant-a"},
+			metadata:       []string{APIKeyMetadata, "key-a"},
+			expectedCode:   codes.OK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:         "missing_api_key",
+			apiKeys:      map[string]string{"key-a": "tenant-a"},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "api_key_with_other_tenant",
+			apiKeys:      map[string]string{"key-a": "tenant-a"},
+			metadata:     []string{APIKeyMetadata, "key-a", TenantIDMetadata, "tenant-b"},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver, err := domain.NewTenantResolver(tc.apiKeys)
+			require.NoError(t, err)
+
+			var tenantID string
+			rocketUseCase := &mocks.MockRocketUseCase{}
+			rocketUseCase.On("GetRocket", mock.Anything, "channel-1").Run(func(args mock.Arguments) {
+				tenantID = domain.TenantFromContext(args.Get(0).(context.Context))
+			}).Return(&domain.Rocket{Channel: "channel-1", LaunchTime: fixedTime, LastUpdated: fixedTime}, nil).Maybe()
+
+			client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{}, TenantServerOptions(resolver)...)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), tc.metadata...)
+			_, err = client.GetRocket(ctx, &rocketpb.GetRocketRequest{Channel: "channel-1"})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			assert.Equal(t, tc.expectedTenant, tenantID)
+		})
+	}
+}
+
+func TestTenantServerOptions_Stream(t *testing.T) {
+	resolver, err := domain.NewTenantResolver(nil)
+	require.NoError(t, err)
+
+	rocketUseCase := &mocks.MockRocketUseCase{}
+	rocketUseCase.On("GetRocket", mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.TenantFromContext(ctx) == "tenant-a"
+	}), "channel-1").Return(&domain.Rocket{Channel: "channel-1", LaunchTime: fixedTime, LastUpdated: fixedTime}, nil)
+
+	client := newTestClient(t, rocketUseCase, &mocks.MockRocketMessageUsecase{}, TenantServerOptions(resolver)...)
+
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(context.Background(), TenantIDMetadata, "tenant-a"))
+	defer cancel()
+
+	stream, err := client.WatchRockets(ctx, &rocketpb.WatchRocketsRequest{Channels: []string{"channel-1"}})
+	require.NoError(t, err)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "channel-1", update.GetRocket().GetChannel())
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+)
+
+// ChannelController handles HTTP requests about message channels
+type ChannelController struct {
+	rocketMessageUsecase usecase.RocketMessageUsecase
+}
+
+// NewChannelController creates a new channel controller
+func NewChannelController(rocketMessageUsecase usecase.RocketMessageUsecase) *ChannelController {
+	return &ChannelController{
+		rocketMessageUsecase: rocketMessageUsecase,
+	}
+}
+
+// @Summary List channels
+// @Description List the channels with processed or out-of-order messages: the last processed message number, the buffered message numbers and the ranges they are waiting for, the time since the last message, and the age of the oldest buffered message
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.ChannelStatus
+// @Failure 405 {string} string "Method not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /channels [get]
+func (c *ChannelController) ListChannels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses, err := c.rocketMessageUsecase.ListChannels(r.Context())
+	if err != nil {
+		log.Printf("Error listing channels: %v", err)
+		http.Error(w, "Failed to get channels", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// @Summary Get a channel
+// @Description Get the status of a channel: the last processed message number, the buffered message numbers and the ranges they are waiting for, the time since the last message, and the age of the oldest buffered message
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel ID"
+// @Success 200 {object} domain.ChannelStatus
+// @Failure 400 {string} string "Missing channel ID"
+// @Failure 404 {string} string "Channel not found"
+// @Failure 405 {string} string "Method not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /channels/{channel} [get]
+func (c *ChannelController) GetChannel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	channel := strings.TrimPrefix(r.URL.Path, "/channels/")
+	if channel == "" {
+		http.Error(w, "Missing channel ID", http.StatusBadRequest)
+		return
+	}
+
+	status, err := c.rocketMessageUsecase.GetChannel(r.Context(), channel)
+	if err != nil {
+		if errors.Is(err, domain.ErrChannelNotFound) {
+			http.Error(w, "Channel not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting channel %s: %v", channel, err)
+		http.Error(w, "Failed to get channel", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+)
+
+// ChannelAdminController handles HTTP requests repairing channels, and lists
+// the audit log of these repairs
+type ChannelAdminController struct {
+	channelAdminUsecase usecase.ChannelAdminUsecase
+}
+
+// NewChannelAdminController creates a new channel admin controller
+func NewChannelAdminController(channelAdminUsecase usecase.ChannelAdminUsecase) *ChannelAdminController {
+	return &ChannelAdminController{
+		channelAdminUsecase: channelAdminUsecase,
+	}
+}
+
+// ChannelAdminRequest is the optional body of an admin operation on a channel
+type ChannelAdminRequest struct {
+	To     int64  `json:"to,omitempty"`     // Message number to skip to, by default the one before the first buffered message
+	Reason string `json:"reason,omitempty"` // Why the operation is made, kept in the audit log
+}
+
+// @Summary Skip a gap of a channel
+// @Description Give up on the messages of a channel up to a message number, by default the missing messages before the first buffered one, and apply the buffered messages that follow. The skipped messages are not applied, and are taken as duplicates if they are sent later. The operation is audited.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel ID"
+// @Param request body controller.ChannelAdminRequest false "Message number to skip to and reason"
+// @Success 200 {object} domain.AuditEntry
+// @Failure 400 {string} string "Invalid request"
+// @Failure 409 {string} string "No message to skip"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /channels/{channel}/skip-gap [post]
+func (c *ChannelAdminController) SkipGap(w http.ResponseWriter, r *http.Request) {
+	channel, req, ok := adminRequest(w, r, "/skip-gap")
+	if !ok {
+		return
+	}
+
+	entry, err := c.channelAdminUsecase.SkipGap(r.Context(), channel, req.To, req.Reason)
+	if err != nil {
+		if errors.Is(err, domain.ErrNothingToSkip) {
+			http.Error(w, "No message to skip", http.StatusConflict)
+			return
+		}
+		log.Printf("Error skipping gap of channel %s: %v", channel, err)
+		http.Error(w, "Failed to skip gap", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// @Summary Discard the buffered messages of a channel
+// @Description Drop the out-of-order messages a channel holds in memory. The operation is audited.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel ID"
+// @Param request body controller.ChannelAdminRequest false "Reason"
+// @Success 200 {object} domain.AuditEntry
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /channels/{channel}/discard-buffer [post]
+func (c *ChannelAdminController) DiscardBuffer(w http.ResponseWriter, r *http.Request) {
+	channel, req, ok := adminRequest(w, r, "/discard-buffer")
+	if !ok {
+		return
+	}
+
+	entry, err := c.channelAdminUsecase.DiscardBuffer(r.Context(), channel, req.Reason)
+	if err != nil {
+		log.Printf("Error discarding buffer of channel %s: %v", channel, err)
+		http.Error(w, "Failed to discard buffer", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// @Summary Reset a channel
+// @Description Delete the rocket, telemetry, message history, processed messages, dead letters, conflicts and rejections of a channel, and drop its buffered messages, so that its messages can be sent again from the first one. A ChannelReset event is published. The operation is audited.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel ID"
+// @Param request body controller.ChannelAdminRequest false "Reason"
+// @Success 200 {object} domain.AuditEntry
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Channel not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /channels/{channel}/reset [post]
+func (c *ChannelAdminController) ResetChannel(w http.ResponseWriter, r *http.Request) {
+	channel, req, ok := adminRequest(w, r, "/reset")
+	if !ok {
+		return
+	}
+
+	entry, err := c.channelAdminUsecase.ResetChannel(r.Context(), channel, req.Reason)
+	if err != nil {
+		if errors.Is(err, domain.ErrChannelNotFound) {
+			http.Error(w, "Channel not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error resetting channel %s: %v", channel, err)
+		http.Error(w, "Failed to reset channel", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// @Summary List the audit log
+// @Description List the admin operations made on channels, newest first, with who made them, why and what they changed
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Param channel query string false "Only operations on this channel"
+// @Param action query string false "Only operations of this action: skip_gap, discard_buffer or reset_channel"
+// @Param limit query int false "Maximum number of entries to return (default 100)"
+// @Success 200 {array} domain.AuditEntry
+// @Failure 400 {string} string "Invalid request"
+// @Failure 405 {string} string "Method not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /audit [get]
+func (c *ChannelAdminController) ListAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	limit, err := queryInt(params, "limit")
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := c.channelAdminUsecase.ListAudit(r.Context(), domain.AuditQuery{
+		Channel: params.Get("channel"),
+		Action:  params.Get("action"),
+		Limit:   limit,
+	})
+	if err != nil {
+		log.Printf("Error listing audit entries: %v", err)
+		http.Error(w, "Failed to get audit log", http.StatusInternalServerError)
+		return
+	}
+
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// adminRequest reads the channel of an admin operation from a path ending
+// with suffix, and the optional body. It answers the request itself when
+// either is invalid.
+func adminRequest(w http.ResponseWriter, r *http.Request, suffix string) (string, ChannelAdminRequest, bool) {
+	var req ChannelAdminRequest
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return "", req, false
+	}
+
+	channel := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/channels/"), suffix)
+	if channel == "" || strings.Contains(channel, "/") {
+		http.Error(w, "Invalid channel ID", http.StatusBadRequest)
+		return "", req, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Error decoding admin request: %v", err)
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return "", req, false
+	}
+	if req.To < 0 {
+		http.Error(w, "Invalid message number", http.StatusBadRequest)
+		return "", req, false
+	}
+
+	return channel, req, true
+}
@@ -0,0 +1,379 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChannelAdminController_SkipGap(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		setupMock      func(*mocks.MockChannelAdminUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "first_gap",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/skip-gap",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("SkipGap", mock.Anything, "channel-1", int64(0), "").Return(&domain.AuditEntry{
+					ID:        1,
+					Action:    domain.AuditActionSkipGap,
+					Channel:   "channel-1",
+					Details:   "skipped messages 2 to 2, discarded buffered messages []",
+					CreatedAt: fixedTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"action":"skip_gap","channel":"channel-1","details":"skipped messages 2 to 2, discarded buffered messages []","createdAt":"2025-05-20T09:39:15Z"}` + "\n",
+		},
+		{
+			name:   "up_to_number_with_reason",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/skip-gap",
+			body:   `{"to":4,"reason":"lost by the producer"}`,
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("SkipGap", mock.Anything, "channel-1", int64(4), "lost by the producer").Return(&domain.AuditEntry{
+					ID:        2,
+					Action:    domain.AuditActionSkipGap,
+					Channel:   "channel-1",
+					Actor:     "operator",
+					Reason:    "lost by the producer",
+					Details:   "skipped messages 2 to 4, discarded buffered messages [3]",
+					CreatedAt: fixedTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":2,"action":"skip_gap","channel":"channel-1","actor":"operator","reason":"lost by the producer","details":"skipped messages 2 to 4, discarded buffered messages [3]","createdAt":"2025-05-20T09:39:15Z"}` + "\n",
+		},
+		{
+			name:   "nothing_to_skip",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/skip-gap",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("SkipGap", mock.Anything, "channel-1", int64(0), "").Return(nil, domain.ErrNothingToSkip)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "No message to skip\n",
+		},
+		{
+			name:   "invalid_body",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/skip-gap",
+			body:   `{"to":`,
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request format\n",
+		},
+		{
+			name:   "negative_number",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/skip-gap",
+			body:   `{"to":-1}`,
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid message number\n",
+		},
+		{
+			name:   "missing_channel",
+			method: http.MethodPost,
+			url:    "/channels//skip-gap",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid channel ID\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodGet,
+			url:    "/channels/channel-1/skip-gap",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/skip-gap",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("SkipGap", mock.Anything, "channel-1", int64(0), "").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to skip gap\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockChannelAdminUsecase{}
+			controller := NewChannelAdminController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			controller.SkipGap(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelAdminController_DiscardBuffer(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		setupMock      func(*mocks.MockChannelAdminUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/discard-buffer",
+			body:   `{"reason":"stale messages"}`,
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("DiscardBuffer", mock.Anything, "channel-1", "stale messages").Return(&domain.AuditEntry{
+					ID:        1,
+					Action:    domain.AuditActionDiscardBuffer,
+					Channel:   "channel-1",
+					Reason:    "stale messages",
+					Details:   "discarded buffered messages [3 5]",
+					CreatedAt: fixedTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"action":"discard_buffer","channel":"channel-1","reason":"stale messages","details":"discarded buffered messages [3 5]","createdAt":"2025-05-20T09:39:15Z"}` + "\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodGet,
+			url:    "/channels/channel-1/discard-buffer",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/discard-buffer",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("DiscardBuffer", mock.Anything, "channel-1", "").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to discard buffer\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockChannelAdminUsecase{}
+			controller := NewChannelAdminController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			controller.DiscardBuffer(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelAdminController_ResetChannel(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		setupMock      func(*mocks.MockChannelAdminUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/reset",
+			body:   `{"reason":"replay from the first message"}`,
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("ResetChannel", mock.Anything, "channel-1", "replay from the first message").Return(&domain.AuditEntry{
+					ID:        1,
+					Action:    domain.AuditActionResetChannel,
+					Channel:   "channel-1",
+					Reason:    "replay from the first message",
+					Details:   "deleted the rocket, its telemetry and 2 processed messages, discarded buffered messages []",
+					CreatedAt: fixedTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"action":"reset_channel","channel":"channel-1","reason":"replay from the first message","details":"deleted the rocket, its telemetry and 2 processed messages, discarded buffered messages []","createdAt":"2025-05-20T09:39:15Z"}` + "\n",
+		},
+		{
+			name:   "channel_not_found",
+			method: http.MethodPost,
+			url:    "/channels/unknown/reset",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("ResetChannel", mock.Anything, "unknown", "").Return(nil, domain.ErrChannelNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Channel not found\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodPost,
+			url:    "/channels/channel-1/reset",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("ResetChannel", mock.Anything, "channel-1", "").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to reset channel\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockChannelAdminUsecase{}
+			controller := NewChannelAdminController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			controller.ResetChannel(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelAdminController_ListAudit(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		setupMock      func(*mocks.MockChannelAdminUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "filtered",
+			method: http.MethodGet,
+			url:    "/audit?channel=channel-1&action=discard_buffer&limit=5",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("ListAudit", mock.Anything, domain.AuditQuery{Channel: "channel-1", Action: "discard_buffer", Limit: 5}).Return([]*domain.AuditEntry{
+					{
+						ID:        1,
+						Action:    domain.AuditActionDiscardBuffer,
+						Channel:   "channel-1",
+						Actor:     "operator",
+						Details:   "discarded buffered messages [3]",
+						CreatedAt: fixedTime,
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"action":"discard_buffer","channel":"channel-1","actor":"operator","details":"discarded buffered messages [3]","createdAt":"2025-05-20T09:39:15Z"}]` + "\n",
+		},
+		{
+			name:   "no_entries",
+			method: http.MethodGet,
+			url:    "/audit",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("ListAudit", mock.Anything, domain.AuditQuery{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:   "invalid_limit",
+			method: http.MethodGet,
+			url:    "/audit?limit=many",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid limit\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodPost,
+			url:    "/audit",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodGet,
+			url:    "/audit",
+			setupMock: func(m *mocks.MockChannelAdminUsecase) {
+				m.On("ListAudit", mock.Anything, domain.AuditQuery{}).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get audit log\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockChannelAdminUsecase{}
+			controller := NewChannelAdminController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			controller.ListAudit(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
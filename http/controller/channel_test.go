@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChannelController_ListChannels(t *testing.T) {
+	processedAt := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	bufferedAt := time.Date(2024, 1, 1, 11, 59, 30, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		setupMock      func(*mocks.MockRocketMessageUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "channels",
+			method: http.MethodGet,
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ListChannels", mock.Anything).Return([]*domain.ChannelStatus{
+					{
+						Channel:                  "channel-1",
+						LastProcessed:            1,
+						Buffered:                 []int64{3, 4},
+						Missing:                  []domain.MessageRange{{From: 2, To: 2}},
+						LastMessageAt:            &bufferedAt,
+						SinceLastMessageSeconds:  30,
+						OldestBufferedAt:         &bufferedAt,
+						OldestBufferedAgeSeconds: 30,
+					},
+					{
+						Channel:                 "channel-2",
+						LastProcessed:           5,
+						Buffered:                []int64{},
+						Missing:                 []domain.MessageRange{},
+						LastMessageAt:           &processedAt,
+						SinceLastMessageSeconds: 3600,
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"channel":"channel-1","lastProcessed":1,"buffered":[3,4],"missing":[{"from":2,"to":2}],"lastMessageAt":"2024-01-01T11:59:30Z","sinceLastMessageSeconds":30,"oldestBufferedAt":"2024-01-01T11:59:30Z","oldestBufferedAgeSeconds":30},` +
+				`{"channel":"channel-2","lastProcessed":5,"buffered":[],"missing":[],"lastMessageAt":"2024-01-01T11:00:00Z","sinceLastMessageSeconds":3600,"oldestBufferedAgeSeconds":0}]` + "\n",
+		},
+		{
+			name:   "no_channels",
+			method: http.MethodGet,
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ListChannels", mock.Anything).Return([]*domain.ChannelStatus{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodPost,
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodGet,
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ListChannels", mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get channels\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockRocketMessageUsecase{}
+			controller := NewChannelController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, "/channels", nil)
+			w := httptest.NewRecorder()
+
+			controller.ListChannels(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelController_GetChannel(t *testing.T) {
+	processedAt := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		setupMock      func(*mocks.MockRocketMessageUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "channel",
+			method: http.MethodGet,
+			path:   "/channels/channel-1",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("GetChannel", mock.Anything, "channel-1").Return(&domain.ChannelStatus{
+					Channel:                 "channel-1",
+					LastProcessed:           5,
+					Buffered:                []int64{},
+					Missing:                 []domain.MessageRange{},
+					LastMessageAt:           &processedAt,
+					SinceLastMessageSeconds: 3600,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"channel":"channel-1","lastProcessed":5,"buffered":[],"missing":[],"lastMessageAt":"2024-01-01T11:00:00Z","sinceLastMessageSeconds":3600,"oldestBufferedAgeSeconds":0}` + "\n",
+		},
+		{
+			name:   "not_found",
+			method: http.MethodGet,
+			path:   "/channels/channel-9",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("GetChannel", mock.Anything, "channel-9").Return(nil, domain.ErrChannelNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Channel not found\n",
+		},
+		{
+			name:   "missing_channel",
+			method: http.MethodGet,
+			path:   "/channels/",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Missing channel ID\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodPost,
+			path:   "/channels/channel-1",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodGet,
+			path:   "/channels/channel-1",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("GetChannel", mock.Anything, "channel-1").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get channel\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockRocketMessageUsecase{}
+			controller := NewChannelController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			controller.GetChannel(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
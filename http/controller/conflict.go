@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+)
+
+// ConflictController handles HTTP requests about the messages that reused the
+// number of a processed message with a different content
+type ConflictController struct {
+	rocketMessageUsecase usecase.RocketMessageUsecase
+}
+
+// NewConflictController creates a new conflict controller
+func NewConflictController(rocketMessageUsecase usecase.RocketMessageUsecase) *ConflictController {
+	return &ConflictController{
+		rocketMessageUsecase: rocketMessageUsecase,
+	}
+}
+
+// @Summary List conflicting messages
+// @Description List the messages received with the number of a processed message but a different content, newest first. They are not applied. The hashes are SHA-256 of the message type, schema version, time and payload.
+// @Tags conflicts
+// @Accept json
+// @Produce json
+// @Param channel query string false "Only conflicts of this channel"
+// @Param limit query int false "Maximum number of conflicts to return (default 100)"
+// @Success 200 {array} domain.MessageConflict
+// @Failure 400 {string} string "Invalid request"
+// @Failure 405 {string} string "Method not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /conflicts [get]
+func (c *ConflictController) ListConflicts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	limit, err := queryInt(params, "limit")
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	conflicts, err := c.rocketMessageUsecase.ListConflicts(r.Context(), domain.ConflictQuery{
+		Channel: params.Get("channel"),
+		Limit:   limit,
+	})
+	if err != nil {
+		log.Printf("Error listing message conflicts: %v", err)
+		http.Error(w, "Failed to get conflicts", http.StatusInternalServerError)
+		return
+	}
+
+	if conflicts == nil {
+		conflicts = []*domain.MessageConflict{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conflicts)
+}
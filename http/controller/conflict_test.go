@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConflictController_ListConflicts(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		setupMock      func(*mocks.MockRocketMessageUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "filtered",
+			method: http.MethodGet,
+			url:    "/conflicts?channel=channel-1&limit=5",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ListConflicts", mock.Anything, domain.ConflictQuery{Channel: "channel-1", Limit: 5}).Return([]*domain.MessageConflict{
+					{
+						ID:            1,
+						Channel:       "channel-1",
+						MessageNumber: 2,
+						MessageType:   "RocketSpeedIncreased",
+						MessageTime:   fixedTime,
+						ProcessedHash: "aaa",
+						ReceivedHash:  "bbb",
+						Payload:       json.RawMessage(`{"by":300}`),
+						DetectedAt:    fixedTime,
+						Actor:         "mission-control",
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"channel":"channel-1","messageNumber":2,"messageType":"RocketSpeedIncreased","messageTime":"2025-05-20T09:39:15Z","processedHash":"aaa","receivedHash":"bbb","payload":{"by":300},"detectedAt":"2025-05-20T09:39:15Z","actor":"mission-control"}]` + "\n",
+		},
+		{
+			name:   "no_conflicts",
+			method: http.MethodGet,
+			url:    "/conflicts",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ListConflicts", mock.Anything, domain.ConflictQuery{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:   "invalid_limit",
+			method: http.MethodGet,
+			url:    "/conflicts?limit=many",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid limit\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodPost,
+			url:    "/conflicts",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodGet,
+			url:    "/conflicts",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ListConflicts", mock.Anything, domain.ConflictQuery{}).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get conflicts\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockRocketMessageUsecase{}
+			controller := NewConflictController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			controller.ListConflicts(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+)
+
+// DeadLetterController handles HTTP requests about the messages the service
+// gave up on
+type DeadLetterController struct {
+	deadLetterUsecase usecase.DeadLetterUsecase
+}
+
+// NewDeadLetterController creates a new dead letter controller
+func NewDeadLetterController(deadLetterUsecase usecase.DeadLetterUsecase) *DeadLetterController {
+	return &DeadLetterController{
+		deadLetterUsecase: deadLetterUsecase,
+	}
+}
+
+// ReplayResponse lists what became of each replayed dead letter
+type ReplayResponse struct {
+	Results []*domain.ReplayResult `json:"results"`
+}
+
+// PurgeResponse counts the purged dead letters
+type PurgeResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// @Summary List dead letters
+// @Description List the messages the service gave up on, by channel and message number: messages that could not be applied, and out-of-order messages evicted from the full buffer. Each one has the reason of its last failure and its number of attempts.
+// @Tags dead-letters
+// @Accept json
+// @Produce json
+// @Param channel query string false "Only dead letters of this channel"
+// @Param before query string false "Only dead letters created before this RFC 3339 time"
+// @Param limit query int false "Maximum number of dead letters to return (default 100)"
+// @Success 200 {array} domain.DeadLetter
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /dead-letters [get]
+func (c *DeadLetterController) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query, err := deadLetterQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	deadLetters, err := c.deadLetterUsecase.ListDeadLetters(r.Context(), query)
+	if err != nil {
+		log.Printf("Error listing dead letters: %v", err)
+		http.Error(w, "Failed to get dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	if deadLetters == nil {
+		deadLetters = []*domain.DeadLetter{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deadLetters)
+}
+
+// @Summary Replay a dead letter
+// @Description Process the message of a dead letter again. The dead letter is deleted once its message is applied, buffered or found to be a duplicate, and counts one more attempt otherwise.
+// @Tags dead-letters
+// @Accept json
+// @Produce json
+// @Param id path int true "Dead letter ID"
+// @Success 200 {object} domain.ReplayResult
+// @Failure 400 {string} string "Invalid dead letter ID"
+// @Failure 404 {string} string "Dead letter not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /dead-letters/{id}/replay [post]
+func (c *DeadLetterController) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := deadLetterID(strings.TrimSuffix(r.URL.Path, "/replay"))
+	if err != nil {
+		http.Error(w, "Invalid dead letter ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.deadLetterUsecase.ReplayDeadLetter(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeadLetterNotFound) {
+			http.Error(w, "Dead letter not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error replaying dead letter: %v", err)
+		http.Error(w, "Failed to replay dead letter", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// @Summary Replay dead letters
+// @Description Replay the matching dead letters by channel and message number. A channel stops at its first failure, and its later dead letters are skipped.
+// @Tags dead-letters
+// @Accept json
+// @Produce json
+// @Param channel query string false "Only dead letters of this channel"
+// @Param before query string false "Only dead letters created before this RFC 3339 time"
+// @Param limit query int false "Maximum number of dead letters to replay (default 100)"
+// @Success 200 {object} controller.ReplayResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /dead-letters/replay [post]
+func (c *DeadLetterController) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query, err := deadLetterQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	results, err := c.deadLetterUsecase.ReplayDeadLetters(r.Context(), query)
+	if err != nil {
+		log.Printf("Error replaying dead letters: %v", err)
+		http.Error(w, "Failed to replay dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReplayResponse{Results: results})
+}
+
+// @Summary Delete a dead letter
+// @Description Delete a dead letter without replaying it
+// @Tags dead-letters
+// @Param id path int true "Dead letter ID"
+// @Success 204 "Dead letter deleted"
+// @Failure 400 {string} string "Invalid dead letter ID"
+// @Failure 404 {string} string "Dead letter not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /dead-letters/{id} [delete]
+func (c *DeadLetterController) DeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := deadLetterID(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid dead letter ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.deadLetterUsecase.DeleteDeadLetter(r.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrDeadLetterNotFound) {
+			http.Error(w, "Dead letter not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error deleting dead letter: %v", err)
+		http.Error(w, "Failed to delete dead letter", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Purge dead letters
+// @Description Delete the matching dead letters without replaying them, every dead letter when no filter is given
+// @Tags dead-letters
+// @Produce json
+// @Param channel query string false "Only dead letters of this channel"
+// @Param before query string false "Only dead letters created before this RFC 3339 time"
+// @Success 200 {object} controller.PurgeResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /dead-letters [delete]
+func (c *DeadLetterController) PurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query, err := deadLetterQuery(r.URL.Query())
+	if err != nil || query.Limit > 0 {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := c.deadLetterUsecase.PurgeDeadLetters(r.Context(), query)
+	if err != nil {
+		log.Printf("Error purging dead letters: %v", err)
+		http.Error(w, "Failed to purge dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PurgeResponse{Deleted: deleted})
+}
+
+// deadLetterQuery parses the channel, before and limit query parameters
+func deadLetterQuery(params url.Values) (domain.DeadLetterQuery, error) {
+	limit, err := queryInt(params, "limit")
+	if err != nil {
+		return domain.DeadLetterQuery{}, err
+	}
+
+	before, err := queryTime(params, "before")
+	if err != nil {
+		return domain.DeadLetterQuery{}, err
+	}
+
+	return domain.DeadLetterQuery{
+		Channel: params.Get("channel"),
+		Before:  before,
+		Limit:   limit,
+	}, nil
+}
+
+// deadLetterID parses the ID at the end of a /dead-letters/{id} path
+func deadLetterID(path string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(path, "/dead-letters/"), 10, 64)
+}
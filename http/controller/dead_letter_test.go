@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeadLetterController(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		handler        func(*DeadLetterController) http.HandlerFunc
+		setupMock      func(*mocks.MockDeadLetterUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "list",
+			method:  http.MethodGet,
+			path:    "/dead-letters?channel=channel-1&limit=10",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.ListDeadLetters },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("ListDeadLetters", mock.Anything, domain.DeadLetterQuery{Channel: "channel-1", Limit: 10}).Return([]*domain.DeadLetter{{
+					ID:            3,
+					Channel:       "channel-1",
+					MessageNumber: 2,
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					Message:       []byte(`{}`),
+					Reason:        "database is locked",
+					Attempts:      2,
+					CreatedAt:     createdAt,
+					UpdatedAt:     createdAt,
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":3,"channel":"channel-1","messageNumber":2,"messageType":"RocketSpeedIncreased","message":{},"reason":"database is locked","attempts":2,"createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z"}]` + "\n",
+		},
+		{
+			name:    "list_empty",
+			method:  http.MethodGet,
+			path:    "/dead-letters",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.ListDeadLetters },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("ListDeadLetters", mock.Anything, domain.DeadLetterQuery{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:           "list_invalid_before",
+			method:         http.MethodGet,
+			path:           "/dead-letters?before=yesterday",
+			handler:        func(c *DeadLetterController) http.HandlerFunc { return c.ListDeadLetters },
+			setupMock:      func(m *mocks.MockDeadLetterUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request\n",
+		},
+		{
+			name:    "replay",
+			method:  http.MethodPost,
+			path:    "/dead-letters/3/replay",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.ReplayDeadLetter },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("ReplayDeadLetter", mock.Anything, int64(3)).
+					Return(&domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusApplied}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":3,"channel":"channel-1","messageNumber":2,"status":"applied"}` + "\n",
+		},
+		{
+			name:    "replay_not_found",
+			method:  http.MethodPost,
+			path:    "/dead-letters/4/replay",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.ReplayDeadLetter },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("ReplayDeadLetter", mock.Anything, int64(4)).Return(nil, domain.ErrDeadLetterNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Dead letter not found\n",
+		},
+		{
+			name:           "replay_invalid_id",
+			method:         http.MethodPost,
+			path:           "/dead-letters/abc/replay",
+			handler:        func(c *DeadLetterController) http.HandlerFunc { return c.ReplayDeadLetter },
+			setupMock:      func(m *mocks.MockDeadLetterUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid dead letter ID\n",
+		},
+		{
+			name:    "replay_matching",
+			method:  http.MethodPost,
+			path:    "/dead-letters/replay?channel=channel-1",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.ReplayDeadLetters },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("ReplayDeadLetters", mock.Anything, domain.DeadLetterQuery{Channel: "channel-1"}).Return([]*domain.ReplayResult{
+					{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.MessageStatusError, Error: "boom"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"results":[{"id":3,"channel":"channel-1","messageNumber":2,"status":"error","error":"boom"}]}` + "\n",
+		},
+		{
+			name:    "replay_matching_error",
+			method:  http.MethodPost,
+			path:    "/dead-letters/replay",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.ReplayDeadLetters },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("ReplayDeadLetters", mock.Anything, domain.DeadLetterQuery{}).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to replay dead letters\n",
+		},
+		{
+			name:    "delete",
+			method:  http.MethodDelete,
+			path:    "/dead-letters/3",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.DeleteDeadLetter },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("DeleteDeadLetter", mock.Anything, int64(3)).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "delete_not_found",
+			method:  http.MethodDelete,
+			path:    "/dead-letters/4",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.DeleteDeadLetter },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("DeleteDeadLetter", mock.Anything, int64(4)).Return(domain.ErrDeadLetterNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Dead letter not found\n",
+		},
+		{
+			name:    "purge",
+			method:  http.MethodDelete,
+			path:    "/dead-letters?channel=channel-1&before=2024-01-01T00:00:00Z",
+			handler: func(c *DeadLetterController) http.HandlerFunc { return c.PurgeDeadLetters },
+			setupMock: func(m *mocks.MockDeadLetterUsecase) {
+				m.On("PurgeDeadLetters", mock.Anything, domain.DeadLetterQuery{Channel: "channel-1", Before: createdAt}).Return(int64(5), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"deleted":5}` + "\n",
+		},
+		{
+			name:           "purge_with_limit",
+			method:         http.MethodDelete,
+			path:           "/dead-letters?limit=10",
+			handler:        func(c *DeadLetterController) http.HandlerFunc { return c.PurgeDeadLetters },
+			setupMock:      func(m *mocks.MockDeadLetterUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request\n",
+		},
+		{
+			name:           "invalid_method",
+			method:         http.MethodGet,
+			path:           "/dead-letters/3/replay",
+			handler:        func(c *DeadLetterController) http.HandlerFunc { return c.ReplayDeadLetter },
+			setupMock:      func(m *mocks.MockDeadLetterUsecase) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockDeadLetterUsecase{}
+			tc.setupMock(mockUsecase)
+			controller := NewDeadLetterController(mockUsecase)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			tc.handler(controller)(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"lunar-rockets/graphql"
+)
+
+// GraphQLController handles GraphQL queries over the rocket domain
+type GraphQLController struct {
+	service *graphql.Service
+}
+
+// NewGraphQLController creates a new GraphQL controller
+func NewGraphQLController(service *graphql.Service) *GraphQLController {
+	return &GraphQLController{
+		service: service,
+	}
+}
+
+// @Summary Run a GraphQL query
+// @Description Query rockets, their message events and fleet stats. Errors in the query are reported in the "errors" field of the response.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graphql.Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{} "GraphQL response"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 405 {string} string "Method not allowed"
+// @Security BearerAuth
+// @Router /graphql [post]
+func (c *GraphQLController) Query(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req graphql.Request
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		log.Printf("Error decoding GraphQL request: %v", err)
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	if req.Query == "" {
+		http.Error(w, "Missing query", http.StatusBadRequest)
+		return
+	}
+
+	result := c.service.Execute(r.Context(), req)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/graphql"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLController_Query(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		body           interface{}
+		setupMock      func(*mocks.MockRocketUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "valid_query",
+			method: http.MethodPost,
+			body:   graphql.Request{Query: `{ stats { total maxSpeed } }`},
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetStats", mock.Anything).Return(&domain.FleetStats{Total: 2, MaxSpeed: 300}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"stats":{"total":2,"maxSpeed":300}}}`,
+		},
+		{
+			name:           "query_error",
+			method:         http.MethodPost,
+			body:           graphql.Request{Query: `{ unknown }`},
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":null,"errors":[{"message":"Cannot query field \"unknown\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:           "invalid_method",
+			method:         http.MethodGet,
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:           "invalid_json",
+			method:         http.MethodPost,
+			body:           "invalid json",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request format\n",
+		},
+		{
+			name:           "missing_query",
+			method:         http.MethodPost,
+			body:           graphql.Request{},
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Missing query\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUseCase := &mocks.MockRocketUseCase{}
+			tc.setupMock(mockUseCase)
+
+			service, err := graphql.NewService(mockUseCase, &mocks.MockMessageRepository{}, graphql.DefaultMaxComplexity)
+			require.NoError(t, err)
+			controller := NewGraphQLController(service)
+
+			var body []byte
+			if s, ok := tc.body.(string); ok {
+				body = []byte(s)
+			} else if tc.body != nil {
+				body, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+
+			req := httptest.NewRequest(tc.method, "/graphql", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			controller.Query(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			} else {
+				assert.Equal(t, tc.expectedBody, rec.Body.String())
+			}
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
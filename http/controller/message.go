@@ -2,8 +2,11 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 
 	"lunar-rockets/domain"
 	"lunar-rockets/usecase"
@@ -12,25 +15,50 @@ import (
 // MessageController handles HTTP requests for rocket messages
 type MessageController struct {
 	rocketMessageUsecase usecase.RocketMessageUsecase
+	// Queues messages for the background workers, nil to process them in the
+	// request
+	ingestionUsecase usecase.IngestionUsecase
 }
 
-// NewMessageController creates a new message controller
-func NewMessageController(rocketMessageUsecase usecase.RocketMessageUsecase) *MessageController {
+// NewMessageController creates a new message controller. With an ingestion
+// use case, received messages are queued and answered with a receipt.
+func NewMessageController(rocketMessageUsecase usecase.RocketMessageUsecase, ingestionUsecase usecase.IngestionUsecase) *MessageController {
 	return &MessageController{
 		rocketMessageUsecase: rocketMessageUsecase,
+		ingestionUsecase:     ingestionUsecase,
 	}
 }
 
+const (
+	// MaxBatchSize bounds the body of a batch of messages
+	MaxBatchSize = 10 << 20
+	// maxBatchMessages bounds the number of messages of a batch
+	maxBatchMessages = 1000
+)
+
+// AcceptedResponse answers a received message, with the ID of its receipt
+// when it was queued
+type AcceptedResponse struct {
+	Status    string `json:"status"`
+	ReceiptID string `json:"receiptId,omitempty"`
+}
+
 // @Summary Receive a message
-// @Description Process and store a new rocket message
+// @Description Queue a new rocket message for processing, and return the ID of its receipt. When the service processes messages in the request, no receipt is returned.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param message body domain.RocketMessage true "Message to be processed"
-// @Success 202 {object} map[string]string "Message accepted"
-// @Failure 400 {string} string "Invalid request"
+// @Success 202 {object} controller.AcceptedResponse "Message accepted"
+// @Failure 400 {object} controller.ValidationErrorResponse "Invalid message"
+// @Failure 401 {string} string "Invalid signature"
+// @Failure 403 {string} string "Channel not allowed"
 // @Failure 405 {string} string "Method not allowed"
+// @Failure 409 {string} string "Message number already processed with a different content"
+// @Failure 429 {string} string "Channel buffer full"
 // @Failure 500 {string} string "Internal server error"
+// @Failure 503 {string} string "Message buffer full or database busy"
+// @Security BearerAuth
 // @Router /messages [post]
 func (c *MessageController) ReceiveMessage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -46,17 +74,39 @@ func (c *MessageController) ReceiveMessage(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if message.Metadata.Channel == "" {
-		http.Error(w, "Missing channel ID", http.StatusBadRequest)
-		return
-	}
-
-	if message.Metadata.MessageType == "" {
-		http.Error(w, "Missing message type", http.StatusBadRequest)
+	if c.ingestionUsecase != nil {
+		c.enqueueMessage(w, r, &message)
 		return
 	}
 
 	if err := c.rocketMessageUsecase.ProcessMessage(r.Context(), &message); err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			writeValidationError(w, validationErr)
+			return
+		}
+		// The producer should send out-of-order messages again once the gap
+		// before them is filled
+		if errors.Is(err, domain.ErrChannelBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Channel buffer full", http.StatusTooManyRequests)
+			return
+		}
+		if errors.Is(err, domain.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Message buffer full", http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(err, domain.ErrMessageConflict) {
+			http.Error(w, "Message number already processed with a different content", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, domain.ErrBusy) {
+			log.Printf("Error processing message: %v", err)
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Database busy", http.StatusServiceUnavailable)
+			return
+		}
 		log.Printf("Error processing message: %v", err)
 		http.Error(w, "Failed to process message", http.StatusInternalServerError)
 		return
@@ -66,3 +116,154 @@ func (c *MessageController) ReceiveMessage(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusAccepted)
 	w.Write([]byte(`{"status":"accepted"}`))
 }
+
+// BatchResponse lists what became of each message of a batch, in batch order
+type BatchResponse struct {
+	Results []*domain.MessageResult `json:"results"`
+}
+
+// @Summary Receive a batch of messages
+// @Description Process up to 1000 rocket messages at once, sent as a JSON array or as NDJSON with one message per line. The messages are grouped by channel, and the consecutive messages of a channel are applied in one transaction. Each message gets a result with its index in the batch: applied, buffered, duplicate, dead_lettered or error.
+// @Tags messages
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param messages body []domain.RocketMessage true "Messages to be processed"
+// @Success 200 {object} controller.BatchResponse "Result of each message"
+// @Failure 400 {string} string "Invalid batch format"
+// @Failure 401 {string} string "Invalid signature"
+// @Failure 403 {string} string "Channel not allowed"
+// @Failure 405 {string} string "Method not allowed"
+// @Failure 413 {string} string "Batch too large"
+// @Security BearerAuth
+// @Router /messages/batch [post]
+func (c *MessageController) ReceiveBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBatchSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Batch too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.Printf("Error reading batch: %v", err)
+		http.Error(w, "Invalid batch format", http.StatusBadRequest)
+		return
+	}
+
+	items, err := domain.SplitBatch(body)
+	if err != nil {
+		log.Printf("Error decoding batch: %v", err)
+		http.Error(w, "Invalid batch format", http.StatusBadRequest)
+		return
+	}
+	if len(items) == 0 {
+		http.Error(w, "Empty batch", http.StatusBadRequest)
+		return
+	}
+	if len(items) > maxBatchMessages {
+		http.Error(w, "Batch too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Messages that cannot be decoded fail alone, the others are processed
+	results := make([]*domain.MessageResult, len(items))
+	messages := make([]*domain.RocketMessage, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		var message domain.RocketMessage
+		if err := json.Unmarshal(item, &message); err != nil {
+			results[i] = &domain.MessageResult{Index: i, Status: domain.MessageStatusError, Error: "invalid message format"}
+			continue
+		}
+		messages = append(messages, &message)
+		indexes = append(indexes, i)
+	}
+
+	if len(messages) > 0 {
+		for j, result := range c.rocketMessageUsecase.ProcessBatch(r.Context(), messages) {
+			result.Index = indexes[j]
+			results[indexes[j]] = result
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BatchResponse{Results: results})
+}
+
+// enqueueMessage queues a message and answers with its receipt
+func (c *MessageController) enqueueMessage(w http.ResponseWriter, r *http.Request, message *domain.RocketMessage) {
+	receipt, err := c.ingestionUsecase.EnqueueMessage(r.Context(), message)
+	if err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			writeValidationError(w, validationErr)
+			return
+		}
+		log.Printf("Error queuing message: %v", err)
+		http.Error(w, "Failed to process message", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/messages/receipts/"+receipt.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(AcceptedResponse{Status: "accepted", ReceiptID: receipt.ID})
+}
+
+// @Summary Get a message receipt
+// @Description Report what became of a queued message: queued, applied, buffered until the messages before it arrive, duplicate, conflict, dead_lettered, failed with an error, or discarded from the buffer by an operator
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path string true "Receipt ID"
+// @Success 200 {object} domain.Receipt
+// @Failure 404 {string} string "Receipt not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /messages/receipts/{id} [get]
+func (c *MessageController) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/messages/receipts/")
+	if id == "" || strings.Contains(id, "/") || c.ingestionUsecase == nil {
+		http.Error(w, "Receipt not found", http.StatusNotFound)
+		return
+	}
+
+	receipt, err := c.ingestionUsecase.GetReceipt(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrReceiptNotFound) {
+			http.Error(w, "Receipt not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting receipt: %v", err)
+		http.Error(w, "Failed to get receipt", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// ValidationErrorResponse lists the invalid fields of a rejected message
+type ValidationErrorResponse struct {
+	Error  string              `json:"error"`
+	Fields []domain.FieldError `json:"fields"`
+}
+
+func writeValidationError(w http.ResponseWriter, err *domain.ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ValidationErrorResponse{
+		Error:  "Invalid message",
+		Fields: err.Fields,
+	})
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"lunar-rockets/domain"
+	"lunar-rockets/test/helper"
 	"lunar-rockets/test/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -18,7 +21,7 @@ import (
 
 func TestNewMessageController(t *testing.T) {
 	mockUsecase := &mocks.MockRocketMessageUsecase{}
-	controller := NewMessageController(mockUsecase)
+	controller := NewMessageController(mockUsecase, nil)
 
 	assert.NotNil(t, controller)
 	assert.Equal(t, mockUsecase, controller.rocketMessageUsecase)
@@ -43,11 +46,11 @@ func TestMessageController_ReceiveMessage(t *testing.T) {
 					MessageTime:   time.Now(),
 					MessageType:   domain.TypeRocketLaunched,
 				},
-				Message: domain.RocketLaunchedMessage{
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
 					Type:        "Falcon-9",
 					LaunchSpeed: 1000,
 					Mission:     "ARTEMIS",
-				},
+				}),
 			},
 			setupMock: func(m *mocks.MockRocketMessageUsecase) {
 				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
@@ -77,59 +80,124 @@ func TestMessageController_ReceiveMessage(t *testing.T) {
 			expectedBody:   "Invalid message format\n",
 		},
 		{
-			name:   "missing_channel",
+			name:   "invalid_message",
 			method: http.MethodPost,
 			body: domain.RocketMessage{
 				Metadata: domain.MessageMetadata{
 					MessageNumber: 1,
 					MessageTime:   time.Now(),
-					MessageType:   domain.TypeRocketLaunched,
+					MessageType:   domain.TypeRocketSpeedIncreased,
 				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{By: -100}),
 			},
 			setupMock: func(m *mocks.MockRocketMessageUsecase) {
-				// No mock setup needed
+				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
+					Return(&domain.ValidationError{Fields: []domain.FieldError{
+						{Field: "metadata.channel", Message: "is required"},
+						{Field: "message.by", Message: "must be greater than zero"},
+					}})
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Missing channel ID\n",
+			expectedBody:   `{"error":"Invalid message","fields":[{"field":"metadata.channel","message":"is required"},{"field":"message.by","message":"must be greater than zero"}]}` + "\n",
 		},
 		{
-			name:   "missing_message_type",
+			name:   "database_error",
 			method: http.MethodPost,
 			body: domain.RocketMessage{
 				Metadata: domain.MessageMetadata{
 					Channel:       "channel-1",
 					MessageNumber: 1,
 					MessageTime:   time.Now(),
+					MessageType:   domain.TypeRocketLaunched,
 				},
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
+					Type:        "Falcon-9",
+					LaunchSpeed: 1000,
+					Mission:     "ARTEMIS",
+				}),
 			},
 			setupMock: func(m *mocks.MockRocketMessageUsecase) {
-				// No mock setup needed
+				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
+					Return(errors.New("database error"))
 			},
-			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Missing message type\n",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to process message\n",
 		},
 		{
-			name:   "database_error",
+			name:   "channel_buffer_full",
 			method: http.MethodPost,
 			body: domain.RocketMessage{
 				Metadata: domain.MessageMetadata{
 					Channel:       "channel-1",
-					MessageNumber: 1,
+					MessageNumber: 9,
 					MessageTime:   time.Now(),
-					MessageType:   domain.TypeRocketLaunched,
+					MessageType:   domain.TypeRocketSpeedIncreased,
 				},
-				Message: domain.RocketLaunchedMessage{
-					Type:        "Falcon-9",
-					LaunchSpeed: 1000,
-					Mission:     "ARTEMIS",
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{By: 100}),
+			},
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
+					Return(domain.ErrChannelBufferFull)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   "Channel buffer full\n",
+		},
+		{
+			name:   "buffer_full",
+			method: http.MethodPost,
+			body: domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageNumber: 9,
+					MessageTime:   time.Now(),
+					MessageType:   domain.TypeRocketSpeedIncreased,
 				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{By: 100}),
 			},
 			setupMock: func(m *mocks.MockRocketMessageUsecase) {
 				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
-					Return(errors.New("database error"))
+					Return(domain.ErrBufferFull)
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "Failed to process message\n",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Message buffer full\n",
+		},
+		{
+			name:   "conflicting_duplicate",
+			method: http.MethodPost,
+			body: domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageNumber: 2,
+					MessageTime:   time.Now(),
+					MessageType:   domain.TypeRocketSpeedIncreased,
+				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{By: 100}),
+			},
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
+					Return(fmt.Errorf("message 2 of channel channel-1: %w", domain.ErrMessageConflict))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Message number already processed with a different content\n",
+		},
+		{
+			name:   "database_busy",
+			method: http.MethodPost,
+			body: domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageNumber: 2,
+					MessageTime:   time.Now(),
+					MessageType:   domain.TypeRocketSpeedIncreased,
+				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{By: 100}),
+			},
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ProcessMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).
+					Return(fmt.Errorf("failed to execute rocket state usecase: %w", domain.ErrBusy))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Database busy\n",
 		},
 	}
 
@@ -138,7 +206,7 @@ func TestMessageController_ReceiveMessage(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create a new mock for each test case
 			mockUsecase := &mocks.MockRocketMessageUsecase{}
-			controller := NewMessageController(mockUsecase)
+			controller := NewMessageController(mockUsecase, nil)
 
 			// Setup mock
 			tc.setupMock(mockUsecase)
@@ -168,3 +236,237 @@ func TestMessageController_ReceiveMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageController_ReceiveMessage_Queued(t *testing.T) {
+	message := domain.RocketMessage{
+		Metadata: domain.MessageMetadata{
+			Channel:       "channel-1",
+			MessageNumber: 1,
+			MessageTime:   time.Now(),
+			MessageType:   domain.TypeRocketLaunched,
+		},
+		Message: helper.EncodePayload(domain.RocketLaunchedMessage{Type: "Falcon-9", LaunchSpeed: 1000, Mission: "ARTEMIS"}),
+	}
+
+	testCases := []struct {
+		name             string
+		enqueueError     error
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name:             "message_queued",
+			expectedStatus:   http.StatusAccepted,
+			expectedBody:     `{"status":"accepted","receiptId":"r-1"}` + "\n",
+			expectedLocation: "/messages/receipts/r-1",
+		},
+		{
+			name:           "invalid_message",
+			enqueueError:   &domain.ValidationError{Fields: []domain.FieldError{{Field: "message.by", Message: "is required"}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid message","fields":[{"field":"message.by","message":"is required"}]}` + "\n",
+		},
+		{
+			name:           "database_error",
+			enqueueError:   errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to process message\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Messages are queued rather than processed
+			mockIngestion := &mocks.MockIngestionUsecase{}
+			if tc.enqueueError != nil {
+				mockIngestion.On("EnqueueMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).Return(nil, tc.enqueueError)
+			} else {
+				mockIngestion.On("EnqueueMessage", mock.Anything, mock.AnythingOfType("*domain.RocketMessage")).Return(&domain.Receipt{ID: "r-1"}, nil)
+			}
+			mockUsecase := &mocks.MockRocketMessageUsecase{}
+			controller := NewMessageController(mockUsecase, mockIngestion)
+
+			body, err := json.Marshal(message)
+			assert.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/messages", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			controller.ReceiveMessage(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			assert.Equal(t, tc.expectedLocation, w.Header().Get("Location"))
+			mockIngestion.AssertExpectations(t)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageController_GetReceipt(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		path           string
+		setupMock      func(*mocks.MockIngestionUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "receipt_found",
+			path: "/messages/receipts/r-1",
+			setupMock: func(m *mocks.MockIngestionUsecase) {
+				m.On("GetReceipt", mock.Anything, "r-1").Return(&domain.Receipt{
+					ID:            "r-1",
+					Channel:       "channel-1",
+					MessageNumber: 3,
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					Status:        domain.ReceiptStatusBuffered,
+					CreatedAt:     createdAt,
+					UpdatedAt:     createdAt,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"r-1","channel":"channel-1","messageNumber":3,"messageType":"RocketSpeedIncreased","status":"buffered","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name: "receipt_not_found",
+			path: "/messages/receipts/r-2",
+			setupMock: func(m *mocks.MockIngestionUsecase) {
+				m.On("GetReceipt", mock.Anything, "r-2").Return(nil, domain.ErrReceiptNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Receipt not found\n",
+		},
+		{
+			name:           "missing_id",
+			path:           "/messages/receipts/",
+			setupMock:      func(m *mocks.MockIngestionUsecase) {},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Receipt not found\n",
+		},
+		{
+			name: "database_error",
+			path: "/messages/receipts/r-1",
+			setupMock: func(m *mocks.MockIngestionUsecase) {
+				m.On("GetReceipt", mock.Anything, "r-1").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get receipt\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockIngestion := &mocks.MockIngestionUsecase{}
+			tc.setupMock(mockIngestion)
+			controller := NewMessageController(&mocks.MockRocketMessageUsecase{}, mockIngestion)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			controller.GetReceipt(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockIngestion.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageController_ReceiveBatch(t *testing.T) {
+	first := `{"metadata":{"channel":"channel-1","messageNumber":1,"messageTime":"2024-01-01T00:00:00Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":1000,"mission":"ARTEMIS"}}`
+	second := `{"metadata":{"channel":"channel-1","messageNumber":2,"messageTime":"2024-01-01T00:00:01Z","messageType":"RocketSpeedIncreased"},"message":{"by":500}}`
+
+	testCases := []struct {
+		name           string
+		method         string
+		body           string
+		setupMock      func(*mocks.MockRocketMessageUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "json_array",
+			method: http.MethodPost,
+			body:   "[" + first + "," + second + "]",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ProcessBatch", mock.Anything, mock.MatchedBy(func(messages []*domain.RocketMessage) bool {
+					return len(messages) == 2 && messages[1].Metadata.MessageNumber == 2
+				})).Return([]*domain.MessageResult{
+					{Index: 0, Channel: "channel-1", MessageNumber: 1, Status: domain.ReceiptStatusApplied},
+					{Index: 1, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusApplied},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"results":[{"index":0,"channel":"channel-1","messageNumber":1,"status":"applied"},{"index":1,"channel":"channel-1","messageNumber":2,"status":"applied"}]}` + "\n",
+		},
+		{
+			name:   "ndjson_with_invalid_line",
+			method: http.MethodPost,
+			body:   first + "\nnot json\n\n" + second + "\n",
+			setupMock: func(m *mocks.MockRocketMessageUsecase) {
+				m.On("ProcessBatch", mock.Anything, mock.MatchedBy(func(messages []*domain.RocketMessage) bool {
+					return len(messages) == 2
+				})).Return([]*domain.MessageResult{
+					{Index: 0, Channel: "channel-1", MessageNumber: 1, Status: domain.ReceiptStatusApplied},
+					{Index: 1, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusDuplicate},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"results":[{"index":0,"channel":"channel-1","messageNumber":1,"status":"applied"},{"index":1,"status":"error","error":"invalid message format"},{"index":2,"channel":"channel-1","messageNumber":2,"status":"duplicate"}]}` + "\n",
+		},
+		{
+			name:           "invalid_method",
+			method:         http.MethodGet,
+			setupMock:      func(m *mocks.MockRocketMessageUsecase) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:           "invalid_array",
+			method:         http.MethodPost,
+			body:           "[" + first,
+			setupMock:      func(m *mocks.MockRocketMessageUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid batch format\n",
+		},
+		{
+			name:           "empty_batch",
+			method:         http.MethodPost,
+			body:           "[]",
+			setupMock:      func(m *mocks.MockRocketMessageUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Empty batch\n",
+		},
+		{
+			name:           "too_many_messages",
+			method:         http.MethodPost,
+			body:           strings.Repeat(first+"\n", maxBatchMessages+1),
+			setupMock:      func(m *mocks.MockRocketMessageUsecase) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "Batch too large\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockRocketMessageUsecase{}
+			tc.setupMock(mockUsecase)
+			controller := NewMessageController(mockUsecase, nil)
+
+			req := httptest.NewRequest(tc.method, "/messages/batch", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			controller.ReceiveBatch(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"lunar-rockets/domain"
+)
+
+// MessageTypeController handles HTTP requests about the accepted message types
+type MessageTypeController struct {
+	handlers *domain.MessageRegistry
+}
+
+// NewMessageTypeController creates a new message type controller
+func NewMessageTypeController(handlers *domain.MessageRegistry) *MessageTypeController {
+	return &MessageTypeController{
+		handlers: handlers,
+	}
+}
+
+// @Summary List message types
+// @Description List the registered message types with the JSON Schema of their payload
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.MessageType
+// @Failure 405 {string} string "Method not allowed"
+// @Security BearerAuth
+// @Router /message-types [get]
+func (c *MessageTypeController) ListMessageTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.handlers.Types())
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageTypeController_ListMessageTypes(t *testing.T) {
+	registry, err := domain.NewMessageRegistry(usecase.MessageHandlers()...)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		method         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "registered_types",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedBody: `[` +
+				`{"type":"RocketAborted","schemaVersion":1,"schema":{"type":"object","properties":{"reason":{"type":"string"}},"required":["reason"],"additionalProperties":false}},` +
+				`{"type":"RocketDocked","schemaVersion":1,"schema":{"type":"object","properties":{"station":{"type":"string"}},"required":["station"],"additionalProperties":false}},` +
+				`{"type":"RocketExploded","schemaVersion":1,"schema":{"type":"object","properties":{"reason":{"type":"string"}},"required":["reason"],"additionalProperties":false}},` +
+				`{"type":"RocketLanded","schemaVersion":1,"schema":{"type":"object","properties":{"site":{"type":"string"}},"required":["site"],"additionalProperties":false}},` +
+				`{"type":"RocketLaunched","schemaVersion":1,"schema":{"type":"object","properties":{"launchSpeed":{"type":"integer"},"mission":{"type":"string"},"type":{"type":"string"}},"required":["type","launchSpeed","mission"],"additionalProperties":false}},` +
+				`{"type":"RocketMissionChanged","schemaVersion":1,"schema":{"type":"object","properties":{"newMission":{"type":"string"}},"required":["newMission"],"additionalProperties":false}},` +
+				`{"type":"RocketSpeedDecreased","schemaVersion":1,"schema":{"type":"object","properties":{"by":{"type":"integer"}},"required":["by"],"additionalProperties":false}},` +
+				`{"type":"RocketSpeedIncreased","schemaVersion":1,"schema":{"type":"object","properties":{"by":{"type":"integer"}},"required":["by"],"additionalProperties":false}},` +
+				`{"type":"RocketTelemetry","schemaVersion":1,"schema":{"type":"object","properties":{"altitude":{"type":"number"},"fuel":{"type":"number"},"heading":{"type":"number"},"latitude":{"type":"number"},"longitude":{"type":"number"}},"required":["altitude","latitude","longitude","fuel","heading"],"additionalProperties":false}}` +
+				`]` + "\n",
+		},
+		{
+			name:           "invalid_method",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			controller := NewMessageTypeController(registry)
+
+			req := httptest.NewRequest(tc.method, "/message-types", nil)
+			w := httptest.NewRecorder()
+
+			controller.ListMessageTypes(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+)
+
+// RejectionController handles HTTP requests about the messages rejected by
+// the rocket state machine
+type RejectionController struct {
+	rocketUseCase usecase.RocketUseCase
+}
+
+// NewRejectionController creates a new rejection controller
+func NewRejectionController(rocketUseCase usecase.RocketUseCase) *RejectionController {
+	return &RejectionController{
+		rocketUseCase: rocketUseCase,
+	}
+}
+
+// @Summary List rejected messages
+// @Description List the messages the rocket state machine rejected, newest first, such as messages received before the launch or after an explosion
+// @Tags rejections
+// @Accept json
+// @Produce json
+// @Param channel query string false "Only rejections of this channel"
+// @Param messageType query string false "Only rejections of this message type"
+// @Param limit query int false "Maximum number of rejections to return (default 100)"
+// @Success 200 {array} domain.Rejection
+// @Failure 400 {string} string "Invalid request"
+// @Failure 405 {string} string "Method not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Security BearerAuth
+// @Router /rejections [get]
+func (c *RejectionController) ListRejections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	limit, err := queryInt(params, "limit")
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	rejections, err := c.rocketUseCase.ListRejections(r.Context(), domain.RejectionQuery{
+		Channel:     params.Get("channel"),
+		MessageType: params.Get("messageType"),
+		Limit:       limit,
+	})
+	if err != nil {
+		log.Printf("Error listing rejections: %v", err)
+		http.Error(w, "Failed to get rejections", http.StatusInternalServerError)
+		return
+	}
+
+	if rejections == nil {
+		rejections = []*domain.Rejection{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rejections)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRejectionController_ListRejections(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		setupMock      func(*mocks.MockRocketUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "filtered",
+			method: http.MethodGet,
+			url:    "/rejections?channel=channel-1&messageType=RocketSpeedIncreased&limit=5",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("ListRejections", mock.Anything, domain.RejectionQuery{Channel: "channel-1", MessageType: "RocketSpeedIncreased", Limit: 5}).Return([]*domain.Rejection{
+					{
+						ID:            1,
+						Channel:       "channel-1",
+						MessageNumber: 1,
+						MessageType:   "RocketSpeedIncreased",
+						MessageTime:   fixedTime,
+						Status:        domain.RocketStatusNotLaunched,
+						Reason:        "RocketSpeedIncreased received before the rocket was launched",
+						RejectedAt:    fixedTime,
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"channel":"channel-1","messageNumber":1,"messageType":"RocketSpeedIncreased","messageTime":"2025-05-20T09:39:15Z","status":"NotLaunched","reason":"RocketSpeedIncreased received before the rocket was launched","rejectedAt":"2025-05-20T09:39:15Z"}]` + "\n",
+		},
+		{
+			name:   "no_rejections",
+			method: http.MethodGet,
+			url:    "/rejections",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("ListRejections", mock.Anything, domain.RejectionQuery{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:   "invalid_limit",
+			method: http.MethodGet,
+			url:    "/rejections?limit=-1",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid limit\n",
+		},
+		{
+			name:   "invalid_method",
+			method: http.MethodPost,
+			url:    "/rejections",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				// No mock setup needed
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed\n",
+		},
+		{
+			name:   "usecase_error",
+			method: http.MethodGet,
+			url:    "/rejections",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("ListRejections", mock.Anything, domain.RejectionQuery{}).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get rejections\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUseCase := &mocks.MockRocketUseCase{}
+			controller := NewRejectionController(mockUseCase)
+			tc.setupMock(mockUseCase)
+
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			controller.ListRejections(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
@@ -3,9 +3,13 @@ package controller
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"lunar-rockets/domain"
 	"lunar-rockets/usecase"
@@ -35,6 +39,7 @@ func NewRocketController(rocketUseCase usecase.RocketUseCase) *RocketController
 // @Success 200 {object} domain.Rocket
 // @Failure 400 {string} string "Invalid request"
 // @Failure 404 {string} string "Rocket not found"
+// @Security BearerAuth
 // @Router /rockets/{channel} [get]
 func (c *RocketController) GetRocket(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -69,14 +74,99 @@ func (c *RocketController) GetRocket(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(rocket)
 }
 
+// @Summary Get the telemetry of a rocket
+// @Description Retrieve the telemetry readings of a rocket, oldest first, optionally within a time range. With an interval, only the last reading of each interval is returned.
+// @Tags rockets
+// @Accept json
+// @Produce json
+// @Param channel path string true "Rocket Channel ID"
+// @Param from query string false "Only readings at or after this time (RFC 3339)"
+// @Param to query string false "Only readings at or before this time (RFC 3339)"
+// @Param interval query string false "Downsampling interval, e.g. 30s or 5m"
+// @Success 200 {array} domain.Telemetry
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Rocket not found"
+// @Security BearerAuth
+// @Router /rockets/{channel}/telemetry [get]
+func (c *RocketController) GetTelemetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	channel := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rockets/"), "/telemetry")
+	if channel == "" || strings.Contains(channel, "/") {
+		http.Error(w, "Missing channel ID", http.StatusBadRequest)
+		return
+	}
+
+	params := r.URL.Query()
+
+	from, err := queryTime(params, "from")
+	if err != nil {
+		http.Error(w, "Invalid from", http.StatusBadRequest)
+		return
+	}
+
+	to, err := queryTime(params, "to")
+	if err != nil {
+		http.Error(w, "Invalid to", http.StatusBadRequest)
+		return
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		http.Error(w, "Invalid time range", http.StatusBadRequest)
+		return
+	}
+
+	var interval time.Duration
+	if value := params.Get("interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			http.Error(w, "Invalid interval", http.StatusBadRequest)
+			return
+		}
+	}
+
+	readings, err := c.rocketUseCase.GetTelemetry(r.Context(), channel, domain.TelemetryQuery{
+		From:     from,
+		To:       to,
+		Interval: interval,
+	})
+	if err != nil {
+		log.Printf("Error getting telemetry: %v", err)
+		if errors.Is(err, domain.ErrRocketNotFound) {
+			http.Error(w, "Rocket not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get telemetry", http.StatusInternalServerError)
+		return
+	}
+
+	if readings == nil {
+		readings = []*domain.Telemetry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readings)
+}
+
 // @Summary List all rockets
-// @Description Retrieve a list of all available rockets with optional sorting
+// @Description Retrieve a list of all available rockets with optional sorting. When a filter or pagination parameter is given, the total number of matching rockets is returned in the X-Total-Count header.
 // @Tags rockets
 // @Accept json
 // @Produce json
 // @Param sort query string false "Sort field ('channel','type','speed','mission','status')"
 // @Param order query string false "Sort order ('asc' or 'desc')"
+// @Param status query string false "Only rockets with this status" Enums(Launched, Exploded, Landed, Aborted, Docked)
+// @Param type query string false "Only rockets of this type"
+// @Param mission query string false "Only rockets on this mission"
+// @Param limit query int false "Maximum number of rockets to return"
+// @Param offset query int false "Number of rockets to skip"
 // @Success 200 {array} domain.Rocket
+// @Header 200 {integer} X-Total-Count "Total number of matching rockets, when filtering or paginating"
+// @Failure 400 {string} string "Invalid request"
+// @Security BearerAuth
 // @Router /rockets [get]
 func (c *RocketController) ListRockets(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -84,8 +174,14 @@ func (c *RocketController) ListRockets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sortBy := strings.ToLower(r.URL.Query().Get("sort"))
-	order := strings.ToUpper(r.URL.Query().Get("order"))
+	params := r.URL.Query()
+	sortBy := strings.ToLower(params.Get("sort"))
+	order := strings.ToUpper(params.Get("order"))
+
+	if isSearch(params) {
+		c.searchRockets(w, r, sortBy, order)
+		return
+	}
 
 	rockets, err := c.rocketUseCase.ListRockets(r.Context(), sortBy, order)
 	if err != nil {
@@ -97,3 +193,89 @@ func (c *RocketController) ListRockets(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(rockets)
 }
+
+// searchRockets lists the rockets matching the filter and pagination
+// parameters of the request
+func (c *RocketController) searchRockets(w http.ResponseWriter, r *http.Request, sortBy string, order string) {
+	params := r.URL.Query()
+
+	status := params.Get("status")
+	if status != "" && !domain.IsValidRocketStatus(status) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := queryInt(params, "limit")
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	offset, err := queryInt(params, "offset")
+	if err != nil {
+		http.Error(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+
+	page, err := c.rocketUseCase.SearchRockets(r.Context(), domain.RocketQuery{
+		Status:  status,
+		Type:    params.Get("type"),
+		Mission: params.Get("mission"),
+		SortBy:  sortBy,
+		Order:   order,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		log.Printf("Error searching rockets: %v", err)
+		http.Error(w, "Failed to get rockets", http.StatusInternalServerError)
+		return
+	}
+
+	rockets := page.Rockets
+	if rockets == nil {
+		rockets = []*domain.Rocket{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	json.NewEncoder(w).Encode(rockets)
+}
+
+// isSearch reports whether the request filters or paginates the rockets
+func isSearch(params url.Values) bool {
+	for _, key := range []string{"status", "type", "mission", "limit", "offset"} {
+		if params.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryInt parses an optional non-negative integer query parameter
+func queryInt(params url.Values, key string) (int, error) {
+	value := params.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, value)
+	}
+	return parsed, nil
+}
+
+// queryTime parses an optional RFC 3339 time query parameter
+func queryTime(params url.Values, key string) (time.Time, error) {
+	value := params.Get(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", key, value)
+	}
+	return parsed, nil
+}
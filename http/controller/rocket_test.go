@@ -204,3 +204,234 @@ func TestRocketController_ListRockets(t *testing.T) {
 		})
 	}
 }
+
+func TestRocketController_ListRocketsFiltered(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockRocketUseCase)
+		expectedStatus int
+		expectedTotal  string
+		expectedBody   string
+	}{
+		{
+			name:  "filter_and_paginate",
+			query: "?status=Launched&type=Falcon-9&sort=speed&order=asc&limit=1&offset=2",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("SearchRockets", mock.Anything, domain.RocketQuery{
+					Status: domain.RocketStatusLaunched,
+					Type:   "Falcon-9",
+					SortBy: "speed",
+					Order:  "ASC",
+					Limit:  1,
+					Offset: 2,
+				}).Return(&domain.RocketPage{
+					Rockets: []*domain.Rocket{
+						{
+							Channel:     "channel-1",
+							Type:        "Falcon-9",
+							Speed:       1000,
+							Mission:     "ARTEMIS",
+							Status:      domain.RocketStatusLaunched,
+							LaunchTime:  fixedTime,
+							LastUpdated: fixedTime,
+							LastMessage: 1,
+						},
+					},
+					Total: 4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  "4",
+			expectedBody:   `[{"channel":"channel-1","type":"Falcon-9","speed":1000,"mission":"ARTEMIS","launchTime":"2024-03-21T00:00:00Z","status":"Launched","lastUpdated":"2024-03-21T00:00:00Z","lastMessage":1}]` + "\n",
+		},
+		{
+			name:  "no_match",
+			query: "?mission=APOLLO",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("SearchRockets", mock.Anything, domain.RocketQuery{Mission: "APOLLO"}).
+					Return(&domain.RocketPage{Total: 0}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  "0",
+			expectedBody:   "[]\n",
+		},
+		{
+			name:  "filter_docked",
+			query: "?status=Docked",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				dockedAt := fixedTime.Add(time.Hour)
+				m.On("SearchRockets", mock.Anything, domain.RocketQuery{Status: domain.RocketStatusDocked}).
+					Return(&domain.RocketPage{
+						Rockets: []*domain.Rocket{
+							{
+								Channel:     "channel-2",
+								Type:        "Falcon-9",
+								Speed:       500,
+								Mission:     "GATEWAY",
+								Status:      domain.RocketStatusDocked,
+								LaunchTime:  fixedTime,
+								DockedAt:    &dockedAt,
+								Station:     "GATEWAY",
+								LastUpdated: dockedAt,
+								LastMessage: 4,
+							},
+						},
+						Total: 1,
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  "1",
+			expectedBody:   `[{"channel":"channel-2","type":"Falcon-9","speed":500,"mission":"GATEWAY","launchTime":"2024-03-21T00:00:00Z","status":"Docked","dockedAt":"2024-03-21T01:00:00Z","station":"GATEWAY","lastUpdated":"2024-03-21T01:00:00Z","lastMessage":4}]` + "\n",
+		},
+		{
+			name:           "invalid_status",
+			query:          "?status=Orbiting",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid status\n",
+		},
+		{
+			name:           "invalid_limit",
+			query:          "?limit=-1",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid limit\n",
+		},
+		{
+			name:           "invalid_offset",
+			query:          "?offset=abc",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid offset\n",
+		},
+		{
+			name:  "database_error",
+			query: "?status=Launched",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("SearchRockets", mock.Anything, domain.RocketQuery{Status: domain.RocketStatusLaunched}).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get rockets\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockRocketUseCase{}
+			controller := NewRocketController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/rockets"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			controller.ListRockets(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedTotal, w.Header().Get("X-Total-Count"))
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRocketController_GetTelemetry(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		setupMock      func(*mocks.MockRocketUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "range_and_interval",
+			path: "/rockets/channel-1/telemetry?from=2024-03-21T00:00:00Z&to=2024-03-21T01:00:00Z&interval=5m",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetTelemetry", mock.Anything, "channel-1", domain.TelemetryQuery{
+					From:     fixedTime,
+					To:       fixedTime.Add(time.Hour),
+					Interval: 5 * time.Minute,
+				}).Return([]*domain.Telemetry{
+					{Time: fixedTime, Altitude: 1200.5, Latitude: 28.5, Longitude: -80.6, Fuel: 97, Heading: 90},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"time":"2024-03-21T00:00:00Z","altitude":1200.5,"latitude":28.5,"longitude":-80.6,"fuel":97,"heading":90}]` + "\n",
+		},
+		{
+			name: "no_readings",
+			path: "/rockets/channel-1/telemetry",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetTelemetry", mock.Anything, "channel-1", domain.TelemetryQuery{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:           "invalid_from",
+			path:           "/rockets/channel-1/telemetry?from=yesterday",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid from\n",
+		},
+		{
+			name:           "inverted_range",
+			path:           "/rockets/channel-1/telemetry?from=2024-03-21T01:00:00Z&to=2024-03-21T00:00:00Z",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid time range\n",
+		},
+		{
+			name:           "invalid_interval",
+			path:           "/rockets/channel-1/telemetry?interval=-1m",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid interval\n",
+		},
+		{
+			name:           "missing_channel",
+			path:           "/rockets//telemetry",
+			setupMock:      func(m *mocks.MockRocketUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Missing channel ID\n",
+		},
+		{
+			name: "rocket_not_found",
+			path: "/rockets/channel-2/telemetry",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetTelemetry", mock.Anything, "channel-2", domain.TelemetryQuery{}).Return(nil, domain.ErrRocketNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Rocket not found\n",
+		},
+		{
+			name: "database_error",
+			path: "/rockets/channel-1/telemetry",
+			setupMock: func(m *mocks.MockRocketUseCase) {
+				m.On("GetTelemetry", mock.Anything, "channel-1", domain.TelemetryQuery{}).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get telemetry\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := &mocks.MockRocketUseCase{}
+			controller := NewRocketController(mockUsecase)
+			tc.setupMock(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			controller.GetTelemetry(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
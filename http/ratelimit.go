@@ -0,0 +1,208 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/metrics"
+	"lunar-rockets/ratelimit"
+)
+
+// RateLimiter throttles the messages of each client and of each channel, so
+// that one producer cannot starve the others
+type RateLimiter struct {
+	clients   *ratelimit.Limiter // By client, nil for no limit
+	channels  *ratelimit.Limiter // By tenant and channel, nil for no limit
+	throttled *metrics.CounterVec
+}
+
+// NewRateLimiter creates a rate limiter counting throttled requests in
+// throttled by limit, "client" or "channel"
+func NewRateLimiter(clients *ratelimit.Limiter, channels *ratelimit.Limiter, throttled *metrics.CounterVec) *RateLimiter {
+	return &RateLimiter{
+		clients:   clients,
+		channels:  channels,
+		throttled: throttled,
+	}
+}
+
+// Limit calls next unless the client or the channel of the message is over
+// its quota, in which case it answers 429. A batch of messages takes one token
+// per message from the client, and from the channel of each message, and is
+// answered 413 when it takes more tokens than a quota ever allows. A refused
+// request takes no tokens, as those it took from the client or from earlier
+// channels are given back. It must
+// run after authentication, as clients are known by their identity when they
+// have one. A nil limiter lets every request through.
+func (l *RateLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		var decisions []ratelimit.Decision
+		var taken []func() // Give back the tokens taken so far
+
+		batch := req.URL.Path == "/messages/batch"
+		messages := 1
+		var channels []channelCount
+		if l.channels != nil || batch {
+			var err error
+			channels, messages, err = peekChannels(w, req, batch)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				log.Printf("Error reading message: %v", err)
+				http.Error(w, "Invalid message format", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if l.clients != nil {
+			client := clientKey(req)
+			decision := l.clients.AllowN(client, max(messages, 1))
+			decisions = append(decisions, decision)
+			if !decision.Allowed {
+				log.Printf("Throttled messages of client %s", client)
+				l.throttle(w, "client", decision)
+				return
+			}
+			taken = append(taken, func() { l.clients.ReturnN(client, max(messages, 1)) })
+		}
+
+		// A message without a channel is left to the controller to reject
+		if l.channels != nil {
+			for _, channel := range channels {
+				key := domain.TenantFromContext(req.Context()) + "/" + channel.channel
+				decision := l.channels.AllowN(key, channel.messages)
+				decisions = append(decisions, decision)
+				if !decision.Allowed {
+					log.Printf("Throttled messages of channel %s", channel.channel)
+					for _, giveBack := range taken {
+						giveBack()
+					}
+					l.throttle(w, "channel", decision)
+					return
+				}
+				taken = append(taken, func() { l.channels.ReturnN(key, channel.messages) })
+			}
+		}
+
+		setRateLimitHeaders(w, decisions)
+		next(w, req)
+	}
+}
+
+// throttle refuses a request over the quota of the decision, whose headers
+// are the only ones reported as the tokens of the other quotas were given back
+func (l *RateLimiter) throttle(w http.ResponseWriter, limit string, decision ratelimit.Decision) {
+	l.throttled.Inc(limit)
+	setRateLimitHeaders(w, []ratelimit.Decision{decision})
+
+	// Waiting would not help, the batch has to be split
+	if decision.OverQuota {
+		http.Error(w, fmt.Sprintf("Batch exceeds the %s quota of %d messages", limit, decision.Limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.Header().Set("Retry-After", strconv.FormatInt(ceilSeconds(decision.RetryAfter), 10))
+	http.Error(w, "Too many requests", http.StatusTooManyRequests)
+}
+
+// setRateLimitHeaders reports the most restrictive of the quotas applied to
+// the request in the RateLimit headers
+func setRateLimitHeaders(w http.ResponseWriter, decisions []ratelimit.Decision) {
+	if len(decisions) == 0 {
+		return
+	}
+
+	tightest := decisions[0]
+	for _, decision := range decisions[1:] {
+		if decision.Remaining < tightest.Remaining {
+			tightest = decision
+		}
+	}
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(tightest.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(tightest.Reset), 10))
+}
+
+// clientKey identifies the client of a request by its authenticated identity,
+// or by its address when it has none
+func clientKey(req *http.Request) string {
+	if identity := domain.IdentityFromContext(req.Context()); identity != nil {
+		return identity.Subject
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// channelCount is the number of messages of a request sent to a channel
+type channelCount struct {
+	channel  string
+	messages int
+}
+
+// peekChannels returns the channels of the message, or of the messages of the
+// batch, in the request body in order of appearance, and the number of
+// messages. It leaves the body to be read again, and fails with an
+// *http.MaxBytesError when the body is too large to be read.
+func peekChannels(w http.ResponseWriter, req *http.Request, batch bool) ([]channelCount, int, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize(batch)))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	items := []json.RawMessage{body}
+	if batch {
+		if items, err = domain.SplitBatch(body); err != nil {
+			return nil, 0, nil
+		}
+	}
+
+	var channels []channelCount
+	indexes := make(map[string]int)
+	for _, item := range items {
+		var message struct {
+			Metadata struct {
+				Channel string `json:"channel"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(item, &message); err != nil || message.Metadata.Channel == "" {
+			continue
+		}
+
+		i, exists := indexes[message.Metadata.Channel]
+		if !exists {
+			i = len(channels)
+			indexes[message.Metadata.Channel] = i
+			channels = append(channels, channelCount{channel: message.Metadata.Channel})
+		}
+		channels[i].messages++
+	}
+
+	return channels, len(items), nil
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/http/controller"
+	"lunar-rockets/metrics"
+	"lunar-rockets/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Limit(t *testing.T) {
+	message := func(channel string) string {
+		return `{"metadata":{"channel":"` + channel + `","messageNumber":1}}`
+	}
+
+	type request struct {
+		client string // Identity subject, empty for an anonymous client
+		tenant string
+		body   string
+		batch  bool // Posted to /messages/batch rather than /messages
+	}
+
+	testCases := []struct {
+		name               string
+		clientQuota        string
+		channelQuota       string
+		requests           []request
+		expectedStatuses   []int
+		expectedRetryAfter string
+		expectedThrottled  map[string]float64
+	}{
+		{
+			name:               "client_quota",
+			clientQuota:        "2/1m",
+			requests:           []request{{client: "a", body: message("c1")}, {client: "a", body: message("c2")}, {client: "a", body: message("c3")}, {client: "b", body: message("c1")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusAccepted, http.StatusTooManyRequests, http.StatusAccepted},
+			expectedRetryAfter: "30",
+			expectedThrottled: map[string]float64{
+				"client": 1,
+			},
+		},
+		{
+			name:               "anonymous_clients_by_address",
+			clientQuota:        "1/1m",
+			requests:           []request{{body: message("c1")}, {body: message("c2")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusTooManyRequests},
+			expectedRetryAfter: "60",
+			expectedThrottled: map[string]float64{
+				"client": 1,
+			},
+		},
+		{
+			name:               "channel_quota",
+			channelQuota:       "1/1m",
+			requests:           []request{{client: "a", body: message("c1")}, {client: "b", body: message("c1")}, {client: "a", body: message("c2")}, {client: "a", tenant: "tenant-a", body: message("c1")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusTooManyRequests, http.StatusAccepted, http.StatusAccepted},
+			expectedRetryAfter: "60",
+			expectedThrottled: map[string]float64{
+				"channel": 1,
+			},
+		},
+		{
+			name:               "batch_takes_a_token_per_message",
+			clientQuota:        "2/1m",
+			requests:           []request{{client: "a", batch: true, body: "[" + message("c1") + "," + message("c2") + "]"}, {client: "a", body: message("c3")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusTooManyRequests},
+			expectedRetryAfter: "30",
+			expectedThrottled: map[string]float64{
+				"client": 1,
+			},
+		},
+		{
+			name:               "batch_takes_tokens_of_each_channel",
+			channelQuota:       "2/1m",
+			requests:           []request{{client: "a", batch: true, body: message("c1") + "\n" + message("c2") + "\n" + message("c1")}, {client: "a", batch: true, body: message("c2") + "\n" + message("c1")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusTooManyRequests},
+			expectedRetryAfter: "30",
+			expectedThrottled: map[string]float64{
+				"channel": 1,
+			},
+		},
+		{
+			name:               "refused_channel_gives_back_client_tokens",
+			clientQuota:        "2/1m",
+			channelQuota:       "1/1m",
+			requests:           []request{{client: "a", body: message("c1")}, {client: "a", body: message("c1")}, {client: "a", body: message("c2")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusTooManyRequests, http.StatusAccepted},
+			expectedRetryAfter: "60",
+			expectedThrottled: map[string]float64{
+				"channel": 1,
+			},
+		},
+		{
+			name:               "refused_channel_gives_back_earlier_channel_tokens",
+			channelQuota:       "1/1m",
+			requests:           []request{{client: "a", body: message("c2")}, {client: "a", batch: true, body: message("c1") + "\n" + message("c2")}, {client: "a", body: message("c1")}},
+			expectedStatuses:   []int{http.StatusAccepted, http.StatusTooManyRequests, http.StatusAccepted},
+			expectedRetryAfter: "60",
+			expectedThrottled: map[string]float64{
+				"channel": 1,
+			},
+		},
+		{
+			name:             "batch_over_client_quota",
+			clientQuota:      "2/1m",
+			requests:         []request{{client: "a", batch: true, body: "[" + message("c1") + "," + message("c2") + "," + message("c3") + "]"}, {client: "a", body: message("c1")}},
+			expectedStatuses: []int{http.StatusRequestEntityTooLarge, http.StatusAccepted},
+			expectedThrottled: map[string]float64{
+				"client": 1,
+			},
+		},
+		{
+			name:             "batch_over_channel_quota",
+			channelQuota:     "2/1m",
+			requests:         []request{{client: "a", batch: true, body: message("c1") + "\n" + message("c2") + "\n" + message("c1") + "\n" + message("c1")}, {client: "a", batch: true, body: message("c2") + "\n" + message("c1")}},
+			expectedStatuses: []int{http.StatusRequestEntityTooLarge, http.StatusAccepted},
+			expectedThrottled: map[string]float64{
+				"channel": 1,
+			},
+		},
+		{
+			name:             "invalid_message_passes",
+			channelQuota:     "1/1m",
+			requests:         []request{{body: "not json"}, {body: "not json"}},
+			expectedStatuses: []int{http.StatusAccepted, http.StatusAccepted},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var clients, channels *ratelimit.Limiter
+			if tc.clientQuota != "" {
+				quota, err := ratelimit.ParseQuota(tc.clientQuota)
+				require.NoError(t, err)
+				clients, err = ratelimit.NewLimiter(quota, nil)
+				require.NoError(t, err)
+			}
+			if tc.channelQuota != "" {
+				quota, err := ratelimit.ParseQuota(tc.channelQuota)
+				require.NoError(t, err)
+				channels, err = ratelimit.NewLimiter(quota, nil)
+				require.NoError(t, err)
+			}
+			throttled := metrics.NewRegistry().Counter("throttled_total", "Throttled requests", "limit")
+
+			handler := NewRateLimiter(clients, channels, throttled).Limit(func(w http.ResponseWriter, r *http.Request) {
+				// The body is left for the controller
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.NotEmpty(t, body)
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			for i, r := range tc.requests {
+				path := "/messages"
+				if r.batch {
+					path = "/messages/batch"
+				}
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(r.body))
+				ctx := req.Context()
+				if r.client != "" {
+					ctx = domain.ContextWithIdentity(ctx, &domain.Identity{Subject: r.client})
+				}
+				if r.tenant != "" {
+					ctx = domain.ContextWithTenant(ctx, r.tenant)
+				}
+				rec := httptest.NewRecorder()
+
+				handler(rec, req.WithContext(ctx))
+
+				assert.Equal(t, tc.expectedStatuses[i], rec.Code, "request %d", i)
+				if rec.Code == http.StatusTooManyRequests {
+					assert.Equal(t, tc.expectedRetryAfter, rec.Header().Get("Retry-After"))
+					assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+				}
+				// Retrying a batch over the quota never succeeds
+				if rec.Code == http.StatusRequestEntityTooLarge {
+					assert.Empty(t, rec.Header().Get("Retry-After"))
+					assert.Contains(t, rec.Body.String(), "quota of 2 messages")
+				}
+			}
+
+			for limit, expected := range tc.expectedThrottled {
+				assert.Equal(t, expected, throttled.Value(limit), limit)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Headers(t *testing.T) {
+	clients, err := ratelimit.NewLimiter(ratelimit.Quota{Requests: 10, Period: 10 * time.Second}, nil)
+	require.NoError(t, err)
+	channels, err := ratelimit.NewLimiter(ratelimit.Quota{Requests: 2, Period: 10 * time.Second}, nil)
+	require.NoError(t, err)
+	throttled := metrics.NewRegistry().Counter("throttled_total", "Throttled requests", "limit")
+
+	handler := NewRateLimiter(clients, channels, throttled).Limit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(`{"metadata":{"channel":"c1"}}`)))
+
+	// The channel quota is the tightest
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "5", rec.Header().Get("RateLimit-Reset"))
+
+	// A nil limiter lets every request through
+	var none *RateLimiter
+	rec = httptest.NewRecorder()
+	none.Limit(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) })(rec, httptest.NewRequest(http.MethodPost, "/messages", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimiter_BodyTooLarge(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		size int
+	}{
+		{
+			name: "message",
+			path: "/messages",
+			size: maxMessageBodySize + 1,
+		},
+		{
+			name: "batch",
+			path: "/messages/batch",
+			size: controller.MaxBatchSize + 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			channels, err := ratelimit.NewLimiter(ratelimit.Quota{Requests: 10, Period: time.Minute}, nil)
+			require.NoError(t, err)
+			throttled := metrics.NewRegistry().Counter("throttled_total", "Throttled requests", "limit")
+
+			called := false
+			handler := NewRateLimiter(nil, channels, throttled).Limit(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(strings.Repeat(" ", tc.size)))
+			rec := httptest.NewRecorder()
+
+			handler(rec, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+			assert.False(t, called)
+		})
+	}
+}
@@ -1,24 +1,49 @@
 package http
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
 	_ "lunar-rockets/docs"
+	"lunar-rockets/domain"
 	"lunar-rockets/http/controller"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 type Router struct {
-	messageController *controller.MessageController
-	rocketController  *controller.RocketController
+	messageController      *controller.MessageController
+	rocketController       *controller.RocketController
+	channelController      *controller.ChannelController
+	rejectionController    *controller.RejectionController
+	messageTypeController  *controller.MessageTypeController
+	graphqlController      *controller.GraphQLController
+	deadLetterController   *controller.DeadLetterController
+	conflictController     *controller.ConflictController
+	channelAdminController *controller.ChannelAdminController
+
+	// Validates the bearer token of every API request, nil to leave the API
+	// open
+	tokens domain.TokenValidator
+	// Throttles the messages of each client and channel, nil for no limits
+	limiter *RateLimiter
 }
 
-func NewRouter(messageController *controller.MessageController, rocketController *controller.RocketController) http.Handler {
+func NewRouter(messageController *controller.MessageController, rocketController *controller.RocketController, channelController *controller.ChannelController, rejectionController *controller.RejectionController, messageTypeController *controller.MessageTypeController, graphqlController *controller.GraphQLController, deadLetterController *controller.DeadLetterController, conflictController *controller.ConflictController, channelAdminController *controller.ChannelAdminController, tokens domain.TokenValidator, limiter *RateLimiter) http.Handler {
 	router := &Router{
-		messageController: messageController,
-		rocketController:  rocketController,
+		messageController:      messageController,
+		rocketController:       rocketController,
+		channelController:      channelController,
+		rejectionController:    rejectionController,
+		messageTypeController:  messageTypeController,
+		graphqlController:      graphqlController,
+		deadLetterController:   deadLetterController,
+		conflictController:     conflictController,
+		channelAdminController: channelAdminController,
+		tokens:                 tokens,
+		limiter:                limiter,
 	}
 
 	return router
@@ -33,19 +58,144 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if req.Method == http.MethodPost && path == "/messages" {
-		r.messageController.ReceiveMessage(w, req)
+		r.serve(w, req, domain.ScopeMessagesWrite, r.limiter.Limit(r.messageController.ReceiveMessage))
+		return
+	}
+
+	if req.Method == http.MethodPost && path == "/messages/batch" {
+		r.serve(w, req, domain.ScopeMessagesWrite, r.limiter.Limit(r.messageController.ReceiveBatch))
+		return
+	}
+
+	if req.Method == http.MethodGet && strings.HasPrefix(path, "/messages/receipts/") {
+		r.serve(w, req, domain.ScopeMessagesWrite, r.messageController.GetReceipt)
 		return
 	}
 
 	if req.Method == http.MethodGet && path == "/rockets" {
-		r.rocketController.ListRockets(w, req)
+		r.serve(w, req, domain.ScopeRocketsRead, r.rocketController.ListRockets)
+		return
+	}
+
+	if req.Method == http.MethodGet && strings.HasPrefix(path, "/rockets/") && strings.HasSuffix(path, "/telemetry") {
+		r.serve(w, req, domain.ScopeRocketsRead, r.rocketController.GetTelemetry)
 		return
 	}
 
 	if req.Method == http.MethodGet && strings.HasPrefix(path, "/rockets/") {
-		r.rocketController.GetRocket(w, req)
+		r.serve(w, req, domain.ScopeRocketsRead, r.rocketController.GetRocket)
+		return
+	}
+
+	if req.Method == http.MethodGet && path == "/channels" {
+		r.serve(w, req, domain.ScopeRocketsRead, r.channelController.ListChannels)
+		return
+	}
+
+	if req.Method == http.MethodPost && strings.HasPrefix(path, "/channels/") && strings.HasSuffix(path, "/skip-gap") {
+		r.serve(w, req, domain.ScopeAdmin, r.channelAdminController.SkipGap)
+		return
+	}
+
+	if req.Method == http.MethodPost && strings.HasPrefix(path, "/channels/") && strings.HasSuffix(path, "/discard-buffer") {
+		r.serve(w, req, domain.ScopeAdmin, r.channelAdminController.DiscardBuffer)
+		return
+	}
+
+	if req.Method == http.MethodPost && strings.HasPrefix(path, "/channels/") && strings.HasSuffix(path, "/reset") {
+		r.serve(w, req, domain.ScopeAdmin, r.channelAdminController.ResetChannel)
+		return
+	}
+
+	if req.Method == http.MethodGet && path == "/audit" {
+		r.serve(w, req, domain.ScopeAdmin, r.channelAdminController.ListAudit)
+		return
+	}
+
+	if req.Method == http.MethodGet && strings.HasPrefix(path, "/channels/") {
+		r.serve(w, req, domain.ScopeRocketsRead, r.channelController.GetChannel)
+		return
+	}
+
+	if req.Method == http.MethodGet && path == "/rejections" {
+		r.serve(w, req, domain.ScopeRocketsRead, r.rejectionController.ListRejections)
+		return
+	}
+
+	if req.Method == http.MethodGet && path == "/conflicts" {
+		r.serve(w, req, domain.ScopeRocketsRead, r.conflictController.ListConflicts)
+		return
+	}
+
+	if req.Method == http.MethodGet && path == "/message-types" {
+		r.serve(w, req, domain.ScopeRocketsRead, r.messageTypeController.ListMessageTypes)
+		return
+	}
+
+	if req.Method == http.MethodGet && path == "/dead-letters" {
+		r.serve(w, req, domain.ScopeAdmin, r.deadLetterController.ListDeadLetters)
+		return
+	}
+
+	if req.Method == http.MethodDelete && path == "/dead-letters" {
+		r.serve(w, req, domain.ScopeAdmin, r.deadLetterController.PurgeDeadLetters)
+		return
+	}
+
+	if req.Method == http.MethodPost && path == "/dead-letters/replay" {
+		r.serve(w, req, domain.ScopeAdmin, r.deadLetterController.ReplayDeadLetters)
+		return
+	}
+
+	if req.Method == http.MethodPost && strings.HasPrefix(path, "/dead-letters/") && strings.HasSuffix(path, "/replay") {
+		r.serve(w, req, domain.ScopeAdmin, r.deadLetterController.ReplayDeadLetter)
+		return
+	}
+
+	if req.Method == http.MethodDelete && strings.HasPrefix(path, "/dead-letters/") {
+		r.serve(w, req, domain.ScopeAdmin, r.deadLetterController.DeleteDeadLetter)
+		return
+	}
+
+	if req.Method == http.MethodPost && path == "/graphql" {
+		r.serve(w, req, domain.ScopeRocketsRead, r.graphqlController.Query)
 		return
 	}
 
 	http.NotFound(w, req)
 }
+
+// serve calls the route's handler once the caller is authenticated and
+// granted the route's scope, with the caller's identity in the request
+// context
+func (r *Router) serve(w http.ResponseWriter, req *http.Request, scope string, handler http.HandlerFunc) {
+	if r.tokens == nil {
+		handler(w, req)
+		return
+	}
+
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	identity, err := r.tokens.Validate(strings.TrimSpace(token))
+	if err != nil {
+		log.Printf("Rejected request to %s %s: %v", req.Method, req.URL.Path, err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	if !identity.HasScope(scope) {
+		log.Printf("Rejected request to %s %s by %s: %v %s", req.Method, req.URL.Path, identity.Subject, domain.ErrInsufficientScope, scope)
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+		http.Error(w, "Insufficient scope", http.StatusForbidden)
+		return
+	}
+
+	log.Printf("Authorized %s %s for %s", req.Method, req.URL.Path, identity.Subject)
+	handler(w, req.WithContext(domain.ContextWithIdentity(req.Context(), identity)))
+}
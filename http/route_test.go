@@ -0,0 +1,240 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/http/controller"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubTokens accepts the tokens it knows, as the identity they map to
+type stubTokens map[string]*domain.Identity
+
+func (s stubTokens) Validate(token string) (*domain.Identity, error) {
+	if identity, ok := s[token]; ok {
+		return identity, nil
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+func TestRouter_Scopes(t *testing.T) {
+	const message = `{"metadata":{"channel":"channel-1","messageNumber":1,"messageTime":"2024-01-01T00:00:00Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`
+
+	tokens := stubTokens{
+		"reader":   {Subject: "dashboard", Scopes: []string{domain.ScopeRocketsRead}},
+		"producer": {Subject: "ground-station", Scopes: []string{domain.ScopeMessagesWrite}},
+		"admin":    {Subject: "operator", Scopes: []string{domain.ScopeAdmin}},
+	}
+
+	testCases := []struct {
+		name              string
+		tokens            domain.TokenValidator
+		method            string
+		path              string
+		authorization     string
+		expectedStatus    int
+		expectedSubject   string
+		expectedChallenge string
+	}{
+		{
+			name:            "read_with_read_scope",
+			tokens:          tokens,
+			method:          http.MethodGet,
+			path:            "/rockets/channel-1",
+			authorization:   "Bearer reader",
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "dashboard",
+		},
+		{
+			name:            "write_with_write_scope",
+			tokens:          tokens,
+			method:          http.MethodPost,
+			path:            "/messages",
+			authorization:   "Bearer producer",
+			expectedStatus:  http.StatusAccepted,
+			expectedSubject: "ground-station",
+		},
+		{
+			name:            "admin_grants_every_scope",
+			tokens:          tokens,
+			method:          http.MethodPost,
+			path:            "/messages",
+			authorization:   "Bearer admin",
+			expectedStatus:  http.StatusAccepted,
+			expectedSubject: "operator",
+		},
+		{
+			name:              "write_with_read_scope",
+			tokens:            tokens,
+			method:            http.MethodPost,
+			path:              "/messages",
+			authorization:     "Bearer reader",
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: `Bearer error="insufficient_scope", scope="messages:write"`,
+		},
+		{
+			name:              "admin_route_with_read_scope",
+			tokens:            tokens,
+			method:            http.MethodGet,
+			path:              "/dead-letters",
+			authorization:     "Bearer reader",
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: `Bearer error="insufficient_scope", scope="admin"`,
+		},
+		{
+			name:            "admin_route_with_admin_scope",
+			tokens:          tokens,
+			method:          http.MethodGet,
+			path:            "/dead-letters",
+			authorization:   "Bearer admin",
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "operator",
+		},
+		{
+			name:            "conflicts_with_read_scope",
+			tokens:          tokens,
+			method:          http.MethodGet,
+			path:            "/conflicts",
+			authorization:   "Bearer reader",
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "dashboard",
+		},
+		{
+			name:            "channel_with_read_scope",
+			tokens:          tokens,
+			method:          http.MethodGet,
+			path:            "/channels/channel-1",
+			authorization:   "Bearer reader",
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "dashboard",
+		},
+		{
+			name:              "channel_reset_with_read_scope",
+			tokens:            tokens,
+			method:            http.MethodPost,
+			path:              "/channels/channel-1/reset",
+			authorization:     "Bearer reader",
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: `Bearer error="insufficient_scope", scope="admin"`,
+		},
+		{
+			name:            "channel_reset_with_admin_scope",
+			tokens:          tokens,
+			method:          http.MethodPost,
+			path:            "/channels/channel-1/reset",
+			authorization:   "Bearer admin",
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "operator",
+		},
+		{
+			name:              "audit_with_read_scope",
+			tokens:            tokens,
+			method:            http.MethodGet,
+			path:              "/audit",
+			authorization:     "Bearer reader",
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: `Bearer error="insufficient_scope", scope="admin"`,
+		},
+		{
+			name:              "read_with_write_scope",
+			tokens:            tokens,
+			method:            http.MethodGet,
+			path:              "/rockets/channel-1",
+			authorization:     "Bearer producer",
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: `Bearer error="insufficient_scope", scope="rockets:read"`,
+		},
+		{
+			name:              "missing_token",
+			tokens:            tokens,
+			method:            http.MethodGet,
+			path:              "/rockets/channel-1",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: "Bearer",
+		},
+		{
+			name:              "invalid_token",
+			tokens:            tokens,
+			method:            http.MethodGet,
+			path:              "/rockets/channel-1",
+			authorization:     "Bearer forged",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: `Bearer error="invalid_token"`,
+		},
+		{
+			name:           "open_without_validator",
+			method:         http.MethodGet,
+			path:           "/rockets/channel-1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown_route",
+			tokens:         tokens,
+			method:         http.MethodGet,
+			path:           "/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var subject string
+			recordSubject := func(ctx context.Context) bool {
+				subject = domain.ActorFromContext(ctx)
+				return true
+			}
+
+			rocketUsecase := &mocks.MockRocketUseCase{}
+			rocketUsecase.On("GetRocket", mock.MatchedBy(recordSubject), "channel-1").
+				Return(&domain.Rocket{Channel: "channel-1"}, nil).Maybe()
+			messageUsecase := &mocks.MockRocketMessageUsecase{}
+			messageUsecase.On("ProcessMessage", mock.MatchedBy(recordSubject), mock.Anything).
+				Return(nil).Maybe()
+			messageUsecase.On("ListConflicts", mock.MatchedBy(recordSubject), mock.Anything).
+				Return([]*domain.MessageConflict{}, nil).Maybe()
+			messageUsecase.On("GetChannel", mock.MatchedBy(recordSubject), "channel-1").
+				Return(&domain.ChannelStatus{Channel: "channel-1"}, nil).Maybe()
+			deadLetterUsecase := &mocks.MockDeadLetterUsecase{}
+			deadLetterUsecase.On("ListDeadLetters", mock.MatchedBy(recordSubject), mock.Anything).
+				Return([]*domain.DeadLetter{}, nil).Maybe()
+			channelAdminUsecase := &mocks.MockChannelAdminUsecase{}
+			channelAdminUsecase.On("ResetChannel", mock.MatchedBy(recordSubject), "channel-1", "").
+				Return(&domain.AuditEntry{Action: domain.AuditActionResetChannel, Channel: "channel-1"}, nil).Maybe()
+
+			router := NewRouter(
+				controller.NewMessageController(messageUsecase, nil),
+				controller.NewRocketController(rocketUsecase),
+				controller.NewChannelController(messageUsecase),
+				nil, nil, nil,
+				controller.NewDeadLetterController(deadLetterUsecase),
+				controller.NewConflictController(messageUsecase),
+				controller.NewChannelAdminController(channelAdminUsecase),
+				tc.tokens,
+				nil,
+			)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(message))
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedSubject, subject)
+			assert.Equal(t, tc.expectedChallenge, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
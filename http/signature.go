@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/http/controller"
+	"lunar-rockets/metrics"
+)
+
+const (
+	ProducerIDHeader = "X-Producer-ID"
+	TimestampHeader  = "X-Timestamp"
+	NonceHeader      = "X-Nonce"
+	SignatureHeader  = "X-Signature"
+)
+
+// maxMessageBodySize bounds the body of a message posted to /messages that the
+// middlewares read before the controller
+const maxMessageBodySize = 1 << 20
+
+// maxBodySize returns the bound of the body of a message, or of a batch, that
+// the middlewares read before the controller. A batch is bounded as in the
+// controller.
+func maxBodySize(batch bool) int64 {
+	if batch {
+		return controller.MaxBatchSize
+	}
+	return maxMessageBodySize
+}
+
+// NewSignatureMiddleware verifies that every message or batch posted to
+// /messages is signed by a known producer, within the allowed clock skew and
+// with a fresh nonce, and that the producer may send to the channel of every
+// message. Rejected requests are counted in failures by reason.
+func NewSignatureMiddleware(verifier *domain.SignatureVerifier, failures *metrics.CounterVec, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		batch := req.URL.Path == "/messages/batch"
+		if req.Method != http.MethodPost || (req.URL.Path != "/messages" && !batch) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize(batch)))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			log.Printf("Error reading signed message: %v", err)
+			http.Error(w, "Invalid message format", http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		producer, err := verifier.Verify(
+			req.Header.Get(ProducerIDHeader),
+			req.Header.Get(TimestampHeader),
+			req.Header.Get(NonceHeader),
+			req.Header.Get(SignatureHeader),
+			body,
+		)
+		if err != nil {
+			log.Printf("Rejected message from producer %q: %v", req.Header.Get(ProducerIDHeader), err)
+			failures.Inc(signatureFailureReason(err))
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		// A body that is not a message is left to the controller to reject
+		for _, channel := range messageChannels(body, batch) {
+			if !producer.Allows(channel) {
+				log.Printf("Rejected message from producer %s: %v: %s", producer.ID, domain.ErrChannelForbidden, channel)
+				failures.Inc(signatureFailureReason(domain.ErrChannelForbidden))
+				http.Error(w, "Channel not allowed", http.StatusForbidden)
+				return
+			}
+		}
+
+		// The producer is the caller unless the router authenticates another
+		next.ServeHTTP(w, req.WithContext(domain.ContextWithIdentity(req.Context(), &domain.Identity{Subject: producer.ID})))
+	})
+}
+
+// messageChannels returns the channels of the message, or of the messages of
+// the batch, that can be decoded from body
+func messageChannels(body []byte, batch bool) []string {
+	items := []json.RawMessage{body}
+	if batch {
+		var err error
+		if items, err = domain.SplitBatch(body); err != nil {
+			return nil
+		}
+	}
+
+	var channels []string
+	for _, item := range items {
+		var message domain.RocketMessage
+		if err := json.Unmarshal(item, &message); err == nil && message.Metadata.Channel != "" {
+			channels = append(channels, message.Metadata.Channel)
+		}
+	}
+	return channels
+}
+
+// signatureFailureReason returns the metric label of a verification error
+func signatureFailureReason(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrMissingSignature):
+		return "missing_signature"
+	case errors.Is(err, domain.ErrUnknownProducer):
+		return "unknown_producer"
+	case errors.Is(err, domain.ErrInvalidSignature):
+		return "invalid_signature"
+	case errors.Is(err, domain.ErrStaleTimestamp):
+		return "stale_timestamp"
+	case errors.Is(err, domain.ErrReplayedNonce):
+		return "replayed_nonce"
+	case errors.Is(err, domain.ErrChannelForbidden):
+		return "channel_forbidden"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,308 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/http/controller"
+	"lunar-rockets/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	const body = `{"metadata":{"channel":"lunar-1","messageNumber":1,"messageTime":"2024-01-01T00:00:00Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`
+
+	batch := "[" + body + "," + strings.Replace(body, `"messageNumber":1`, `"messageNumber":2`, 1) + "]"
+	mixedBatch := body + "\n" + strings.Replace(body, "lunar-1", "mars-1", 1) + "\n"
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	signed := func(producerID string, secret string, timestamp string, nonce string, body string) map[string]string {
+		return map[string]string{
+			ProducerIDHeader: producerID,
+			TimestampHeader:  timestamp,
+			NonceHeader:      nonce,
+			SignatureHeader:  domain.SignMessage(secret, timestamp, nonce, []byte(body)),
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		headers        map[string]string
+		expectedStatus int
+		expectedReason string
+	}{
+		{
+			name:           "signed",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			headers:        signed("lunar", "lunar-secret", now, "nonce-1", body),
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "other_route",
+			method:         http.MethodGet,
+			path:           "/rockets",
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "unsigned",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "missing_signature",
+		},
+		{
+			name:           "unknown_producer",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			headers:        signed("mars", "lunar-secret", now, "nonce-1", body),
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "unknown_producer",
+		},
+		{
+			name:           "wrong_secret",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			headers:        signed("lunar", "guessed", now, "nonce-1", body),
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "invalid_signature",
+		},
+		{
+			name:           "tampered_body",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           strings.Replace(body, "lunar-1", "lunar-2", 1),
+			headers:        signed("lunar", "lunar-secret", now, "nonce-1", body),
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "invalid_signature",
+		},
+		{
+			name:           "stale_timestamp",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			headers:        signed("lunar", "lunar-secret", stale, "nonce-1", body),
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "stale_timestamp",
+		},
+		{
+			name:           "invalid_timestamp",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			headers:        signed("lunar", "lunar-secret", "yesterday", "nonce-1", body),
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "stale_timestamp",
+		},
+		{
+			name:           "signed_batch",
+			method:         http.MethodPost,
+			path:           "/messages/batch",
+			body:           batch,
+			headers:        signed("lunar", "lunar-secret", now, "nonce-1", batch),
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "unsigned_batch",
+			method:         http.MethodPost,
+			path:           "/messages/batch",
+			body:           batch,
+			expectedStatus: http.StatusUnauthorized,
+			expectedReason: "missing_signature",
+		},
+		{
+			name:           "batch_channel_forbidden",
+			method:         http.MethodPost,
+			path:           "/messages/batch",
+			body:           mixedBatch,
+			headers:        signed("lunar", "lunar-secret", now, "nonce-1", mixedBatch),
+			expectedStatus: http.StatusForbidden,
+			expectedReason: "channel_forbidden",
+		},
+		{
+			name:           "channel_forbidden",
+			method:         http.MethodPost,
+			path:           "/messages",
+			body:           body,
+			headers:        signed("mars", "mars-secret", now, "nonce-1", body),
+			expectedStatus: http.StatusForbidden,
+			expectedReason: "channel_forbidden",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			producers := []domain.Producer{
+				{ID: "lunar", Secret: "lunar-secret", Channels: []string{"lunar-*"}},
+			}
+			if tc.name == "channel_forbidden" {
+				producers = append(producers, domain.Producer{ID: "mars", Secret: "mars-secret", Channels: []string{"mars-*"}})
+			}
+			verifier, err := domain.NewSignatureVerifier(producers, 5*time.Minute)
+			require.NoError(t, err)
+			failures := metrics.NewRegistry().Counter("auth_failures_total", "Rejected messages", "reason")
+
+			var received, actor string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = domain.ActorFromContext(r.Context())
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				received = string(b)
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			NewSignatureMiddleware(verifier, failures, next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedReason != "" {
+				assert.Equal(t, 1.0, failures.Value(tc.expectedReason))
+			} else {
+				// The verified body reaches the handler as sent, from the producer
+				assert.Equal(t, tc.body, received)
+				if tc.method == http.MethodPost {
+					assert.Equal(t, "lunar", actor)
+				}
+			}
+		})
+	}
+}
+
+func TestSignatureMiddleware_Replay(t *testing.T) {
+	const body = `{"metadata":{"channel":"lunar-1","messageNumber":1}}`
+
+	verifier, err := domain.NewSignatureVerifier([]domain.Producer{{ID: "lunar", Secret: "lunar-secret"}}, 5*time.Minute)
+	require.NoError(t, err)
+	failures := metrics.NewRegistry().Counter("auth_failures_total", "Rejected messages", "reason")
+	handler := NewSignatureMiddleware(verifier, failures, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	send := func(nonce string) int {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(body))
+		req.Header.Set(ProducerIDHeader, "lunar")
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(NonceHeader, nonce)
+		req.Header.Set(SignatureHeader, domain.SignMessage("lunar-secret", timestamp, nonce, []byte(body)))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, send("nonce-1"))
+	assert.Equal(t, http.StatusUnauthorized, send("nonce-1"))
+	assert.Equal(t, http.StatusAccepted, send("nonce-2"))
+	assert.Equal(t, 1.0, failures.Value("replayed_nonce"))
+}
+
+func TestSignatureMiddleware_BodyTooLarge(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		size int
+	}{
+		{
+			name: "message",
+			path: "/messages",
+			size: maxMessageBodySize + 1,
+		},
+		{
+			name: "batch",
+			path: "/messages/batch",
+			size: controller.MaxBatchSize + 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifier, err := domain.NewSignatureVerifier([]domain.Producer{{ID: "lunar", Secret: "lunar-secret"}}, 5*time.Minute)
+			require.NoError(t, err)
+			failures := metrics.NewRegistry().Counter("auth_failures_total", "Rejected messages", "reason")
+
+			called := false
+			handler := NewSignatureMiddleware(verifier, failures, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(strings.Repeat(" ", tc.size)))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+			assert.False(t, called)
+		})
+	}
+}
+
+func TestNewSignatureVerifier_Invalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		producers     []domain.Producer
+		maxSkew       time.Duration
+		expectedError string
+	}{
+		{
+			name:          "no_skew",
+			maxSkew:       0,
+			expectedError: "max clock skew must be positive",
+		},
+		{
+			name:          "missing_secret",
+			producers:     []domain.Producer{{ID: "lunar"}},
+			maxSkew:       time.Minute,
+			expectedError: "secret of producer lunar is required",
+		},
+		{
+			name:          "duplicate_producer",
+			producers:     []domain.Producer{{ID: "lunar", Secret: "a"}, {ID: "lunar", Secret: "b"}},
+			maxSkew:       time.Minute,
+			expectedError: "duplicate producer lunar",
+		},
+		{
+			name:          "invalid_pattern",
+			producers:     []domain.Producer{{ID: "lunar", Secret: "a", Channels: []string{"lunar-["}}},
+			maxSkew:       time.Minute,
+			expectedError: `invalid channel pattern "lunar-[" of producer lunar: syntax error in pattern`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := domain.NewSignatureVerifier(tc.producers, tc.maxSkew)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+const (
+	APIKeyHeader   = "X-API-Key"
+	TenantIDHeader = "X-Tenant-ID"
+)
+
+// NewTenantMiddleware resolves the tenant of every request from its API key
+// or tenant header and carries it in the request context, so that handlers
+// only see the tenant's rockets and messages. The Swagger UI is left open.
+func NewTenantMiddleware(resolver *domain.TenantResolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/swagger/") {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		tenantID, err := resolver.Resolve(req.Header.Get(APIKeyHeader), req.Header.Get(TenantIDHeader))
+		if err != nil {
+			log.Printf("Rejected request to %s: %v", req.URL.Path, err)
+			switch {
+			case errors.Is(err, domain.ErrInvalidAPIKey):
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			case errors.Is(err, domain.ErrTenantForbidden):
+				http.Error(w, "Tenant not allowed", http.StatusForbidden)
+			default:
+				http.Error(w, "Invalid tenant ID", http.StatusBadRequest)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, req.WithContext(domain.ContextWithTenant(req.Context(), tenantID)))
+	})
+}
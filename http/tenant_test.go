@@ -0,0 +1,118 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apiKeys        map[string]string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{
+			name:           "default_tenant",
+			path:           "/rockets",
+			expectedStatus: http.StatusOK,
+			expectedTenant: domain.DefaultTenantID,
+		},
+		{
+			name:           "tenant_header",
+			path:           "/rockets",
+			headers:        map[string]string{TenantIDHeader: "tenant-a"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:           "invalid_tenant_header",
+			path:           "/rockets",
+			headers:        map[string]string{TenantIDHeader: "tenant/a"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "api_key",
+			apiKeys:        map[string]string{"key-a": "tenant-a", "key-b": "tenant-b"},
+			path:           "/rockets",
+			headers:        map[string]string{APIKeyHeader: "key-b"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "tenant-b",
+		},
+		{
+			name:           "api_key_with_its_tenant",
+			apiKeys:        map[string]string{"key-a": "tenant-a"},
+			path:           "/rockets",
+			headers:        map[string]string{APIKeyHeader: "key-a", TenantIDHeader: "tenant-a"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:           "api_key_with_other_tenant",
+			apiKeys:        map[string]string{"key-a": "tenant-a", "key-b": "tenant-b"},
+			path:           "/rockets",
+			headers:        map[string]string{APIKeyHeader: "key-a", TenantIDHeader: "tenant-b"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing_api_key",
+			apiKeys:        map[string]string{"key-a": "tenant-a"},
+			path:           "/rockets",
+			headers:        map[string]string{TenantIDHeader: "tenant-a"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown_api_key",
+			apiKeys:        map[string]string{"key-a": "tenant-a"},
+			path:           "/rockets",
+			headers:        map[string]string{APIKeyHeader: "key-x"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "swagger_without_api_key",
+			apiKeys:        map[string]string{"key-a": "tenant-a"},
+			path:           "/swagger/index.html",
+			expectedStatus: http.StatusOK,
+			expectedTenant: domain.DefaultTenantID,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver, err := domain.NewTenantResolver(tc.apiKeys)
+			require.NoError(t, err)
+
+			var tenantID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenantID = domain.TenantFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			NewTenantMiddleware(resolver, next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedTenant, tenantID)
+		})
+	}
+}
+
+func TestNewTenantResolver_InvalidTenant(t *testing.T) {
+	_, err := domain.NewTenantResolver(map[string]string{"key-a": "tenant a"})
+	assert.EqualError(t, err, `invalid tenant ID "tenant a"`)
+}
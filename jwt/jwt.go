@@ -0,0 +1,284 @@
+// Package jwt validates bearer JSON Web Tokens signed with a shared secret
+// (HS256) or with the RSA keys of a local JWKS file (RS256), and turns their
+// claims into the caller's identity
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// Config holds the keys trusted to sign tokens and the claims every token
+// must carry
+type Config struct {
+	// Shared secret of HS256 tokens, empty to refuse them
+	Secret []byte
+	// Public keys of RS256 tokens by key ID, empty to refuse them
+	Keys map[string]*rsa.PublicKey
+	// Required issuer and audience, not checked when empty
+	Issuer   string
+	Audience string
+	// Clock difference tolerated on the expiry and not-before claims
+	Leeway time.Duration
+}
+
+// Validator validates tokens against a Config
+type Validator struct {
+	config Config
+	now    func() time.Time
+}
+
+// NewValidator creates a validator trusting the configured keys
+func NewValidator(config Config) (*Validator, error) {
+	if len(config.Secret) == 0 && len(config.Keys) == 0 {
+		return nil, errors.New("a secret or signing keys are required")
+	}
+	return &Validator{config: config, now: time.Now}, nil
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type claims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  stringList  `json:"aud"`
+	ExpiresAt *float64    `json:"exp"`
+	NotBefore *float64    `json:"nbf"`
+	Scope     scopeClaims `json:"scope"`
+}
+
+// Validate checks the token's signature and claims and returns the identity
+// of its subject. Every error wraps domain.ErrInvalidToken.
+func (v *Validator) Validate(token string) (*domain.Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed token")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, invalid("malformed header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+
+	if err := v.verify(h, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, invalid("malformed claims")
+	}
+
+	if err := v.checkClaims(&c); err != nil {
+		return nil, err
+	}
+
+	return &domain.Identity{Subject: c.Subject, Scopes: c.Scope}, nil
+}
+
+// verify checks the signature of the signed header and claims with the key
+// named by the header
+func (v *Validator) verify(h header, signed string, signature []byte) error {
+	switch h.Algorithm {
+	case AlgorithmHS256:
+		if len(v.config.Secret) == 0 {
+			return invalid("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, v.config.Secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return invalid("signature mismatch")
+		}
+		return nil
+	case AlgorithmRS256:
+		key, err := v.rsaKey(h.KeyID)
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return invalid("signature mismatch")
+		}
+		return nil
+	default:
+		return invalid(fmt.Sprintf("unsupported algorithm %q", h.Algorithm))
+	}
+}
+
+// rsaKey returns the key of the given ID, or the only key when the token
+// names none
+func (v *Validator) rsaKey(keyID string) (*rsa.PublicKey, error) {
+	if len(v.config.Keys) == 0 {
+		return nil, invalid("RS256 tokens are not accepted")
+	}
+	if keyID == "" {
+		if len(v.config.Keys) == 1 {
+			for _, key := range v.config.Keys {
+				return key, nil
+			}
+		}
+		return nil, invalid("missing key ID")
+	}
+	key, ok := v.config.Keys[keyID]
+	if !ok {
+		return nil, invalid(fmt.Sprintf("unknown key ID %q", keyID))
+	}
+	return key, nil
+}
+
+func (v *Validator) checkClaims(c *claims) error {
+	if c.Subject == "" {
+		return invalid("missing subject")
+	}
+
+	now := v.now()
+	if c.ExpiresAt == nil {
+		return invalid("missing expiry")
+	}
+	if now.After(numericDate(*c.ExpiresAt).Add(v.config.Leeway)) {
+		return invalid("token expired")
+	}
+	if c.NotBefore != nil && now.Add(v.config.Leeway).Before(numericDate(*c.NotBefore)) {
+		return invalid("token not valid yet")
+	}
+
+	if v.config.Issuer != "" && c.Issuer != v.config.Issuer {
+		return invalid(fmt.Sprintf("unexpected issuer %q", c.Issuer))
+	}
+	if v.config.Audience != "" && !slices.Contains(c.Audience, v.config.Audience) {
+		return invalid("unexpected audience")
+	}
+
+	return nil
+}
+
+// ParseJWKS returns the RSA signing keys of a JSON Web Key Set by key ID.
+// Keys of other types or uses are skipped.
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			KeyType   string `json:"kty"`
+			KeyID     string `json:"kid"`
+			Use       string `json:"use"`
+			Algorithm string `json:"alg"`
+			Modulus   string `json:"n"`
+			Exponent  string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Algorithm != "" && jwk.Algorithm != AlgorithmRS256) {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("invalid modulus of key %q", jwk.KeyID)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent of key %q", jwk.KeyID)
+		}
+		if _, ok := keys[jwk.KeyID]; ok {
+			return nil, fmt.Errorf("duplicate key %q", jwk.KeyID)
+		}
+
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// LoadJWKS reads the RSA signing keys of a JWKS file
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+func invalid(reason string) error {
+	return fmt.Errorf("%w: %s", domain.ErrInvalidToken, reason)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// stringList is a claim holding a single string or an array of strings
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// scopeClaims is the scope claim, either space separated or an array
+type scopeClaims []string
+
+func (s *scopeClaims) UnmarshalJSON(data []byte) error {
+	var list stringList
+	if err := list.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	var scopes []string
+	for _, item := range list {
+		scopes = append(scopes, strings.Fields(item)...)
+	}
+	*s = scopes
+	return nil
+}
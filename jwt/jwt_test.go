@@ -0,0 +1,262 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedTime = time.Date(2024, 3, 21, 12, 0, 0, 0, time.UTC)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, h map[string]interface{}, c map[string]interface{}) string {
+	signed := encodeSegment(t, h) + "." + encodeSegment(t, c)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, h map[string]interface{}, c map[string]interface{}) string {
+	signed := encodeSegment(t, h) + "." + encodeSegment(t, c)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "mission-control",
+		"iss":   "lunar-auth",
+		"aud":   []string{"lunar-rockets", "other"},
+		"exp":   fixedTime.Add(time.Hour).Unix(),
+		"nbf":   fixedTime.Add(-time.Hour).Unix(),
+		"scope": "rockets:read messages:write",
+	}
+}
+
+func withClaim(key string, value interface{}) map[string]interface{} {
+	c := validClaims()
+	if value == nil {
+		delete(c, key)
+	} else {
+		c[key] = value
+	}
+	return c
+}
+
+func TestValidator_Validate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	hs256 := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	rs256 := map[string]interface{}{"alg": "RS256", "kid": "key-1"}
+
+	testCases := []struct {
+		name             string
+		token            string
+		expectedIdentity *domain.Identity
+		expectedError    string
+	}{
+		{
+			name:             "hs256",
+			token:            signHS256(t, "shared-secret", hs256, validClaims()),
+			expectedIdentity: &domain.Identity{Subject: "mission-control", Scopes: []string{"rockets:read", "messages:write"}},
+		},
+		{
+			name:             "rs256",
+			token:            signRS256(t, rsaKey, rs256, withClaim("scope", []string{"admin"})),
+			expectedIdentity: &domain.Identity{Subject: "mission-control", Scopes: []string{"admin"}},
+		},
+		{
+			name:             "rs256_without_key_id",
+			token:            signRS256(t, rsaKey, map[string]interface{}{"alg": "RS256"}, withClaim("aud", "lunar-rockets")),
+			expectedIdentity: &domain.Identity{Subject: "mission-control", Scopes: []string{"rockets:read", "messages:write"}},
+		},
+		{
+			name:             "expired_within_leeway",
+			token:            signHS256(t, "shared-secret", hs256, withClaim("exp", fixedTime.Add(-10*time.Second).Unix())),
+			expectedIdentity: &domain.Identity{Subject: "mission-control", Scopes: []string{"rockets:read", "messages:write"}},
+		},
+		{
+			name:          "wrong_secret",
+			token:         signHS256(t, "guessed", hs256, validClaims()),
+			expectedError: "invalid token: signature mismatch",
+		},
+		{
+			name:          "wrong_rsa_key",
+			token:         signRS256(t, otherKey, rs256, validClaims()),
+			expectedError: "invalid token: signature mismatch",
+		},
+		{
+			name:          "unknown_key_id",
+			token:         signRS256(t, rsaKey, map[string]interface{}{"alg": "RS256", "kid": "key-2"}, validClaims()),
+			expectedError: `invalid token: unknown key ID "key-2"`,
+		},
+		{
+			name:          "none_algorithm",
+			token:         encodeSegment(t, map[string]interface{}{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + ".",
+			expectedError: `invalid token: unsupported algorithm "none"`,
+		},
+		{
+			name:          "malformed",
+			token:         "not-a-token",
+			expectedError: "invalid token: malformed token",
+		},
+		{
+			name:          "expired",
+			token:         signHS256(t, "shared-secret", hs256, withClaim("exp", fixedTime.Add(-time.Minute).Unix())),
+			expectedError: "invalid token: token expired",
+		},
+		{
+			name:          "missing_expiry",
+			token:         signHS256(t, "shared-secret", hs256, withClaim("exp", nil)),
+			expectedError: "invalid token: missing expiry",
+		},
+		{
+			name:          "not_valid_yet",
+			token:         signHS256(t, "shared-secret", hs256, withClaim("nbf", fixedTime.Add(time.Minute).Unix())),
+			expectedError: "invalid token: token not valid yet",
+		},
+		{
+			name:          "missing_subject",
+			token:         signHS256(t, "shared-secret", hs256, withClaim("sub", nil)),
+			expectedError: "invalid token: missing subject",
+		},
+		{
+			name:          "wrong_issuer",
+			token:         signHS256(t, "shared-secret", hs256, withClaim("iss", "mars-auth")),
+			expectedError: `invalid token: unexpected issuer "mars-auth"`,
+		},
+		{
+			name:          "wrong_audience",
+			token:         signHS256(t, "shared-secret", hs256, withClaim("aud", "mars-rockets")),
+			expectedError: "invalid token: unexpected audience",
+		},
+	}
+
+	validator, err := NewValidator(Config{
+		Secret:   []byte("shared-secret"),
+		Keys:     map[string]*rsa.PublicKey{"key-1": &rsaKey.PublicKey},
+		Issuer:   "lunar-auth",
+		Audience: "lunar-rockets",
+		Leeway:   30 * time.Second,
+	})
+	require.NoError(t, err)
+	validator.now = func() time.Time { return fixedTime }
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			identity, err := validator.Validate(tc.token)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.True(t, errors.Is(err, domain.ErrInvalidToken))
+				assert.Nil(t, identity)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedIdentity, identity)
+			}
+		})
+	}
+}
+
+func TestValidator_RefusesUnconfiguredAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// A token signed with the public key as HS256 secret must not pass for an
+	// RS256 token
+	validator, err := NewValidator(Config{Keys: map[string]*rsa.PublicKey{"key-1": &rsaKey.PublicKey}})
+	require.NoError(t, err)
+	validator.now = func() time.Time { return fixedTime }
+
+	_, err = validator.Validate(signHS256(t, rsaKey.PublicKey.N.String(), map[string]interface{}{"alg": "HS256"}, validClaims()))
+	assert.EqualError(t, err, "invalid token: HS256 tokens are not accepted")
+
+	_, err = NewValidator(Config{})
+	assert.EqualError(t, err, "a secret or signing keys are required")
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	n := base64.RawURLEncoding.EncodeToString(rsaKey.PublicKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.PublicKey.E)).Bytes())
+
+	testCases := []struct {
+		name          string
+		jwks          string
+		expectedKeys  []string
+		expectedError string
+	}{
+		{
+			name:         "rsa_keys",
+			jwks:         `{"keys":[{"kty":"RSA","kid":"key-1","use":"sig","alg":"RS256","n":"` + n + `","e":"` + e + `"},{"kty":"EC","kid":"key-2","crv":"P-256"},{"kty":"RSA","kid":"key-3","use":"enc","n":"` + n + `","e":"` + e + `"}]}`,
+			expectedKeys: []string{"key-1"},
+		},
+		{
+			name:          "no_rsa_keys",
+			jwks:          `{"keys":[{"kty":"EC","kid":"key-2","crv":"P-256"}]}`,
+			expectedError: "JWKS has no RSA signing keys",
+		},
+		{
+			name:          "invalid_modulus",
+			jwks:          `{"keys":[{"kty":"RSA","kid":"key-1","n":"!","e":"` + e + `"}]}`,
+			expectedError: `invalid modulus of key "key-1"`,
+		},
+		{
+			name:          "duplicate_key",
+			jwks:          `{"keys":[{"kty":"RSA","kid":"key-1","n":"` + n + `","e":"` + e + `"},{"kty":"RSA","kid":"key-1","n":"` + n + `","e":"` + e + `"}]}`,
+			expectedError: `duplicate key "key-1"`,
+		},
+		{
+			name:          "invalid_json",
+			jwks:          `{"keys":`,
+			expectedError: "invalid JWKS: unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := ParseJWKS([]byte(tc.jwks))
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, keys, len(tc.expectedKeys))
+			for _, kid := range tc.expectedKeys {
+				require.Contains(t, keys, kid)
+				assert.True(t, rsaKey.PublicKey.Equal(keys[kid]))
+			}
+		})
+	}
+}
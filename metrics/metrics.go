@@ -0,0 +1,179 @@
+// Package metrics keeps counters and serves them in the Prometheus text
+// exposition format, so that the service can be scraped without a client
+// library
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds the metrics served by its handler
+type Registry struct {
+	mu       sync.RWMutex
+	counters []*CounterVec
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers a counter partitioned by the given labels. It panics if
+// a metric of the same name is already registered, as that is a programming
+// error.
+func (r *Registry) Counter(name string, help string, labels ...string) *CounterVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, counter := range r.counters {
+		if counter.name == name {
+			panic(fmt.Sprintf("metric %s is already registered", name))
+		}
+	}
+
+	counter := &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*sample),
+	}
+	r.counters = append(r.counters, counter)
+	return counter
+}
+
+// WriteTo writes every metric in the Prometheus text exposition format,
+// sorted by name and label values
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	counters := make([]*CounterVec, len(r.counters))
+	copy(counters, r.counters)
+	r.mu.RUnlock()
+
+	sort.Slice(counters, func(i, j int) bool { return counters[i].name < counters[j].name })
+
+	var b strings.Builder
+	for _, counter := range counters {
+		counter.write(&b)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+// CounterVec is a counter partitioned by label values
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*sample // By encoded label values
+}
+
+type sample struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc adds one to the counter of the given label values, which must match
+// the labels of the counter
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative value to the counter of the given label values
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", c.name, len(c.labels), len(labelValues)))
+	}
+	if value < 0 {
+		panic(fmt.Sprintf("metric %s is a counter and cannot decrease", c.name))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.values[key]
+	if !ok {
+		s = &sample{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = s
+	}
+	s.value += value
+}
+
+// Value returns the counter of the given label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, ok := c.values[strings.Join(labelValues, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", c.name, escapeHelp(c.help))
+	fmt.Fprintf(b, "# TYPE %s counter\n", c.name)
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := c.values[key]
+		b.WriteString(c.name)
+		if len(c.labels) > 0 {
+			b.WriteByte('{')
+			for i, label := range c.labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(b, "%s=\"%s\"", label, escapeLabelValue(s.labelValues[i]))
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		b.WriteByte('\n')
+	}
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
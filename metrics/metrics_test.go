@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := NewRegistry()
+
+	failures := registry.Counter("ingestion_auth_failures_total", "Rejected ingestion requests", "reason")
+	failures.Inc("invalid_signature")
+	failures.Inc("invalid_signature")
+	failures.Add(3, `quoted "reason"`)
+
+	requests := registry.Counter("api_requests_total", "Requests\nserved")
+	requests.Inc()
+
+	var b strings.Builder
+	_, err := registry.WriteTo(&b)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `# HELP api_requests_total Requests\nserved
+# TYPE api_requests_total counter
+api_requests_total 1
+# HELP ingestion_auth_failures_total Rejected ingestion requests
+# TYPE ingestion_auth_failures_total counter
+ingestion_auth_failures_total{reason="invalid_signature"} 2
+ingestion_auth_failures_total{reason="quoted \"reason\""} 3
+`, b.String())
+	assert.Equal(t, 2.0, failures.Value("invalid_signature"))
+	assert.Zero(t, failures.Value("unknown"))
+}
+
+func TestRegistry_Counter_Invalid(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.Counter("events_total", "Events", "type")
+
+	assert.Panics(t, func() { registry.Counter("events_total", "Events again") })
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { counter.Add(-1, "launched") })
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("events_total", "Events").Inc()
+
+	testCases := []struct {
+		name           string
+		method         string
+		expectedStatus int
+	}{
+		{name: "get", method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "post", method: http.MethodPost, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			registry.Handler().ServeHTTP(rec, httptest.NewRequest(tc.method, "/metrics", nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+				assert.Contains(t, rec.Body.String(), "events_total 1\n")
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events", "events.ndjson")
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	events := []*domain.OutboxEvent{
+		{ID: 1, Channel: "channel-1", EventType: domain.TypeRocketLaunched, MessageNumber: 1, Payload: []byte(`{"speed":100}`)},
+		{ID: 2, Channel: "channel-1", EventType: domain.TypeRocketSpeedIncreased, MessageNumber: 2, Payload: []byte(`{"speed":200}`)},
+	}
+	for _, event := range events {
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	}
+	require.NoError(t, publisher.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var decoded domain.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	assert.Equal(t, int64(2), decoded.ID)
+	assert.JSONEq(t, `{"speed":200}`, string(decoded.Payload))
+}
@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"lunar-rockets/domain"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSPublisher publishes events to a JetStream stream. Events are sent to
+// "<subject prefix>.<channel>" with the outbox ID as the message ID, so the
+// broker drops redeliveries within its duplicate window.
+type NATSPublisher struct {
+	js            jetstream.JetStream
+	subjectPrefix string
+}
+
+// NewNATSPublisher creates a publisher, creating the stream if it is missing
+func NewNATSPublisher(ctx context.Context, nc *nats.Conn, stream string, subjectPrefix string) (*NATSPublisher, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	_, err = js.Stream(ctx, stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     stream,
+			Subjects: []string{subjectPrefix + ".>"},
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event stream: %w", err)
+	}
+
+	return &NATSPublisher{
+		js:            js,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	subject := p.subjectPrefix + "." + event.Channel
+	if _, err := p.js.Publish(ctx, subject, data, jetstream.WithMsgID(strconv.FormatInt(event.ID, 10))); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second), "nats-server did not start")
+	defer srv.Shutdown()
+
+	nc, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+
+	ctx := context.Background()
+	publisher, err := NewNATSPublisher(ctx, nc, "ROCKET_EVENTS", "rockets.events")
+	require.NoError(t, err)
+
+	event := &domain.OutboxEvent{ID: 42, Channel: "channel-1", EventType: domain.TypeRocketLaunched, Payload: []byte(`{"speed":100}`)}
+
+	// Publishing the same event twice, as a relay retry would, is deduplicated
+	require.NoError(t, publisher.Publish(ctx, event))
+	require.NoError(t, publisher.Publish(ctx, event))
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	stream, err := js.Stream(ctx, "ROCKET_EVENTS")
+	require.NoError(t, err)
+
+	info, err := stream.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), info.State.Msgs)
+
+	msg, err := stream.GetLastMsgForSubject(ctx, "rockets.events.channel-1")
+	require.NoError(t, err)
+
+	var decoded domain.OutboxEvent
+	require.NoError(t, json.Unmarshal(msg.Data, &decoded))
+	assert.Equal(t, int64(42), decoded.ID)
+}
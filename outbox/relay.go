@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// RelayConfig tunes how often the relay polls the outbox and how long
+// published events are kept before being cleaned up
+type RelayConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	Retention time.Duration
+}
+
+// DefaultRelayConfig returns the configuration used when none is provided
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		Interval:  time.Second,
+		BatchSize: 100,
+		Retention: 24 * time.Hour,
+	}
+}
+
+// Relay moves events from the outbox table to a publisher. An event is only
+// marked as published after the publisher accepted it, so a crash in between
+// causes a redelivery rather than a loss: consumers must tolerate duplicates
+// and can use the event ID to drop them.
+type Relay struct {
+	outboxRepo domain.OutboxRepository
+	publisher  domain.EventPublisher
+	cfg        RelayConfig
+}
+
+// NewRelay creates a relay publishing outbox events through publisher
+func NewRelay(outboxRepo domain.OutboxRepository, publisher domain.EventPublisher, cfg RelayConfig) *Relay {
+	return &Relay{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		cfg:        cfg,
+	}
+}
+
+// Start relays events until the context is cancelled
+func (r *Relay) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil {
+			log.Printf("Outbox relay failed: %v", err)
+		}
+
+		if err := r.Cleanup(ctx); err != nil {
+			log.Printf("Outbox cleanup failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes the next batch of unpublished events in order and
+// returns how many were published. It stops at the first failure so that
+// events of a channel are never published out of order.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.outboxRepo.FetchUnpublished(ctx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, event := range events {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			return i, fmt.Errorf("failed to publish outbox event %d: %w", event.ID, err)
+		}
+
+		if err := r.outboxRepo.MarkPublished(ctx, event.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// Cleanup deletes events that were published longer ago than the retention
+func (r *Relay) Cleanup(ctx context.Context) error {
+	deleted, err := r.outboxRepo.DeletePublishedBefore(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		log.Printf("Outbox cleanup removed %d published events", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// publisherFunc adapts a function to domain.EventPublisher
+type publisherFunc func(ctx context.Context, event *domain.OutboxEvent) error
+
+func (f publisherFunc) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	t.Parallel()
+
+	events := []*domain.OutboxEvent{
+		{ID: 1, Channel: "channel-1"},
+		{ID: 2, Channel: "channel-1"},
+		{ID: 3, Channel: "channel-2"},
+	}
+
+	testCases := []struct {
+		name              string
+		fetchError        error
+		failPublishOn     int64
+		markError         error
+		expectedPublished []int64
+		expectedMarked    []int64
+		expectedCount     int
+		expectedError     string
+	}{
+		{
+			name:              "publishes_all_events",
+			expectedPublished: []int64{1, 2, 3},
+			expectedMarked:    []int64{1, 2, 3},
+			expectedCount:     3,
+		},
+		{
+			name:              "stops_at_first_publish_failure",
+			failPublishOn:     2,
+			expectedPublished: []int64{1, 2},
+			expectedMarked:    []int64{1},
+			expectedCount:     1,
+			expectedError:     "failed to publish outbox event 2: broker unavailable",
+		},
+		{
+			name:              "mark_failure_leaves_event_for_redelivery",
+			markError:         errors.New("database error"),
+			expectedPublished: []int64{1},
+			expectedMarked:    []int64{1},
+			expectedCount:     0,
+			expectedError:     "database error",
+		},
+		{
+			name:          "fetch_error",
+			fetchError:    errors.New("database error"),
+			expectedCount: 0,
+			expectedError: "database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var published, marked []int64
+
+			outboxRepo := &mocks.MockOutboxRepository{
+				FetchUnpublishedFunc: func(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+					assert.Equal(t, 100, limit)
+					return events, tc.fetchError
+				},
+				MarkPublishedFunc: func(ctx context.Context, id int64) error {
+					marked = append(marked, id)
+					return tc.markError
+				},
+			}
+
+			publisher := publisherFunc(func(ctx context.Context, event *domain.OutboxEvent) error {
+				published = append(published, event.ID)
+				if event.ID == tc.failPublishOn {
+					return errors.New("broker unavailable")
+				}
+				return nil
+			})
+
+			relay := NewRelay(outboxRepo, publisher, DefaultRelayConfig())
+
+			count, err := relay.RelayOnce(context.Background())
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCount, count)
+			assert.Equal(t, tc.expectedPublished, published)
+			assert.Equal(t, tc.expectedMarked, marked)
+		})
+	}
+}
+
+func TestRelay_Cleanup(t *testing.T) {
+	t.Parallel()
+
+	var cutoff time.Time
+	outboxRepo := &mocks.MockOutboxRepository{
+		DeletePublishedBeforeFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			cutoff = before
+			return 2, nil
+		},
+	}
+
+	cfg := DefaultRelayConfig()
+	cfg.Retention = time.Hour
+	relay := NewRelay(outboxRepo, nil, cfg)
+
+	err := relay.Cleanup(context.Background())
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), cutoff, time.Second)
+}
@@ -0,0 +1,185 @@
+// Package ratelimit limits the rate of requests per key with token buckets
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quota allows Requests per Period on average, and bursts of up to Requests
+// at once
+type Quota struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseQuota parses a quota written as requests/period, e.g. "100/1s" or
+// "600/1m"
+func ParseQuota(value string) (Quota, error) {
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Quota{}, fmt.Errorf("invalid quota %q: expected requests/period", value)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return Quota{}, fmt.Errorf("invalid quota %q: requests must be a positive integer", value)
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Quota{}, fmt.Errorf("invalid quota %q: period must be a positive duration", value)
+	}
+
+	return Quota{Requests: n, Period: d}, nil
+}
+
+func (q Quota) String() string {
+	return fmt.Sprintf("%d/%s", q.Requests, q.Period)
+}
+
+// interval returns the time it takes to refill one token
+func (q Quota) interval() time.Duration {
+	return q.Period / time.Duration(q.Requests)
+}
+
+// Decision is the outcome of taking a token for a request
+type Decision struct {
+	Allowed bool
+	// Quota that applied to the key
+	Limit int
+	// Tokens left after the request
+	Remaining int
+	// Wait before the next request is allowed, zero when one is or when none
+	// ever will be
+	RetryAfter time.Duration
+	// The request takes more tokens than the quota allows per period, so it
+	// is never allowed
+	OverQuota bool
+	// Wait before the bucket is full again
+	Reset time.Duration
+}
+
+// Limiter keeps a token bucket per key
+type Limiter struct {
+	quota     Quota
+	overrides map[string]Quota
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewLimiter creates a limiter applying the quota to every key, except for
+// the keys with an override
+func NewLimiter(quota Quota, overrides map[string]Quota) (*Limiter, error) {
+	if quota.Requests <= 0 || quota.Period <= 0 {
+		return nil, errors.New("quota must allow at least one request per period")
+	}
+	for key, override := range overrides {
+		if override.Requests <= 0 || override.Period <= 0 {
+			return nil, fmt.Errorf("quota of %s must allow at least one request per period", key)
+		}
+	}
+
+	return &Limiter{
+		quota:     quota,
+		overrides: overrides,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+	}, nil
+}
+
+// Allow takes a token from the key's bucket if one is left
+func (l *Limiter) Allow(key string) Decision {
+	return l.AllowN(key, 1)
+}
+
+// AllowN takes n tokens from the key's bucket if that many are left, or none.
+// More tokens than the quota allows per period are never allowed, which the
+// decision reports as over quota rather than with a wait.
+func (l *Limiter) AllowN(key string, n int) Decision {
+	quota := l.quota
+	if override, ok := l.overrides[key]; ok {
+		quota = override
+	}
+	capacity := float64(quota.Requests)
+	interval := quota.interval()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		l.buckets[key] = b
+	}
+
+	// Refill the tokens earned since the last request
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/float64(interval))
+		b.updated = now
+	}
+
+	decision := Decision{Limit: quota.Requests}
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		decision.Allowed = true
+	} else if n > quota.Requests {
+		decision.OverQuota = true
+	} else {
+		decision.RetryAfter = time.Duration((float64(n) - b.tokens) * float64(interval))
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = time.Duration((capacity - b.tokens) * float64(interval))
+
+	return decision
+}
+
+// ReturnN gives back n tokens taken from the key's bucket, for a request that
+// was allowed here but refused by another limit
+func (l *Limiter) ReturnN(key string, n int) {
+	quota := l.quota
+	if override, ok := l.overrides[key]; ok {
+		quota = override
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(float64(quota.Requests), b.tokens+float64(n))
+	}
+}
+
+// sweep forgets the buckets that are full again, as a new bucket for their
+// key would be the same, at most once per period of the default quota
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.quota.Period {
+		return
+	}
+	for key, b := range l.buckets {
+		quota := l.quota
+		if override, ok := l.overrides[key]; ok {
+			quota = override
+		}
+		if now.Sub(b.updated) >= quota.Period {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuota(t *testing.T) {
+	testCases := []struct {
+		name          string
+		value         string
+		expectedQuota Quota
+		expectedError string
+	}{
+		{name: "per_second", value: "100/1s", expectedQuota: Quota{Requests: 100, Period: time.Second}},
+		{name: "per_minute", value: " 600 / 1m ", expectedQuota: Quota{Requests: 600, Period: time.Minute}},
+		{name: "missing_period", value: "100", expectedError: `invalid quota "100": expected requests/period`},
+		{name: "zero_requests", value: "0/1s", expectedError: `invalid quota "0/1s": requests must be a positive integer`},
+		{name: "invalid_period", value: "10/second", expectedError: `invalid quota "10/second": period must be a positive duration`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			quota, err := ParseQuota(tc.value)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedQuota, quota)
+			}
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter, err := NewLimiter(Quota{Requests: 3, Period: 3 * time.Second}, map[string]Quota{"vip": {Requests: 10, Period: time.Second}})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	// The burst is the whole quota
+	for remaining := 2; remaining >= 0; remaining-- {
+		decision := limiter.Allow("client-1")
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 3, decision.Limit)
+		assert.Equal(t, remaining, decision.Remaining)
+	}
+
+	decision := limiter.Allow("client-1")
+	assert.Equal(t, Decision{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second}, decision)
+
+	// Other keys have their own bucket, and overrides their own quota
+	assert.True(t, limiter.Allow("client-2").Allowed)
+	assert.Equal(t, 10, limiter.Allow("vip").Limit)
+
+	// Tokens are earned back over time
+	now = now.Add(500 * time.Millisecond)
+	decision = limiter.Allow("client-1")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	decision = limiter.Allow("client-1")
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 0, decision.Remaining)
+	assert.Equal(t, 3*time.Second, decision.Reset)
+}
+
+func TestLimiter_AllowN(t *testing.T) {
+	limiter, err := NewLimiter(Quota{Requests: 3, Period: 3 * time.Second}, nil)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	decision := limiter.AllowN("client-1", 2)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1, decision.Remaining)
+
+	// No token is taken unless all of them are left
+	decision = limiter.AllowN("client-1", 2)
+	assert.Equal(t, Decision{Allowed: false, Limit: 3, Remaining: 1, RetryAfter: time.Second, Reset: 2 * time.Second}, decision)
+	assert.True(t, limiter.Allow("client-1").Allowed)
+
+	// More tokens than the quota are never left, so there is no use waiting
+	now = now.Add(time.Hour)
+	assert.Equal(t, Decision{Allowed: false, Limit: 3, Remaining: 3, OverQuota: true}, limiter.AllowN("client-1", 4))
+	assert.True(t, limiter.AllowN("client-1", 3).Allowed)
+}
+
+func TestLimiter_ReturnN(t *testing.T) {
+	limiter, err := NewLimiter(Quota{Requests: 3, Period: 3 * time.Second}, nil)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.AllowN("client-1", 2).Allowed)
+	limiter.ReturnN("client-1", 2)
+	assert.Equal(t, 0, limiter.AllowN("client-1", 3).Remaining)
+
+	// A bucket never holds more than the quota
+	limiter.ReturnN("client-1", 5)
+	assert.True(t, limiter.AllowN("client-1", 3).Allowed)
+	assert.False(t, limiter.Allow("client-1").Allowed)
+}
+
+func TestLimiter_SweepsFullBuckets(t *testing.T) {
+	limiter, err := NewLimiter(Quota{Requests: 1, Period: time.Second}, nil)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("client-1")
+	limiter.Allow("client-2")
+	assert.Len(t, limiter.buckets, 2)
+
+	now = now.Add(2 * time.Second)
+	limiter.Allow("client-3")
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestNewLimiter_Invalid(t *testing.T) {
+	_, err := NewLimiter(Quota{}, nil)
+	assert.EqualError(t, err, "quota must allow at least one request per period")
+
+	_, err = NewLimiter(Quota{Requests: 1, Period: time.Second}, map[string]Quota{"vip": {}})
+	assert.EqualError(t, err, "quota of vip must allow at least one request per period")
+}
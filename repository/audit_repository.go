@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Save(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `INSERT INTO audit_log (tenant_id, action, channel, actor, reason, details, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		domain.TenantFromContext(ctx),
+		entry.Action,
+		entry.Channel,
+		entry.Actor,
+		entry.Reason,
+		entry.Details,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get audit entry id: %w", err)
+	}
+	entry.ID = id
+
+	return nil
+}
+
+// List returns the matching audit entries of the tenant, newest first
+func (r *AuditRepository) List(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error) {
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{domain.TenantFromContext(ctx)}
+	if query.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		args = append(args, query.Channel)
+	}
+	if query.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, query.Action)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	limit := ""
+	if query.Limit > 0 {
+		limit = "LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	sqlQuery := fmt.Sprintf(`SELECT id, action, channel, actor, reason, details, created_at
+							 FROM audit_log
+							 %s
+							 ORDER BY id DESC
+							 %s`, where, limit)
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+
+	for rows.Next() {
+		var entry domain.AuditEntry
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&entry.Channel,
+			&entry.Actor,
+			&entry.Reason,
+			&entry.Details,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+
+	return entries, nil
+}
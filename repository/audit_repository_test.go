@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_Save(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedID    int64
+		expectedError string
+	}{
+		{
+			name:       "successful_save",
+			expectedID: 3,
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save audit entry: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			entry := &domain.AuditEntry{
+				Action:    domain.AuditActionDiscardBuffer,
+				Channel:   "channel-1",
+				Actor:     "operator",
+				Reason:    "producer restarted",
+				Details:   "discarded buffered messages [5 7]",
+				CreatedAt: now,
+			}
+
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO audit_log").
+				WithArgs(domain.DefaultTenantID, domain.AuditActionDiscardBuffer, "channel-1", "operator", "producer restarted", "discarded buffered messages [5 7]", now)
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(tc.expectedID, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.Save(context.Background(), entry)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedID, entry.ID)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuditRepository_List(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+
+	now := time.Now()
+	columns := []string{"id", "action", "channel", "actor", "reason", "details", "created_at"}
+
+	testCases := []struct {
+		name            string
+		query           domain.AuditQuery
+		setupMock       func()
+		expectedEntries []*domain.AuditEntry
+		expectedError   string
+	}{
+		{
+			name:  "filtered",
+			query: domain.AuditQuery{Channel: "channel-1", Action: domain.AuditActionSkipGap, Limit: 10},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE tenant_id = \\? AND channel = \\? AND action = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(domain.DefaultTenantID, "channel-1", domain.AuditActionSkipGap, 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(3), domain.AuditActionSkipGap, "channel-1", "operator", "", "skipped messages 2 to 4", now))
+			},
+			expectedEntries: []*domain.AuditEntry{
+				{ID: 3, Action: domain.AuditActionSkipGap, Channel: "channel-1", Actor: "operator", Details: "skipped messages 2 to 4", CreatedAt: now},
+			},
+		},
+		{
+			name:  "unfiltered",
+			query: domain.AuditQuery{},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE tenant_id = \\? ORDER BY id DESC").
+					WithArgs(domain.DefaultTenantID).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedEntries: nil,
+		},
+		{
+			name:  "database_error",
+			query: domain.AuditQuery{Limit: 10},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE tenant_id = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(domain.DefaultTenantID, 10).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to list audit entries: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			entries, err := repo.List(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, entries)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedEntries, entries)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
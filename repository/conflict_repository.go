@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+type ConflictRepository struct {
+	db *sql.DB
+}
+
+func NewConflictRepository(db *sql.DB) *ConflictRepository {
+	return &ConflictRepository{db: db}
+}
+
+func (r *ConflictRepository) Save(ctx context.Context, conflict *domain.MessageConflict) error {
+	query := `INSERT INTO message_conflicts (
+				tenant_id, channel, message_number, message_type, message_time, processed_hash, received_hash, payload, detected_at, actor
+			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		domain.TenantFromContext(ctx),
+		conflict.Channel,
+		conflict.MessageNumber,
+		conflict.MessageType,
+		conflict.MessageTime,
+		conflict.ProcessedHash,
+		conflict.ReceivedHash,
+		string(conflict.Payload),
+		conflict.DetectedAt,
+		conflict.Actor,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save message conflict: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get message conflict id: %w", err)
+	}
+	conflict.ID = id
+
+	return nil
+}
+
+// List returns the matching conflicts of the tenant, newest first
+func (r *ConflictRepository) List(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error) {
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{domain.TenantFromContext(ctx)}
+	if query.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		args = append(args, query.Channel)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	limit := ""
+	if query.Limit > 0 {
+		limit = "LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	sqlQuery := fmt.Sprintf(`SELECT id, channel, message_number, message_type, message_time, processed_hash, received_hash, payload, detected_at, actor
+							 FROM message_conflicts
+							 %s
+							 ORDER BY id DESC
+							 %s`, where, limit)
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []*domain.MessageConflict
+
+	for rows.Next() {
+		var conflict domain.MessageConflict
+		var payload string
+
+		err := rows.Scan(
+			&conflict.ID,
+			&conflict.Channel,
+			&conflict.MessageNumber,
+			&conflict.MessageType,
+			&conflict.MessageTime,
+			&conflict.ProcessedHash,
+			&conflict.ReceivedHash,
+			&payload,
+			&conflict.DetectedAt,
+			&conflict.Actor,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message conflict: %w", err)
+		}
+
+		conflict.Payload = json.RawMessage(payload)
+		conflicts = append(conflicts, &conflict)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message conflicts: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+func (r *ConflictRepository) DeleteChannel(ctx context.Context, channel string) (int64, error) {
+	query := `DELETE FROM message_conflicts WHERE tenant_id = ? AND channel = ?`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.TenantFromContext(ctx), channel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete message conflicts: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictRepository_Save(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewConflictRepository(db)
+
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedID    int64
+		expectedError string
+	}{
+		{
+			name:       "successful_save",
+			expectedID: 4,
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save message conflict: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			conflict := &domain.MessageConflict{
+				Channel:       "channel-1",
+				MessageNumber: 2,
+				MessageType:   domain.TypeRocketSpeedIncreased,
+				MessageTime:   now,
+				ProcessedHash: "aaa",
+				ReceivedHash:  "bbb",
+				Payload:       json.RawMessage(`{"by":300}`),
+				DetectedAt:    now,
+				Actor:         "mission-control",
+			}
+
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO message_conflicts").
+				WithArgs(domain.DefaultTenantID, "channel-1", int64(2), domain.TypeRocketSpeedIncreased, now, "aaa", "bbb", `{"by":300}`, now, "mission-control")
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(tc.expectedID, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.Save(context.Background(), conflict)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedID, conflict.ID)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestConflictRepository_List(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewConflictRepository(db)
+
+	now := time.Now()
+	columns := []string{"id", "channel", "message_number", "message_type", "message_time", "processed_hash", "received_hash", "payload", "detected_at", "actor"}
+
+	testCases := []struct {
+		name              string
+		query             domain.ConflictQuery
+		setupMock         func()
+		expectedConflicts []*domain.MessageConflict
+		expectedError     string
+	}{
+		{
+			name:  "filtered",
+			query: domain.ConflictQuery{Channel: "channel-1", Limit: 10},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM message_conflicts WHERE tenant_id = \\? AND channel = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(domain.DefaultTenantID, "channel-1", 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(4), "channel-1", int64(2), domain.TypeRocketSpeedIncreased, now, "aaa", "bbb", `{"by":300}`, now, ""))
+			},
+			expectedConflicts: []*domain.MessageConflict{
+				{ID: 4, Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased, MessageTime: now, ProcessedHash: "aaa", ReceivedHash: "bbb", Payload: json.RawMessage(`{"by":300}`), DetectedAt: now},
+			},
+		},
+		{
+			name:  "unfiltered",
+			query: domain.ConflictQuery{},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM message_conflicts WHERE tenant_id = \\? ORDER BY id DESC").
+					WithArgs(domain.DefaultTenantID).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedConflicts: nil,
+		},
+		{
+			name:  "database_error",
+			query: domain.ConflictQuery{Limit: 10},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM message_conflicts WHERE tenant_id = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(domain.DefaultTenantID, 10).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to list message conflicts: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			conflicts, err := repo.List(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, conflicts)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedConflicts, conflicts)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestConflictRepository_DeleteChannel(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewConflictRepository(db)
+
+	mock.ExpectExec("DELETE FROM message_conflicts WHERE tenant_id = \\? AND channel = \\?").
+		WithArgs(domain.DefaultTenantID, "channel-1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteChannel(context.Background(), "channel-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
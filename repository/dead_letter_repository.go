@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+type DeadLetterRepository struct {
+	db *sql.DB
+}
+
+func NewDeadLetterRepository(db *sql.DB) *DeadLetterRepository {
+	return &DeadLetterRepository{db: db}
+}
+
+// deadLetterColumns lists the columns read by scanDeadLetter, in order
+const deadLetterColumns = `id, channel, message_number, message_type, message, reason, attempts, created_at, updated_at`
+
+// Save records a failed attempt at the time of deadLetter.CreatedAt. A dead
+// letter of the same channel and message number takes the new message and
+// reason, and counts one more attempt.
+func (r *DeadLetterRepository) Save(ctx context.Context, deadLetter *domain.DeadLetter) error {
+	tenantID := domain.TenantFromContext(ctx)
+	attemptedAt := deadLetter.CreatedAt
+
+	query := `UPDATE dead_letters
+			  SET message_type = ?, message = ?, reason = ?, attempts = attempts + 1, updated_at = ?
+			  WHERE tenant_id = ? AND channel = ? AND message_number = ?
+			  RETURNING id, attempts, created_at`
+
+	err := executorFor(ctx, r.db).QueryRowContext(ctx, query,
+		deadLetter.MessageType,
+		string(deadLetter.Message),
+		deadLetter.Reason,
+		attemptedAt,
+		tenantID,
+		deadLetter.Channel,
+		deadLetter.MessageNumber,
+	).Scan(&deadLetter.ID, &deadLetter.Attempts, &deadLetter.CreatedAt)
+	if err == nil {
+		deadLetter.UpdatedAt = attemptedAt
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	query = `INSERT INTO dead_letters (
+				tenant_id, channel, message_number, message_type, message, reason, attempts, created_at, updated_at
+			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		tenantID,
+		deadLetter.Channel,
+		deadLetter.MessageNumber,
+		deadLetter.MessageType,
+		string(deadLetter.Message),
+		deadLetter.Reason,
+		1,
+		attemptedAt,
+		attemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get dead letter id: %w", err)
+	}
+	deadLetter.ID = id
+	deadLetter.Attempts = 1
+	deadLetter.UpdatedAt = attemptedAt
+
+	return nil
+}
+
+func (r *DeadLetterRepository) FindByID(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letters WHERE tenant_id = ? AND id = ?`
+
+	deadLetter, err := scanDeadLetter(executorFor(ctx, r.db).QueryRowContext(ctx, query, domain.TenantFromContext(ctx), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("failed to find dead letter: %w", err)
+	}
+
+	return deadLetter, nil
+}
+
+// List returns the matching dead letters of the tenant, by channel and message
+// number, so that replaying them in order fills the gaps of each channel
+func (r *DeadLetterRepository) List(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+	where, args := deadLetterFilter(ctx, query)
+
+	limit := ""
+	if query.Limit > 0 {
+		limit = "LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	sqlQuery := fmt.Sprintf(`SELECT %s
+							 FROM dead_letters
+							 %s
+							 ORDER BY channel ASC, message_number ASC, id ASC
+							 %s`, deadLetterColumns, where, limit)
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters []*domain.DeadLetter
+
+	for rows.Next() {
+		deadLetter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dead letters: %w", err)
+	}
+
+	return deadLetters, nil
+}
+
+func (r *DeadLetterRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM dead_letters WHERE tenant_id = ? AND id = ?`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.TenantFromContext(ctx), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+func (r *DeadLetterRepository) DeleteMatching(ctx context.Context, query domain.DeadLetterQuery) (int64, error) {
+	where, args := deadLetterFilter(ctx, query)
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, `DELETE FROM dead_letters `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dead letters: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// deadLetterFilter returns the WHERE clause matching the query in the tenant
+// of ctx, and its arguments
+func deadLetterFilter(ctx context.Context, query domain.DeadLetterQuery) (string, []interface{}) {
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{domain.TenantFromContext(ctx)}
+	if query.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		args = append(args, query.Channel)
+	}
+	if !query.Before.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, query.Before)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// scanDeadLetter reads a row of deadLetterColumns
+func scanDeadLetter(row rowScanner) (*domain.DeadLetter, error) {
+	var deadLetter domain.DeadLetter
+	var message string
+	var updatedAt sql.NullTime
+
+	err := row.Scan(
+		&deadLetter.ID,
+		&deadLetter.Channel,
+		&deadLetter.MessageNumber,
+		&deadLetter.MessageType,
+		&message,
+		&deadLetter.Reason,
+		&deadLetter.Attempts,
+		&deadLetter.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetter.Message = []byte(message)
+	// Dead letters of earlier versions were never attempted again
+	deadLetter.UpdatedAt = deadLetter.CreatedAt
+	if updatedAt.Valid {
+		deadLetter.UpdatedAt = updatedAt.Time
+	}
+	return &deadLetter, nil
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var deadLetterRowColumns = []string{"id", "channel", "message_number", "message_type", "message", "reason", "attempts", "created_at", "updated_at"}
+
+func TestDeadLetterRepository_Save(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDeadLetterRepository(db)
+
+	now := time.Now()
+	createdAt := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name              string
+		existing          bool
+		dbError           error
+		expectedID        int64
+		expectedAttempts  int
+		expectedCreatedAt time.Time
+		expectedError     string
+	}{
+		{
+			name:              "new_dead_letter",
+			expectedID:        5,
+			expectedAttempts:  1,
+			expectedCreatedAt: now,
+		},
+		{
+			name:              "existing_dead_letter",
+			existing:          true,
+			expectedID:        3,
+			expectedAttempts:  2,
+			expectedCreatedAt: createdAt,
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save dead letter: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			deadLetter := &domain.DeadLetter{
+				Channel:       "channel-1",
+				MessageNumber: 9,
+				MessageType:   domain.TypeRocketSpeedIncreased,
+				Message:       json.RawMessage(`{"metadata":{"channel":"channel-1"}}`),
+				Reason:        "evicted from the full buffer",
+				CreatedAt:     now,
+			}
+
+			// The dead letter of the same message is updated, or else a new
+			// one is inserted
+			update := mock.ExpectQuery("UPDATE dead_letters").
+				WithArgs(domain.TypeRocketSpeedIncreased, `{"metadata":{"channel":"channel-1"}}`, deadLetter.Reason, now, "tenant-a", "channel-1", int64(9))
+			switch {
+			case tc.existing:
+				update.WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "created_at"}).AddRow(tc.expectedID, tc.expectedAttempts, createdAt))
+			case tc.dbError != nil:
+				update.WillReturnError(tc.dbError)
+			default:
+				update.WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "created_at"}))
+				mock.ExpectExec("INSERT INTO dead_letters").
+					WithArgs("tenant-a", "channel-1", int64(9), domain.TypeRocketSpeedIncreased, `{"metadata":{"channel":"channel-1"}}`, deadLetter.Reason, 1, now, now).
+					WillReturnResult(sqlmock.NewResult(tc.expectedID, 1))
+			}
+
+			// Execute test
+			err := repo.Save(domain.ContextWithTenant(context.Background(), "tenant-a"), deadLetter)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedID, deadLetter.ID)
+				assert.Equal(t, tc.expectedAttempts, deadLetter.Attempts)
+				assert.Equal(t, tc.expectedCreatedAt, deadLetter.CreatedAt)
+				assert.Equal(t, now, deadLetter.UpdatedAt)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeadLetterRepository_FindByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDeadLetterRepository(db)
+	ctx := domain.ContextWithTenant(context.Background(), "tenant-a")
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM dead_letters WHERE tenant_id = \\? AND id = \\?").
+		WithArgs("tenant-a", int64(3)).
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+			AddRow(3, "channel-1", 9, domain.TypeRocketSpeedIncreased, `{}`, "boom", 1, createdAt, nil))
+
+	deadLetter, err := repo.FindByID(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.DeadLetter{
+		ID:            3,
+		Channel:       "channel-1",
+		MessageNumber: 9,
+		MessageType:   domain.TypeRocketSpeedIncreased,
+		Message:       json.RawMessage(`{}`),
+		Reason:        "boom",
+		Attempts:      1,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}, deadLetter)
+
+	mock.ExpectQuery("SELECT (.+) FROM dead_letters").
+		WithArgs("tenant-a", int64(4)).
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns))
+
+	_, err = repo.FindByID(ctx, 4)
+	assert.ErrorIs(t, err, domain.ErrDeadLetterNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterRepository_List(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(time.Minute)
+
+	testCases := []struct {
+		name          string
+		query         domain.DeadLetterQuery
+		expectedArgs  []driver.Value
+		dbError       error
+		expectedError string
+	}{
+		{
+			name:         "every_dead_letter",
+			query:        domain.DeadLetterQuery{},
+			expectedArgs: []driver.Value{"tenant-a"},
+		},
+		{
+			name:         "filtered",
+			query:        domain.DeadLetterQuery{Channel: "channel-1", Before: updatedAt, Limit: 10},
+			expectedArgs: []driver.Value{"tenant-a", "channel-1", updatedAt, 10},
+		},
+		{
+			name:          "database_error",
+			expectedArgs:  []driver.Value{"tenant-a"},
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to list dead letters: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			expectation := mock.ExpectQuery("SELECT (.+) FROM dead_letters (.+) ORDER BY channel ASC, message_number ASC").
+				WithArgs(tc.expectedArgs...)
+			if tc.dbError != nil {
+				expectation.WillReturnError(tc.dbError)
+			} else {
+				expectation.WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+					AddRow(1, "channel-1", 4, domain.TypeRocketSpeedIncreased, `{}`, "boom", 2, createdAt, updatedAt).
+					AddRow(2, "channel-1", 5, domain.TypeRocketSpeedDecreased, `{}`, "boom", 1, createdAt, createdAt))
+			}
+
+			deadLetters, err := NewDeadLetterRepository(db).List(domain.ContextWithTenant(context.Background(), "tenant-a"), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				if assert.Len(t, deadLetters, 2) {
+					assert.Equal(t, int64(4), deadLetters[0].MessageNumber)
+					assert.Equal(t, 2, deadLetters[0].Attempts)
+					assert.Equal(t, updatedAt, deadLetters[0].UpdatedAt)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeadLetterRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDeadLetterRepository(db)
+	ctx := domain.ContextWithTenant(context.Background(), "tenant-a")
+
+	mock.ExpectExec("DELETE FROM dead_letters WHERE tenant_id = \\? AND id = \\?").
+		WithArgs("tenant-a", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Delete(ctx, 3))
+
+	// Dead letters of other tenants are not found
+	mock.ExpectExec("DELETE FROM dead_letters").
+		WithArgs("tenant-a", int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Delete(ctx, 4), domain.ErrDeadLetterNotFound)
+
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("DELETE FROM dead_letters WHERE tenant_id = \\? AND channel = \\? AND created_at < \\?").
+		WithArgs("tenant-a", "channel-1", before).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+	deleted, err := repo.DeleteMatching(ctx, domain.DeadLetterQuery{Channel: "channel-1", Before: before})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+
+	"lunar-rockets/domain"
 )
 
 type MessageRepository struct {
@@ -14,23 +17,51 @@ func NewMessageRepository(db *sql.DB) *MessageRepository {
 	return &MessageRepository{db: db}
 }
 
-func (r *MessageRepository) MarkAsProcessed(ctx context.Context, channel string, messageNumber int64) error {
-	query := `INSERT INTO processed_messages (channel, message_number, processed_at)
-			  VALUES (?, ?, CURRENT_TIMESTAMP)`
+// MarkAsProcessed records a processed message, and fails with
+// domain.ErrAlreadyProcessed when its number already was
+func (r *MessageRepository) MarkAsProcessed(ctx context.Context, channel string, messageNumber int64, payloadHash string) error {
+	query := `INSERT INTO processed_messages (tenant_id, channel, message_number, processed_at, payload_hash)
+			  VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?)
+			  ON CONFLICT DO NOTHING`
 
-	_, err := r.db.ExecContext(ctx, query, channel, messageNumber)
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.TenantFromContext(ctx), channel, messageNumber, payloadHash)
 	if err != nil {
 		return fmt.Errorf("failed to mark message as processed: %w", err)
 	}
 
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrAlreadyProcessed
+	}
+
 	return nil
 }
 
+// FindPayloadHash returns the hash of a processed message, or an empty hash
+// when the message was not processed or was processed before hashes were kept
+func (r *MessageRepository) FindPayloadHash(ctx context.Context, channel string, messageNumber int64) (string, error) {
+	query := `SELECT payload_hash FROM processed_messages WHERE tenant_id = ? AND channel = ? AND message_number = ?`
+
+	var payloadHash sql.NullString
+	err := executorFor(ctx, r.db).QueryRowContext(ctx, query, domain.TenantFromContext(ctx), channel, messageNumber).Scan(&payloadHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to find payload hash: %w", err)
+	}
+
+	return payloadHash.String, nil
+}
+
 func (r *MessageRepository) FindLastMessageNumber(ctx context.Context, channel string) (int64, error) {
-	query := `SELECT MAX(message_number) FROM processed_messages WHERE channel = ?`
+	query := `SELECT MAX(message_number) FROM processed_messages WHERE tenant_id = ? AND channel = ?`
 
 	var lastMessageNumber sql.NullInt64
-	err := r.db.QueryRowContext(ctx, query, channel).Scan(&lastMessageNumber)
+	err := executorFor(ctx, r.db).QueryRowContext(ctx, query, domain.TenantFromContext(ctx), channel).Scan(&lastMessageNumber)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil // No messages found, return 0
@@ -44,3 +75,142 @@ func (r *MessageRepository) FindLastMessageNumber(ctx context.Context, channel s
 
 	return lastMessageNumber.Int64, nil
 }
+
+func (r *MessageRepository) ListChannelProgress(ctx context.Context) ([]*domain.ChannelProgress, error) {
+	query := `SELECT p.channel, p.message_number, p.processed_at
+			  FROM processed_messages p
+			  JOIN (SELECT channel, MAX(message_number) AS last_number
+			        FROM processed_messages WHERE tenant_id = ? GROUP BY channel) l
+			  ON p.channel = l.channel AND p.message_number = l.last_number
+			  WHERE p.tenant_id = ?
+			  ORDER BY p.channel ASC`
+
+	tenantID := domain.TenantFromContext(ctx)
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel progress: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []*domain.ChannelProgress
+
+	for rows.Next() {
+		var progress domain.ChannelProgress
+		if err := rows.Scan(&progress.Channel, &progress.LastProcessed, &progress.LastProcessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan channel progress: %w", err)
+		}
+		channels = append(channels, &progress)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating channel progress: %w", err)
+	}
+
+	return channels, nil
+}
+
+func (r *MessageRepository) FindChannelProgress(ctx context.Context, channel string) (*domain.ChannelProgress, error) {
+	query := `SELECT channel, message_number, processed_at
+			  FROM processed_messages
+			  WHERE tenant_id = ? AND channel = ?
+			  ORDER BY message_number DESC
+			  LIMIT 1`
+
+	var progress domain.ChannelProgress
+	err := executorFor(ctx, r.db).QueryRowContext(ctx, query, domain.TenantFromContext(ctx), channel).
+		Scan(&progress.Channel, &progress.LastProcessed, &progress.LastProcessedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find channel progress: %w", err)
+	}
+
+	return &progress, nil
+}
+
+func (r *MessageRepository) SaveEvent(ctx context.Context, message *domain.RocketMessage) error {
+	query := `INSERT INTO message_events (tenant_id, channel, message_number, message_type, message_time, schema_version, payload, processed_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+
+	// The payload is kept as received, with its version, so that replaying it
+	// goes through the same upcasters
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		domain.TenantFromContext(ctx),
+		message.Metadata.Channel,
+		message.Metadata.MessageNumber,
+		message.Metadata.MessageType,
+		message.Metadata.MessageTime,
+		message.Metadata.Version(),
+		string(message.Message),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save message event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MessageRepository) ListEvents(ctx context.Context, channel string, afterNumber int64, limit int) ([]*domain.MessageEvent, error) {
+	query := `SELECT channel, message_number, message_type, message_time, schema_version, payload, processed_at
+			  FROM message_events
+			  WHERE tenant_id = ? AND channel = ? AND message_number > ?
+			  ORDER BY message_number ASC
+			  LIMIT ?`
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, domain.TenantFromContext(ctx), channel, afterNumber, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.MessageEvent
+
+	for rows.Next() {
+		var event domain.MessageEvent
+		var payload string
+
+		err := rows.Scan(
+			&event.Channel,
+			&event.MessageNumber,
+			&event.MessageType,
+			&event.MessageTime,
+			&event.SchemaVersion,
+			&payload,
+			&event.ProcessedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message event: %w", err)
+		}
+
+		event.Payload = json.RawMessage(payload)
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *MessageRepository) DeleteChannel(ctx context.Context, channel string) (int64, error) {
+	executor := executorFor(ctx, r.db)
+	tenantID := domain.TenantFromContext(ctx)
+
+	if _, err := executor.ExecContext(ctx, `DELETE FROM message_events WHERE tenant_id = ? AND channel = ?`, tenantID, channel); err != nil {
+		return 0, fmt.Errorf("failed to delete message events: %w", err)
+	}
+
+	result, err := executor.ExecContext(ctx, `DELETE FROM processed_messages WHERE tenant_id = ? AND channel = ?`, tenantID, channel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete processed messages: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
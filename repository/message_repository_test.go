@@ -3,7 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/helper"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +42,12 @@ func TestMessageRepository_MarkAsProcessed(t *testing.T) {
 			messageNumber: 1,
 			expectedError: "failed to mark message as processed: sql: connection is already closed",
 		},
+		{
+			name:          "already_processed",
+			channel:       "channel-1",
+			messageNumber: 1,
+			expectedError: "message already processed",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -44,17 +55,21 @@ func TestMessageRepository_MarkAsProcessed(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Set up expectations
 			if tc.expectedError == "" {
-				mock.ExpectExec("INSERT INTO processed_messages \\(channel, message_number, processed_at\\)").
-					WithArgs(tc.channel, tc.messageNumber).
+				mock.ExpectExec("INSERT INTO processed_messages \\(tenant_id, channel, message_number, processed_at, payload_hash\\)").
+					WithArgs(domain.DefaultTenantID, tc.channel, tc.messageNumber, "hash-1").
 					WillReturnResult(sqlmock.NewResult(1, 1))
+			} else if tc.name == "already_processed" {
+				mock.ExpectExec("INSERT INTO processed_messages \\(tenant_id, channel, message_number, processed_at, payload_hash\\)").
+					WithArgs(domain.DefaultTenantID, tc.channel, tc.messageNumber, "hash-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
 			} else {
-				mock.ExpectExec("INSERT INTO processed_messages \\(channel, message_number, processed_at\\)").
-					WithArgs(tc.channel, tc.messageNumber).
+				mock.ExpectExec("INSERT INTO processed_messages \\(tenant_id, channel, message_number, processed_at, payload_hash\\)").
+					WithArgs(domain.DefaultTenantID, tc.channel, tc.messageNumber, "hash-1").
 					WillReturnError(sql.ErrConnDone)
 			}
 
 			// Execute test
-			err := repo.MarkAsProcessed(context.Background(), tc.channel, tc.messageNumber)
+			err := repo.MarkAsProcessed(context.Background(), tc.channel, tc.messageNumber, "hash-1")
 
 			// Check results
 			if tc.expectedError != "" {
@@ -70,6 +85,74 @@ func TestMessageRepository_MarkAsProcessed(t *testing.T) {
 	}
 }
 
+func TestMessageRepository_FindPayloadHash(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+
+	testCases := []struct {
+		name          string
+		mockRows      *sqlmock.Rows
+		dbError       error
+		expectedHash  string
+		expectedError string
+	}{
+		{
+			name:         "hashed",
+			mockRows:     sqlmock.NewRows([]string{"payload_hash"}).AddRow("hash-1"),
+			expectedHash: "hash-1",
+		},
+		{
+			name:         "processed_before_hashes",
+			mockRows:     sqlmock.NewRows([]string{"payload_hash"}).AddRow(nil),
+			expectedHash: "",
+		},
+		{
+			name:         "not_processed",
+			mockRows:     sqlmock.NewRows([]string{"payload_hash"}),
+			expectedHash: "",
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to find payload hash: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Set up expectations
+			expectation := mock.ExpectQuery("SELECT payload_hash FROM processed_messages WHERE tenant_id = \\? AND channel = \\? AND message_number = \\?").
+				WithArgs(domain.DefaultTenantID, "channel-1", int64(2))
+			if tc.dbError != nil {
+				expectation.WillReturnError(tc.dbError)
+			} else {
+				expectation.WillReturnRows(tc.mockRows)
+			}
+
+			// Execute test
+			hash, err := repo.FindPayloadHash(context.Background(), "channel-1", 2)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedHash, hash)
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestMessageRepository_FindLastMessageNumber(t *testing.T) {
 	// Create sqlmock
 	db, mock, err := sqlmock.New()
@@ -116,12 +199,12 @@ func TestMessageRepository_FindLastMessageNumber(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Set up expectations
 			if tc.expectedError == "" {
-				mock.ExpectQuery("SELECT MAX\\(message_number\\) FROM processed_messages WHERE channel = \\?").
-					WithArgs(tc.channel).
+				mock.ExpectQuery("SELECT MAX\\(message_number\\) FROM processed_messages WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, tc.channel).
 					WillReturnRows(tc.mockRows)
 			} else {
-				mock.ExpectQuery("SELECT MAX\\(message_number\\) FROM processed_messages WHERE channel = \\?").
-					WithArgs(tc.channel).
+				mock.ExpectQuery("SELECT MAX\\(message_number\\) FROM processed_messages WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, tc.channel).
 					WillReturnError(sql.ErrConnDone)
 			}
 
@@ -143,3 +226,281 @@ func TestMessageRepository_FindLastMessageNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageRepository_ListChannelProgress(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	processedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT p.channel, p.message_number, p.processed_at FROM processed_messages p JOIN").
+		WithArgs(domain.DefaultTenantID, domain.DefaultTenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"channel", "message_number", "processed_at"}).
+			AddRow("channel-1", 4, processedAt).
+			AddRow("channel-2", 1, processedAt))
+
+	channels, err := repo.ListChannelProgress(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.ChannelProgress{
+		{Channel: "channel-1", LastProcessed: 4, LastProcessedAt: processedAt},
+		{Channel: "channel-2", LastProcessed: 1, LastProcessedAt: processedAt},
+	}, channels)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_ListChannelProgress_Error(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+
+	mock.ExpectQuery("SELECT p.channel, p.message_number, p.processed_at FROM processed_messages p").
+		WillReturnError(sql.ErrConnDone)
+
+	channels, err := repo.ListChannelProgress(context.Background())
+
+	assert.Nil(t, channels)
+	assert.EqualError(t, err, "failed to list channel progress: sql: connection is already closed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_FindChannelProgress(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	processedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name             string
+		mockRows         *sqlmock.Rows
+		dbError          error
+		expectedProgress *domain.ChannelProgress
+		expectedError    string
+	}{
+		{
+			name:             "processed",
+			mockRows:         sqlmock.NewRows([]string{"channel", "message_number", "processed_at"}).AddRow("channel-1", 4, processedAt),
+			expectedProgress: &domain.ChannelProgress{Channel: "channel-1", LastProcessed: 4, LastProcessedAt: processedAt},
+		},
+		{
+			name:     "not_processed",
+			mockRows: sqlmock.NewRows([]string{"channel", "message_number", "processed_at"}),
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to find channel progress: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Set up expectations
+			expectation := mock.ExpectQuery("SELECT channel, message_number, processed_at FROM processed_messages WHERE tenant_id = \\? AND channel = \\? ORDER BY message_number DESC LIMIT 1").
+				WithArgs(domain.DefaultTenantID, "channel-1")
+			if tc.dbError != nil {
+				expectation.WillReturnError(tc.dbError)
+			} else {
+				expectation.WillReturnRows(tc.mockRows)
+			}
+
+			// Execute test
+			progress, err := repo.FindChannelProgress(context.Background(), "channel-1")
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedProgress, progress)
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMessageRepository_SaveEvent(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	messageTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		schemaVersion   int
+		expectedVersion int
+	}{
+		{
+			name:            "first_version_when_unset",
+			schemaVersion:   0,
+			expectedVersion: domain.FirstSchemaVersion,
+		},
+		{
+			name:            "original_version",
+			schemaVersion:   3,
+			expectedVersion: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			message := &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageNumber: 2,
+					MessageTime:   messageTime,
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					SchemaVersion: tc.schemaVersion,
+				},
+				Message: helper.EncodePayload(map[string]interface{}{"by": 100}),
+			}
+
+			mock.ExpectExec("INSERT INTO message_events \\(tenant_id, channel, message_number, message_type, message_time, schema_version, payload, processed_at\\)").
+				WithArgs(domain.DefaultTenantID, "channel-1", int64(2), domain.TypeRocketSpeedIncreased, messageTime, tc.expectedVersion, `{"by":100}`).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			err := repo.SaveEvent(context.Background(), message)
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMessageRepository_ListEvents(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	eventTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT channel, message_number, message_type, message_time, schema_version, payload, processed_at FROM message_events WHERE tenant_id = \\? AND channel = \\? AND message_number > \\?").
+		WithArgs(domain.DefaultTenantID, "channel-1", int64(1), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"channel", "message_number", "message_type", "message_time", "schema_version", "payload", "processed_at"}).
+			AddRow("channel-1", 2, domain.TypeRocketSpeedIncreased, eventTime, 1, `{"by":100}`, eventTime).
+			AddRow("channel-1", 3, domain.TypeRocketSpeedDecreased, eventTime, 2, `{"by":50}`, eventTime))
+
+	events, err := repo.ListEvents(context.Background(), "channel-1", 1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.MessageEvent{
+		{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketSpeedIncreased, MessageTime: eventTime, SchemaVersion: 1, Payload: json.RawMessage(`{"by":100}`), ProcessedAt: eventTime},
+		{Channel: "channel-1", MessageNumber: 3, MessageType: domain.TypeRocketSpeedDecreased, MessageTime: eventTime, SchemaVersion: 2, Payload: json.RawMessage(`{"by":50}`), ProcessedAt: eventTime},
+	}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_ListEvents_Error(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+
+	mock.ExpectQuery("SELECT channel, message_number, message_type, message_time, schema_version, payload, processed_at FROM message_events").
+		WillReturnError(sql.ErrConnDone)
+
+	events, err := repo.ListEvents(context.Background(), "channel-1", 0, 10)
+
+	assert.Nil(t, events)
+	assert.EqualError(t, err, "failed to list message events: sql: connection is already closed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_DeleteChannel(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+
+	testCases := []struct {
+		name            string
+		setupMock       func()
+		expectedDeleted int64
+		expectedError   string
+	}{
+		{
+			name: "deleted",
+			setupMock: func() {
+				mock.ExpectExec("DELETE FROM message_events WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, "channel-1").
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec("DELETE FROM processed_messages WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, "channel-1").
+					WillReturnResult(sqlmock.NewResult(0, 5))
+			},
+			expectedDeleted: 5,
+		},
+		{
+			name: "events_error",
+			setupMock: func() {
+				mock.ExpectExec("DELETE FROM message_events").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to delete message events: sql: connection is already closed",
+		},
+		{
+			name: "processed_messages_error",
+			setupMock: func() {
+				mock.ExpectExec("DELETE FROM message_events").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM processed_messages").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to delete processed messages: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			deleted, err := repo.DeleteChannel(context.Background(), "channel-1")
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedDeleted, deleted)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
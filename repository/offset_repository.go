@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type OffsetRepository struct {
+	db *sql.DB
+}
+
+func NewOffsetRepository(db *sql.DB) *OffsetRepository {
+	return &OffsetRepository{db: db}
+}
+
+func (r *OffsetRepository) GetOffset(ctx context.Context, source string) (int64, error) {
+	query := `SELECT offset_value FROM source_offsets WHERE source = ?`
+
+	var offset int64
+	err := r.db.QueryRowContext(ctx, query, source).Scan(&offset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil // Source never consumed, start from the beginning
+		}
+		return 0, fmt.Errorf("failed to get source offset: %w", err)
+	}
+
+	return offset, nil
+}
+
+func (r *OffsetRepository) SaveOffset(ctx context.Context, source string, offset int64) error {
+	query := `INSERT INTO source_offsets (source, offset_value, updated_at)
+			  VALUES (?, ?, CURRENT_TIMESTAMP)
+			  ON CONFLICT(source) DO UPDATE SET offset_value = excluded.offset_value, updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(ctx, query, source, offset)
+	if err != nil {
+		return fmt.Errorf("failed to save source offset: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OffsetRepository) DeleteOffset(ctx context.Context, source string) error {
+	query := `DELETE FROM source_offsets WHERE source = ?`
+
+	_, err := r.db.ExecContext(ctx, query, source)
+	if err != nil {
+		return fmt.Errorf("failed to delete source offset: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetRepository_GetOffset(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOffsetRepository(db)
+
+	testCases := []struct {
+		name           string
+		source         string
+		mockRows       *sqlmock.Rows
+		expectedOffset int64
+		expectedError  string
+	}{
+		{
+			name:           "existing_offset",
+			source:         "file:capture.ndjson",
+			mockRows:       sqlmock.NewRows([]string{"offset_value"}).AddRow(128),
+			expectedOffset: 128,
+			expectedError:  "",
+		},
+		{
+			name:           "no_offset",
+			source:         "stdin",
+			mockRows:       sqlmock.NewRows([]string{"offset_value"}),
+			expectedOffset: 0,
+			expectedError:  "",
+		},
+		{
+			name:           "database_error",
+			source:         "stdin",
+			mockRows:       nil,
+			expectedOffset: 0,
+			expectedError:  "failed to get source offset: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Set up expectations
+			expectation := mock.ExpectQuery("SELECT offset_value FROM source_offsets WHERE source = \\?").
+				WithArgs(tc.source)
+			if tc.expectedError == "" {
+				expectation.WillReturnRows(tc.mockRows)
+			} else {
+				expectation.WillReturnError(sql.ErrConnDone)
+			}
+
+			// Execute test
+			offset, err := repo.GetOffset(context.Background(), tc.source)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedOffset, offset)
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOffsetRepository_SaveOffset(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOffsetRepository(db)
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedError string
+	}{
+		{
+			name:          "successful_save",
+			dbError:       nil,
+			expectedError: "",
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save source offset: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO source_offsets \\(source, offset_value, updated_at\\)").
+				WithArgs("stdin", int64(42))
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(1, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.SaveOffset(context.Background(), "stdin", 42)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOffsetRepository_DeleteOffset(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOffsetRepository(db)
+
+	mock.ExpectExec("DELETE FROM source_offsets WHERE source = \\?").
+		WithArgs("dir:drop/a.ndjson").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.DeleteOffset(context.Background(), "dir:drop/a.ndjson")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
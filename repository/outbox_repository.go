@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, event *domain.OutboxEvent) error {
+	query := `INSERT INTO outbox (tenant_id, channel, event_type, message_number, payload, created_at, actor)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		event.TenantID,
+		event.Channel,
+		event.EventType,
+		event.MessageNumber,
+		string(event.Payload),
+		event.CreatedAt,
+		event.Actor,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add outbox event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get outbox event id: %w", err)
+	}
+	event.ID = id
+
+	return nil
+}
+
+func (r *OutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+	query := `SELECT id, tenant_id, channel, event_type, message_number, payload, created_at, actor
+			  FROM outbox
+			  WHERE published_at IS NULL
+			  ORDER BY id ASC
+			  LIMIT ?`
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.OutboxEvent
+
+	for rows.Next() {
+		var event domain.OutboxEvent
+		var payload string
+
+		err := rows.Scan(
+			&event.ID,
+			&event.TenantID,
+			&event.Channel,
+			&event.EventType,
+			&event.MessageNumber,
+			&payload,
+			&event.CreatedAt,
+			&event.Actor,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+
+		event.Payload = []byte(payload)
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	query := `UPDATE outbox SET published_at = ? WHERE id = ?`
+
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event as published: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM outbox WHERE published_at IS NOT NULL AND published_at < ?`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published outbox events: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted outbox events: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository_Add(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedID    int64
+		expectedError string
+	}{
+		{
+			name:          "successful_add",
+			dbError:       nil,
+			expectedID:    7,
+			expectedError: "",
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedID:    0,
+			expectedError: "failed to add outbox event: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			event := &domain.OutboxEvent{
+				TenantID:      "tenant-a",
+				Channel:       "channel-1",
+				EventType:     domain.TypeRocketLaunched,
+				MessageNumber: 1,
+				Payload:       []byte(`{"channel":"channel-1"}`),
+				CreatedAt:     createdAt,
+				Actor:         "mission-control",
+			}
+
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO outbox \\(tenant_id, channel, event_type, message_number, payload, created_at, actor\\)").
+				WithArgs("tenant-a", "channel-1", domain.TypeRocketLaunched, int64(1), `{"channel":"channel-1"}`, createdAt, "mission-control")
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(tc.expectedID, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.Add(context.Background(), event)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedID, event.ID)
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxRepository_AddInTransaction(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	rocketRepo := NewRocketRepository(db)
+	outboxRepo := NewOutboxRepository(db)
+
+	// The rocket change and its event must be committed together
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM rockets WHERE tenant_id = \\? AND channel = \\?").
+		WithArgs(domain.DefaultTenantID, "channel-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := rocketRepo.BeginTx(context.Background())
+	assert.NoError(t, err)
+	ctx := domain.ContextWithTransaction(context.Background(), tx)
+
+	assert.NoError(t, rocketRepo.Delete(ctx, "channel-1"))
+	assert.NoError(t, outboxRepo.Add(ctx, &domain.OutboxEvent{Channel: "channel-1", Payload: []byte("{}")}))
+	assert.NoError(t, tx.Commit())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_FetchUnpublished(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The relay publishes the events of every tenant
+	mock.ExpectQuery("SELECT id, tenant_id, channel, event_type, message_number, payload, created_at, actor FROM outbox WHERE published_at IS NULL").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "channel", "event_type", "message_number", "payload", "created_at", "actor"}).
+			AddRow(1, "tenant-a", "channel-1", domain.TypeRocketLaunched, 1, `{"speed":100}`, createdAt, "mission-control").
+			AddRow(2, "tenant-b", "channel-1", domain.TypeRocketSpeedIncreased, 2, `{"speed":200}`, createdAt, ""))
+
+	events, err := repo.FetchUnpublished(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.OutboxEvent{
+		{ID: 1, TenantID: "tenant-a", Channel: "channel-1", EventType: domain.TypeRocketLaunched, MessageNumber: 1, Payload: []byte(`{"speed":100}`), CreatedAt: createdAt, Actor: "mission-control"},
+		{ID: 2, TenantID: "tenant-b", Channel: "channel-1", EventType: domain.TypeRocketSpeedIncreased, MessageNumber: 2, Payload: []byte(`{"speed":200}`), CreatedAt: createdAt},
+	}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkPublished(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectExec("UPDATE outbox SET published_at = \\? WHERE id = \\?").
+		WithArgs(sqlmock.AnyArg(), int64(3)).
+		WillReturnError(sql.ErrConnDone)
+
+	err = repo.MarkPublished(context.Background(), 3)
+
+	assert.EqualError(t, err, "failed to mark outbox event as published: sql: connection is already closed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_DeletePublishedBefore(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("DELETE FROM outbox WHERE published_at IS NOT NULL AND published_at < \\?").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := repo.DeletePublishedBefore(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
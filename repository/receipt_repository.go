@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+type ReceiptRepository struct {
+	db *sql.DB
+}
+
+func NewReceiptRepository(db *sql.DB) *ReceiptRepository {
+	return &ReceiptRepository{db: db}
+}
+
+const receiptColumns = `seq, id, tenant_id, channel, message_number, message_type, message, actor, status, error, created_at, updated_at`
+
+func (r *ReceiptRepository) Save(ctx context.Context, receipt *domain.Receipt) error {
+	query := `INSERT INTO receipts (
+				id, tenant_id, channel, message_number, message_type, message, actor, status, error, created_at, updated_at
+			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	receipt.TenantID = domain.TenantFromContext(ctx)
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		receipt.ID,
+		receipt.TenantID,
+		receipt.Channel,
+		receipt.MessageNumber,
+		receipt.MessageType,
+		string(receipt.Message),
+		receipt.Actor,
+		receipt.Status,
+		receipt.Error,
+		receipt.CreatedAt,
+		receipt.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save receipt: %w", err)
+	}
+
+	seq, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get receipt sequence: %w", err)
+	}
+	receipt.Seq = seq
+
+	return nil
+}
+
+func (r *ReceiptRepository) FindByID(ctx context.Context, id string) (*domain.Receipt, error) {
+	query := `SELECT ` + receiptColumns + ` FROM receipts WHERE tenant_id = ? AND id = ?`
+
+	receipt, err := scanReceipt(executorFor(ctx, r.db).QueryRowContext(ctx, query, domain.TenantFromContext(ctx), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrReceiptNotFound
+		}
+		return nil, fmt.Errorf("failed to find receipt: %w", err)
+	}
+
+	return receipt, nil
+}
+
+func (r *ReceiptRepository) FetchQueued(ctx context.Context, afterSeq int64, limit int) ([]*domain.Receipt, error) {
+	query := `SELECT ` + receiptColumns + `
+			  FROM receipts
+			  WHERE status = ? AND seq > ?
+			  ORDER BY seq ASC
+			  LIMIT ?`
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, domain.ReceiptStatusQueued, afterSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch queued receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var receipts []*domain.Receipt
+
+	for rows.Next() {
+		receipt, err := scanReceipt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
+func (r *ReceiptRepository) UpdateStatus(ctx context.Context, id string, status string, reason string) error {
+	query := `UPDATE receipts SET status = ?, error = ?, updated_at = ? WHERE id = ?`
+
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query, status, reason, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update receipt status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReceiptRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM receipts WHERE status NOT IN (?, ?) AND updated_at < ?`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.ReceiptStatusQueued, domain.ReceiptStatusBuffered, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete processed receipts: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted receipts: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// scanReceipt reads a row of receiptColumns
+func scanReceipt(row rowScanner) (*domain.Receipt, error) {
+	var receipt domain.Receipt
+	var message string
+
+	err := row.Scan(
+		&receipt.Seq,
+		&receipt.ID,
+		&receipt.TenantID,
+		&receipt.Channel,
+		&receipt.MessageNumber,
+		&receipt.MessageType,
+		&message,
+		&receipt.Actor,
+		&receipt.Status,
+		&receipt.Error,
+		&receipt.CreatedAt,
+		&receipt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt.Message = []byte(message)
+	return &receipt, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var receiptRowColumns = []string{"seq", "id", "tenant_id", "channel", "message_number", "message_type", "message", "actor", "status", "error", "created_at", "updated_at"}
+
+func TestReceiptRepository_Save(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewReceiptRepository(db)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedSeq   int64
+		expectedError string
+	}{
+		{
+			name:        "successful_save",
+			expectedSeq: 3,
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save receipt: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			receipt := &domain.Receipt{
+				ID:            "r-1",
+				Channel:       "channel-1",
+				MessageNumber: 1,
+				MessageType:   domain.TypeRocketLaunched,
+				Status:        domain.ReceiptStatusQueued,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+				Actor:         "ground-station",
+				Message:       []byte(`{"metadata":{"channel":"channel-1"}}`),
+			}
+
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO receipts").
+				WithArgs("r-1", "tenant-a", "channel-1", int64(1), domain.TypeRocketLaunched, `{"metadata":{"channel":"channel-1"}}`, "ground-station", domain.ReceiptStatusQueued, "", now, now)
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(tc.expectedSeq, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.Save(domain.ContextWithTenant(context.Background(), "tenant-a"), receipt)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedSeq, receipt.Seq)
+				assert.Equal(t, "tenant-a", receipt.TenantID)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceiptRepository_FindByID(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewReceiptRepository(db)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		rows            *sqlmock.Rows
+		dbError         error
+		expectedReceipt *domain.Receipt
+		expectedError   string
+	}{
+		{
+			name: "receipt_found",
+			rows: sqlmock.NewRows(receiptRowColumns).
+				AddRow(3, "r-1", "tenant-a", "channel-1", 1, domain.TypeRocketLaunched, `{}`, "", domain.ReceiptStatusFailed, "invalid message", now, now),
+			expectedReceipt: &domain.Receipt{
+				ID:            "r-1",
+				Channel:       "channel-1",
+				MessageNumber: 1,
+				MessageType:   domain.TypeRocketLaunched,
+				Status:        domain.ReceiptStatusFailed,
+				Error:         "invalid message",
+				CreatedAt:     now,
+				UpdatedAt:     now,
+				Seq:           3,
+				TenantID:      "tenant-a",
+				Message:       []byte(`{}`),
+			},
+		},
+		{
+			name:          "receipt_not_found",
+			rows:          sqlmock.NewRows(receiptRowColumns),
+			expectedError: "receipt not found",
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to find receipt: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Set up expectations
+			expectation := mock.ExpectQuery("SELECT (.+) FROM receipts WHERE tenant_id = \\? AND id = \\?").
+				WithArgs("tenant-a", "r-1")
+			if tc.dbError == nil {
+				expectation.WillReturnRows(tc.rows)
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			receipt, err := repo.FindByID(domain.ContextWithTenant(context.Background(), "tenant-a"), "r-1")
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, receipt)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReceipt, receipt)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceiptRepository_FetchQueued(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewReceiptRepository(db)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Queued receipts of every tenant are fetched
+	mock.ExpectQuery("SELECT (.+) FROM receipts WHERE status = \\? AND seq > \\? ORDER BY seq ASC LIMIT \\?").
+		WithArgs(domain.ReceiptStatusQueued, int64(4), 10).
+		WillReturnRows(sqlmock.NewRows(receiptRowColumns).
+			AddRow(5, "r-5", "tenant-a", "channel-1", 2, domain.TypeRocketSpeedIncreased, `{}`, "", domain.ReceiptStatusQueued, "", now, now).
+			AddRow(6, "r-6", "tenant-b", "channel-1", 1, domain.TypeRocketLaunched, `{}`, "", domain.ReceiptStatusQueued, "", now, now))
+
+	receipts, err := repo.FetchQueued(context.Background(), 4, 10)
+
+	assert.NoError(t, err)
+	if assert.Len(t, receipts, 2) {
+		assert.Equal(t, "r-5", receipts[0].ID)
+		assert.Equal(t, "tenant-b", receipts[1].TenantID)
+		assert.Equal(t, int64(6), receipts[1].Seq)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceiptRepository_DeleteProcessedBefore(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewReceiptRepository(db)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Receipts of queued and buffered messages are kept
+	mock.ExpectExec("DELETE FROM receipts WHERE status NOT IN \\(\\?, \\?\\) AND updated_at < \\?").
+		WithArgs(domain.ReceiptStatusQueued, domain.ReceiptStatusBuffered, before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteProcessedBefore(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
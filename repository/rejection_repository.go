@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+type RejectionRepository struct {
+	db *sql.DB
+}
+
+func NewRejectionRepository(db *sql.DB) *RejectionRepository {
+	return &RejectionRepository{db: db}
+}
+
+func (r *RejectionRepository) Save(ctx context.Context, rejection *domain.Rejection) error {
+	query := `INSERT INTO rejections (
+				tenant_id, channel, message_number, message_type, message_time, status, reason, rejected_at, actor
+			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		domain.TenantFromContext(ctx),
+		rejection.Channel,
+		rejection.MessageNumber,
+		rejection.MessageType,
+		rejection.MessageTime,
+		rejection.Status,
+		rejection.Reason,
+		rejection.RejectedAt,
+		rejection.Actor,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save rejection: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get rejection id: %w", err)
+	}
+	rejection.ID = id
+
+	return nil
+}
+
+// List returns the matching rejections of the tenant, newest first
+func (r *RejectionRepository) List(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error) {
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{domain.TenantFromContext(ctx)}
+	if query.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		args = append(args, query.Channel)
+	}
+	if query.MessageType != "" {
+		conditions = append(conditions, "message_type = ?")
+		args = append(args, query.MessageType)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	limit := ""
+	if query.Limit > 0 {
+		limit = "LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	sqlQuery := fmt.Sprintf(`SELECT id, channel, message_number, message_type, message_time, status, reason, rejected_at, actor
+							 FROM rejections
+							 %s
+							 ORDER BY id DESC
+							 %s`, where, limit)
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rejections: %w", err)
+	}
+	defer rows.Close()
+
+	var rejections []*domain.Rejection
+
+	for rows.Next() {
+		var rejection domain.Rejection
+
+		err := rows.Scan(
+			&rejection.ID,
+			&rejection.Channel,
+			&rejection.MessageNumber,
+			&rejection.MessageType,
+			&rejection.MessageTime,
+			&rejection.Status,
+			&rejection.Reason,
+			&rejection.RejectedAt,
+			&rejection.Actor,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rejection: %w", err)
+		}
+
+		rejections = append(rejections, &rejection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rejections: %w", err)
+	}
+
+	return rejections, nil
+}
+
+func (r *RejectionRepository) DeleteChannel(ctx context.Context, channel string) (int64, error) {
+	query := `DELETE FROM rejections WHERE tenant_id = ? AND channel = ?`
+
+	result, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.TenantFromContext(ctx), channel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete rejections: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectionRepository_Save(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRejectionRepository(db)
+
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedID    int64
+		expectedError string
+	}{
+		{
+			name:          "successful_save",
+			dbError:       nil,
+			expectedID:    7,
+			expectedError: "",
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save rejection: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			rejection := &domain.Rejection{
+				Channel:       "channel-1",
+				MessageNumber: 2,
+				MessageType:   domain.TypeRocketSpeedIncreased,
+				MessageTime:   now,
+				Status:        domain.RocketStatusNotLaunched,
+				Reason:        "RocketSpeedIncreased received before the rocket was launched",
+				RejectedAt:    now,
+				Actor:         "mission-control",
+			}
+
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO rejections").
+				WithArgs(domain.DefaultTenantID, "channel-1", int64(2), domain.TypeRocketSpeedIncreased, now, domain.RocketStatusNotLaunched, rejection.Reason, now, "mission-control")
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(tc.expectedID, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.Save(context.Background(), rejection)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedID, rejection.ID)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRejectionRepository_List(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRejectionRepository(db)
+
+	now := time.Now()
+	columns := []string{"id", "channel", "message_number", "message_type", "message_time", "status", "reason", "rejected_at", "actor"}
+
+	testCases := []struct {
+		name               string
+		query              domain.RejectionQuery
+		setupMock          func()
+		expectedRejections []*domain.Rejection
+		expectedError      string
+	}{
+		{
+			name:  "filtered",
+			query: domain.RejectionQuery{Channel: "channel-1", MessageType: domain.TypeRocketLaunched, Limit: 10},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM rejections WHERE tenant_id = \\? AND channel = \\? AND message_type = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(domain.DefaultTenantID, "channel-1", domain.TypeRocketLaunched, 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(3), "channel-1", int64(4), domain.TypeRocketLaunched, now, domain.RocketStatusLaunched, "rocket already launched", now, "mission-control"))
+			},
+			expectedRejections: []*domain.Rejection{
+				{ID: 3, Channel: "channel-1", MessageNumber: 4, MessageType: domain.TypeRocketLaunched, MessageTime: now, Status: domain.RocketStatusLaunched, Reason: "rocket already launched", RejectedAt: now, Actor: "mission-control"},
+			},
+		},
+		{
+			name:  "unfiltered",
+			query: domain.RejectionQuery{},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM rejections WHERE tenant_id = \\? ORDER BY id DESC").
+					WithArgs(domain.DefaultTenantID).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedRejections: nil,
+		},
+		{
+			name:  "database_error",
+			query: domain.RejectionQuery{Limit: 10},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM rejections WHERE tenant_id = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(domain.DefaultTenantID, 10).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to list rejections: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			rejections, err := repo.List(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, rejections)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRejections, rejections)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRejectionRepository_DeleteChannel(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRejectionRepository(db)
+
+	mock.ExpectExec("DELETE FROM rejections WHERE tenant_id = \\? AND channel = \\?").
+		WithArgs(domain.DefaultTenantID, "channel-1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteChannel(context.Background(), "channel-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
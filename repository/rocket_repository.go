@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"lunar-rockets/domain"
@@ -22,6 +23,33 @@ func (t *sqliteTransaction) Rollback() error {
 	return t.tx.Rollback()
 }
 
+// executor is the subset of *sql.DB and *sql.Tx used by the repositories
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// executorFor returns the transaction carried by ctx, falling back to db
+func executorFor(ctx context.Context, db *sql.DB) executor {
+	if tx, ok := domain.TransactionFromContext(ctx); ok {
+		if sqliteTx, ok := tx.(*sqliteTransaction); ok {
+			return sqliteTx.tx
+		}
+	}
+	return db
+}
+
+// rocketColumns are the columns scanned by scanRocket, in order
+const rocketColumns = `channel, type, speed, mission, launch_time, status, exploded_at, reason,
+	landed_at, landing_site, aborted_at, docked_at, station,
+	telemetry_time, altitude, latitude, longitude, fuel, heading, last_updated, last_message`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type RocketRepository struct {
 	db *sql.DB
 }
@@ -31,26 +59,11 @@ func NewRocketRepository(db *sql.DB) domain.RocketRepository {
 }
 
 func (r *RocketRepository) GetByChannel(ctx context.Context, channel string) (*domain.Rocket, error) {
-	query := `SELECT channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated 
+	query := `SELECT ` + rocketColumns + `
 			  FROM rockets 
-			  WHERE channel = ?`
-
-	var rocket domain.Rocket
-	var explodedAt sql.NullTime
-	var reason sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, channel).Scan(
-		&rocket.Channel,
-		&rocket.Type,
-		&rocket.Speed,
-		&rocket.Mission,
-		&rocket.LaunchTime,
-		&rocket.Status,
-		&explodedAt,
-		&reason,
-		&rocket.LastUpdated,
-	)
+			  WHERE tenant_id = ? AND channel = ?`
 
+	rocket, err := scanRocket(executorFor(ctx, r.db).QueryRowContext(ctx, query, domain.TenantFromContext(ctx), channel))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -58,16 +71,7 @@ func (r *RocketRepository) GetByChannel(ctx context.Context, channel string) (*d
 		return nil, fmt.Errorf("failed to get rocket: %w", err)
 	}
 
-	if explodedAt.Valid {
-		t := explodedAt.Time
-		rocket.ExplodedAt = &t
-	}
-
-	if reason.Valid {
-		rocket.Reason = reason.String
-	}
-
-	return &rocket, nil
+	return rocket, nil
 }
 
 func (r *RocketRepository) GetAll(ctx context.Context, sortBy string, order string) ([]*domain.Rocket, error) {
@@ -90,11 +94,12 @@ func (r *RocketRepository) GetAll(ctx context.Context, sortBy string, order stri
 		return nil, fmt.Errorf("invalid sort order: %s", order)
 	}
 
-	query := fmt.Sprintf(`SELECT channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated 
+	query := fmt.Sprintf(`SELECT %s 
 						  FROM rockets 
-						  ORDER BY %s %s`, sortBy, order)
+						  WHERE tenant_id = ?
+						  ORDER BY %s %s`, rocketColumns, sortBy, order)
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, domain.TenantFromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rockets: %w", err)
 	}
@@ -103,67 +108,176 @@ func (r *RocketRepository) GetAll(ctx context.Context, sortBy string, order stri
 	var rockets []*domain.Rocket
 
 	for rows.Next() {
-		var rocket domain.Rocket
-		var explodedAt sql.NullTime
-		var reason sql.NullString
-
-		err := rows.Scan(
-			&rocket.Channel,
-			&rocket.Type,
-			&rocket.Speed,
-			&rocket.Mission,
-			&rocket.LaunchTime,
-			&rocket.Status,
-			&explodedAt,
-			&reason,
-			&rocket.LastUpdated,
-		)
-
+		rocket, err := scanRocket(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan rocket: %w", err)
 		}
 
-		if explodedAt.Valid {
-			t := explodedAt.Time
-			rocket.ExplodedAt = &t
-		}
+		rockets = append(rockets, rocket)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rockets: %w", err)
+	}
+
+	return rockets, nil
+}
+
+func (r *RocketRepository) Search(ctx context.Context, query domain.RocketQuery) ([]*domain.Rocket, int, error) {
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = "type"
+	}
+	order := query.Order
+	if order == "" {
+		order = "DESC"
+	}
 
-		if reason.Valid {
-			rocket.Reason = reason.String
+	validColumns := map[string]bool{
+		"channel": true, "type": true, "speed": true, "mission": true, "status": true,
+	}
+
+	if !validColumns[sortBy] {
+		return nil, 0, fmt.Errorf("invalid sort column: %s", sortBy)
+	}
+
+	if order != "ASC" && order != "DESC" {
+		return nil, 0, fmt.Errorf("invalid sort order: %s", order)
+	}
+
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{domain.TenantFromContext(ctx)}
+	if query.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, query.Status)
+	}
+	if query.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, query.Type)
+	}
+	if query.Mission != "" {
+		conditions = append(conditions, "mission = ?")
+		args = append(args, query.Mission)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM rockets %s`, where)
+	if err := executorFor(ctx, r.db).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rockets: %w", err)
+	}
+
+	// Channel breaks ties so that pages are stable
+	selectQuery := fmt.Sprintf(`SELECT %s
+						  FROM rockets
+						  %s
+						  ORDER BY %s %s, channel ASC`, rocketColumns, where, sortBy, order)
+	if query.Limit > 0 {
+		selectQuery += " LIMIT ? OFFSET ?"
+		args = append(args, query.Limit, query.Offset)
+	}
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search rockets: %w", err)
+	}
+	defer rows.Close()
+
+	var rockets []*domain.Rocket
+
+	for rows.Next() {
+		rocket, err := scanRocket(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan rocket: %w", err)
 		}
 
-		rockets = append(rockets, &rocket)
+		rockets = append(rockets, rocket)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rockets: %w", err)
+		return nil, 0, fmt.Errorf("error iterating rockets: %w", err)
 	}
 
-	return rockets, nil
+	return rockets, total, nil
 }
 
-func (r *RocketRepository) Save(ctx context.Context, rocket *domain.Rocket) error {
-	query := `INSERT INTO rockets (
-				channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated, last_message
-			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+func (r *RocketRepository) Stats(ctx context.Context) (*domain.FleetStats, error) {
+	stats := &domain.FleetStats{
+		ByStatus: make(map[string]int),
+		ByType:   make(map[string]int),
+	}
+
+	totalsQuery := `SELECT COUNT(*), COALESCE(AVG(speed), 0), COALESCE(MAX(speed), 0) FROM rockets WHERE tenant_id = ?`
+	err := executorFor(ctx, r.db).QueryRowContext(ctx, totalsQuery, domain.TenantFromContext(ctx)).Scan(&stats.Total, &stats.AverageSpeed, &stats.MaxSpeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fleet totals: %w", err)
+	}
 
-	var explodedAt interface{}
-	if rocket.ExplodedAt != nil {
-		explodedAt = *rocket.ExplodedAt
+	if err := r.countBy(ctx, "status", stats.ByStatus); err != nil {
+		return nil, err
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	if err := r.countBy(ctx, "type", stats.ByType); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// countBy fills counts with the number of rockets per value of column
+func (r *RocketRepository) countBy(ctx context.Context, column string, counts map[string]int) error {
+	query := fmt.Sprintf(`SELECT %s, COUNT(*) FROM rockets WHERE tenant_id = ? GROUP BY %s`, column, column)
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, domain.TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to count rockets by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return fmt.Errorf("failed to scan rocket count: %w", err)
+		}
+		counts[value] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rocket counts: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RocketRepository) Save(ctx context.Context, rocket *domain.Rocket) error {
+	query := `INSERT INTO rockets (
+				tenant_id, channel, type, speed, mission, launch_time, status, exploded_at, reason,
+				landed_at, landing_site, aborted_at, docked_at, station,
+				telemetry_time, altitude, latitude, longitude, fuel, heading, last_updated, last_message
+			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	args := []interface{}{
+		domain.TenantFromContext(ctx),
 		rocket.Channel,
 		rocket.Type,
 		rocket.Speed,
 		rocket.Mission,
 		rocket.LaunchTime,
 		rocket.Status,
-		explodedAt,
+		nullableTime(rocket.ExplodedAt),
 		rocket.Reason,
-		time.Now(),
-		rocket.LastMessage,
-	)
+		nullableTime(rocket.LandedAt),
+		rocket.LandingSite,
+		nullableTime(rocket.AbortedAt),
+		nullableTime(rocket.DockedAt),
+		rocket.Station,
+	}
+	args = append(args, telemetryValues(rocket.Telemetry)...)
+	args = append(args, time.Now(), rocket.LastMessage)
+
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
 		return fmt.Errorf("failed to save rocket: %w", err)
@@ -175,25 +289,29 @@ func (r *RocketRepository) Save(ctx context.Context, rocket *domain.Rocket) erro
 func (r *RocketRepository) Update(ctx context.Context, rocket *domain.Rocket) error {
 	query := `UPDATE rockets 
 			  SET type = ?, speed = ?, mission = ?, status = ?, 
-				  exploded_at = ?, reason = ?, last_updated = ?, last_message = ?
-			  WHERE channel = ?`
-
-	var explodedAt interface{}
-	if rocket.ExplodedAt != nil {
-		explodedAt = *rocket.ExplodedAt
-	}
+				  exploded_at = ?, reason = ?, landed_at = ?, landing_site = ?,
+				  aborted_at = ?, docked_at = ?, station = ?,
+				  telemetry_time = ?, altitude = ?, latitude = ?, longitude = ?, fuel = ?, heading = ?,
+				  last_updated = ?, last_message = ?
+			  WHERE tenant_id = ? AND channel = ?`
 
-	_, err := r.db.ExecContext(ctx, query,
+	args := []interface{}{
 		rocket.Type,
 		rocket.Speed,
 		rocket.Mission,
 		rocket.Status,
-		explodedAt,
+		nullableTime(rocket.ExplodedAt),
 		rocket.Reason,
-		time.Now(),
-		rocket.LastMessage,
-		rocket.Channel,
-	)
+		nullableTime(rocket.LandedAt),
+		rocket.LandingSite,
+		nullableTime(rocket.AbortedAt),
+		nullableTime(rocket.DockedAt),
+		rocket.Station,
+	}
+	args = append(args, telemetryValues(rocket.Telemetry)...)
+	args = append(args, time.Now(), rocket.LastMessage, domain.TenantFromContext(ctx), rocket.Channel)
+
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
 		return fmt.Errorf("failed to update rocket: %w", err)
@@ -203,9 +321,9 @@ func (r *RocketRepository) Update(ctx context.Context, rocket *domain.Rocket) er
 }
 
 func (r *RocketRepository) Delete(ctx context.Context, channel string) error {
-	query := `DELETE FROM rockets WHERE channel = ?`
+	query := `DELETE FROM rockets WHERE tenant_id = ? AND channel = ?`
 
-	_, err := r.db.ExecContext(ctx, query, channel)
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.TenantFromContext(ctx), channel)
 	if err != nil {
 		return fmt.Errorf("failed to delete rocket: %w", err)
 	}
@@ -221,3 +339,93 @@ func (r *RocketRepository) BeginTx(ctx context.Context) (domain.Transaction, err
 
 	return &sqliteTransaction{tx: tx}, nil
 }
+
+// scanRocket reads a rocket from a row selecting rocketColumns
+func scanRocket(row rowScanner) (*domain.Rocket, error) {
+	var rocket domain.Rocket
+	var explodedAt, landedAt, abortedAt, dockedAt sql.NullTime
+	var reason, landingSite, station sql.NullString
+	var telemetryTime sql.NullTime
+	var altitude, latitude, longitude, fuel, heading sql.NullFloat64
+
+	err := row.Scan(
+		&rocket.Channel,
+		&rocket.Type,
+		&rocket.Speed,
+		&rocket.Mission,
+		&rocket.LaunchTime,
+		&rocket.Status,
+		&explodedAt,
+		&reason,
+		&landedAt,
+		&landingSite,
+		&abortedAt,
+		&dockedAt,
+		&station,
+		&telemetryTime,
+		&altitude,
+		&latitude,
+		&longitude,
+		&fuel,
+		&heading,
+		&rocket.LastUpdated,
+		&rocket.LastMessage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rocket.ExplodedAt = timePointer(explodedAt)
+	rocket.LandedAt = timePointer(landedAt)
+	rocket.AbortedAt = timePointer(abortedAt)
+	rocket.DockedAt = timePointer(dockedAt)
+	rocket.Reason = reason.String
+	rocket.LandingSite = landingSite.String
+	rocket.Station = station.String
+
+	if telemetryTime.Valid {
+		rocket.Telemetry = &domain.Telemetry{
+			Time:      telemetryTime.Time,
+			Altitude:  altitude.Float64,
+			Latitude:  latitude.Float64,
+			Longitude: longitude.Float64,
+			Fuel:      fuel.Float64,
+			Heading:   heading.Float64,
+		}
+	}
+
+	return &rocket, nil
+}
+
+// telemetryValues returns the values of the latest telemetry columns, all
+// NULL when the rocket has no reading
+func telemetryValues(telemetry *domain.Telemetry) []interface{} {
+	if telemetry == nil {
+		return []interface{}{nil, nil, nil, nil, nil, nil}
+	}
+	return []interface{}{
+		telemetry.Time,
+		telemetry.Altitude,
+		telemetry.Latitude,
+		telemetry.Longitude,
+		telemetry.Fuel,
+		telemetry.Heading,
+	}
+}
+
+// nullableTime converts an optional time to a value stored as NULL when unset
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// timePointer converts a nullable time column to an optional time
+func timePointer(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
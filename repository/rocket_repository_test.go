@@ -12,6 +12,14 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// rocketRowColumns are the columns of the rows scanned by the rocket repository
+var rocketRowColumns = []string{
+	"channel", "type", "speed", "mission", "launch_time", "status",
+	"exploded_at", "reason", "landed_at", "landing_site", "aborted_at",
+	"docked_at", "station", "telemetry_time", "altitude", "latitude",
+	"longitude", "fuel", "heading", "last_updated", "last_message",
+}
+
 func TestRocketRepository_GetByChannel(t *testing.T) {
 	// Create sqlmock
 	db, mock, err := sqlmock.New()
@@ -22,6 +30,9 @@ func TestRocketRepository_GetByChannel(t *testing.T) {
 
 	repo := NewRocketRepository(db)
 
+	landedAt := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	abortedAt := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
 	testCases := []struct {
 		name           string
 		channel        string
@@ -32,15 +43,13 @@ func TestRocketRepository_GetByChannel(t *testing.T) {
 		{
 			name:    "successful_get",
 			channel: "channel-1",
-			mockRows: sqlmock.NewRows([]string{
-				"channel", "type", "speed", "mission", "launch_time", "status",
-				"exploded_at", "reason", "last_updated",
-			}).AddRow(
+			mockRows: sqlmock.NewRows(rocketRowColumns).AddRow(
 				"channel-1", "Falcon-9", 1000, "ARTEMIS",
 				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 				domain.RocketStatusLaunched,
-				nil, nil,
-				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				nil, nil, nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, nil,
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 3,
 			),
 			expectedRocket: &domain.Rocket{
 				Channel:     "channel-1",
@@ -50,6 +59,43 @@ func TestRocketRepository_GetByChannel(t *testing.T) {
 				LaunchTime:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 				Status:      domain.RocketStatusLaunched,
 				LastUpdated: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				LastMessage: 3,
+			},
+			expectedError: "",
+		},
+		{
+			name:    "landed_after_abort",
+			channel: "channel-1",
+			mockRows: sqlmock.NewRows(rocketRowColumns).AddRow(
+				"channel-1", "Falcon-9", 0, "ARTEMIS",
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				domain.RocketStatusLanded,
+				nil, "GUIDANCE_FAILURE",
+				landedAt, "CAPE_CANAVERAL", abortedAt, nil, nil,
+				landedAt, 0.0, 28.5, -80.6, 12.5, 270.0,
+				landedAt, 5,
+			),
+			expectedRocket: &domain.Rocket{
+				Channel:     "channel-1",
+				Type:        "Falcon-9",
+				Speed:       0,
+				Mission:     "ARTEMIS",
+				LaunchTime:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Status:      domain.RocketStatusLanded,
+				Reason:      "GUIDANCE_FAILURE",
+				LandedAt:    &landedAt,
+				LandingSite: "CAPE_CANAVERAL",
+				AbortedAt:   &abortedAt,
+				Telemetry: &domain.Telemetry{
+					Time:      landedAt,
+					Altitude:  0,
+					Latitude:  28.5,
+					Longitude: -80.6,
+					Fuel:      12.5,
+					Heading:   270,
+				},
+				LastUpdated: landedAt,
+				LastMessage: 5,
 			},
 			expectedError: "",
 		},
@@ -74,12 +120,12 @@ func TestRocketRepository_GetByChannel(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Set up expectations
 			if tc.expectedError == "" {
-				mock.ExpectQuery("SELECT channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated FROM rockets WHERE channel = ?").
-					WithArgs(tc.channel).
+				mock.ExpectQuery("SELECT (.+) FROM rockets WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, tc.channel).
 					WillReturnRows(tc.mockRows)
 			} else {
-				mock.ExpectQuery("SELECT channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated FROM rockets WHERE channel = ?").
-					WithArgs(tc.channel).
+				mock.ExpectQuery("SELECT (.+) FROM rockets WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, tc.channel).
 					WillReturnError(sql.ErrConnDone)
 			}
 
@@ -147,6 +193,7 @@ func TestRocketRepository_Save(t *testing.T) {
 			if tc.expectedError == "" {
 				mock.ExpectExec("INSERT INTO rockets").
 					WithArgs(
+						domain.DefaultTenantID,
 						tc.rocket.Channel,
 						tc.rocket.Type,
 						tc.rocket.Speed,
@@ -155,6 +202,12 @@ func TestRocketRepository_Save(t *testing.T) {
 						tc.rocket.Status,
 						nil,
 						tc.rocket.Reason,
+						nil,
+						tc.rocket.LandingSite,
+						nil,
+						nil,
+						tc.rocket.Station,
+						nil, nil, nil, nil, nil, nil, // telemetry
 						sqlmock.AnyArg(), // last_updated
 						tc.rocket.LastMessage,
 					).
@@ -162,6 +215,7 @@ func TestRocketRepository_Save(t *testing.T) {
 			} else {
 				mock.ExpectExec("INSERT INTO rockets").
 					WithArgs(
+						domain.DefaultTenantID,
 						tc.rocket.Channel,
 						tc.rocket.Type,
 						tc.rocket.Speed,
@@ -170,6 +224,12 @@ func TestRocketRepository_Save(t *testing.T) {
 						tc.rocket.Status,
 						nil,
 						tc.rocket.Reason,
+						nil,
+						tc.rocket.LandingSite,
+						nil,
+						nil,
+						tc.rocket.Station,
+						nil, nil, nil, nil, nil, nil, // telemetry
 						sqlmock.AnyArg(), // last_updated
 						tc.rocket.LastMessage,
 					).
@@ -244,8 +304,15 @@ func TestRocketRepository_Update(t *testing.T) {
 						tc.rocket.Status,
 						nil,
 						tc.rocket.Reason,
+						nil,
+						tc.rocket.LandingSite,
+						nil,
+						nil,
+						tc.rocket.Station,
+						nil, nil, nil, nil, nil, nil, // telemetry
 						sqlmock.AnyArg(), // last_updated
 						tc.rocket.LastMessage,
+						domain.DefaultTenantID,
 						tc.rocket.Channel,
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
@@ -258,8 +325,15 @@ func TestRocketRepository_Update(t *testing.T) {
 						tc.rocket.Status,
 						nil,
 						tc.rocket.Reason,
+						nil,
+						tc.rocket.LandingSite,
+						nil,
+						nil,
+						tc.rocket.Station,
+						nil, nil, nil, nil, nil, nil, // telemetry
 						sqlmock.AnyArg(), // last_updated
 						tc.rocket.LastMessage,
+						domain.DefaultTenantID,
 						tc.rocket.Channel,
 					).
 					WillReturnError(sql.ErrConnDone)
@@ -314,12 +388,12 @@ func TestRocketRepository_Delete(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Set up expectations
 			if tc.expectedError == "" {
-				mock.ExpectExec("DELETE FROM rockets").
-					WithArgs(tc.channel).
+				mock.ExpectExec("DELETE FROM rockets WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, tc.channel).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			} else {
-				mock.ExpectExec("DELETE FROM rockets").
-					WithArgs(tc.channel).
+				mock.ExpectExec("DELETE FROM rockets WHERE tenant_id = \\? AND channel = \\?").
+					WithArgs(domain.DefaultTenantID, tc.channel).
 					WillReturnError(sql.ErrConnDone)
 			}
 
@@ -418,15 +492,14 @@ func TestRocketRepository_GetAll(t *testing.T) {
 			name:   "default_sorting",
 			sortBy: "",
 			order:  "",
-			mockRows: sqlmock.NewRows([]string{
-				"channel", "type", "speed", "mission", "launch_time", "status",
-				"exploded_at", "reason", "last_updated",
-			}).AddRow(
+			mockRows: sqlmock.NewRows(rocketRowColumns).AddRow(
 				"channel-1", "type-1", 100, "mission-1", now, "launched",
-				explodedAt, "reason-1", now,
+				explodedAt, "reason-1", nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, nil, now, 1,
 			).AddRow(
 				"channel-2", "type-2", 200, "mission-2", now.Add(time.Hour), "exploded",
-				nil, "", now,
+				nil, "", nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, nil, now, 1,
 			),
 			expectedError: "",
 			expectedCount: 2,
@@ -435,15 +508,14 @@ func TestRocketRepository_GetAll(t *testing.T) {
 			name:   "custom_sorting",
 			sortBy: "speed",
 			order:  "ASC",
-			mockRows: sqlmock.NewRows([]string{
-				"channel", "type", "speed", "mission", "launch_time", "status",
-				"exploded_at", "reason", "last_updated",
-			}).AddRow(
+			mockRows: sqlmock.NewRows(rocketRowColumns).AddRow(
 				"channel-1", "type-1", 100, "mission-1", now, "launched",
-				nil, "", now,
+				nil, "", nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, nil, now, 1,
 			).AddRow(
 				"channel-2", "type-2", 200, "mission-2", now, "launched",
-				nil, "", now,
+				nil, "", nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, nil, now, 1,
 			),
 			expectedError: "",
 			expectedCount: 2,
@@ -465,13 +537,10 @@ func TestRocketRepository_GetAll(t *testing.T) {
 			expectedCount: 0,
 		},
 		{
-			name:   "database_error",
-			sortBy: "",
-			order:  "",
-			mockRows: sqlmock.NewRows([]string{
-				"channel", "type", "speed", "mission", "launch_time", "status",
-				"exploded_at", "reason", "last_updated",
-			}),
+			name:          "database_error",
+			sortBy:        "",
+			order:         "",
+			mockRows:      sqlmock.NewRows(rocketRowColumns),
 			expectedError: "failed to get rockets: sql: connection is already closed",
 			expectedCount: 0,
 		},
@@ -482,9 +551,7 @@ func TestRocketRepository_GetAll(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Set up expectations
 			if tc.expectedError == "" && tc.mockRows != nil {
-				expectedQuery := `SELECT channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated 
-								FROM rockets 
-								ORDER BY `
+				expectedQuery := `SELECT (.+) FROM rockets WHERE tenant_id = \? ORDER BY `
 				if tc.sortBy != "" {
 					expectedQuery += tc.sortBy + " " + tc.order
 				} else {
@@ -492,9 +559,10 @@ func TestRocketRepository_GetAll(t *testing.T) {
 				}
 
 				mock.ExpectQuery(expectedQuery).
+					WithArgs(domain.DefaultTenantID).
 					WillReturnRows(tc.mockRows)
 			} else if tc.expectedError != "" && tc.mockRows != nil {
-				mock.ExpectQuery("SELECT channel, type, speed, mission, launch_time, status, exploded_at, reason, last_updated FROM rockets").
+				mock.ExpectQuery("SELECT (.+) FROM rockets").
 					WillReturnError(sql.ErrConnDone)
 			}
 
@@ -538,3 +606,149 @@ func TestRocketRepository_GetAll(t *testing.T) {
 		})
 	}
 }
+
+func TestRocketRepository_Search(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRocketRepository(db)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         domain.RocketQuery
+		setupMock     func()
+		expectedTotal int
+		expectedCount int
+		expectedError string
+	}{
+		{
+			name:  "filters_and_pagination",
+			query: domain.RocketQuery{Status: domain.RocketStatusLaunched, Type: "Falcon-9", SortBy: "speed", Order: "ASC", Limit: 1, Offset: 1},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM rockets WHERE tenant_id = \\? AND status = \\? AND type = \\?").
+					WithArgs(domain.DefaultTenantID, domain.RocketStatusLaunched, "Falcon-9").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM rockets WHERE tenant_id = \\? AND status = \\? AND type = \\? ORDER BY speed ASC, channel ASC LIMIT \\? OFFSET \\?").
+					WithArgs(domain.DefaultTenantID, domain.RocketStatusLaunched, "Falcon-9", 1, 1).
+					WillReturnRows(sqlmock.NewRows(rocketRowColumns).
+						AddRow("channel-2", "Falcon-9", 200, "ARTEMIS", now, domain.RocketStatusLaunched, nil, nil, nil, nil, nil, nil, nil,
+							nil, nil, nil, nil, nil, nil, now, 4))
+			},
+			expectedTotal: 2,
+			expectedCount: 1,
+		},
+		{
+			name:  "no_filters",
+			query: domain.RocketQuery{},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM rockets WHERE tenant_id = \\?").
+					WithArgs(domain.DefaultTenantID).
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+				mock.ExpectQuery("SELECT (.+) FROM rockets WHERE tenant_id = \\? ORDER BY type DESC, channel ASC").
+					WithArgs(domain.DefaultTenantID).
+					WillReturnRows(sqlmock.NewRows(rocketRowColumns))
+			},
+			expectedTotal: 0,
+			expectedCount: 0,
+		},
+		{
+			name:          "invalid_sort_column",
+			query:         domain.RocketQuery{SortBy: "reason"},
+			setupMock:     func() {},
+			expectedError: "invalid sort column: reason",
+		},
+		{
+			name:  "count_error",
+			query: domain.RocketQuery{Mission: "MARS"},
+			setupMock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM rockets WHERE tenant_id = \\? AND mission = \\?").
+					WithArgs(domain.DefaultTenantID, "MARS").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to count rockets: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			rockets, total, err := repo.Search(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedTotal, total)
+				assert.Len(t, rockets, tc.expectedCount)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRocketRepository_Stats(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRocketRepository(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(AVG\\(speed\\), 0\\), COALESCE\\(MAX\\(speed\\), 0\\) FROM rockets WHERE tenant_id = \\?").
+		WithArgs(domain.DefaultTenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "avg", "max"}).AddRow(3, 200.0, 300))
+	mock.ExpectQuery("SELECT status, COUNT\\(\\*\\) FROM rockets WHERE tenant_id = \\? GROUP BY status").
+		WithArgs(domain.DefaultTenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).
+			AddRow(domain.RocketStatusLaunched, 2).
+			AddRow(domain.RocketStatusExploded, 1))
+	mock.ExpectQuery("SELECT type, COUNT\\(\\*\\) FROM rockets WHERE tenant_id = \\? GROUP BY type").
+		WithArgs(domain.DefaultTenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "count"}).AddRow("Falcon-9", 3))
+
+	stats, err := repo.Stats(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.FleetStats{
+		Total:        3,
+		AverageSpeed: 200,
+		MaxSpeed:     300,
+		ByStatus:     map[string]int{domain.RocketStatusLaunched: 2, domain.RocketStatusExploded: 1},
+		ByType:       map[string]int{"Falcon-9": 3},
+	}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRocketRepository_Stats_Error(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRocketRepository(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(AVG\\(speed\\), 0\\), COALESCE\\(MAX\\(speed\\), 0\\) FROM rockets").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "avg", "max"}).AddRow(0, 0.0, 0))
+	mock.ExpectQuery("SELECT status, COUNT\\(\\*\\) FROM rockets WHERE tenant_id = \\? GROUP BY status").
+		WillReturnError(sql.ErrConnDone)
+
+	stats, err := repo.Stats(context.Background())
+
+	assert.Nil(t, stats)
+	assert.EqualError(t, err, "failed to count rockets by status: sql: connection is already closed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
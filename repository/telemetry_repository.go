@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+type TelemetryRepository struct {
+	db *sql.DB
+}
+
+func NewTelemetryRepository(db *sql.DB) *TelemetryRepository {
+	return &TelemetryRepository{db: db}
+}
+
+// Save records a reading. Times are stored in UTC so that they sort as text.
+func (r *TelemetryRepository) Save(ctx context.Context, channel string, messageNumber int64, reading *domain.Telemetry) error {
+	query := `INSERT INTO telemetry (
+				tenant_id, channel, message_number, reading_time, altitude, latitude, longitude, fuel, heading
+			  ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query,
+		domain.TenantFromContext(ctx),
+		channel,
+		messageNumber,
+		reading.Time.UTC(),
+		reading.Altitude,
+		reading.Latitude,
+		reading.Longitude,
+		reading.Fuel,
+		reading.Heading,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save telemetry: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the readings of a channel between from and to, both included,
+// oldest first. Zero times leave the range open.
+func (r *TelemetryRepository) List(ctx context.Context, channel string, from time.Time, to time.Time) ([]*domain.Telemetry, error) {
+	conditions := []string{"tenant_id = ?", "channel = ?"}
+	args := []interface{}{domain.TenantFromContext(ctx), channel}
+	if !from.IsZero() {
+		conditions = append(conditions, "reading_time >= ?")
+		args = append(args, from.UTC())
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, "reading_time <= ?")
+		args = append(args, to.UTC())
+	}
+
+	query := fmt.Sprintf(`SELECT reading_time, altitude, latitude, longitude, fuel, heading
+						  FROM telemetry
+						  WHERE %s
+						  ORDER BY reading_time ASC, message_number ASC`, strings.Join(conditions, " AND "))
+
+	rows, err := executorFor(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	var readings []*domain.Telemetry
+
+	for rows.Next() {
+		var reading domain.Telemetry
+
+		err := rows.Scan(
+			&reading.Time,
+			&reading.Altitude,
+			&reading.Latitude,
+			&reading.Longitude,
+			&reading.Fuel,
+			&reading.Heading,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry: %w", err)
+		}
+
+		readings = append(readings, &reading)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry: %w", err)
+	}
+
+	return readings, nil
+}
+
+func (r *TelemetryRepository) DeleteChannel(ctx context.Context, channel string) error {
+	query := `DELETE FROM telemetry WHERE tenant_id = ? AND channel = ?`
+
+	_, err := executorFor(ctx, r.db).ExecContext(ctx, query, domain.TenantFromContext(ctx), channel)
+	if err != nil {
+		return fmt.Errorf("failed to delete telemetry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelemetryRepository_Save(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTelemetryRepository(db)
+
+	// Readings are stored in UTC whatever their zone
+	readingTime := time.Date(2024, 1, 1, 2, 0, 0, 0, time.FixedZone("CET", 3600))
+	reading := &domain.Telemetry{Time: readingTime, Altitude: 1500, Latitude: 28.5, Longitude: -80.6, Fuel: 80, Heading: 90}
+
+	testCases := []struct {
+		name          string
+		dbError       error
+		expectedError string
+	}{
+		{
+			name:          "successful_save",
+			dbError:       nil,
+			expectedError: "",
+		},
+		{
+			name:          "database_error",
+			dbError:       sql.ErrConnDone,
+			expectedError: "failed to save telemetry: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			// Set up expectations
+			expectation := mock.ExpectExec("INSERT INTO telemetry").
+				WithArgs(domain.DefaultTenantID, "channel-1", int64(4), readingTime.UTC(), 1500.0, 28.5, -80.6, 80.0, 90.0)
+			if tc.dbError == nil {
+				expectation.WillReturnResult(sqlmock.NewResult(1, 1))
+			} else {
+				expectation.WillReturnError(tc.dbError)
+			}
+
+			// Execute test
+			err := repo.Save(context.Background(), "channel-1", 4, reading)
+
+			// Check results
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTelemetryRepository_List(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTelemetryRepository(db)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	columns := []string{"reading_time", "altitude", "latitude", "longitude", "fuel", "heading"}
+
+	testCases := []struct {
+		name             string
+		from             time.Time
+		to               time.Time
+		setupMock        func()
+		expectedReadings []*domain.Telemetry
+		expectedError    string
+	}{
+		{
+			name: "time_range",
+			from: from,
+			to:   to,
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM telemetry WHERE tenant_id = \\? AND channel = \\? AND reading_time >= \\? AND reading_time <= \\? ORDER BY reading_time ASC, message_number ASC").
+					WithArgs(domain.DefaultTenantID, "channel-1", from, to).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(from, 100.0, 28.5, -80.6, 99.0, 90.0).
+						AddRow(to, 5000.0, 28.6, -80.5, 90.0, 95.0))
+			},
+			expectedReadings: []*domain.Telemetry{
+				{Time: from, Altitude: 100, Latitude: 28.5, Longitude: -80.6, Fuel: 99, Heading: 90},
+				{Time: to, Altitude: 5000, Latitude: 28.6, Longitude: -80.5, Fuel: 90, Heading: 95},
+			},
+		},
+		{
+			name: "open_range",
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM telemetry WHERE tenant_id = \\? AND channel = \\? ORDER BY").
+					WithArgs(domain.DefaultTenantID, "channel-1").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedReadings: nil,
+		},
+		{
+			name: "database_error",
+			from: from,
+			setupMock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM telemetry WHERE tenant_id = \\? AND channel = \\? AND reading_time >= \\? ORDER BY").
+					WithArgs(domain.DefaultTenantID, "channel-1", from).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to list telemetry: sql: connection is already closed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			readings, err := repo.List(context.Background(), "channel-1", tc.from, tc.to)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, readings)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReadings, readings)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTelemetryRepository_DeleteChannel(t *testing.T) {
+	// Create sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTelemetryRepository(db)
+
+	mock.ExpectExec("DELETE FROM telemetry WHERE tenant_id = \\? AND channel = \\?").
+		WithArgs(domain.DefaultTenantID, "channel-1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, repo.DeleteChannel(context.Background(), "channel-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
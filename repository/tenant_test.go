@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lunar-rockets/db/sqlite"
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantIsolation runs the repositories against a real database, as the
+// isolation lives in the queries and keys rather than in any single call
+func TestTenantIsolation(t *testing.T) {
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "rockets.db"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	rocketRepo := NewRocketRepository(db)
+	messageRepo := NewMessageRepository(db)
+	telemetryRepo := NewTelemetryRepository(db)
+	rejectionRepo := NewRejectionRepository(db)
+	conflictRepo := NewConflictRepository(db)
+	auditRepo := NewAuditRepository(db)
+
+	tenantA := domain.ContextWithTenant(context.Background(), "tenant-a")
+	tenantB := domain.ContextWithTenant(context.Background(), "tenant-b")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Tenant A launches a rocket on channel-1
+	rocket := &domain.Rocket{Channel: "channel-1", Type: "Falcon-9", Speed: 500, Mission: "ARTEMIS", LaunchTime: now, Status: domain.RocketStatusLaunched, LastMessage: 1}
+	require.NoError(t, rocketRepo.Save(tenantA, rocket))
+	require.NoError(t, messageRepo.MarkAsProcessed(tenantA, "channel-1", 1, "hash-a"))
+	require.NoError(t, messageRepo.SaveEvent(tenantA, &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1, MessageType: domain.TypeRocketLaunched, MessageTime: now},
+		Message:  json.RawMessage(`{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`),
+	}))
+	require.NoError(t, telemetryRepo.Save(tenantA, "channel-1", 1, &domain.Telemetry{Time: now, Altitude: 100}))
+	require.NoError(t, rejectionRepo.Save(tenantA, &domain.Rejection{Channel: "channel-1", MessageNumber: 2, MessageType: domain.TypeRocketLaunched, MessageTime: now, Status: domain.RocketStatusLaunched, Reason: "rocket already launched", RejectedAt: now}))
+	require.NoError(t, conflictRepo.Save(tenantA, &domain.MessageConflict{Channel: "channel-1", MessageNumber: 1, MessageType: domain.TypeRocketLaunched, MessageTime: now, ProcessedHash: "hash-a", ReceivedHash: "hash-x", Payload: json.RawMessage(`{}`), DetectedAt: now}))
+	require.NoError(t, auditRepo.Save(tenantA, &domain.AuditEntry{Action: domain.AuditActionDiscardBuffer, Channel: "channel-1", Details: "discarded buffered messages [3]", CreatedAt: now}))
+
+	t.Run("cannot_read", func(t *testing.T) {
+		got, err := rocketRepo.GetByChannel(tenantB, "channel-1")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		rockets, err := rocketRepo.GetAll(tenantB, "channel", "ASC")
+		require.NoError(t, err)
+		assert.Empty(t, rockets)
+
+		rockets, total, err := rocketRepo.Search(tenantB, domain.RocketQuery{Status: domain.RocketStatusLaunched})
+		require.NoError(t, err)
+		assert.Empty(t, rockets)
+		assert.Zero(t, total)
+
+		stats, err := rocketRepo.Stats(tenantB)
+		require.NoError(t, err)
+		assert.Zero(t, stats.Total)
+		assert.Empty(t, stats.ByStatus)
+
+		last, err := messageRepo.FindLastMessageNumber(tenantB, "channel-1")
+		require.NoError(t, err)
+		assert.Zero(t, last)
+
+		hash, err := messageRepo.FindPayloadHash(tenantB, "channel-1", 1)
+		require.NoError(t, err)
+		assert.Empty(t, hash)
+
+		events, err := messageRepo.ListEvents(tenantB, "channel-1", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		channels, err := messageRepo.ListChannelProgress(tenantB)
+		require.NoError(t, err)
+		assert.Empty(t, channels)
+
+		progress, err := messageRepo.FindChannelProgress(tenantB, "channel-1")
+		require.NoError(t, err)
+		assert.Nil(t, progress)
+
+		readings, err := telemetryRepo.List(tenantB, "channel-1", time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, readings)
+
+		rejections, err := rejectionRepo.List(tenantB, domain.RejectionQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, rejections)
+
+		conflicts, err := conflictRepo.List(tenantB, domain.ConflictQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+
+		entries, err := auditRepo.List(tenantB, domain.AuditQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("cannot_affect", func(t *testing.T) {
+		require.NoError(t, rocketRepo.Update(tenantB, &domain.Rocket{Channel: "channel-1", Type: "Hijacked", Speed: 1, Mission: "NONE", Status: domain.RocketStatusExploded, LastMessage: 9}))
+		require.NoError(t, rocketRepo.Delete(tenantB, "channel-1"))
+		require.NoError(t, telemetryRepo.DeleteChannel(tenantB, "channel-1"))
+		deleted, err := messageRepo.DeleteChannel(tenantB, "channel-1")
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+
+		last, err := messageRepo.FindLastMessageNumber(tenantA, "channel-1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), last)
+
+		events, err := messageRepo.ListEvents(tenantA, "channel-1", 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		got, err := rocketRepo.GetByChannel(tenantA, "channel-1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "Falcon-9", got.Type)
+		assert.Equal(t, 500, got.Speed)
+		assert.Equal(t, domain.RocketStatusLaunched, got.Status)
+	})
+
+	t.Run("same_channel_and_number", func(t *testing.T) {
+		// The tenant is part of every key, so tenant B's channel-1 does not
+		// collide with tenant A's
+		require.NoError(t, rocketRepo.Save(tenantB, &domain.Rocket{Channel: "channel-1", Type: "Starship", Speed: 900, Mission: "MARS", LaunchTime: now, Status: domain.RocketStatusLaunched, LastMessage: 1}))
+		require.NoError(t, messageRepo.MarkAsProcessed(tenantB, "channel-1", 1, "hash-b"))
+		require.NoError(t, telemetryRepo.Save(tenantB, "channel-1", 1, &domain.Telemetry{Time: now, Altitude: 200}))
+
+		got, err := rocketRepo.GetByChannel(tenantB, "channel-1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "Starship", got.Type)
+
+		got, err = rocketRepo.GetByChannel(tenantA, "channel-1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "Falcon-9", got.Type)
+
+		readings, err := telemetryRepo.List(tenantA, "channel-1", time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, readings, 1)
+		assert.Equal(t, 100.0, readings[0].Altitude)
+
+		hash, err := messageRepo.FindPayloadHash(tenantA, "channel-1", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "hash-a", hash)
+
+		require.NoError(t, messageRepo.MarkAsProcessed(tenantB, "channel-1", 2, "hash-b"))
+
+		channels, err := messageRepo.ListChannelProgress(tenantA)
+		require.NoError(t, err)
+		require.Len(t, channels, 1)
+		assert.Equal(t, "channel-1", channels[0].Channel)
+		assert.Equal(t, int64(1), channels[0].LastProcessed)
+		assert.WithinDuration(t, time.Now(), channels[0].LastProcessedAt, time.Minute)
+
+		progress, err := messageRepo.FindChannelProgress(tenantB, "channel-1")
+		require.NoError(t, err)
+		require.NotNil(t, progress)
+		assert.Equal(t, int64(2), progress.LastProcessed)
+		assert.WithinDuration(t, time.Now(), progress.LastProcessedAt, time.Minute)
+	})
+}
@@ -0,0 +1,103 @@
+// Package retry runs operations again when they fail with transient errors,
+// waiting a bounded exponential backoff with jitter between attempts
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrExhausted is wrapped by the error of an operation that still failed with
+// a retryable error on its last attempt
+var ErrExhausted = errors.New("retries exhausted")
+
+// Policy decides which errors are retried, how many times and how long to
+// wait in between. Its hooks, when set, are called from the goroutine running
+// the operation.
+type Policy struct {
+	MaxAttempts int           // Attempts in all, the first one included
+	BaseDelay   time.Duration // Backoff after the first attempt, doubled after each other
+	MaxDelay    time.Duration // Bound of the backoff
+	Retryable   func(err error) bool
+
+	// OnRetry is called before an operation is attempted again
+	OnRetry func(operation string, attempt int, err error)
+	// OnExhausted is called when an operation fails its last attempt with a
+	// retryable error
+	OnExhausted func(operation string, err error)
+
+	jitter func() float64
+}
+
+// NewPolicy creates a policy retrying the errors for which retryable returns
+// true
+func NewPolicy(maxAttempts int, baseDelay time.Duration, maxDelay time.Duration, retryable func(err error) bool) (*Policy, error) {
+	if maxAttempts < 1 {
+		return nil, errors.New("at least one attempt is required")
+	}
+	if baseDelay < 0 || maxDelay < baseDelay {
+		return nil, fmt.Errorf("invalid backoff from %s to %s", baseDelay, maxDelay)
+	}
+
+	return &Policy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   baseDelay,
+		MaxDelay:    maxDelay,
+		Retryable:   retryable,
+		jitter:      rand.Float64,
+	}, nil
+}
+
+// Do runs op until it succeeds, fails with an error that is not retryable or
+// was attempted MaxAttempts times, and returns its last error. The backoff is
+// cut short when ctx is done. A nil policy runs op once.
+func (p *Policy) Do(ctx context.Context, operation string, op func() error) error {
+	if p == nil {
+		return op()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !p.Retryable(err) {
+			return err
+		}
+
+		if attempt >= p.MaxAttempts {
+			if p.OnExhausted != nil {
+				p.OnExhausted(operation, err)
+			}
+			return fmt.Errorf("%w after %d attempts: %w", ErrExhausted, attempt, err)
+		}
+
+		if p.OnRetry != nil {
+			p.OnRetry(operation, attempt, err)
+		}
+
+		timer := time.NewTimer(p.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the wait after a failed attempt: the exponential delay,
+// bounded by MaxDelay, of which the second half is random so that competing
+// operations do not retry in step
+func (p *Policy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(p.jitter()*float64(delay-half))
+}
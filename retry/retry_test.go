@@ -0,0 +1,149 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBusy = errors.New("database is locked")
+
+func isBusy(err error) bool {
+	return errors.Is(err, errBusy)
+}
+
+func TestPolicy_Do(t *testing.T) {
+	errOther := errors.New("constraint failed")
+
+	testCases := []struct {
+		name              string
+		errors            []error // Error of each attempt, nil once it succeeds
+		expectedAttempts  int
+		expectedRetries   []int
+		expectedError     error
+		expectedExhausted bool
+	}{
+		{
+			name:             "succeeds_at_once",
+			errors:           []error{nil},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "succeeds_after_retries",
+			errors:           []error{errBusy, errBusy, nil},
+			expectedAttempts: 3,
+			expectedRetries:  []int{1, 2},
+		},
+		{
+			name:             "other_errors_are_not_retried",
+			errors:           []error{errBusy, errOther},
+			expectedAttempts: 2,
+			expectedRetries:  []int{1},
+			expectedError:    errOther,
+		},
+		{
+			name:              "attempts_run_out",
+			errors:            []error{errBusy, errBusy, errBusy, errBusy},
+			expectedAttempts:  3,
+			expectedRetries:   []int{1, 2},
+			expectedError:     errBusy,
+			expectedExhausted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewPolicy(3, time.Millisecond, 4*time.Millisecond, isBusy)
+			require.NoError(t, err)
+
+			var retries []int
+			var exhausted bool
+			policy.OnRetry = func(operation string, attempt int, err error) {
+				assert.Equal(t, "apply", operation)
+				retries = append(retries, attempt)
+			}
+			policy.OnExhausted = func(operation string, err error) {
+				exhausted = true
+			}
+
+			attempts := 0
+			err = policy.Do(context.Background(), "apply", func() error {
+				attempts++
+				return tc.errors[attempts-1]
+			})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedExhausted, errors.Is(err, ErrExhausted))
+			assert.Equal(t, tc.expectedExhausted, exhausted)
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.Equal(t, tc.expectedRetries, retries)
+		})
+	}
+}
+
+func TestPolicy_DoStopsWithContext(t *testing.T) {
+	policy, err := NewPolicy(5, time.Hour, time.Hour, isBusy)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err = policy.Do(ctx, "apply", func() error {
+		attempts++
+		return errBusy
+	})
+
+	assert.ErrorIs(t, err, errBusy)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPolicy_DoWithoutPolicy(t *testing.T) {
+	var policy *Policy
+
+	attempts := 0
+	err := policy.Do(context.Background(), "apply", func() error {
+		attempts++
+		return errBusy
+	})
+
+	assert.Equal(t, errBusy, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	policy, err := NewPolicy(10, 10*time.Millisecond, 100*time.Millisecond, isBusy)
+	require.NoError(t, err)
+
+	// Without jitter the backoff is half the delay, and with the most jitter
+	// the whole delay
+	policy.jitter = func() float64 { return 0 }
+	assert.Equal(t, 5*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 10*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 40*time.Millisecond, policy.Backoff(4))
+	assert.Equal(t, 50*time.Millisecond, policy.Backoff(5))
+	assert.Equal(t, 50*time.Millisecond, policy.Backoff(100))
+
+	policy.jitter = func() float64 { return 1 }
+	assert.Equal(t, 10*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(100))
+}
+
+func TestNewPolicy_Invalid(t *testing.T) {
+	_, err := NewPolicy(0, time.Millisecond, time.Second, isBusy)
+	assert.EqualError(t, err, "at least one attempt is required")
+
+	_, err = NewPolicy(3, time.Second, time.Millisecond, isBusy)
+	assert.EqualError(t, err, "invalid backoff from 1s to 1ms")
+}
@@ -0,0 +1,199 @@
+package simulator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/usecase"
+)
+
+// Expected is the state the service should reach once every delivered
+// message has been processed
+type Expected struct {
+	// Channels are all the simulated channels
+	Channels []string `json:"channels"`
+	// Rockets are the launched rockets, sorted by channel
+	Rockets []*domain.Rocket `json:"rockets"`
+	// Gaps are the channels left waiting for a dropped message, sorted by channel
+	Gaps []*domain.ChannelStatus `json:"gaps"`
+}
+
+// ExpectedState computes the final state of each channel. Messages are
+// applied in order up to the first dropped one; the delivered messages after
+// it stay buffered in the service.
+func ExpectedState(streams [][]*domain.RocketMessage, delivery *Delivery) *Expected {
+	firstDropped := make(map[string]int64)
+	for _, message := range delivery.Dropped {
+		channel := message.Metadata.Channel
+		if first, ok := firstDropped[channel]; !ok || message.Metadata.MessageNumber < first {
+			firstDropped[channel] = message.Metadata.MessageNumber
+		}
+	}
+
+	buffered := make(map[string]map[int64]bool)
+	for _, message := range delivery.Messages {
+		channel := message.Metadata.Channel
+		if first, ok := firstDropped[channel]; ok && message.Metadata.MessageNumber > first {
+			if buffered[channel] == nil {
+				buffered[channel] = make(map[int64]bool)
+			}
+			buffered[channel][message.Metadata.MessageNumber] = true
+		}
+	}
+
+	handlers := make(map[string]domain.MessageHandler)
+	for _, handler := range usecase.MessageHandlers() {
+		handlers[handler.MessageType()] = handler
+	}
+	stateMachine := domain.NewStateMachine(domain.RocketTransitions...)
+
+	expected := &Expected{
+		Channels: []string{},
+		Rockets:  []*domain.Rocket{},
+		Gaps:     []*domain.ChannelStatus{},
+	}
+
+	for _, stream := range streams {
+		if len(stream) == 0 {
+			continue
+		}
+		channel := stream[0].Metadata.Channel
+		expected.Channels = append(expected.Channels, channel)
+
+		var rocket *domain.Rocket
+		for _, message := range stream {
+			if first, ok := firstDropped[channel]; ok && message.Metadata.MessageNumber >= first {
+				break
+			}
+			rocket = apply(handlers, stateMachine, rocket, message)
+		}
+		if rocket != nil {
+			expected.Rockets = append(expected.Rockets, rocket)
+		}
+
+		if len(buffered[channel]) > 0 {
+			numbers := make([]int64, 0, len(buffered[channel]))
+			for number := range buffered[channel] {
+				numbers = append(numbers, number)
+			}
+			sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+			lastProcessed := firstDropped[channel] - 1
+			expected.Gaps = append(expected.Gaps, &domain.ChannelStatus{
+				Channel:       channel,
+				LastProcessed: lastProcessed,
+				Buffered:      numbers,
+				Missing:       domain.MissingRanges(lastProcessed, numbers),
+			})
+		}
+	}
+
+	sort.Strings(expected.Channels)
+	sort.Slice(expected.Rockets, func(i, j int) bool { return expected.Rockets[i].Channel < expected.Rockets[j].Channel })
+	sort.Slice(expected.Gaps, func(i, j int) bool { return expected.Gaps[i].Channel < expected.Gaps[j].Channel })
+
+	return expected
+}
+
+// apply returns the state of a rocket after a message, using the state machine
+// and the transitions of the service's message handlers
+func apply(handlers map[string]domain.MessageHandler, stateMachine *domain.StateMachine, rocket *domain.Rocket, message *domain.RocketMessage) *domain.Rocket {
+	handler, ok := handlers[message.Metadata.MessageType]
+	if !ok {
+		return rocket
+	}
+
+	status, err := stateMachine.Next(rocket, message.Metadata.MessageType)
+	if err != nil {
+		return rocket
+	}
+
+	changed, err := handler.Apply(rocket, message)
+	if err != nil || changed == nil {
+		return rocket
+	}
+	changed.Status = status
+	changed.LastMessage = message.Metadata.MessageNumber
+	return changed
+}
+
+// Verify compares the expected state with the rockets and channel gaps
+// reported by the service, and describes every difference. Rockets and gaps
+// of channels that were not simulated are ignored.
+func Verify(expected *Expected, rockets []*domain.Rocket, gaps []*domain.ChannelStatus) []string {
+	actualRockets := make(map[string]*domain.Rocket, len(rockets))
+	for _, rocket := range rockets {
+		actualRockets[rocket.Channel] = rocket
+	}
+
+	actualGaps := make(map[string]*domain.ChannelStatus, len(gaps))
+	for _, gap := range gaps {
+		actualGaps[gap.Channel] = gap
+	}
+
+	expectedRockets := make(map[string]*domain.Rocket, len(expected.Rockets))
+	for _, rocket := range expected.Rockets {
+		expectedRockets[rocket.Channel] = rocket
+	}
+
+	expectedGaps := make(map[string]*domain.ChannelStatus, len(expected.Gaps))
+	for _, gap := range expected.Gaps {
+		expectedGaps[gap.Channel] = gap
+	}
+
+	var diffs []string
+	for _, channel := range expected.Channels {
+		diffs = append(diffs, compareRocket(channel, expectedRockets[channel], actualRockets[channel])...)
+		diffs = append(diffs, compareGap(channel, expectedGaps[channel], actualGaps[channel])...)
+	}
+
+	return diffs
+}
+
+func compareRocket(channel string, expected *domain.Rocket, actual *domain.Rocket) []string {
+	switch {
+	case expected == nil && actual == nil:
+		return nil
+	case expected == nil:
+		return []string{fmt.Sprintf("rocket %s: expected no rocket, got one", channel)}
+	case actual == nil:
+		return []string{fmt.Sprintf("rocket %s: not found", channel)}
+	}
+
+	var diffs []string
+	check := func(field string, want interface{}, got interface{}) {
+		if want != got {
+			diffs = append(diffs, fmt.Sprintf("rocket %s: %s is %v, expected %v", channel, field, got, want))
+		}
+	}
+
+	check("type", expected.Type, actual.Type)
+	check("speed", expected.Speed, actual.Speed)
+	check("mission", expected.Mission, actual.Mission)
+	check("status", expected.Status, actual.Status)
+	check("reason", expected.Reason, actual.Reason)
+
+	return diffs
+}
+
+func compareGap(channel string, expected *domain.ChannelStatus, actual *domain.ChannelStatus) []string {
+	switch {
+	case expected == nil && actual == nil:
+		return nil
+	case expected == nil:
+		return []string{fmt.Sprintf("channel %s: expected no buffered messages, got %v", channel, actual.Buffered)}
+	case actual == nil:
+		return []string{fmt.Sprintf("channel %s: expected buffered messages %v, got none", channel, expected.Buffered)}
+	}
+
+	if expected.LastProcessed != actual.LastProcessed ||
+		!reflect.DeepEqual(expected.Buffered, actual.Buffered) ||
+		!reflect.DeepEqual(expected.Missing, actual.Missing) {
+		return []string{fmt.Sprintf("channel %s: last processed %d with buffered %v, expected %d with buffered %v",
+			channel, actual.LastProcessed, actual.Buffered, expected.LastProcessed, expected.Buffered)}
+	}
+
+	return nil
+}
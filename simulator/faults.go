@@ -0,0 +1,108 @@
+package simulator
+
+import (
+	"math/rand"
+	"sort"
+
+	"lunar-rockets/domain"
+)
+
+// Faults are the delivery problems to simulate. Rates are probabilities
+// between 0 and 1, applied to each message independently.
+type Faults struct {
+	// DuplicateRate is the probability that a message is delivered twice
+	DuplicateRate float64
+	// OutOfOrderRate is the probability that a message is delivered after
+	// some of the messages that follow it
+	OutOfOrderRate float64
+	// DropRate is the probability that a message is never delivered
+	DropRate float64
+	// ReorderWindow is how many deliveries a late message or a duplicate can
+	// be pushed back by
+	ReorderWindow int
+}
+
+// Delivery is the order in which messages are sent, along with what was
+// done to them
+type Delivery struct {
+	Messages   []*domain.RocketMessage
+	Dropped    []*domain.RocketMessage
+	Duplicates int
+	Reordered  int
+}
+
+// scheduled is a message with its position in the delivery order
+type scheduled struct {
+	message  *domain.RocketMessage
+	position float64
+}
+
+// Schedule interleaves the channel streams at random, keeping each stream in
+// order, then applies the faults
+func Schedule(streams [][]*domain.RocketMessage, faults Faults, rng *rand.Rand) *Delivery {
+	window := faults.ReorderWindow
+	if window <= 0 {
+		window = 1
+	}
+
+	delivery := &Delivery{}
+	var queue []scheduled
+	for i, message := range interleave(streams, rng) {
+		if rng.Float64() < faults.DropRate {
+			delivery.Dropped = append(delivery.Dropped, message)
+			continue
+		}
+
+		// Fractions keep a moved message after the one at its new position
+		position := float64(i)
+		if rng.Float64() < faults.OutOfOrderRate {
+			position += float64(1+rng.Intn(window)) + 0.5
+			delivery.Reordered++
+		}
+		queue = append(queue, scheduled{message: message, position: position})
+
+		if rng.Float64() < faults.DuplicateRate {
+			queue = append(queue, scheduled{message: message, position: position + float64(rng.Intn(window+1)) + 0.25})
+			delivery.Duplicates++
+		}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].position < queue[j].position
+	})
+
+	delivery.Messages = make([]*domain.RocketMessage, 0, len(queue))
+	for _, s := range queue {
+		delivery.Messages = append(delivery.Messages, s.message)
+	}
+
+	return delivery
+}
+
+// interleave merges the streams by repeatedly taking the next message of a
+// random channel
+func interleave(streams [][]*domain.RocketMessage, rng *rand.Rand) []*domain.RocketMessage {
+	var merged []*domain.RocketMessage
+	next := make([]int, len(streams))
+
+	var pending []int
+	for i, stream := range streams {
+		if len(stream) > 0 {
+			pending = append(pending, i)
+		}
+	}
+
+	for len(pending) > 0 {
+		j := rng.Intn(len(pending))
+		stream := pending[j]
+
+		merged = append(merged, streams[stream][next[stream]])
+		next[stream]++
+
+		if next[stream] == len(streams[stream]) {
+			pending = append(pending[:j], pending[j+1:]...)
+		}
+	}
+
+	return merged
+}
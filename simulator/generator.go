@@ -0,0 +1,129 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// maxMessageDelay is the longest time between two messages of a channel
+const maxMessageDelay = 3 * time.Second
+
+var (
+	rocketTypes    = []string{"Falcon-9", "Falcon-Heavy", "Starship", "Atlas-V", "Delta-IV", "Ariane-5", "Soyuz"}
+	missions       = []string{"ARTEMIS", "APOLLO", "GEMINI", "MERCURY", "DRAGON", "SHUTTLE_MIR", "LUNA"}
+	explodeReasons = []string{"PRESSURE_VESSEL_FAILURE", "ENGINE_FAILURE", "GUIDANCE_FAILURE", "STAGE_SEPARATION_FAILURE"}
+)
+
+// Config describes the traffic to generate
+type Config struct {
+	// Channels is the number of rockets
+	Channels int
+	// MessagesPerChannel is the number of messages of each rocket, launch included
+	MessagesPerChannel int
+	// ExplodeRate is the probability that a rocket ends with an explosion
+	ExplodeRate float64
+	// StartTime is the message time of the first launch
+	StartTime time.Time
+}
+
+// DefaultConfig returns the configuration used when none is provided
+func DefaultConfig() Config {
+	return Config{
+		Channels:           10,
+		MessagesPerChannel: 20,
+		ExplodeRate:        0.2,
+		StartTime:          time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+// Generate creates the messages of every channel, each stream in message
+// number order. A stream starts with RocketLaunched, continues with speed and
+// mission changes, and may end with RocketExploded.
+func Generate(cfg Config, rng *rand.Rand) [][]*domain.RocketMessage {
+	streams := make([][]*domain.RocketMessage, 0, cfg.Channels)
+	for i := 0; i < cfg.Channels; i++ {
+		streams = append(streams, generateStream(cfg, rng, newChannelID(rng)))
+	}
+	return streams
+}
+
+func generateStream(cfg Config, rng *rand.Rand, channel string) []*domain.RocketMessage {
+	if cfg.MessagesPerChannel <= 0 {
+		return nil
+	}
+
+	messageTime := cfg.StartTime.Add(randomDelay(rng))
+	messages := make([]*domain.RocketMessage, 0, cfg.MessagesPerChannel)
+	add := func(messageType string, payload interface{}) {
+		// The payloads are plain structs, which always encode
+		data, _ := json.Marshal(payload)
+		messages = append(messages, &domain.RocketMessage{
+			Metadata: domain.MessageMetadata{
+				Channel:       channel,
+				MessageNumber: int64(len(messages) + 1),
+				MessageTime:   messageTime,
+				MessageType:   messageType,
+			},
+			Message: data,
+			Payload: payload,
+		})
+		messageTime = messageTime.Add(randomDelay(rng))
+	}
+
+	speed := 500 + rng.Intn(46)*100
+	add(domain.TypeRocketLaunched, &domain.RocketLaunchedMessage{
+		Type:        pick(rng, rocketTypes),
+		LaunchSpeed: speed,
+		Mission:     pick(rng, missions),
+	})
+
+	explodes := cfg.MessagesPerChannel > 1 && rng.Float64() < cfg.ExplodeRate
+	changes := cfg.MessagesPerChannel - 1
+	if explodes {
+		changes--
+	}
+
+	for i := 0; i < changes; i++ {
+		switch roll := rng.Float64(); {
+		case roll < 0.4 || speed == 0:
+			by := 100 + rng.Intn(30)*100
+			speed += by
+			add(domain.TypeRocketSpeedIncreased, &domain.RocketSpeedIncreasedMessage{By: by})
+		case roll < 0.75:
+			// Mostly slow down without stopping, but sometimes ask for more
+			// than the current speed, which the service clamps to zero
+			by := 100 + rng.Intn(speed/100+2)*100
+			speed = max(speed-by, 0)
+			add(domain.TypeRocketSpeedDecreased, &domain.RocketSpeedDecreasedMessage{By: by})
+		default:
+			add(domain.TypeRocketMissionChanged, &domain.RocketMissionChangedMessage{NewMission: pick(rng, missions)})
+		}
+	}
+
+	if explodes {
+		add(domain.TypeRocketExploded, &domain.RocketExplodedMessage{Reason: pick(rng, explodeReasons)})
+	}
+
+	return messages
+}
+
+// newChannelID returns a random UUID, like the channels of real rockets
+func newChannelID(rng *rand.Rand) string {
+	var b [16]byte
+	rng.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func randomDelay(rng *rand.Rand) time.Duration {
+	return time.Duration(rng.Int63n(int64(maxMessageDelay/time.Millisecond))+1) * time.Millisecond
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,340 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var startTime = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testConfig() Config {
+	return Config{Channels: 20, MessagesPerChannel: 30, ExplodeRate: 0.5, StartTime: startTime}
+}
+
+func TestGenerate(t *testing.T) {
+	streams := Generate(testConfig(), rand.New(rand.NewSource(1)))
+	require.Len(t, streams, 20)
+
+	types := make(map[string]int)
+	for _, stream := range streams {
+		require.Len(t, stream, 30)
+		assert.Equal(t, domain.TypeRocketLaunched, stream[0].Metadata.MessageType)
+
+		for i, message := range stream {
+			assert.Equal(t, stream[0].Metadata.Channel, message.Metadata.Channel)
+			assert.Equal(t, int64(i+1), message.Metadata.MessageNumber)
+			if i > 0 {
+				assert.True(t, message.Metadata.MessageTime.After(stream[i-1].Metadata.MessageTime))
+				assert.NotEqual(t, domain.TypeRocketLaunched, message.Metadata.MessageType)
+			}
+			if message.Metadata.MessageType == domain.TypeRocketExploded {
+				assert.Equal(t, len(stream)-1, i, "explosion must be the last message")
+			}
+			types[message.Metadata.MessageType]++
+		}
+	}
+
+	for _, messageType := range []string{
+		domain.TypeRocketLaunched,
+		domain.TypeRocketSpeedIncreased,
+		domain.TypeRocketSpeedDecreased,
+		domain.TypeRocketExploded,
+		domain.TypeRocketMissionChanged,
+	} {
+		assert.Positive(t, types[messageType], messageType)
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	first := Generate(testConfig(), rand.New(rand.NewSource(42)))
+	second := Generate(testConfig(), rand.New(rand.NewSource(42)))
+	assert.Equal(t, first, second)
+
+	other := Generate(testConfig(), rand.New(rand.NewSource(43)))
+	assert.NotEqual(t, first, other)
+}
+
+func TestSchedule(t *testing.T) {
+	streams := Generate(testConfig(), rand.New(rand.NewSource(1)))
+
+	tests := []struct {
+		name           string
+		faults         Faults
+		wantInOrder    bool
+		wantDuplicates bool
+		wantDropped    bool
+	}{
+		{
+			name:        "no faults",
+			faults:      Faults{},
+			wantInOrder: true,
+		},
+		{
+			name:           "duplicates",
+			faults:         Faults{DuplicateRate: 0.3, ReorderWindow: 3},
+			wantDuplicates: true,
+		},
+		{
+			name:   "out of order",
+			faults: Faults{OutOfOrderRate: 0.3, ReorderWindow: 3},
+		},
+		{
+			name:        "drops",
+			faults:      Faults{DropRate: 0.1},
+			wantInOrder: true,
+			wantDropped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := Schedule(streams, tt.faults, rand.New(rand.NewSource(7)))
+
+			total := 0
+			for _, stream := range streams {
+				total += len(stream)
+			}
+			assert.Equal(t, total+delivery.Duplicates, len(delivery.Messages)+len(delivery.Dropped))
+			assert.Equal(t, tt.wantDuplicates, delivery.Duplicates > 0)
+			assert.Equal(t, tt.wantDropped, len(delivery.Dropped) > 0)
+
+			last := make(map[string]int64)
+			inOrder := true
+			for _, message := range delivery.Messages {
+				if message.Metadata.MessageNumber < last[message.Metadata.Channel] {
+					inOrder = false
+				}
+				last[message.Metadata.Channel] = message.Metadata.MessageNumber
+			}
+			assert.Equal(t, tt.wantInOrder, inOrder)
+		})
+	}
+}
+
+func TestExpectedState(t *testing.T) {
+	launch := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{Channel: "c1", MessageNumber: 1, MessageTime: startTime, MessageType: domain.TypeRocketLaunched},
+		Payload:  &domain.RocketLaunchedMessage{Type: "Falcon-9", LaunchSpeed: 500, Mission: "ARTEMIS"},
+	}
+	decrease := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{Channel: "c1", MessageNumber: 2, MessageTime: startTime.Add(time.Second), MessageType: domain.TypeRocketSpeedDecreased},
+		Payload:  &domain.RocketSpeedDecreasedMessage{By: 800},
+	}
+	mission := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{Channel: "c1", MessageNumber: 3, MessageTime: startTime.Add(2 * time.Second), MessageType: domain.TypeRocketMissionChanged},
+		Payload:  &domain.RocketMissionChangedMessage{NewMission: "APOLLO"},
+	}
+	explode := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{Channel: "c1", MessageNumber: 4, MessageTime: startTime.Add(3 * time.Second), MessageType: domain.TypeRocketExploded},
+		Payload:  &domain.RocketExplodedMessage{Reason: "ENGINE_FAILURE"},
+	}
+	increase := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{Channel: "c1", MessageNumber: 5, MessageTime: startTime.Add(4 * time.Second), MessageType: domain.TypeRocketSpeedIncreased},
+		Payload:  &domain.RocketSpeedIncreasedMessage{By: 300},
+	}
+	streams := [][]*domain.RocketMessage{{launch, decrease, mission, explode, increase}}
+
+	tests := []struct {
+		name        string
+		delivery    *Delivery
+		wantRockets []*domain.Rocket
+		wantGaps    []*domain.ChannelStatus
+	}{
+		{
+			name:     "all delivered",
+			delivery: &Delivery{Messages: []*domain.RocketMessage{launch, mission, decrease, decrease, explode, increase}},
+			wantRockets: []*domain.Rocket{{
+				Channel:     "c1",
+				Type:        "Falcon-9",
+				Speed:       0,
+				Mission:     "APOLLO",
+				LaunchTime:  startTime,
+				Status:      domain.RocketStatusExploded,
+				Reason:      "ENGINE_FAILURE",
+				ExplodedAt:  timePtr(startTime.Add(3 * time.Second)),
+				LastMessage: 4,
+			}},
+			wantGaps: []*domain.ChannelStatus{},
+		},
+		{
+			name: "dropped message leaves the rest buffered",
+			delivery: &Delivery{
+				Messages: []*domain.RocketMessage{launch, increase, mission, increase},
+				Dropped:  []*domain.RocketMessage{decrease, explode},
+			},
+			wantRockets: []*domain.Rocket{{
+				Channel:     "c1",
+				Type:        "Falcon-9",
+				Speed:       500,
+				Mission:     "ARTEMIS",
+				LaunchTime:  startTime,
+				Status:      domain.RocketStatusLaunched,
+				LastMessage: 1,
+			}},
+			wantGaps: []*domain.ChannelStatus{{
+				Channel:       "c1",
+				LastProcessed: 1,
+				Buffered:      []int64{3, 5},
+				Missing:       []domain.MessageRange{{From: 2, To: 2}, {From: 4, To: 4}},
+			}},
+		},
+		{
+			name: "dropped launch",
+			delivery: &Delivery{
+				Messages: []*domain.RocketMessage{decrease},
+				Dropped:  []*domain.RocketMessage{launch, mission, explode, increase},
+			},
+			wantRockets: []*domain.Rocket{},
+			wantGaps: []*domain.ChannelStatus{{
+				Channel:       "c1",
+				LastProcessed: 0,
+				Buffered:      []int64{2},
+				Missing:       []domain.MessageRange{{From: 1, To: 1}},
+			}},
+		},
+		{
+			name: "dropped last message",
+			delivery: &Delivery{
+				Messages: []*domain.RocketMessage{launch, decrease, mission, explode},
+				Dropped:  []*domain.RocketMessage{increase},
+			},
+			wantRockets: []*domain.Rocket{{
+				Channel:     "c1",
+				Type:        "Falcon-9",
+				Speed:       0,
+				Mission:     "APOLLO",
+				LaunchTime:  startTime,
+				Status:      domain.RocketStatusExploded,
+				Reason:      "ENGINE_FAILURE",
+				ExplodedAt:  timePtr(startTime.Add(3 * time.Second)),
+				LastMessage: 4,
+			}},
+			wantGaps: []*domain.ChannelStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := ExpectedState(streams, tt.delivery)
+
+			assert.Equal(t, []string{"c1"}, expected.Channels)
+			assert.Equal(t, tt.wantRockets, expected.Rockets)
+			assert.Equal(t, tt.wantGaps, expected.Gaps)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	expected := &Expected{
+		Channels: []string{"c1", "c2", "c3"},
+		Rockets: []*domain.Rocket{
+			{Channel: "c1", Type: "Falcon-9", Speed: 500, Mission: "ARTEMIS", Status: domain.RocketStatusLaunched},
+			{Channel: "c2", Type: "Soyuz", Speed: 0, Mission: "LUNA", Status: domain.RocketStatusExploded, Reason: "ENGINE_FAILURE"},
+		},
+		Gaps: []*domain.ChannelStatus{
+			{Channel: "c3", LastProcessed: 0, Buffered: []int64{2}, Missing: []domain.MessageRange{{From: 1, To: 1}}},
+		},
+	}
+
+	matching := []*domain.Rocket{
+		{Channel: "c1", Type: "Falcon-9", Speed: 500, Mission: "ARTEMIS", Status: domain.RocketStatusLaunched, LastMessage: 7},
+		{Channel: "c2", Type: "Soyuz", Speed: 0, Mission: "LUNA", Status: domain.RocketStatusExploded, Reason: "ENGINE_FAILURE"},
+		{Channel: "other", Type: "Atlas-V"},
+	}
+	matchingGaps := []*domain.ChannelStatus{
+		{Channel: "c3", LastProcessed: 0, Buffered: []int64{2}, Missing: []domain.MessageRange{{From: 1, To: 1}}},
+		{Channel: "other", LastProcessed: 4, Buffered: []int64{6}},
+	}
+
+	tests := []struct {
+		name      string
+		rockets   []*domain.Rocket
+		gaps      []*domain.ChannelStatus
+		wantDiffs []string
+	}{
+		{
+			name:    "matching state",
+			rockets: matching,
+			gaps:    matchingGaps,
+		},
+		{
+			name: "different rocket",
+			rockets: []*domain.Rocket{
+				{Channel: "c1", Type: "Falcon-9", Speed: 400, Mission: "ARTEMIS", Status: domain.RocketStatusLaunched},
+				matching[1],
+			},
+			gaps:      matchingGaps,
+			wantDiffs: []string{"rocket c1: speed is 400, expected 500"},
+		},
+		{
+			name:    "missing rocket and gap",
+			rockets: matching[:1],
+			gaps:    []*domain.ChannelStatus{},
+			wantDiffs: []string{
+				"rocket c2: not found",
+				"channel c3: expected buffered messages [2], got none",
+			},
+		},
+		{
+			name:    "unexpected rocket",
+			rockets: append([]*domain.Rocket{{Channel: "c3", Type: "Soyuz"}}, matching...),
+			gaps:    matchingGaps,
+			wantDiffs: []string{
+				"rocket c3: expected no rocket, got one",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantDiffs, Verify(expected, tt.rockets, tt.gaps))
+		})
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	streams := Generate(Config{Channels: 2, MessagesPerChannel: 3, StartTime: startTime}, rand.New(rand.NewSource(1)))
+	delivery := Schedule(streams, Faults{}, rand.New(rand.NewSource(1)))
+
+	var buf bytes.Buffer
+	sent, err := Deliver(context.Background(), NewNDJSONSink(&buf), delivery.Messages, Pacing{}, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	assert.Equal(t, 6, sent)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 6)
+	for i, line := range lines {
+		var message struct {
+			Metadata domain.MessageMetadata `json:"metadata"`
+			Message  json.RawMessage        `json:"message"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &message))
+		assert.Equal(t, delivery.Messages[i].Metadata, message.Metadata)
+	}
+}
+
+func TestDeliver_Cancelled(t *testing.T) {
+	streams := Generate(Config{Channels: 1, MessagesPerChannel: 3, StartTime: startTime}, rand.New(rand.NewSource(1)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	sent, err := Deliver(ctx, NewNDJSONSink(&buf), streams[0], Pacing{Interval: time.Hour}, rand.New(rand.NewSource(1)))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, sent)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,102 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// Sink receives the delivered messages
+type Sink interface {
+	Send(ctx context.Context, message *domain.RocketMessage) error
+}
+
+// MessageSender is the part of the API client the HTTP sink needs
+type MessageSender interface {
+	SendMessage(ctx context.Context, message *domain.RocketMessage) error
+}
+
+// HTTPSink posts messages to the service
+type HTTPSink struct {
+	sender MessageSender
+}
+
+// NewHTTPSink creates a sink that posts messages with the given client
+func NewHTTPSink(sender MessageSender) *HTTPSink {
+	return &HTTPSink{sender: sender}
+}
+
+// Send posts a message to the service
+func (s *HTTPSink) Send(ctx context.Context, message *domain.RocketMessage) error {
+	if err := s.sender.SendMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to send message %d of channel %s: %w", message.Metadata.MessageNumber, message.Metadata.Channel, err)
+	}
+	return nil
+}
+
+// NDJSONSink writes messages as newline-delimited JSON
+type NDJSONSink struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONSink creates a sink that writes one message per line
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{enc: json.NewEncoder(w)}
+}
+
+// Send writes a message as a single line
+func (s *NDJSONSink) Send(ctx context.Context, message *domain.RocketMessage) error {
+	if err := s.enc.Encode(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Pacing is how fast messages are delivered
+type Pacing struct {
+	// Interval is the wait between two messages
+	Interval time.Duration
+	// Jitter is the maximum random time added to each wait
+	Jitter time.Duration
+}
+
+// Deliver sends the messages in order, waiting between them as paced. It
+// stops at the first error.
+func Deliver(ctx context.Context, sink Sink, messages []*domain.RocketMessage, pacing Pacing, rng *rand.Rand) (int, error) {
+	for i, message := range messages {
+		if i > 0 {
+			if err := wait(ctx, pacing, rng); err != nil {
+				return i, err
+			}
+		}
+		if err := sink.Send(ctx, message); err != nil {
+			return i, err
+		}
+	}
+	return len(messages), nil
+}
+
+func wait(ctx context.Context, pacing Pacing, rng *rand.Rand) error {
+	delay := pacing.Interval
+	if pacing.Jitter > 0 {
+		delay += time.Duration(rng.Int63n(int64(pacing.Jitter) + 1))
+	}
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+// processedDirName is the sub-directory completed drop files are moved into
+const processedDirName = "processed"
+
+// DirSource watches a drop directory for NDJSON files. Files are handled in
+// name order and moved to a "processed" sub-directory once fully consumed and
+// none of their messages is buffered any more. Producers should write files
+// elsewhere and rename them into the directory so that a half-written file is
+// never picked up.
+type DirSource struct {
+	dir     string
+	offsets domain.OffsetRepository
+	opts    Options
+	// Files read to the end whose buffered messages are not settled yet
+	waiting map[string]*offsetTracker
+}
+
+// NewDirSource creates a source that watches dir for new files
+func NewDirSource(dir string, offsets domain.OffsetRepository, opts Options) *DirSource {
+	return &DirSource{
+		dir:     dir,
+		offsets: offsets,
+		opts:    opts,
+		waiting: make(map[string]*offsetTracker),
+	}
+}
+
+func (s *DirSource) Name() string {
+	return "dir:" + s.dir
+}
+
+func (s *DirSource) Start(ctx context.Context, handle Handler) error {
+	if err := os.MkdirAll(filepath.Join(s.dir, processedDirName), 0755); err != nil {
+		return fmt.Errorf("failed to create processed directory: %w", err)
+	}
+
+	for {
+		if err := s.poll(ctx, handle); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return err
+		}
+
+		if err := wait(ctx, s.opts.PollInterval); err != nil {
+			return nil
+		}
+	}
+}
+
+// poll consumes every pending file currently in the directory
+func (s *DirSource) poll(ctx context.Context, handle Handler) error {
+	files, err := s.pendingFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		if err := s.consumeFile(ctx, handle, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pendingFiles lists the NDJSON files waiting in the directory, sorted by name
+func (s *DirSource) pendingFiles() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext == ".ndjson" || ext == ".jsonl" || ext == ".json" {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// consumeFile handles a single drop file and moves it out of the way, once
+// the messages it buffered are settled
+func (s *DirSource) consumeFile(ctx context.Context, handle Handler, name string) error {
+	key := s.Name() + "/" + name
+
+	if tracker, waiting := s.waiting[name]; waiting {
+		if tracker.pending() {
+			return nil
+		}
+		delete(s.waiting, name)
+		return s.finishFile(ctx, name)
+	}
+
+	offset, err := s.offsets.GetOffset(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to open drop file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek drop file: %w", err)
+	}
+
+	tracker := newOffsetTracker(offset, func(next int64) error {
+		return s.offsets.SaveOffset(ctx, key, next)
+	})
+	if _, err := consumeLines(ctx, s.Name(), s.opts, handle, file, tracker, true); err != nil {
+		return err
+	}
+
+	if tracker.pending() {
+		s.waiting[name] = tracker
+		return nil
+	}
+	return s.finishFile(ctx, name)
+}
+
+// finishFile moves a consumed drop file to the processed directory and
+// forgets its offset
+func (s *DirSource) finishFile(ctx context.Context, name string) error {
+	if err := os.Rename(filepath.Join(s.dir, name), filepath.Join(s.dir, processedDirName, name)); err != nil {
+		return fmt.Errorf("failed to move processed drop file: %w", err)
+	}
+
+	return s.offsets.DeleteOffset(ctx, s.Name()+"/"+name)
+}
@@ -0,0 +1,90 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirSource_Start(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.ndjson"), []byte(ndjson(t, 3, 4)), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.ndjson"), []byte(ndjson(t, 1, 2)), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("not a capture"), 0644))
+
+	offsets := newOffsetStore(nil)
+	rec := &recorder{}
+	src := NewDirSource(dir, offsets, testOptions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- src.Start(ctx, rec.handle) }()
+
+	assert.Eventually(t, func() bool { return len(rec.numbers()) == 4 }, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+
+	assert.Equal(t, []int64{1, 2, 3, 4}, rec.numbers())
+	assert.FileExists(t, filepath.Join(dir, processedDirName, "a.ndjson"))
+	assert.FileExists(t, filepath.Join(dir, processedDirName, "b.ndjson"))
+	assert.FileExists(t, filepath.Join(dir, "ignored.txt"))
+
+	offset, _ := offsets.GetOffset(context.Background(), src.Name()+"/a.ndjson")
+	assert.Equal(t, int64(0), offset)
+}
+
+func TestDirSource_ResumesPartiallyConsumedFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, processedDirName), 0755))
+	first := ndjson(t, 1)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.ndjson"), []byte(first+ndjson(t, 2)), 0644))
+
+	src := NewDirSource(dir, nil, testOptions)
+	src.offsets = newOffsetStore(map[string]int64{src.Name() + "/a.ndjson": int64(len(first))})
+	rec := &recorder{}
+
+	err := src.poll(context.Background(), rec.handle)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, rec.numbers())
+}
+
+func TestDirSource_WaitsForBufferedMessages(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, processedDirName), 0755))
+	first := ndjson(t, 1)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.ndjson"), []byte(first+ndjson(t, 2)), 0644))
+
+	offsets := newOffsetStore(nil)
+	src := NewDirSource(dir, offsets, testOptions)
+	rec := &recorder{buffer: map[int64]bool{2: true}}
+	key := src.Name() + "/a.ndjson"
+
+	// The file stays until its buffered message is settled, with the offset
+	// at that message
+	assert.NoError(t, src.poll(context.Background(), rec.handle))
+	assert.NoError(t, src.poll(context.Background(), rec.handle))
+	assert.Equal(t, []int64{1, 2}, rec.numbers())
+	assert.FileExists(t, filepath.Join(dir, "a.ndjson"))
+	offset, _ := offsets.GetOffset(context.Background(), key)
+	assert.Equal(t, int64(len(first)), offset)
+
+	rec.settle(2, domain.ReceiptStatusApplied)
+	assert.NoError(t, src.poll(context.Background(), rec.handle))
+	assert.Equal(t, []int64{1, 2}, rec.numbers())
+	assert.FileExists(t, filepath.Join(dir, processedDirName, "a.ndjson"))
+	offset, _ = offsets.GetOffset(context.Background(), key)
+	assert.Equal(t, int64(0), offset)
+}
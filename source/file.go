@@ -0,0 +1,93 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"lunar-rockets/domain"
+)
+
+// FileSource tails an NDJSON file, following appends the way `tail -f` does.
+// The committed byte offset lets a restart resume after the last handled
+// record, or at the first record still buffered; if the file shrinks it is
+// assumed to be rotated and read from the beginning.
+type FileSource struct {
+	path    string
+	offsets domain.OffsetRepository
+	opts    Options
+}
+
+// NewFileSource creates a source that tails the file at path
+func NewFileSource(path string, offsets domain.OffsetRepository, opts Options) *FileSource {
+	return &FileSource{
+		path:    path,
+		offsets: offsets,
+		opts:    opts,
+	}
+}
+
+func (s *FileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *FileSource) Start(ctx context.Context, handle Handler) error {
+	offset, err := s.offsets.GetOffset(ctx, s.Name())
+	if err != nil {
+		return err
+	}
+
+	tracker := newOffsetTracker(offset, func(next int64) error {
+		return s.offsets.SaveOffset(ctx, s.Name(), next)
+	})
+	for {
+		if err := s.poll(ctx, handle, tracker); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return err
+		}
+
+		if err := wait(ctx, s.opts.PollInterval); err != nil {
+			return nil
+		}
+	}
+}
+
+// poll consumes whatever has been appended since the last handled record
+func (s *FileSource) poll(ctx context.Context, handle Handler, tracker *offsetTracker) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // Wait for the producer to create it
+		}
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	offset := tracker.position()
+	if info.Size() < offset {
+		log.Printf("Source %s was truncated, restarting from the beginning", s.Name())
+		offset = 0
+		tracker.reset(offset)
+	}
+
+	if info.Size() == offset {
+		return nil
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek source file: %w", err)
+	}
+
+	_, err = consumeLines(ctx, s.Name(), s.opts, handle, file, tracker, false)
+	return err
+}
@@ -0,0 +1,74 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSource_Start(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "capture.ndjson")
+	first := ndjson(t, 1, 2)
+	assert.NoError(t, os.WriteFile(path, []byte(first), 0644))
+
+	offsets := newOffsetStore(nil)
+	rec := &recorder{}
+	src := NewFileSource(path, offsets, testOptions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- src.Start(ctx, rec.handle) }()
+
+	assert.Eventually(t, func() bool { return len(rec.numbers()) == 2 }, time.Second, 5*time.Millisecond)
+
+	// Appended records are picked up on the next poll
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = file.WriteString(ndjson(t, 3))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	assert.Eventually(t, func() bool { return len(rec.numbers()) == 3 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, []int64{1, 2, 3}, rec.numbers())
+
+	// A restarted source resumes from the committed offset
+	rec = &recorder{}
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() { done <- src.Start(ctx, rec.handle) }()
+	time.Sleep(5 * testOptions.PollInterval)
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Empty(t, rec.numbers())
+}
+
+func TestFileSource_Truncated(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "capture.ndjson")
+	content := ndjson(t, 7)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	offsets := newOffsetStore(nil)
+	src := NewFileSource(path, offsets, testOptions)
+	rec := &recorder{}
+	tracker := newOffsetTracker(10_000, func(next int64) error {
+		return offsets.SaveOffset(context.Background(), src.Name(), next)
+	})
+
+	err := src.poll(context.Background(), rec.handle, tracker)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{7}, rec.numbers())
+	assert.Equal(t, int64(len(content)), tracker.position())
+	offset, _ := offsets.GetOffset(context.Background(), src.Name())
+	assert.Equal(t, int64(len(content)), offset)
+}
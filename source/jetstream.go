@@ -0,0 +1,204 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// JetStreamConfig describes where the JetStream source consumes from
+type JetStreamConfig struct {
+	Stream     string
+	Subject    string
+	Durable    string
+	AckWait    time.Duration
+	MaxDeliver int
+	RetryDelay time.Duration
+}
+
+// DefaultJetStreamConfig returns the configuration used when none is provided
+func DefaultJetStreamConfig() JetStreamConfig {
+	return JetStreamConfig{
+		Stream:     "ROCKETS",
+		Subject:    "rockets.messages",
+		Durable:    "lunar-rockets",
+		AckWait:    30 * time.Second,
+		MaxDeliver: 10,
+		RetryDelay: time.Second,
+	}
+}
+
+// JetStreamSource consumes rocket messages from a durable NATS JetStream
+// consumer. A message is only acked once the handler succeeds; failures are
+// nacked and left to the broker to redeliver, up to MaxDeliver times.
+// Records that cannot be decoded are terminated so they are never redelivered.
+// A buffered message is kept in progress, and acked once its channel applies
+// it.
+type JetStreamSource struct {
+	js  jetstream.JetStream
+	cfg JetStreamConfig
+}
+
+// NewJetStreamSource creates a source on top of an existing NATS connection
+func NewJetStreamSource(nc *nats.Conn, cfg JetStreamConfig) (*JetStreamSource, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	return &JetStreamSource{
+		js:  js,
+		cfg: cfg,
+	}, nil
+}
+
+func (s *JetStreamSource) Name() string {
+	return "jetstream:" + s.cfg.Subject
+}
+
+func (s *JetStreamSource) Start(ctx context.Context, handle Handler) error {
+	consumer, err := s.consumer(ctx)
+	if err != nil {
+		return err
+	}
+
+	iter, err := consumer.Messages(jetstream.PullMaxMessages(1))
+	if err != nil {
+		return fmt.Errorf("failed to start jetstream consumer: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		iter.Stop()
+	}()
+
+	for {
+		msg, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to receive jetstream message: %w", err)
+		}
+
+		s.handleMsg(ctx, msg, handle)
+	}
+}
+
+// consumer makes sure the stream and the durable consumer exist
+func (s *JetStreamSource) consumer(ctx context.Context) (jetstream.Consumer, error) {
+	stream, err := s.js.Stream(ctx, s.cfg.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		stream, err = s.js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     s.cfg.Stream,
+			Subjects: []string{s.cfg.Subject},
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jetstream stream: %w", err)
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       s.cfg.Durable,
+		FilterSubject: s.cfg.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       s.cfg.AckWait,
+		MaxDeliver:    s.cfg.MaxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream consumer: %w", err)
+	}
+
+	return consumer, nil
+}
+
+// handleMsg processes a single delivery and settles it with the broker: acked
+// once applied or dead-lettered, terminated when it failed for good, and
+// redelivered otherwise. A buffered message is settled when it leaves the
+// buffer, as it only lives in memory until then.
+func (s *JetStreamSource) handleMsg(ctx context.Context, msg jetstream.Msg, handle Handler) {
+	message, err := decodeMessage(msg.Data())
+	if err != nil {
+		log.Printf("Source %s terminating undecodable record: %v", s.Name(), err)
+		if termErr := msg.Term(); termErr != nil {
+			log.Printf("Source %s failed to terminate record: %v", s.Name(), termErr)
+		}
+		return
+	}
+
+	settled := make(chan struct{})
+	settle := func(status string, reason string) {
+		defer close(settled)
+		if status == domain.ReceiptStatusDiscarded {
+			log.Printf("Source %s terminating discarded message %d for channel %s: %s",
+				s.Name(), message.Metadata.MessageNumber, message.Metadata.Channel, reason)
+			if termErr := msg.Term(); termErr != nil {
+				log.Printf("Source %s failed to terminate message: %v", s.Name(), termErr)
+			}
+			return
+		}
+		s.ack(msg, message)
+	}
+
+	status, err := handle(domain.ContextWithSettle(ctx, settle), message)
+	switch {
+	case status == domain.ReceiptStatusBuffered:
+		go s.keepInProgress(ctx, msg, settled)
+		return
+	case errors.Is(err, domain.ErrDeadLettered):
+		// The message waits in the dead-letter store to be replayed
+		log.Printf("Source %s acking dead-lettered message %d for channel %s: %v",
+			s.Name(), message.Metadata.MessageNumber, message.Metadata.Channel, err)
+	case isPermanent(err):
+		log.Printf("Source %s terminating message %d for channel %s: %v",
+			s.Name(), message.Metadata.MessageNumber, message.Metadata.Channel, err)
+		if termErr := msg.Term(); termErr != nil {
+			log.Printf("Source %s failed to terminate message: %v", s.Name(), termErr)
+		}
+		return
+	case err != nil:
+		log.Printf("Source %s failed to process message %d for channel %s, requesting redelivery: %v",
+			s.Name(), message.Metadata.MessageNumber, message.Metadata.Channel, err)
+		if nakErr := msg.NakWithDelay(s.cfg.RetryDelay); nakErr != nil {
+			log.Printf("Source %s failed to nak message: %v", s.Name(), nakErr)
+		}
+		return
+	}
+
+	s.ack(msg, message)
+}
+
+func (s *JetStreamSource) ack(msg jetstream.Msg, message *domain.RocketMessage) {
+	if err := msg.Ack(); err != nil {
+		log.Printf("Source %s failed to ack message %d for channel %s: %v",
+			s.Name(), message.Metadata.MessageNumber, message.Metadata.Channel, err)
+	}
+}
+
+// keepInProgress keeps the broker from redelivering a buffered message until
+// it is settled. A message still buffered when the source stops is
+// redelivered once its ack wait is over.
+func (s *JetStreamSource) keepInProgress(ctx context.Context, msg jetstream.Msg, settled <-chan struct{}) {
+	ticker := time.NewTicker(s.cfg.AckWait / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-settled:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := msg.InProgress(); err != nil {
+				log.Printf("Source %s failed to extend the ack wait of a buffered message: %v", s.Name(), err)
+			}
+		}
+	}
+}
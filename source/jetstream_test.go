@@ -0,0 +1,134 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runJetStreamServer starts an embedded nats-server with JetStream enabled
+func runJetStreamServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second), "nats-server did not start")
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+func TestJetStreamSource_Start(t *testing.T) {
+	t.Parallel()
+
+	srv := runJetStreamServer(t)
+	nc, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+
+	cfg := DefaultJetStreamConfig()
+	cfg.AckWait = time.Second
+	cfg.RetryDelay = 10 * time.Millisecond
+
+	src, err := NewJetStreamSource(nc, cfg)
+	require.NoError(t, err)
+
+	// Message 2 fails on its first delivery and must be redelivered, while
+	// messages 4 and 5 fail for good and must not be. Message 6 is buffered,
+	// and must neither be acked nor redelivered until it is settled.
+	var mu sync.Mutex
+	attempts := make(map[int64]int)
+	var settle domain.SettleFunc
+	handle := func(ctx context.Context, message *domain.RocketMessage) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[message.Metadata.MessageNumber]++
+		switch message.Metadata.MessageNumber {
+		case 6:
+			settle = domain.SettleFromContext(ctx)
+			return domain.ReceiptStatusBuffered, nil
+		case 2:
+			if attempts[2] == 1 {
+				return "", assert.AnError
+			}
+		case 4:
+			return "", fmt.Errorf("message 4 of channel channel-1: %w", domain.ErrMessageConflict)
+		case 5:
+			return "", fmt.Errorf("failed to execute rocket state usecase: corrupt payload (%w)", domain.ErrDeadLettered)
+		}
+		return domain.ReceiptStatusApplied, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- src.Start(ctx, handle) }()
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	// Wait for the source to create the stream before publishing
+	require.Eventually(t, func() bool {
+		_, err := js.Stream(context.Background(), cfg.Stream)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lines := strings.Split(strings.TrimSpace(ndjson(t, 1, 2, 3, 4, 5, 6)), "\n")
+	lines = append(lines, "not json")
+	for _, line := range lines {
+		_, err := js.Publish(context.Background(), cfg.Subject, []byte(line))
+		require.NoError(t, err)
+	}
+
+	ackPending := func() int {
+		consumer, err := js.Consumer(context.Background(), cfg.Stream, cfg.Durable)
+		if err != nil {
+			return -1
+		}
+		info, err := consumer.Info(context.Background())
+		if err != nil || info.NumPending != 0 {
+			return -1
+		}
+		return info.NumAckPending
+	}
+
+	// Every record but the buffered one is settled: acked, or terminated
+	// when undecodable
+	require.Eventually(t, func() bool { return ackPending() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The buffered message outlives its ack wait without being redelivered
+	time.Sleep(cfg.AckWait + cfg.AckWait/2)
+	assert.Equal(t, 1, ackPending())
+
+	mu.Lock()
+	settleBuffered := settle
+	mu.Unlock()
+	settleBuffered(domain.ReceiptStatusApplied, "")
+	require.Eventually(t, func() bool { return ackPending() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[int64]int{1: 1, 2: 2, 3: 1, 4: 1, 5: 1, 6: 1}, attempts)
+}
@@ -0,0 +1,290 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// Handler consumes a decoded rocket message, and reports what became of it as
+// a receipt status. A buffered message calls the domain.SettleFunc of ctx once
+// it leaves the buffer. RocketMessageUsecase.ApplyMessage satisfies this
+// signature.
+type Handler func(ctx context.Context, message *domain.RocketMessage) (string, error)
+
+// MessageSource feeds rocket messages from an external producer into a Handler.
+// Start blocks until the context is cancelled or the source is exhausted.
+type MessageSource interface {
+	Name() string
+	Start(ctx context.Context, handle Handler) error
+}
+
+// Options tunes how sources poll for new data and retry failed messages
+type Options struct {
+	PollInterval time.Duration
+	MaxAttempts  int
+	RetryDelay   time.Duration
+}
+
+// DefaultOptions returns the options used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		PollInterval: time.Second,
+		MaxAttempts:  3,
+		RetryDelay:   500 * time.Millisecond,
+	}
+}
+
+// decodeMessage parses a single NDJSON line into a rocket message
+func decodeMessage(line []byte) (*domain.RocketMessage, error) {
+	var message domain.RocketMessage
+	if err := json.Unmarshal(line, &message); err != nil {
+		return nil, fmt.Errorf("invalid message format: %w", err)
+	}
+
+	if message.Metadata.Channel == "" {
+		return nil, errors.New("missing channel ID")
+	}
+
+	if message.Metadata.MessageType == "" {
+		return nil, errors.New("missing message type")
+	}
+
+	return &message, nil
+}
+
+// isPermanent reports whether a message failed for a reason that delivering
+// it again does not change: it is invalid, conflicts with the processed
+// message of its number, or waits in the dead-letter store. Each delivery
+// would only record the failure again.
+func isPermanent(err error) bool {
+	var validationErr *domain.ValidationError
+	return errors.As(err, &validationErr) || errors.Is(err, domain.ErrMessageConflict) || errors.Is(err, domain.ErrDeadLettered)
+}
+
+// deliver hands a message to the handler, retrying up to MaxAttempts times
+// unless it failed for good, in which case it is logged and skipped. It
+// returns the status of the message, or an error when the context is
+// cancelled or the last attempt fails, so callers must not advance their
+// offset past the message in that case.
+func deliver(ctx context.Context, name string, opts Options, handle Handler, message *domain.RocketMessage) (string, error) {
+	var err error
+	for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+		status, handleErr := handle(ctx, message)
+		if err = handleErr; err == nil {
+			return status, nil
+		}
+
+		if isPermanent(err) {
+			log.Printf("Source %s skipping message %d for channel %s: %v",
+				name, message.Metadata.MessageNumber, message.Metadata.Channel, err)
+			return "", nil
+		}
+
+		log.Printf("Source %s failed to process message %d for channel %s (attempt %d/%d): %v",
+			name, message.Metadata.MessageNumber, message.Metadata.Channel, attempt, opts.MaxAttempts, err)
+
+		if attempt < opts.MaxAttempts {
+			if waitErr := wait(ctx, opts.RetryDelay); waitErr != nil {
+				return "", waitErr
+			}
+		}
+	}
+
+	return "", fmt.Errorf("source %s failed to process message %d for channel %s after %d attempts: %w",
+		name, message.Metadata.MessageNumber, message.Metadata.Channel, opts.MaxAttempts, err)
+}
+
+// processLine decodes and delivers one NDJSON line, and returns the status of
+// its message. Blank and malformed lines are logged and skipped so that a
+// single bad record cannot stall a source.
+func processLine(ctx context.Context, name string, opts Options, handle Handler, line []byte) (string, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return "", nil
+	}
+
+	message, err := decodeMessage(line)
+	if err != nil {
+		log.Printf("Source %s skipping record: %v", name, err)
+		return "", nil
+	}
+
+	return deliver(ctx, name, opts, handle, message)
+}
+
+// handleRecord processes the record between the offsets start and end, and
+// commits the offset past it. A buffered message holds the committed offset
+// at start until its channel settles it.
+func handleRecord(ctx context.Context, name string, opts Options, handle Handler, offsets *offsetTracker, line []byte, start int64, end int64) error {
+	id := offsets.hold(start)
+	settle := func(status string, reason string) {
+		if err := offsets.release(id); err != nil {
+			log.Printf("Source %s failed to commit offset: %v", name, err)
+		}
+	}
+
+	status, err := processLine(domain.ContextWithSettle(ctx, settle), name, opts, handle, line)
+	if status != domain.ReceiptStatusBuffered {
+		if releaseErr := offsets.release(id); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return offsets.advance(end)
+}
+
+// consumeLines reads NDJSON records from r, which is at the read offset of
+// offsets, and commits the byte offset following every record once it has
+// been handled. An unterminated trailing line is left unconsumed unless final
+// is set, so a file that is still being written is picked up again on the
+// next poll. It returns the offset following the last record read.
+func consumeLines(ctx context.Context, name string, opts Options, handle Handler, r io.Reader, offsets *offsetTracker, final bool) (int64, error) {
+	offset := offsets.position()
+	reader := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return offset, err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return offset, fmt.Errorf("failed to read from source %s: %w", name, err)
+		}
+
+		if errors.Is(err, io.EOF) && (!final || len(line) == 0) {
+			return offset, nil
+		}
+
+		next := offset + int64(len(line))
+		if procErr := handleRecord(ctx, name, opts, handle, offsets, line, offset, next); procErr != nil {
+			return offset, procErr
+		}
+		offset = next
+
+		if errors.Is(err, io.EOF) {
+			return offset, nil
+		}
+	}
+}
+
+// offsetTracker commits how far a source has been consumed. A record whose
+// message was buffered holds the committed offset back until its channel
+// settles it, so that a restart reads it again rather than losing it with the
+// buffer, which only lives in memory.
+type offsetTracker struct {
+	commit func(int64) error
+
+	mu        sync.Mutex
+	read      int64           // Offset following the last handled record
+	committed int64           // Offset last committed
+	held      map[int64]int64 // Start offsets of the held records, by hold ID
+	lastHold  int64
+}
+
+// newOffsetTracker creates a tracker of a source committed up to offset
+func newOffsetTracker(offset int64, commit func(int64) error) *offsetTracker {
+	return &offsetTracker{
+		commit:    commit,
+		read:      offset,
+		committed: offset,
+		held:      make(map[int64]int64),
+	}
+}
+
+// position returns the offset following the last handled record
+func (t *offsetTracker) position() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.read
+}
+
+// hold keeps the committed offset at or before the record starting at start
+// until the returned ID is released
+func (t *offsetTracker) hold(start int64) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastHold++
+	t.held[t.lastHold] = start
+	return t.lastHold
+}
+
+// release lets the committed offset move past a held record, and commits it
+func (t *offsetTracker) release(id int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.held, id)
+	return t.commitLocked()
+}
+
+// advance records that the records up to offset were handled, and commits it
+// unless a record before is held
+func (t *offsetTracker) advance(offset int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.read = offset
+	return t.commitLocked()
+}
+
+// reset starts reading again from offset, such as a truncated file from the
+// beginning. The held records are forgotten, as they are not read again.
+func (t *offsetTracker) reset(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.read = offset
+	t.held = make(map[int64]int64)
+}
+
+// pending reports whether records are held
+func (t *offsetTracker) pending() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.held) > 0
+}
+
+// commitLocked commits the offset of the first held record, or the read offset
+// when none is held, if it moved
+func (t *offsetTracker) commitLocked() error {
+	offset := t.read
+	for _, start := range t.held {
+		offset = min(offset, start)
+	}
+
+	if offset == t.committed {
+		return nil
+	}
+	if err := t.commit(offset); err != nil {
+		return err
+	}
+	t.committed = offset
+	return nil
+}
+
+// wait sleeps for d or until the context is cancelled
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
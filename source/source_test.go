@@ -0,0 +1,308 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/helper"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testOptions keeps polling and retries fast in tests
+var testOptions = Options{
+	PollInterval: 10 * time.Millisecond,
+	MaxAttempts:  2,
+	RetryDelay:   time.Millisecond,
+}
+
+// newOffsetStore returns an in-memory offset repository
+func newOffsetStore(initial map[string]int64) *mocks.MockOffsetRepository {
+	var mu sync.Mutex
+	offsets := make(map[string]int64)
+	for k, v := range initial {
+		offsets[k] = v
+	}
+
+	return &mocks.MockOffsetRepository{
+		GetOffsetFunc: func(ctx context.Context, source string) (int64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return offsets[source], nil
+		},
+		SaveOffsetFunc: func(ctx context.Context, source string, offset int64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			offsets[source] = offset
+			return nil
+		},
+		DeleteOffsetFunc: func(ctx context.Context, source string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(offsets, source)
+			return nil
+		},
+	}
+}
+
+// recorder collects the message numbers a handler received
+type recorder struct {
+	mu       sync.Mutex
+	received []int64
+	failOn   map[int64]bool
+	failWith map[int64]error // Error of a message, instead of a failure to retry
+	buffer   map[int64]bool  // Messages reported as buffered, until settled
+	settles  map[int64]domain.SettleFunc
+}
+
+func (r *recorder) handle(ctx context.Context, message *domain.RocketMessage) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	number := message.Metadata.MessageNumber
+	r.received = append(r.received, number)
+	if r.failOn[number] {
+		return "", errors.New("processing failed")
+	}
+	if err := r.failWith[number]; err != nil {
+		return "", err
+	}
+	if r.buffer[number] {
+		if r.settles == nil {
+			r.settles = make(map[int64]domain.SettleFunc)
+		}
+		r.settles[number] = domain.SettleFromContext(ctx)
+		return domain.ReceiptStatusBuffered, nil
+	}
+	return domain.ReceiptStatusApplied, nil
+}
+
+// settle tells the source what became of a buffered message
+func (r *recorder) settle(number int64, status string) {
+	r.mu.Lock()
+	settle := r.settles[number]
+	r.mu.Unlock()
+	settle(status, "")
+}
+
+func (r *recorder) numbers() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.received...)
+}
+
+// ndjson renders test messages with the given numbers as NDJSON
+func ndjson(t *testing.T, numbers ...int64) string {
+	var sb strings.Builder
+	for _, n := range numbers {
+		line, err := json.Marshal(helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, n, time.Now()))
+		assert.NoError(t, err)
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func TestDecodeMessage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		line          string
+		expectedError string
+	}{
+		{
+			name:          "valid_message",
+			line:          `{"metadata":{"channel":"channel-1","messageNumber":1,"messageType":"RocketLaunched"},"message":{}}`,
+			expectedError: "",
+		},
+		{
+			name:          "invalid_json",
+			line:          `{"metadata":`,
+			expectedError: "invalid message format: unexpected end of JSON input",
+		},
+		{
+			name:          "missing_channel",
+			line:          `{"metadata":{"messageNumber":1,"messageType":"RocketLaunched"}}`,
+			expectedError: "missing channel ID",
+		},
+		{
+			name:          "missing_message_type",
+			line:          `{"metadata":{"channel":"channel-1","messageNumber":1}}`,
+			expectedError: "missing message type",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			message, err := decodeMessage([]byte(tc.line))
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, message)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "channel-1", message.Metadata.Channel)
+			}
+		})
+	}
+}
+
+func TestConsumeLines(t *testing.T) {
+	t.Parallel()
+
+	complete := ndjson(t, 1, 2)
+	partial := strings.TrimSuffix(ndjson(t, 3), "\n")
+
+	testCases := []struct {
+		name             string
+		input            string
+		final            bool
+		failOn           map[int64]bool
+		failWith         map[int64]error
+		expectedReceived []int64
+		expectedOffset   int64
+		expectedError    string
+	}{
+		{
+			name:             "complete_lines",
+			input:            complete,
+			expectedReceived: []int64{1, 2},
+			expectedOffset:   int64(len(complete)),
+		},
+		{
+			name:             "unterminated_line_left_for_next_poll",
+			input:            complete + partial,
+			expectedReceived: []int64{1, 2},
+			expectedOffset:   int64(len(complete)),
+		},
+		{
+			name:             "unterminated_line_consumed_when_final",
+			input:            complete + partial,
+			final:            true,
+			expectedReceived: []int64{1, 2, 3},
+			expectedOffset:   int64(len(complete) + len(partial)),
+		},
+		{
+			name:             "malformed_line_skipped",
+			input:            "not json\n" + complete,
+			expectedReceived: []int64{1, 2},
+			expectedOffset:   int64(len("not json\n") + len(complete)),
+		},
+		{
+			name:             "failed_message_retried_then_left_unconsumed",
+			input:            complete,
+			failOn:           map[int64]bool{1: true},
+			expectedReceived: []int64{1, 1},
+			expectedOffset:   0,
+			expectedError:    "source test failed to process message 1 for channel channel-1 after 2 attempts: processing failed",
+		},
+		{
+			name:             "failed_message_stops_before_later_ones",
+			input:            complete,
+			failOn:           map[int64]bool{2: true},
+			expectedReceived: []int64{1, 2, 2},
+			expectedOffset:   int64(strings.Index(complete, "\n") + 1),
+			expectedError:    "source test failed to process message 2 for channel channel-1 after 2 attempts: processing failed",
+		},
+		{
+			name:             "conflicting_message_skipped",
+			input:            complete,
+			failWith:         map[int64]error{1: fmt.Errorf("message 1 of channel channel-1: %w", domain.ErrMessageConflict)},
+			expectedReceived: []int64{1, 2},
+			expectedOffset:   int64(len(complete)),
+		},
+		{
+			name:             "dead_lettered_message_skipped",
+			input:            complete,
+			failWith:         map[int64]error{1: fmt.Errorf("failed to execute rocket state usecase: corrupt payload (%w)", domain.ErrDeadLettered)},
+			expectedReceived: []int64{1, 2},
+			expectedOffset:   int64(len(complete)),
+		},
+		{
+			name:             "invalid_message_skipped",
+			input:            complete,
+			failWith:         map[int64]error{1: &domain.ValidationError{Fields: []domain.FieldError{{Field: "message.launchSpeed", Message: "must be positive"}}}},
+			expectedReceived: []int64{1, 2},
+			expectedOffset:   int64(len(complete)),
+		},
+		{
+			name:             "busy_database_retried",
+			input:            complete,
+			failWith:         map[int64]error{1: fmt.Errorf("%w: retries exhausted", domain.ErrBusy)},
+			expectedReceived: []int64{1, 1},
+			expectedOffset:   0,
+			expectedError:    "source test failed to process message 1 for channel channel-1 after 2 attempts: database busy: retries exhausted",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := &recorder{failOn: tc.failOn, failWith: tc.failWith}
+			var committed int64
+			tracker := newOffsetTracker(0, func(next int64) error {
+				committed = next
+				return nil
+			})
+
+			offset, err := consumeLines(context.Background(), "test", testOptions, rec.handle,
+				strings.NewReader(tc.input), tracker, tc.final)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedReceived, rec.numbers())
+			assert.Equal(t, tc.expectedOffset, offset)
+			assert.Equal(t, tc.expectedOffset, committed)
+		})
+	}
+}
+
+func TestConsumeLines_Buffered(t *testing.T) {
+	t.Parallel()
+
+	first, second, third := ndjson(t, 1), ndjson(t, 2), ndjson(t, 3)
+	input := first + second + third
+
+	rec := &recorder{buffer: map[int64]bool{1: true, 3: true}}
+	var mu sync.Mutex
+	var committed []int64
+	tracker := newOffsetTracker(0, func(next int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		committed = append(committed, next)
+		return nil
+	})
+
+	offset, err := consumeLines(context.Background(), "test", testOptions, rec.handle, strings.NewReader(input), tracker, false)
+
+	// Reading goes on, while the offset stays at the first buffered message
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, rec.numbers())
+	assert.Equal(t, int64(len(input)), offset)
+	assert.Empty(t, committed)
+	assert.True(t, tracker.pending())
+
+	// It moves to the next one still buffered once the first is settled
+	rec.settle(1, domain.ReceiptStatusApplied)
+	assert.Equal(t, []int64{int64(len(first + second))}, committed)
+
+	rec.settle(3, domain.ReceiptStatusDeadLettered)
+	assert.Equal(t, []int64{int64(len(first + second)), int64(len(input))}, committed)
+	assert.False(t, tracker.pending())
+}
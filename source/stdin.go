@@ -0,0 +1,79 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"lunar-rockets/domain"
+)
+
+// StdinSource reads NDJSON messages from a stream such as os.Stdin. Streams
+// cannot seek, so the offset is the number of lines consumed: replaying the
+// same capture after a restart skips the lines that were already handled, up
+// to the first one still buffered.
+type StdinSource struct {
+	reader  io.Reader
+	offsets domain.OffsetRepository
+	opts    Options
+}
+
+// NewStdinSource creates a source that consumes the given reader
+func NewStdinSource(reader io.Reader, offsets domain.OffsetRepository, opts Options) *StdinSource {
+	return &StdinSource{
+		reader:  reader,
+		offsets: offsets,
+		opts:    opts,
+	}
+}
+
+func (s *StdinSource) Name() string {
+	return "stdin"
+}
+
+func (s *StdinSource) Start(ctx context.Context, handle Handler) error {
+	skip, err := s.offsets.GetOffset(ctx, s.Name())
+	if err != nil {
+		return err
+	}
+
+	if skip > 0 {
+		log.Printf("Source %s resuming after line %d", s.Name(), skip)
+	}
+
+	tracker := newOffsetTracker(skip, func(next int64) error {
+		return s.offsets.SaveOffset(ctx, s.Name(), next)
+	})
+	reader := bufio.NewReader(s.reader)
+	var lineNumber int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("failed to read from source %s: %w", s.Name(), readErr)
+		}
+
+		if len(line) > 0 {
+			lineNumber++
+			if lineNumber > skip {
+				if err := handleRecord(ctx, s.Name(), s.opts, handle, tracker, line, lineNumber-1, lineNumber); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			log.Printf("Source %s reached end of input after %d lines", s.Name(), lineNumber)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package source
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdinSource_Start(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		savedOffset      int64
+		failOn           map[int64]bool
+		expectedReceived []int64
+		expectedOffset   int64
+		expectedError    string
+	}{
+		{
+			name:             "fresh_start",
+			savedOffset:      0,
+			expectedReceived: []int64{1, 2, 3},
+			expectedOffset:   3,
+		},
+		{
+			name:             "resume_after_saved_line",
+			savedOffset:      2,
+			expectedReceived: []int64{3},
+			expectedOffset:   3,
+		},
+		{
+			name:             "stops_before_failed_message",
+			savedOffset:      0,
+			failOn:           map[int64]bool{2: true},
+			expectedReceived: []int64{1, 2, 2},
+			expectedOffset:   1,
+			expectedError:    "source stdin failed to process message 2 for channel channel-1 after 2 attempts: processing failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			offsets := newOffsetStore(map[string]int64{"stdin": tc.savedOffset})
+			rec := &recorder{failOn: tc.failOn}
+			src := NewStdinSource(strings.NewReader(ndjson(t, 1, 2, 3)), offsets, testOptions)
+
+			err := src.Start(context.Background(), rec.handle)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedReceived, rec.numbers())
+
+			offset, _ := offsets.GetOffset(context.Background(), src.Name())
+			assert.Equal(t, tc.expectedOffset, offset)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"lunar-rockets/domain"
+)
+
+// Ack is written back to a socket producer for every record it sends. The
+// producer owns its own offset: it should resend anything it has not seen an
+// "ack" for after reconnecting. A message buffered until the ones before it
+// arrive is only acked once its channel applies it, so acks may come out of
+// order.
+type Ack struct {
+	Line          int64  `json:"line"`
+	Channel       string `json:"channel,omitempty"`
+	MessageNumber int64  `json:"messageNumber,omitempty"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+const (
+	AckStatusOK    = "ack"
+	AckStatusError = "nack"
+)
+
+// UnixSocketSource listens on a Unix domain socket for sidecar producers.
+// Each connection streams NDJSON records and receives one Ack line per record.
+type UnixSocketSource struct {
+	path string
+}
+
+// NewUnixSocketSource creates a source listening on the socket at path
+func NewUnixSocketSource(path string) *UnixSocketSource {
+	return &UnixSocketSource{path: path}
+}
+
+func (s *UnixSocketSource) Name() string {
+	return "unix:" + s.path
+}
+
+func (s *UnixSocketSource) Start(ctx context.Context, handle Handler) error {
+	// Remove a stale socket left behind by a previous run
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on socket: %w", err)
+	}
+	defer os.Remove(s.path)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept socket connection: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serve(ctx, conn, handle)
+		}()
+	}
+}
+
+// serve handles the records sent over a single connection
+func (s *UnixSocketSource) serve(ctx context.Context, conn net.Conn, handle Handler) {
+	defer conn.Close()
+
+	// Unblock the scanner on shutdown, without outliving the connection
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	// Acks of buffered messages are written by whoever settles them
+	var encoderMutex sync.Mutex
+	writeAck := func(ack Ack) error {
+		encoderMutex.Lock()
+		defer encoderMutex.Unlock()
+		return encoder.Encode(ack)
+	}
+
+	var lineNumber int64
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		lineNumber++
+
+		ack := Ack{Line: lineNumber, Status: AckStatusOK}
+		message, err := decodeMessage(line)
+		if err == nil {
+			ack.Channel = message.Metadata.Channel
+			ack.MessageNumber = message.Metadata.MessageNumber
+
+			settled := ack
+			settle := func(status string, reason string) {
+				if status == domain.ReceiptStatusDiscarded {
+					settled.Status = AckStatusError
+					settled.Error = reason
+				}
+				if err := writeAck(settled); err != nil {
+					log.Printf("Source %s failed to write ack of record %d: %v", s.Name(), settled.Line, err)
+				}
+			}
+
+			var status string
+			status, err = handle(domain.ContextWithSettle(ctx, settle), message)
+			if status == domain.ReceiptStatusBuffered {
+				continue
+			}
+		}
+
+		if err != nil {
+			log.Printf("Source %s rejected record %d: %v", s.Name(), lineNumber, err)
+			ack.Status = AckStatusError
+			ack.Error = err.Error()
+		}
+
+		if err := writeAck(ack); err != nil {
+			log.Printf("Source %s failed to write ack: %v", s.Name(), err)
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		log.Printf("Source %s connection error: %v", s.Name(), err)
+	}
+}
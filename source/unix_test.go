@@ -0,0 +1,70 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnixSocketSource_Start(t *testing.T) {
+	t.Parallel()
+
+	// Socket paths are limited in length, so avoid the long default temp dir
+	dir, err := os.MkdirTemp("", "rockets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "source.sock")
+
+	rec := &recorder{failOn: map[int64]bool{2: true}, buffer: map[int64]bool{3: true}}
+	src := NewUnixSocketSource(path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- src.Start(ctx, rec.handle) }()
+
+	var conn net.Conn
+	assert.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	// Message 3 is buffered, and only acked once settled
+	_, err = conn.Write([]byte(ndjson(t, 1, 2, 3) + "not json\n"))
+	assert.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	readAck := func() Ack {
+		line, err := reader.ReadBytes('\n')
+		assert.NoError(t, err)
+
+		var ack Ack
+		assert.NoError(t, json.Unmarshal(line, &ack))
+		return ack
+	}
+	var acks []Ack
+	for i := 0; i < 3; i++ {
+		acks = append(acks, readAck())
+	}
+	rec.settle(3, domain.ReceiptStatusApplied)
+	acks = append(acks, readAck())
+	conn.Close()
+
+	assert.Equal(t, Ack{Line: 1, Channel: "channel-1", MessageNumber: 1, Status: AckStatusOK}, acks[0])
+	assert.Equal(t, Ack{Line: 2, Channel: "channel-1", MessageNumber: 2, Status: AckStatusError, Error: "processing failed"}, acks[1])
+	assert.Equal(t, AckStatusError, acks[2].Status)
+	assert.Equal(t, int64(4), acks[2].Line)
+	assert.Equal(t, Ack{Line: 3, Channel: "channel-1", MessageNumber: 3, Status: AckStatusOK}, acks[3])
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.NoFileExists(t, path)
+}
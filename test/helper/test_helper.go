@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"encoding/json"
 	"time"
 
 	"lunar-rockets/domain"
@@ -49,10 +50,35 @@ func CreateTestMessage(channel string, messageType string, messageNumber int64,
 			MessageNumber: messageNumber,
 			MessageTime:   timestamp,
 		},
-		Message: domain.RocketLaunchedMessage{
+		Message: EncodePayload(CreateTestPayload(messageType)),
+	}
+}
+
+// EncodePayload encodes a message payload as it is received
+func EncodePayload(payload interface{}) json.RawMessage {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// CreateTestPayload creates a valid payload for a message type
+func CreateTestPayload(messageType string) interface{} {
+	switch messageType {
+	case domain.TypeRocketSpeedIncreased:
+		return domain.RocketSpeedIncreasedMessage{By: 500}
+	case domain.TypeRocketSpeedDecreased:
+		return domain.RocketSpeedDecreasedMessage{By: 500}
+	case domain.TypeRocketExploded:
+		return domain.RocketExplodedMessage{Reason: "PRESSURE_VESSEL_FAILURE"}
+	case domain.TypeRocketMissionChanged:
+		return domain.RocketMissionChangedMessage{NewMission: "SHUTTLE_MIR"}
+	default:
+		return domain.RocketLaunchedMessage{
 			Type:        "Falcon-9",
 			LaunchSpeed: 1000,
 			Mission:     "ARTEMIS",
-		},
+		}
 	}
 }
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+)
+
+// MockAuditRepository is a mock implementation of domain.AuditRepository
+type MockAuditRepository struct {
+	SaveFunc func(ctx context.Context, entry *domain.AuditEntry) error
+	ListFunc func(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error)
+}
+
+// Ensure MockAuditRepository implements domain.AuditRepository
+var _ domain.AuditRepository = (*MockAuditRepository)(nil)
+
+// Save calls the mocked implementation
+func (m *MockAuditRepository) Save(ctx context.Context, entry *domain.AuditEntry) error {
+	return m.SaveFunc(ctx, entry)
+}
+
+// List calls the mocked implementation
+func (m *MockAuditRepository) List(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error) {
+	return m.ListFunc(ctx, query)
+}
@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockChannelAdminUsecase struct {
+	mock.Mock
+}
+
+func (m *MockChannelAdminUsecase) SkipGap(ctx context.Context, channel string, to int64, reason string) (*domain.AuditEntry, error) {
+	args := m.Called(ctx, channel, to, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockChannelAdminUsecase) DiscardBuffer(ctx context.Context, channel string, reason string) (*domain.AuditEntry, error) {
+	args := m.Called(ctx, channel, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockChannelAdminUsecase) ResetChannel(ctx context.Context, channel string, reason string) (*domain.AuditEntry, error) {
+	args := m.Called(ctx, channel, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockChannelAdminUsecase) ListAudit(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AuditEntry), args.Error(1)
+}
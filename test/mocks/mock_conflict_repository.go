@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+)
+
+// MockConflictRepository is a mock implementation of domain.ConflictRepository
+type MockConflictRepository struct {
+	SaveFunc          func(ctx context.Context, conflict *domain.MessageConflict) error
+	ListFunc          func(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error)
+	DeleteChannelFunc func(ctx context.Context, channel string) (int64, error)
+}
+
+// Ensure MockConflictRepository implements domain.ConflictRepository
+var _ domain.ConflictRepository = (*MockConflictRepository)(nil)
+
+// Save calls the mocked implementation
+func (m *MockConflictRepository) Save(ctx context.Context, conflict *domain.MessageConflict) error {
+	return m.SaveFunc(ctx, conflict)
+}
+
+// List calls the mocked implementation
+func (m *MockConflictRepository) List(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error) {
+	return m.ListFunc(ctx, query)
+}
+
+// DeleteChannel calls the mocked implementation
+func (m *MockConflictRepository) DeleteChannel(ctx context.Context, channel string) (int64, error) {
+	return m.DeleteChannelFunc(ctx, channel)
+}
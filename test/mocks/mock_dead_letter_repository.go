@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+)
+
+// MockDeadLetterRepository is a mock implementation of domain.DeadLetterRepository
+type MockDeadLetterRepository struct {
+	SaveFunc           func(ctx context.Context, deadLetter *domain.DeadLetter) error
+	FindByIDFunc       func(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	ListFunc           func(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error)
+	DeleteFunc         func(ctx context.Context, id int64) error
+	DeleteMatchingFunc func(ctx context.Context, query domain.DeadLetterQuery) (int64, error)
+}
+
+// Ensure MockDeadLetterRepository implements domain.DeadLetterRepository
+var _ domain.DeadLetterRepository = (*MockDeadLetterRepository)(nil)
+
+// Save calls the mocked implementation
+func (m *MockDeadLetterRepository) Save(ctx context.Context, deadLetter *domain.DeadLetter) error {
+	return m.SaveFunc(ctx, deadLetter)
+}
+
+// FindByID calls the mocked implementation
+func (m *MockDeadLetterRepository) FindByID(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	return m.FindByIDFunc(ctx, id)
+}
+
+// List calls the mocked implementation
+func (m *MockDeadLetterRepository) List(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+	return m.ListFunc(ctx, query)
+}
+
+// Delete calls the mocked implementation
+func (m *MockDeadLetterRepository) Delete(ctx context.Context, id int64) error {
+	return m.DeleteFunc(ctx, id)
+}
+
+// DeleteMatching calls the mocked implementation
+func (m *MockDeadLetterRepository) DeleteMatching(ctx context.Context, query domain.DeadLetterQuery) (int64, error) {
+	return m.DeleteMatchingFunc(ctx, query)
+}
@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeadLetterUsecase struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterUsecase) ListDeadLetters(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterUsecase) ReplayDeadLetter(ctx context.Context, id int64) (*domain.ReplayResult, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReplayResult), args.Error(1)
+}
+
+func (m *MockDeadLetterUsecase) ReplayDeadLetters(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.ReplayResult, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ReplayResult), args.Error(1)
+}
+
+func (m *MockDeadLetterUsecase) DeleteDeadLetter(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterUsecase) PurgeDeadLetters(ctx context.Context, query domain.DeadLetterQuery) (int64, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(int64), args.Error(1)
+}
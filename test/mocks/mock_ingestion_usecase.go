@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIngestionUsecase struct {
+	mock.Mock
+}
+
+func (m *MockIngestionUsecase) EnqueueMessage(ctx context.Context, message *domain.RocketMessage) (*domain.Receipt, error) {
+	args := m.Called(ctx, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Receipt), args.Error(1)
+}
+
+func (m *MockIngestionUsecase) GetReceipt(ctx context.Context, id string) (*domain.Receipt, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Receipt), args.Error(1)
+}
+
+func (m *MockIngestionUsecase) Start(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
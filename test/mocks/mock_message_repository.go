@@ -7,19 +7,55 @@ import (
 
 // MockMessageRepository is a mock implementation of domain.MessageRepository
 type MockMessageRepository struct {
-	MarkAsProcessedFunc       func(ctx context.Context, channel string, messageNumber int64) error
+	MarkAsProcessedFunc       func(ctx context.Context, channel string, messageNumber int64, payloadHash string) error
 	FindLastMessageNumberFunc func(ctx context.Context, channel string) (int64, error)
+	FindPayloadHashFunc       func(ctx context.Context, channel string, messageNumber int64) (string, error)
+	ListChannelProgressFunc   func(ctx context.Context) ([]*domain.ChannelProgress, error)
+	FindChannelProgressFunc   func(ctx context.Context, channel string) (*domain.ChannelProgress, error)
+	SaveEventFunc             func(ctx context.Context, message *domain.RocketMessage) error
+	ListEventsFunc            func(ctx context.Context, channel string, afterNumber int64, limit int) ([]*domain.MessageEvent, error)
+	DeleteChannelFunc         func(ctx context.Context, channel string) (int64, error)
 }
 
 // Ensure MockMessageRepository implements domain.MessageRepository
 var _ domain.MessageRepository = (*MockMessageRepository)(nil)
 
 // MarkAsProcessed calls the mocked implementation
-func (m *MockMessageRepository) MarkAsProcessed(ctx context.Context, channel string, messageNumber int64) error {
-	return m.MarkAsProcessedFunc(ctx, channel, messageNumber)
+func (m *MockMessageRepository) MarkAsProcessed(ctx context.Context, channel string, messageNumber int64, payloadHash string) error {
+	return m.MarkAsProcessedFunc(ctx, channel, messageNumber, payloadHash)
 }
 
 // FindLastMessageNumber calls the mocked implementation
 func (m *MockMessageRepository) FindLastMessageNumber(ctx context.Context, channel string) (int64, error) {
 	return m.FindLastMessageNumberFunc(ctx, channel)
 }
+
+// FindPayloadHash calls the mocked implementation
+func (m *MockMessageRepository) FindPayloadHash(ctx context.Context, channel string, messageNumber int64) (string, error) {
+	return m.FindPayloadHashFunc(ctx, channel, messageNumber)
+}
+
+// ListChannelProgress calls the mocked implementation
+func (m *MockMessageRepository) ListChannelProgress(ctx context.Context) ([]*domain.ChannelProgress, error) {
+	return m.ListChannelProgressFunc(ctx)
+}
+
+// FindChannelProgress calls the mocked implementation
+func (m *MockMessageRepository) FindChannelProgress(ctx context.Context, channel string) (*domain.ChannelProgress, error) {
+	return m.FindChannelProgressFunc(ctx, channel)
+}
+
+// SaveEvent calls the mocked implementation
+func (m *MockMessageRepository) SaveEvent(ctx context.Context, message *domain.RocketMessage) error {
+	return m.SaveEventFunc(ctx, message)
+}
+
+// ListEvents calls the mocked implementation
+func (m *MockMessageRepository) ListEvents(ctx context.Context, channel string, afterNumber int64, limit int) ([]*domain.MessageEvent, error) {
+	return m.ListEventsFunc(ctx, channel, afterNumber, limit)
+}
+
+// DeleteChannel calls the mocked implementation
+func (m *MockMessageRepository) DeleteChannel(ctx context.Context, channel string) (int64, error) {
+	return m.DeleteChannelFunc(ctx, channel)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+	"lunar-rockets/domain"
+)
+
+// MockOffsetRepository is a mock implementation of domain.OffsetRepository
+type MockOffsetRepository struct {
+	GetOffsetFunc    func(ctx context.Context, source string) (int64, error)
+	SaveOffsetFunc   func(ctx context.Context, source string, offset int64) error
+	DeleteOffsetFunc func(ctx context.Context, source string) error
+}
+
+// Ensure MockOffsetRepository implements domain.OffsetRepository
+var _ domain.OffsetRepository = (*MockOffsetRepository)(nil)
+
+// GetOffset calls the mocked implementation
+func (m *MockOffsetRepository) GetOffset(ctx context.Context, source string) (int64, error) {
+	return m.GetOffsetFunc(ctx, source)
+}
+
+// SaveOffset calls the mocked implementation
+func (m *MockOffsetRepository) SaveOffset(ctx context.Context, source string, offset int64) error {
+	return m.SaveOffsetFunc(ctx, source, offset)
+}
+
+// DeleteOffset calls the mocked implementation
+func (m *MockOffsetRepository) DeleteOffset(ctx context.Context, source string) error {
+	return m.DeleteOffsetFunc(ctx, source)
+}
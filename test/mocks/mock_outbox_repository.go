@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// MockOutboxRepository is a mock implementation of domain.OutboxRepository
+type MockOutboxRepository struct {
+	AddFunc                   func(ctx context.Context, event *domain.OutboxEvent) error
+	FetchUnpublishedFunc      func(ctx context.Context, limit int) ([]*domain.OutboxEvent, error)
+	MarkPublishedFunc         func(ctx context.Context, id int64) error
+	DeletePublishedBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Ensure MockOutboxRepository implements domain.OutboxRepository
+var _ domain.OutboxRepository = (*MockOutboxRepository)(nil)
+
+// Add calls the mocked implementation
+func (m *MockOutboxRepository) Add(ctx context.Context, event *domain.OutboxEvent) error {
+	return m.AddFunc(ctx, event)
+}
+
+// FetchUnpublished calls the mocked implementation
+func (m *MockOutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+	return m.FetchUnpublishedFunc(ctx, limit)
+}
+
+// MarkPublished calls the mocked implementation
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	return m.MarkPublishedFunc(ctx, id)
+}
+
+// DeletePublishedBefore calls the mocked implementation
+func (m *MockOutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.DeletePublishedBeforeFunc(ctx, before)
+}
@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// MockReceiptRepository is a mock implementation of domain.ReceiptRepository
+type MockReceiptRepository struct {
+	SaveFunc                  func(ctx context.Context, receipt *domain.Receipt) error
+	FindByIDFunc              func(ctx context.Context, id string) (*domain.Receipt, error)
+	FetchQueuedFunc           func(ctx context.Context, afterSeq int64, limit int) ([]*domain.Receipt, error)
+	UpdateStatusFunc          func(ctx context.Context, id string, status string, reason string) error
+	DeleteProcessedBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Ensure MockReceiptRepository implements domain.ReceiptRepository
+var _ domain.ReceiptRepository = (*MockReceiptRepository)(nil)
+
+// Save calls the mocked implementation
+func (m *MockReceiptRepository) Save(ctx context.Context, receipt *domain.Receipt) error {
+	return m.SaveFunc(ctx, receipt)
+}
+
+// FindByID calls the mocked implementation
+func (m *MockReceiptRepository) FindByID(ctx context.Context, id string) (*domain.Receipt, error) {
+	return m.FindByIDFunc(ctx, id)
+}
+
+// FetchQueued calls the mocked implementation
+func (m *MockReceiptRepository) FetchQueued(ctx context.Context, afterSeq int64, limit int) ([]*domain.Receipt, error) {
+	return m.FetchQueuedFunc(ctx, afterSeq, limit)
+}
+
+// UpdateStatus calls the mocked implementation
+func (m *MockReceiptRepository) UpdateStatus(ctx context.Context, id string, status string, reason string) error {
+	return m.UpdateStatusFunc(ctx, id, status, reason)
+}
+
+// DeleteProcessedBefore calls the mocked implementation
+func (m *MockReceiptRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.DeleteProcessedBeforeFunc(ctx, before)
+}
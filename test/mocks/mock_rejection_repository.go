@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"lunar-rockets/domain"
+)
+
+// MockRejectionRepository is a mock implementation of domain.RejectionRepository
+type MockRejectionRepository struct {
+	SaveFunc          func(ctx context.Context, rejection *domain.Rejection) error
+	ListFunc          func(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error)
+	DeleteChannelFunc func(ctx context.Context, channel string) (int64, error)
+}
+
+// Ensure MockRejectionRepository implements domain.RejectionRepository
+var _ domain.RejectionRepository = (*MockRejectionRepository)(nil)
+
+// Save calls the mocked implementation
+func (m *MockRejectionRepository) Save(ctx context.Context, rejection *domain.Rejection) error {
+	return m.SaveFunc(ctx, rejection)
+}
+
+// List calls the mocked implementation
+func (m *MockRejectionRepository) List(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error) {
+	return m.ListFunc(ctx, query)
+}
+
+// DeleteChannel calls the mocked implementation
+func (m *MockRejectionRepository) DeleteChannel(ctx context.Context, channel string) (int64, error) {
+	return m.DeleteChannelFunc(ctx, channel)
+}
@@ -16,3 +16,61 @@ func (m *MockRocketMessageUsecase) ProcessMessage(ctx context.Context, message *
 	args := m.Called(ctx, message)
 	return args.Error(0)
 }
+
+func (m *MockRocketMessageUsecase) ApplyMessage(ctx context.Context, message *domain.RocketMessage) (string, error) {
+	args := m.Called(ctx, message)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRocketMessageUsecase) ProcessBatch(ctx context.Context, messages []*domain.RocketMessage) []*domain.MessageResult {
+	args := m.Called(ctx, messages)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]*domain.MessageResult)
+}
+
+func (m *MockRocketMessageUsecase) ListChannels(ctx context.Context) ([]*domain.ChannelStatus, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ChannelStatus), args.Error(1)
+}
+
+func (m *MockRocketMessageUsecase) GetChannel(ctx context.Context, channel string) (*domain.ChannelStatus, error) {
+	args := m.Called(ctx, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ChannelStatus), args.Error(1)
+}
+
+func (m *MockRocketMessageUsecase) SkipGap(ctx context.Context, channel string, to int64) (*domain.GapSkip, error) {
+	args := m.Called(ctx, channel, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GapSkip), args.Error(1)
+}
+
+func (m *MockRocketMessageUsecase) ApplyAfterSkip(ctx context.Context, channel string, skip *domain.GapSkip) error {
+	args := m.Called(ctx, channel, skip)
+	return args.Error(0)
+}
+
+func (m *MockRocketMessageUsecase) DiscardBuffer(ctx context.Context, channel string) []int64 {
+	args := m.Called(ctx, channel)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]int64)
+}
+
+func (m *MockRocketMessageUsecase) ListConflicts(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MessageConflict), args.Error(1)
+}
@@ -9,6 +9,8 @@ import (
 type MockRocketRepository struct {
 	GetByChannelFunc func(ctx context.Context, channel string) (*domain.Rocket, error)
 	GetAllFunc       func(ctx context.Context, sortBy string, order string) ([]*domain.Rocket, error)
+	SearchFunc       func(ctx context.Context, query domain.RocketQuery) ([]*domain.Rocket, int, error)
+	StatsFunc        func(ctx context.Context) (*domain.FleetStats, error)
 	SaveFunc         func(ctx context.Context, rocket *domain.Rocket) error
 	UpdateFunc       func(ctx context.Context, rocket *domain.Rocket) error
 	DeleteFunc       func(ctx context.Context, channel string) error
@@ -28,6 +30,16 @@ func (m *MockRocketRepository) GetAll(ctx context.Context, sortBy string, order
 	return m.GetAllFunc(ctx, sortBy, order)
 }
 
+// Search calls the mocked implementation
+func (m *MockRocketRepository) Search(ctx context.Context, query domain.RocketQuery) ([]*domain.Rocket, int, error) {
+	return m.SearchFunc(ctx, query)
+}
+
+// Stats calls the mocked implementation
+func (m *MockRocketRepository) Stats(ctx context.Context) (*domain.FleetStats, error) {
+	return m.StatsFunc(ctx)
+}
+
 // Save calls the mocked implementation
 func (m *MockRocketRepository) Save(ctx context.Context, rocket *domain.Rocket) error {
 	return m.SaveFunc(ctx, rocket)
@@ -27,3 +27,35 @@ func (m *MockRocketUseCase) ListRockets(ctx context.Context, sortBy, order strin
 	}
 	return args.Get(0).([]*domain.Rocket), args.Error(1)
 }
+
+func (m *MockRocketUseCase) SearchRockets(ctx context.Context, query domain.RocketQuery) (*domain.RocketPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RocketPage), args.Error(1)
+}
+
+func (m *MockRocketUseCase) GetStats(ctx context.Context) (*domain.FleetStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FleetStats), args.Error(1)
+}
+
+func (m *MockRocketUseCase) GetTelemetry(ctx context.Context, channel string, query domain.TelemetryQuery) ([]*domain.Telemetry, error) {
+	args := m.Called(ctx, channel, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Telemetry), args.Error(1)
+}
+
+func (m *MockRocketUseCase) ListRejections(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Rejection), args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// MockTelemetryRepository is a mock implementation of domain.TelemetryRepository
+type MockTelemetryRepository struct {
+	SaveFunc          func(ctx context.Context, channel string, messageNumber int64, reading *domain.Telemetry) error
+	ListFunc          func(ctx context.Context, channel string, from time.Time, to time.Time) ([]*domain.Telemetry, error)
+	DeleteChannelFunc func(ctx context.Context, channel string) error
+}
+
+// Ensure MockTelemetryRepository implements domain.TelemetryRepository
+var _ domain.TelemetryRepository = (*MockTelemetryRepository)(nil)
+
+// Save calls the mocked implementation
+func (m *MockTelemetryRepository) Save(ctx context.Context, channel string, messageNumber int64, reading *domain.Telemetry) error {
+	return m.SaveFunc(ctx, channel, messageNumber, reading)
+}
+
+// List calls the mocked implementation
+func (m *MockTelemetryRepository) List(ctx context.Context, channel string, from time.Time, to time.Time) ([]*domain.Telemetry, error) {
+	return m.ListFunc(ctx, channel, from, to)
+}
+
+// DeleteChannel calls the mocked implementation
+func (m *MockTelemetryRepository) DeleteChannel(ctx context.Context, channel string) error {
+	return m.DeleteChannelFunc(ctx, channel)
+}
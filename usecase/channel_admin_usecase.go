@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/retry"
+)
+
+// ChannelAdminUsecase repairs the channels that cannot recover by themselves,
+// such as one whose producer lost a message for good. Every operation is
+// recorded in the audit log before its buffered messages are dropped, and
+// returns its audit entry.
+type ChannelAdminUsecase interface {
+	// SkipGap gives up on the messages of a channel up to a number, a zero
+	// number meaning the first gap, and applies the buffered messages that
+	// follow. The skip and its audit entry are written in one transaction.
+	SkipGap(ctx context.Context, channel string, to int64, reason string) (*domain.AuditEntry, error)
+	// DiscardBuffer drops the buffered messages of a channel
+	DiscardBuffer(ctx context.Context, channel string, reason string) (*domain.AuditEntry, error)
+	// ResetChannel deletes the rocket, telemetry, processed messages, dead
+	// letters, conflicts and rejections of a channel and drops its buffered
+	// messages, so that its messages can be sent again from the first one,
+	// and publishes a domain.EventTypeChannelReset event. It fails with
+	// domain.ErrChannelNotFound when the channel has none of these.
+	ResetChannel(ctx context.Context, channel string, reason string) (*domain.AuditEntry, error)
+	ListAudit(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error)
+}
+
+// DefaultAuditLimit is the number of audit entries listed when the query sets
+// no limit
+const DefaultAuditLimit = 100
+
+type channelAdminUsecase struct {
+	rocketRepo           domain.RocketRepository
+	messageRepo          domain.MessageRepository
+	telemetryRepo        domain.TelemetryRepository
+	deadLetterRepo       domain.DeadLetterRepository
+	conflictRepo         domain.ConflictRepository
+	rejectionRepo        domain.RejectionRepository
+	outboxRepo           domain.OutboxRepository
+	auditRepo            domain.AuditRepository
+	rocketMessageUsecase RocketMessageUsecase
+	retryPolicy          *retry.Policy
+}
+
+func NewChannelAdminUsecase(rocketRepo domain.RocketRepository, messageRepo domain.MessageRepository, telemetryRepo domain.TelemetryRepository, deadLetterRepo domain.DeadLetterRepository, conflictRepo domain.ConflictRepository, rejectionRepo domain.RejectionRepository, outboxRepo domain.OutboxRepository, auditRepo domain.AuditRepository, rocketMessageUsecase RocketMessageUsecase, retryPolicy *retry.Policy) ChannelAdminUsecase {
+	return &channelAdminUsecase{
+		rocketRepo:           rocketRepo,
+		messageRepo:          messageRepo,
+		telemetryRepo:        telemetryRepo,
+		deadLetterRepo:       deadLetterRepo,
+		conflictRepo:         conflictRepo,
+		rejectionRepo:        rejectionRepo,
+		outboxRepo:           outboxRepo,
+		auditRepo:            auditRepo,
+		rocketMessageUsecase: rocketMessageUsecase,
+		retryPolicy:          retryPolicy,
+	}
+}
+
+// SkipGap marks the skipped messages as processed and audits the skip in one
+// transaction, so that a skip is never left unaudited
+func (u *channelAdminUsecase) SkipGap(ctx context.Context, channel string, to int64, reason string) (*domain.AuditEntry, error) {
+	var skip *domain.GapSkip
+	var entry *domain.AuditEntry
+	err := u.inTransaction(ctx, domain.AuditActionSkipGap, channel, func(ctx context.Context) error {
+		var err error
+		if skip, err = u.rocketMessageUsecase.SkipGap(ctx, channel, to); err != nil {
+			return err
+		}
+
+		details := fmt.Sprintf("skipped messages %d to %d, discarded buffered messages %v", skip.From+1, skip.To, skip.Discarded)
+		entry, err = u.audit(ctx, domain.AuditActionSkipGap, channel, reason, details)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The buffered messages are dropped and the following ones applied once
+	// the skip is committed, as the buffer is not part of the transaction
+	if err := u.rocketMessageUsecase.ApplyAfterSkip(ctx, channel, skip); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// DiscardBuffer audits the discard before dropping the buffered messages, so
+// that they are never dropped unaudited
+func (u *channelAdminUsecase) DiscardBuffer(ctx context.Context, channel string, reason string) (*domain.AuditEntry, error) {
+	var buffered []int64
+	status, err := u.rocketMessageUsecase.GetChannel(ctx, channel)
+	switch {
+	case err == nil:
+		buffered = status.Buffered
+	case !errors.Is(err, domain.ErrChannelNotFound):
+		return nil, err
+	}
+
+	var entry *domain.AuditEntry
+	details := fmt.Sprintf("discarded buffered messages %v", buffered)
+	err = withRetry(ctx, u.retryPolicy, domain.AuditActionDiscardBuffer, func() error {
+		entry, err = u.audit(ctx, domain.AuditActionDiscardBuffer, channel, reason, details)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if discarded := u.rocketMessageUsecase.DiscardBuffer(ctx, channel); !slices.Equal(discarded, buffered) {
+		log.Printf("Discarded buffered messages %v of channel %s, received during its discard", discarded, channel)
+	}
+
+	return entry, nil
+}
+
+// ResetChannel deletes the channel's data, records the reset event and audits
+// it in one transaction, so that a failed reset leaves the channel as it was
+// and a reset is never left unaudited
+func (u *channelAdminUsecase) ResetChannel(ctx context.Context, channel string, reason string) (*domain.AuditEntry, error) {
+	// The buffered messages are dropped once the reset is committed, as the
+	// buffer is not part of the transaction
+	var buffered []int64
+	status, err := u.rocketMessageUsecase.GetChannel(ctx, channel)
+	switch {
+	case err == nil:
+		buffered = status.Buffered
+	case errors.Is(err, domain.ErrChannelNotFound):
+		// A channel whose first message failed has nothing processed, but
+		// still needs a reset to clear what its messages left behind
+		if err := u.findRecords(ctx, channel); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	var entry *domain.AuditEntry
+	err = u.inTransaction(ctx, domain.AuditActionResetChannel, channel, func(ctx context.Context) error {
+		entry, err = u.resetInTransaction(ctx, channel, reason, buffered)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The buffered messages follow the deleted ones, so they would wait for
+	// messages already sent
+	if discarded := u.rocketMessageUsecase.DiscardBuffer(ctx, channel); !slices.Equal(discarded, buffered) {
+		log.Printf("Discarded buffered messages %v of channel %s, received during its reset", discarded, channel)
+	}
+
+	return entry, nil
+}
+
+// findRecords checks that a channel without processed or buffered messages
+// has dead letters, conflicts or rejections, and fails with
+// domain.ErrChannelNotFound otherwise
+func (u *channelAdminUsecase) findRecords(ctx context.Context, channel string) error {
+	deadLetters, err := u.deadLetterRepo.List(ctx, domain.DeadLetterQuery{Channel: channel, Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	conflicts, err := u.conflictRepo.List(ctx, domain.ConflictQuery{Channel: channel, Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to list message conflicts: %w", err)
+	}
+
+	rejections, err := u.rejectionRepo.List(ctx, domain.RejectionQuery{Channel: channel, Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to list rejections: %w", err)
+	}
+
+	if len(deadLetters) == 0 && len(conflicts) == 0 && len(rejections) == 0 {
+		return domain.ErrChannelNotFound
+	}
+	return nil
+}
+
+// inTransaction runs an operation on a channel in a transaction of its own,
+// committed when the operation succeeds and rolled back otherwise. The
+// transaction is attempted again when it fails on a lock held by another
+// connection.
+func (u *channelAdminUsecase) inTransaction(ctx context.Context, action string, channel string, op func(ctx context.Context) error) error {
+	return withRetry(ctx, u.retryPolicy, action, func() error {
+		tx, err := u.rocketRepo.BeginTx(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		if err := op(domain.ContextWithTransaction(ctx, tx)); err != nil {
+			log.Printf("Rolling back %s of channel %s due to error: %v", action, channel, err)
+			_ = tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	})
+}
+
+// resetInTransaction deletes the channel's data within the transaction of
+// ctx, and records the reset event and audit entry
+func (u *channelAdminUsecase) resetInTransaction(ctx context.Context, channel string, reason string, buffered []int64) (*domain.AuditEntry, error) {
+	if err := u.rocketRepo.Delete(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	if err := u.telemetryRepo.DeleteChannel(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	processed, err := u.messageRepo.DeleteChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replaying a dead letter would apply it onto the reset channel, and the
+	// conflicts and rejections were against the deleted messages
+	deadLetters, err := u.deadLetterRepo.DeleteMatching(ctx, domain.DeadLetterQuery{Channel: channel})
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts, err := u.conflictRepo.DeleteChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	rejections, err := u.rejectionRepo.DeleteChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consumers of the state changes drop the rocket they know
+	err = u.outboxRepo.Add(ctx, &domain.OutboxEvent{
+		TenantID:  domain.TenantFromContext(ctx),
+		Channel:   channel,
+		EventType: domain.EventTypeChannelReset,
+		Payload:   json.RawMessage("null"),
+		CreatedAt: time.Now(),
+		Actor:     domain.ActorFromContext(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record channel reset: %w", err)
+	}
+
+	details := fmt.Sprintf("deleted the rocket, its telemetry, %d processed messages, %d dead letters, %d conflicts and %d rejections, discarded buffered messages %v",
+		processed, deadLetters, conflicts, rejections, buffered)
+	return u.audit(ctx, domain.AuditActionResetChannel, channel, reason, details)
+}
+
+func (u *channelAdminUsecase) ListAudit(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditLimit
+	}
+
+	entries, err := u.auditRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	log.Printf("Successfully listed %d audit entries", len(entries))
+	return entries, nil
+}
+
+// audit records an operation made on a channel by the caller. The operation
+// may be made already, so a failure to record it is logged along with what it
+// changed.
+func (u *channelAdminUsecase) audit(ctx context.Context, action string, channel string, reason string, details string) (*domain.AuditEntry, error) {
+	entry := &domain.AuditEntry{
+		Action:    action,
+		Channel:   channel,
+		Actor:     domain.ActorFromContext(ctx),
+		Reason:    reason,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+
+	if err := u.auditRepo.Save(ctx, entry); err != nil {
+		log.Printf("Error auditing %s of channel %s by %q (%s): %v", action, channel, entry.Actor, details, err)
+		return nil, fmt.Errorf("failed to audit %s of channel %s: %w", action, channel, err)
+	}
+
+	log.Printf("Audited %s of channel %s by %q: %s", action, channel, entry.Actor, details)
+	return entry, nil
+}
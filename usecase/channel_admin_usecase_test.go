@@ -0,0 +1,516 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/retry"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// savedEntries returns an audit repository recording the entries it saves
+func savedEntries(saveError error) (*mocks.MockAuditRepository, *[]*domain.AuditEntry) {
+	var entries []*domain.AuditEntry
+	return &mocks.MockAuditRepository{
+		SaveFunc: func(ctx context.Context, entry *domain.AuditEntry) error {
+			if saveError != nil {
+				return saveError
+			}
+			entry.ID = int64(len(entries) + 1)
+			entries = append(entries, entry)
+			return nil
+		},
+	}, &entries
+}
+
+func TestChannelAdminUsecase_SkipGap(t *testing.T) {
+	errLocked := errors.New("database is locked")
+
+	testCases := []struct {
+		name              string
+		skip              *domain.GapSkip
+		skipError         error
+		saveError         error
+		lockedBegins      int // Transactions that fail to begin on a lock
+		expectedDetails   string
+		expectedError     string
+		expectedErrorType error
+		expectedBegins    int
+	}{
+		{
+			name:            "skipped",
+			skip:            &domain.GapSkip{From: 1, To: 3},
+			expectedDetails: "skipped messages 2 to 3, discarded buffered messages []",
+			expectedBegins:  1,
+		},
+		{
+			name:            "past_buffered_messages",
+			skip:            &domain.GapSkip{From: 1, To: 5, Discarded: []int64{4, 5}},
+			expectedDetails: "skipped messages 2 to 5, discarded buffered messages [4 5]",
+			expectedBegins:  1,
+		},
+		{
+			name:            "retried_on_lock",
+			skip:            &domain.GapSkip{From: 1, To: 3},
+			lockedBegins:    2,
+			expectedDetails: "skipped messages 2 to 3, discarded buffered messages []",
+			expectedBegins:  3,
+		},
+		{
+			name:              "busy",
+			lockedBegins:      3,
+			expectedErrorType: domain.ErrBusy,
+			expectedBegins:    3,
+		},
+		{
+			name:           "nothing_to_skip",
+			skipError:      domain.ErrNothingToSkip,
+			expectedError:  "no message to skip",
+			expectedBegins: 1,
+		},
+		{
+			// A skip is never left unaudited
+			name:           "audit_error",
+			skip:           &domain.GapSkip{From: 1, To: 3, Discarded: []int64{2}},
+			saveError:      errors.New("database error"),
+			expectedError:  "failed to audit skip_gap of channel channel-1: database error",
+			expectedBegins: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var committed, rolledBack bool
+			tx := &mocks.MockTransaction{
+				CommitFunc: func() error {
+					committed = true
+					return nil
+				},
+				RollbackFunc: func() error {
+					rolledBack = true
+					return nil
+				},
+			}
+			begins := 0
+			mockRocketRepo := &mocks.MockRocketRepository{
+				BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
+					begins++
+					if begins <= tc.lockedBegins {
+						return nil, errLocked
+					}
+					return tx, nil
+				},
+			}
+
+			// The skip and its audit entry are written in the transaction
+			inTransaction := func(ctx context.Context) {
+				ctxTx, ok := domain.TransactionFromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, tx, ctxTx)
+			}
+			auditRepo, entries := savedEntries(tc.saveError)
+			save := auditRepo.SaveFunc
+			auditRepo.SaveFunc = func(ctx context.Context, entry *domain.AuditEntry) error {
+				inTransaction(ctx)
+				return save(ctx, entry)
+			}
+
+			mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+			if tc.skip != nil || tc.skipError != nil {
+				mockRocketMessageUsecase.On("SkipGap", mock.Anything, "channel-1", int64(0)).Run(func(args mock.Arguments) {
+					inTransaction(args.Get(0).(context.Context))
+				}).Return(tc.skip, tc.skipError)
+			}
+			// The buffered messages are only dropped once the skip is committed
+			if tc.expectedError == "" && tc.expectedErrorType == nil {
+				mockRocketMessageUsecase.On("ApplyAfterSkip", mock.Anything, "channel-1", tc.skip).Run(func(args mock.Arguments) {
+					assert.True(t, committed)
+					_, inTx := domain.TransactionFromContext(args.Get(0).(context.Context))
+					assert.False(t, inTx)
+				}).Return(nil)
+			}
+
+			policy, err := retry.NewPolicy(3, 0, 0, func(err error) bool {
+				return errors.Is(err, errLocked)
+			})
+			require.NoError(t, err)
+
+			useCase := NewChannelAdminUsecase(mockRocketRepo, &mocks.MockMessageRepository{}, &mocks.MockTelemetryRepository{}, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, &mocks.MockRejectionRepository{}, &mocks.MockOutboxRepository{}, auditRepo, mockRocketMessageUsecase, policy)
+			ctx := domain.ContextWithIdentity(context.Background(), &domain.Identity{Subject: "operator"})
+
+			entry, err := useCase.SkipGap(ctx, "channel-1", 0, "message 2 lost")
+
+			switch {
+			case tc.expectedErrorType != nil:
+				assert.ErrorIs(t, err, tc.expectedErrorType)
+				assert.Nil(t, entry)
+				assert.Empty(t, *entries)
+			case tc.expectedError != "":
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, entry)
+				assert.Empty(t, *entries)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, []*domain.AuditEntry{entry}, *entries)
+				assert.Equal(t, domain.AuditActionSkipGap, entry.Action)
+				assert.Equal(t, "channel-1", entry.Channel)
+				assert.Equal(t, "operator", entry.Actor)
+				assert.Equal(t, "message 2 lost", entry.Reason)
+				assert.Equal(t, tc.expectedDetails, entry.Details)
+				assert.False(t, entry.CreatedAt.IsZero())
+			}
+			assert.Equal(t, tc.expectedBegins, begins)
+			assert.Equal(t, tc.skipError != nil || tc.saveError != nil, rolledBack)
+			mockRocketMessageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelAdminUsecase_DiscardBuffer(t *testing.T) {
+	testCases := []struct {
+		name            string
+		channelError    error
+		saveError       error
+		expectedDetails string
+		expectedError   string
+	}{
+		{
+			name:            "discarded",
+			expectedDetails: "discarded buffered messages [5 7]",
+		},
+		{
+			name:            "unknown_channel",
+			channelError:    domain.ErrChannelNotFound,
+			expectedDetails: "discarded buffered messages []",
+		},
+		{
+			// The buffer is kept when the discard cannot be audited
+			name:          "audit_error",
+			saveError:     errors.New("database error"),
+			expectedError: "failed to audit discard_buffer of channel channel-1: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			auditRepo, entries := savedEntries(tc.saveError)
+
+			mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+			if tc.channelError != nil {
+				mockRocketMessageUsecase.On("GetChannel", mock.Anything, "channel-1").Return(nil, tc.channelError)
+			} else {
+				mockRocketMessageUsecase.On("GetChannel", mock.Anything, "channel-1").Return(&domain.ChannelStatus{Channel: "channel-1", LastProcessed: 3, Buffered: []int64{5, 7}}, nil)
+			}
+			if tc.expectedError == "" {
+				mockRocketMessageUsecase.On("DiscardBuffer", mock.Anything, "channel-1").Run(func(mock.Arguments) {
+					assert.Len(t, *entries, 1, "The discard must be audited first")
+				}).Return(nil)
+			}
+
+			useCase := NewChannelAdminUsecase(&mocks.MockRocketRepository{}, &mocks.MockMessageRepository{}, &mocks.MockTelemetryRepository{}, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, &mocks.MockRejectionRepository{}, &mocks.MockOutboxRepository{}, auditRepo, mockRocketMessageUsecase, nil)
+
+			entry, err := useCase.DiscardBuffer(context.Background(), "channel-1", "")
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, entry)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []*domain.AuditEntry{entry}, *entries)
+				assert.Equal(t, domain.AuditActionDiscardBuffer, entry.Action)
+				assert.Equal(t, tc.expectedDetails, entry.Details)
+			}
+			mockRocketMessageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelAdminUsecase_ResetChannel(t *testing.T) {
+	testCases := []struct {
+		name              string
+		channelError      error
+		recordedIn        string // Store still holding records of a channel not found
+		deleteError       error
+		auditError        error
+		commitError       error
+		expectedDetails   string
+		expectedError     string
+		expectedErrorType error
+		expectedCommitted bool
+		expectedDiscarded bool
+	}{
+		{
+			name:              "reset",
+			expectedDetails:   "deleted the rocket, its telemetry, 12 processed messages, 2 dead letters, 3 conflicts and 4 rejections, discarded buffered messages [15]",
+			expectedCommitted: true,
+			expectedDiscarded: true,
+		},
+		{
+			name:              "unknown_channel",
+			channelError:      domain.ErrChannelNotFound,
+			expectedErrorType: domain.ErrChannelNotFound,
+		},
+		{
+			// A channel whose first message failed has nothing processed
+			name:              "only_dead_letters",
+			channelError:      domain.ErrChannelNotFound,
+			recordedIn:        "dead_letters",
+			expectedDetails:   "deleted the rocket, its telemetry, 12 processed messages, 2 dead letters, 3 conflicts and 4 rejections, discarded buffered messages []",
+			expectedCommitted: true,
+			expectedDiscarded: true,
+		},
+		{
+			name:              "only_conflicts",
+			channelError:      domain.ErrChannelNotFound,
+			recordedIn:        "conflicts",
+			expectedDetails:   "deleted the rocket, its telemetry, 12 processed messages, 2 dead letters, 3 conflicts and 4 rejections, discarded buffered messages []",
+			expectedCommitted: true,
+			expectedDiscarded: true,
+		},
+		{
+			name:              "only_rejections",
+			channelError:      domain.ErrChannelNotFound,
+			recordedIn:        "rejections",
+			expectedDetails:   "deleted the rocket, its telemetry, 12 processed messages, 2 dead letters, 3 conflicts and 4 rejections, discarded buffered messages []",
+			expectedCommitted: true,
+			expectedDiscarded: true,
+		},
+		{
+			name:          "delete_error",
+			deleteError:   errors.New("database error"),
+			expectedError: "failed to delete processed messages: database error",
+		},
+		{
+			// A reset is never left unaudited
+			name:          "audit_error",
+			auditError:    errors.New("database error"),
+			expectedError: "failed to audit reset_channel of channel channel-1: database error",
+		},
+		{
+			name:              "commit_error",
+			commitError:       errors.New("disk full"),
+			expectedError:     "failed to commit transaction: disk full",
+			expectedCommitted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var committed, rolledBack bool
+			tx := &mocks.MockTransaction{
+				CommitFunc: func() error {
+					committed = true
+					return tc.commitError
+				},
+				RollbackFunc: func() error {
+					rolledBack = true
+					return nil
+				},
+			}
+
+			// Every deletion, the event and the audit entry are written in the
+			// transaction
+			inTransaction := func(ctx context.Context) {
+				ctxTx, ok := domain.TransactionFromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, tx, ctxTx)
+			}
+			mockRocketRepo := &mocks.MockRocketRepository{
+				BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
+					return tx, nil
+				},
+				DeleteFunc: func(ctx context.Context, channel string) error {
+					inTransaction(ctx)
+					assert.Equal(t, "channel-1", channel)
+					return nil
+				},
+			}
+			mockTelemetryRepo := &mocks.MockTelemetryRepository{
+				DeleteChannelFunc: func(ctx context.Context, channel string) error {
+					inTransaction(ctx)
+					return nil
+				},
+			}
+			mockMessageRepo := &mocks.MockMessageRepository{
+				DeleteChannelFunc: func(ctx context.Context, channel string) (int64, error) {
+					inTransaction(ctx)
+					if tc.deleteError != nil {
+						return 0, errors.New("failed to delete processed messages: " + tc.deleteError.Error())
+					}
+					return 12, nil
+				},
+			}
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				ListFunc: func(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+					assert.Equal(t, domain.DeadLetterQuery{Channel: "channel-1", Limit: 1}, query)
+					if tc.recordedIn == "dead_letters" {
+						return []*domain.DeadLetter{{Channel: "channel-1", MessageNumber: 1}}, nil
+					}
+					return nil, nil
+				},
+				DeleteMatchingFunc: func(ctx context.Context, query domain.DeadLetterQuery) (int64, error) {
+					inTransaction(ctx)
+					assert.Equal(t, domain.DeadLetterQuery{Channel: "channel-1"}, query)
+					return 2, nil
+				},
+			}
+			mockConflictRepo := &mocks.MockConflictRepository{
+				ListFunc: func(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error) {
+					assert.Equal(t, domain.ConflictQuery{Channel: "channel-1", Limit: 1}, query)
+					if tc.recordedIn == "conflicts" {
+						return []*domain.MessageConflict{{Channel: "channel-1", MessageNumber: 1}}, nil
+					}
+					return nil, nil
+				},
+				DeleteChannelFunc: func(ctx context.Context, channel string) (int64, error) {
+					inTransaction(ctx)
+					return 3, nil
+				},
+			}
+			mockRejectionRepo := &mocks.MockRejectionRepository{
+				ListFunc: func(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error) {
+					assert.Equal(t, domain.RejectionQuery{Channel: "channel-1", Limit: 1}, query)
+					if tc.recordedIn == "rejections" {
+						return []*domain.Rejection{{Channel: "channel-1", MessageNumber: 1}}, nil
+					}
+					return nil, nil
+				},
+				DeleteChannelFunc: func(ctx context.Context, channel string) (int64, error) {
+					inTransaction(ctx)
+					return 4, nil
+				},
+			}
+			var events []*domain.OutboxEvent
+			mockOutboxRepo := &mocks.MockOutboxRepository{
+				AddFunc: func(ctx context.Context, event *domain.OutboxEvent) error {
+					inTransaction(ctx)
+					events = append(events, event)
+					return nil
+				},
+			}
+			auditRepo, entries := savedEntries(tc.auditError)
+			save := auditRepo.SaveFunc
+			auditRepo.SaveFunc = func(ctx context.Context, entry *domain.AuditEntry) error {
+				inTransaction(ctx)
+				return save(ctx, entry)
+			}
+
+			mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+			if tc.channelError != nil {
+				mockRocketMessageUsecase.On("GetChannel", mock.Anything, "channel-1").Return(nil, tc.channelError)
+			} else {
+				mockRocketMessageUsecase.On("GetChannel", mock.Anything, "channel-1").Return(&domain.ChannelStatus{Channel: "channel-1", LastProcessed: 12, Buffered: []int64{15}}, nil)
+			}
+			if tc.expectedDiscarded && tc.channelError == nil {
+				mockRocketMessageUsecase.On("DiscardBuffer", mock.Anything, "channel-1").Return([]int64{15})
+			} else if tc.expectedDiscarded {
+				mockRocketMessageUsecase.On("DiscardBuffer", mock.Anything, "channel-1").Return(nil)
+			}
+
+			useCase := NewChannelAdminUsecase(mockRocketRepo, mockMessageRepo, mockTelemetryRepo, mockDeadLetterRepo, mockConflictRepo, mockRejectionRepo, mockOutboxRepo, auditRepo, mockRocketMessageUsecase, nil)
+
+			entry, err := useCase.ResetChannel(context.Background(), "channel-1", "replay from scratch")
+
+			switch {
+			case tc.expectedErrorType != nil:
+				assert.ErrorIs(t, err, tc.expectedErrorType)
+				assert.Nil(t, entry)
+			case tc.expectedError != "":
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, entry)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, []*domain.AuditEntry{entry}, *entries)
+				assert.Equal(t, domain.AuditActionResetChannel, entry.Action)
+				assert.Equal(t, "replay from scratch", entry.Reason)
+				assert.Equal(t, tc.expectedDetails, entry.Details)
+				if assert.Len(t, events, 1) {
+					assert.Equal(t, domain.EventTypeChannelReset, events[0].EventType)
+					assert.Equal(t, "channel-1", events[0].Channel)
+				}
+			}
+			if tc.auditError != nil {
+				assert.Empty(t, *entries)
+			}
+			assert.Equal(t, tc.expectedCommitted, committed)
+			assert.Equal(t, tc.deleteError != nil || tc.auditError != nil, rolledBack)
+			mockRocketMessageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChannelAdminUsecase_ListAudit(t *testing.T) {
+	entries := []*domain.AuditEntry{{ID: 2, Action: domain.AuditActionSkipGap, Channel: "channel-1"}}
+
+	testCases := []struct {
+		name            string
+		query           domain.AuditQuery
+		repoError       error
+		expectedQuery   domain.AuditQuery
+		expectedEntries []*domain.AuditEntry
+		expectedError   string
+	}{
+		{
+			name:            "default_limit",
+			query:           domain.AuditQuery{Channel: "channel-1"},
+			expectedQuery:   domain.AuditQuery{Channel: "channel-1", Limit: DefaultAuditLimit},
+			expectedEntries: entries,
+		},
+		{
+			name:            "given_limit",
+			query:           domain.AuditQuery{Action: domain.AuditActionSkipGap, Limit: 5},
+			expectedQuery:   domain.AuditQuery{Action: domain.AuditActionSkipGap, Limit: 5},
+			expectedEntries: entries,
+		},
+		{
+			name:          "repository_error",
+			repoError:     errors.New("database error"),
+			expectedQuery: domain.AuditQuery{Limit: DefaultAuditLimit},
+			expectedError: "failed to list audit entries: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			auditRepo := &mocks.MockAuditRepository{
+				ListFunc: func(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEntry, error) {
+					assert.Equal(t, tc.expectedQuery, query)
+					if tc.repoError != nil {
+						return nil, tc.repoError
+					}
+					return entries, nil
+				},
+			}
+
+			useCase := NewChannelAdminUsecase(&mocks.MockRocketRepository{}, &mocks.MockMessageRepository{}, &mocks.MockTelemetryRepository{}, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, &mocks.MockRejectionRepository{}, &mocks.MockOutboxRepository{}, auditRepo, &mocks.MockRocketMessageUsecase{}, nil)
+
+			result, err := useCase.ListAudit(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedEntries, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// DeadLetterUsecase inspects the messages the service gave up on, and sends
+// them again once the cause of their failure is fixed
+type DeadLetterUsecase interface {
+	ListDeadLetters(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error)
+	// ReplayDeadLetter processes a dead letter again. It leaves the store once
+	// processed, and counts one more attempt otherwise.
+	ReplayDeadLetter(ctx context.Context, id int64) (*domain.ReplayResult, error)
+	// ReplayDeadLetters replays the matching dead letters by channel and
+	// message number
+	ReplayDeadLetters(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.ReplayResult, error)
+	DeleteDeadLetter(ctx context.Context, id int64) error
+	// PurgeDeadLetters deletes the matching dead letters, and returns how many
+	// were deleted
+	PurgeDeadLetters(ctx context.Context, query domain.DeadLetterQuery) (int64, error)
+}
+
+// DefaultDeadLetterLimit is the number of dead letters listed or replayed when
+// the query sets no limit
+const DefaultDeadLetterLimit = 100
+
+type deadLetterUsecase struct {
+	deadLetterRepo       domain.DeadLetterRepository
+	rocketMessageUsecase RocketMessageUsecase
+}
+
+func NewDeadLetterUsecase(deadLetterRepo domain.DeadLetterRepository, rocketMessageUsecase RocketMessageUsecase) DeadLetterUsecase {
+	return &deadLetterUsecase{
+		deadLetterRepo:       deadLetterRepo,
+		rocketMessageUsecase: rocketMessageUsecase,
+	}
+}
+
+func (u *deadLetterUsecase) ListDeadLetters(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultDeadLetterLimit
+	}
+
+	deadLetters, err := u.deadLetterRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	log.Printf("Successfully listed %d dead letters", len(deadLetters))
+	return deadLetters, nil
+}
+
+func (u *deadLetterUsecase) ReplayDeadLetter(ctx context.Context, id int64) (*domain.ReplayResult, error) {
+	deadLetter, err := u.deadLetterRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.replay(ctx, deadLetter), nil
+}
+
+// ReplayDeadLetters stops replaying a channel at its first failure, as the
+// messages after it would only be buffered until it is replayed
+func (u *deadLetterUsecase) ReplayDeadLetters(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.ReplayResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultDeadLetterLimit
+	}
+
+	deadLetters, err := u.deadLetterRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	results := make([]*domain.ReplayResult, 0, len(deadLetters))
+	failed := make(map[string]int64)
+	for _, deadLetter := range deadLetters {
+		if number, exists := failed[deadLetter.Channel]; exists {
+			results = append(results, &domain.ReplayResult{
+				ID:            deadLetter.ID,
+				Channel:       deadLetter.Channel,
+				MessageNumber: deadLetter.MessageNumber,
+				Status:        domain.MessageStatusError,
+				Error:         fmt.Sprintf("skipped after message %d failed", number),
+			})
+			continue
+		}
+
+		result := u.replay(ctx, deadLetter)
+		if result.Status == domain.MessageStatusError {
+			failed[deadLetter.Channel] = deadLetter.MessageNumber
+		}
+		results = append(results, result)
+	}
+
+	log.Printf("Replayed %d dead letters", len(results))
+	return results, nil
+}
+
+// replay processes the message of a dead letter again, and deletes the dead
+// letter once the message is applied or found to be a duplicate. A buffered
+// message keeps its dead letter, as the buffer does not outlive a restart or
+// a discard.
+func (u *deadLetterUsecase) replay(ctx context.Context, deadLetter *domain.DeadLetter) *domain.ReplayResult {
+	result := &domain.ReplayResult{
+		ID:            deadLetter.ID,
+		Channel:       deadLetter.Channel,
+		MessageNumber: deadLetter.MessageNumber,
+	}
+
+	var status string
+	var message domain.RocketMessage
+	err := json.Unmarshal(deadLetter.Message, &message)
+	if err != nil {
+		err = fmt.Errorf("failed to decode message: %w", err)
+	} else {
+		status, err = u.rocketMessageUsecase.ApplyMessage(ctx, &message)
+	}
+
+	if err != nil {
+		log.Printf("Failed to replay dead letter %d: %v", deadLetter.ID, err)
+		// A message that could not be applied counted its attempt already
+		if !errors.Is(err, domain.ErrDeadLettered) {
+			u.recordAttempt(ctx, deadLetter, err)
+		}
+		result.Status = domain.MessageStatusError
+		result.Error = err.Error()
+		// The number was processed with another content, so the messages after
+		// it do not wait for this one
+		if errors.Is(err, domain.ErrMessageConflict) {
+			result.Status = domain.ReceiptStatusConflict
+		}
+		return result
+	}
+
+	result.Status = status
+	// A message the full buffer gave up on counted its attempt already, and a
+	// buffered one is only safe once applied, when replaying it again
+	// reports a duplicate
+	if status != domain.ReceiptStatusApplied && status != domain.ReceiptStatusDuplicate {
+		return result
+	}
+
+	if err := u.deadLetterRepo.Delete(ctx, deadLetter.ID); err != nil && !errors.Is(err, domain.ErrDeadLetterNotFound) {
+		// Replaying it again reports a duplicate and deletes it
+		log.Printf("Error deleting replayed dead letter %d: %v", deadLetter.ID, err)
+	}
+
+	log.Printf("Replayed dead letter %d as %s", deadLetter.ID, status)
+	return result
+}
+
+// recordAttempt counts a failed replay of a dead letter
+func (u *deadLetterUsecase) recordAttempt(ctx context.Context, deadLetter *domain.DeadLetter, err error) {
+	attempt := &domain.DeadLetter{
+		Channel:       deadLetter.Channel,
+		MessageNumber: deadLetter.MessageNumber,
+		MessageType:   deadLetter.MessageType,
+		Message:       deadLetter.Message,
+		Reason:        err.Error(),
+		CreatedAt:     time.Now(),
+	}
+	if err := u.deadLetterRepo.Save(ctx, attempt); err != nil {
+		log.Printf("Error recording replay of dead letter %d: %v", deadLetter.ID, err)
+	}
+}
+
+func (u *deadLetterUsecase) DeleteDeadLetter(ctx context.Context, id int64) error {
+	if err := u.deadLetterRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	log.Printf("Deleted dead letter %d", id)
+	return nil
+}
+
+func (u *deadLetterUsecase) PurgeDeadLetters(ctx context.Context, query domain.DeadLetterQuery) (int64, error) {
+	deleted, err := u.deadLetterRepo.DeleteMatching(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+
+	log.Printf("Purged %d dead letters", deleted)
+	return deleted, nil
+}
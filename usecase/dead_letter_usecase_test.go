@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/test/helper"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testDeadLetter returns a dead letter of a message of channel-1
+func testDeadLetter(t *testing.T, id int64, number int64) *domain.DeadLetter {
+	message := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, number, time.Now())
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	return &domain.DeadLetter{
+		ID:            id,
+		Channel:       "channel-1",
+		MessageNumber: number,
+		MessageType:   domain.TypeRocketSpeedIncreased,
+		Message:       data,
+		Reason:        "database is locked",
+		Attempts:      1,
+	}
+}
+
+func TestDeadLetterUsecase_ReplayDeadLetter(t *testing.T) {
+	testCases := []struct {
+		name            string
+		findError       error
+		applyStatus     string
+		applyError      error
+		expectedResult  *domain.ReplayResult
+		expectedError   error
+		expectDelete    bool
+		expectedAttempt string // Reason of the attempt recorded by the replay
+	}{
+		{
+			name:           "applied",
+			applyStatus:    domain.ReceiptStatusApplied,
+			expectedResult: &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusApplied},
+			expectDelete:   true,
+		},
+		{
+			name:           "duplicate",
+			applyStatus:    domain.ReceiptStatusDuplicate,
+			expectedResult: &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusDuplicate},
+			expectDelete:   true,
+		},
+		{
+			// The buffer is lost on a restart, so the dead letter is kept
+			name:           "buffered",
+			applyStatus:    domain.ReceiptStatusBuffered,
+			expectedResult: &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusBuffered},
+		},
+		{
+			name:           "dead_lettered_by_the_buffer",
+			applyStatus:    domain.ReceiptStatusDeadLettered,
+			expectedResult: &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusDeadLettered},
+		},
+		{
+			name:           "failed_again",
+			applyError:     fmt.Errorf("failed to execute rocket state usecase: database is locked (%w)", domain.ErrDeadLettered),
+			expectedResult: &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.MessageStatusError, Error: "failed to execute rocket state usecase: database is locked (message dead-lettered)"},
+		},
+		{
+			name:            "refused",
+			applyError:      domain.ErrChannelBufferFull,
+			expectedResult:  &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.MessageStatusError, Error: "channel message buffer full"},
+			expectedAttempt: "channel message buffer full",
+		},
+		{
+			name:            "conflict",
+			applyError:      fmt.Errorf("message 2 of channel channel-1: %w", domain.ErrMessageConflict),
+			expectedResult:  &domain.ReplayResult{ID: 3, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusConflict, Error: "message 2 of channel channel-1: message conflicts with the processed one"},
+			expectedAttempt: "message 2 of channel channel-1: message conflicts with the processed one",
+		},
+		{
+			name:          "not_found",
+			findError:     domain.ErrDeadLetterNotFound,
+			expectedError: domain.ErrDeadLetterNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			deadLetter := testDeadLetter(t, 3, 2)
+			var deleted []int64
+			var attempts []*domain.DeadLetter
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				FindByIDFunc: func(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+					assert.Equal(t, int64(3), id)
+					if tc.findError != nil {
+						return nil, tc.findError
+					}
+					return deadLetter, nil
+				},
+				DeleteFunc: func(ctx context.Context, id int64) error {
+					deleted = append(deleted, id)
+					return nil
+				},
+				SaveFunc: func(ctx context.Context, attempt *domain.DeadLetter) error {
+					attempts = append(attempts, attempt)
+					return nil
+				},
+			}
+
+			mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+			if tc.findError == nil {
+				mockRocketMessageUsecase.On("ApplyMessage", mock.Anything, mock.MatchedBy(func(message *domain.RocketMessage) bool {
+					return message.Metadata.Channel == "channel-1" && message.Metadata.MessageNumber == 2
+				})).Return(tc.applyStatus, tc.applyError)
+			}
+
+			useCase := NewDeadLetterUsecase(mockDeadLetterRepo, mockRocketMessageUsecase)
+
+			result, err := useCase.ReplayDeadLetter(context.Background(), 3)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedResult, result)
+			if tc.expectDelete {
+				assert.Equal(t, []int64{3}, deleted)
+			} else {
+				assert.Empty(t, deleted)
+			}
+			if tc.expectedAttempt != "" {
+				if assert.Len(t, attempts, 1) {
+					assert.Equal(t, int64(2), attempts[0].MessageNumber)
+					assert.Equal(t, tc.expectedAttempt, attempts[0].Reason)
+					assert.Equal(t, deadLetter.Message, attempts[0].Message)
+				}
+			} else {
+				assert.Empty(t, attempts)
+			}
+			mockRocketMessageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeadLetterUsecase_ReplayDeadLetters(t *testing.T) {
+	deadLetters := []*domain.DeadLetter{
+		testDeadLetter(t, 1, 2),
+		testDeadLetter(t, 2, 3),
+		testDeadLetter(t, 4, 4),
+	}
+
+	var query domain.DeadLetterQuery
+	var deleted []int64
+	mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+		ListFunc: func(ctx context.Context, q domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+			query = q
+			return deadLetters, nil
+		},
+		DeleteFunc: func(ctx context.Context, id int64) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+
+	// Message 3 fails again, so message 4 is left for a later replay
+	mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+	mockRocketMessageUsecase.On("ApplyMessage", mock.Anything, mock.MatchedBy(func(message *domain.RocketMessage) bool {
+		return message.Metadata.MessageNumber == 2
+	})).Return(domain.ReceiptStatusApplied, nil)
+	mockRocketMessageUsecase.On("ApplyMessage", mock.Anything, mock.MatchedBy(func(message *domain.RocketMessage) bool {
+		return message.Metadata.MessageNumber == 3
+	})).Return("", fmt.Errorf("failed to execute rocket state usecase: boom (%w)", domain.ErrDeadLettered))
+
+	useCase := NewDeadLetterUsecase(mockDeadLetterRepo, mockRocketMessageUsecase)
+
+	results, err := useCase.ReplayDeadLetters(context.Background(), domain.DeadLetterQuery{Channel: "channel-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeadLetterQuery{Channel: "channel-1", Limit: DefaultDeadLetterLimit}, query)
+	assert.Equal(t, []*domain.ReplayResult{
+		{ID: 1, Channel: "channel-1", MessageNumber: 2, Status: domain.ReceiptStatusApplied},
+		{ID: 2, Channel: "channel-1", MessageNumber: 3, Status: domain.MessageStatusError, Error: "failed to execute rocket state usecase: boom (message dead-lettered)"},
+		{ID: 4, Channel: "channel-1", MessageNumber: 4, Status: domain.MessageStatusError, Error: "skipped after message 3 failed"},
+	}, results)
+	assert.Equal(t, []int64{1}, deleted)
+	mockRocketMessageUsecase.AssertExpectations(t)
+}
+
+func TestDeadLetterUsecase_ListAndPurge(t *testing.T) {
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+		ListFunc: func(ctx context.Context, query domain.DeadLetterQuery) ([]*domain.DeadLetter, error) {
+			assert.Equal(t, domain.DeadLetterQuery{Limit: DefaultDeadLetterLimit}, query)
+			return []*domain.DeadLetter{testDeadLetter(t, 1, 2)}, nil
+		},
+		DeleteMatchingFunc: func(ctx context.Context, query domain.DeadLetterQuery) (int64, error) {
+			if query.Channel == "broken" {
+				return 0, errors.New("database error")
+			}
+			assert.Equal(t, domain.DeadLetterQuery{Channel: "channel-1", Before: before}, query)
+			return 4, nil
+		},
+		DeleteFunc: func(ctx context.Context, id int64) error {
+			return domain.ErrDeadLetterNotFound
+		},
+	}
+
+	useCase := NewDeadLetterUsecase(mockDeadLetterRepo, &mocks.MockRocketMessageUsecase{})
+	ctx := context.Background()
+
+	deadLetters, err := useCase.ListDeadLetters(ctx, domain.DeadLetterQuery{})
+	require.NoError(t, err)
+	assert.Len(t, deadLetters, 1)
+
+	deleted, err := useCase.PurgeDeadLetters(ctx, domain.DeadLetterQuery{Channel: "channel-1", Before: before})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+
+	_, err = useCase.PurgeDeadLetters(ctx, domain.DeadLetterQuery{Channel: "broken"})
+	assert.EqualError(t, err, "failed to purge dead letters: database error")
+
+	assert.ErrorIs(t, useCase.DeleteDeadLetter(ctx, 9), domain.ErrDeadLetterNotFound)
+}
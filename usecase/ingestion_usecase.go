@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/retry"
+)
+
+// IngestionUsecase accepts messages into a durable queue, and processes the
+// queue in the background
+type IngestionUsecase interface {
+	// EnqueueMessage validates a message and queues it, returning the receipt
+	// to follow it with
+	EnqueueMessage(ctx context.Context, message *domain.RocketMessage) (*domain.Receipt, error)
+	GetReceipt(ctx context.Context, id string) (*domain.Receipt, error)
+	// Start processes the queue with a pool of workers until the context is
+	// cancelled
+	Start(ctx context.Context) error
+}
+
+// IngestionConfig tunes the workers processing the queue and how long the
+// receipts of processed messages are kept
+type IngestionConfig struct {
+	Workers   int
+	BatchSize int
+	Interval  time.Duration // How often the queue is polled when idle
+	Retention time.Duration
+	// Requeues of the messages failing with a retryable error, nil to fail
+	// them at once
+	Retry *retry.Policy
+}
+
+// DefaultIngestionConfig returns the configuration used when none is provided
+func DefaultIngestionConfig() IngestionConfig {
+	policy, _ := retry.NewPolicy(10, time.Second, time.Minute, IsTransientIngestionError) // Valid constants
+	return IngestionConfig{
+		Workers:   4,
+		BatchSize: 100,
+		Interval:  time.Second,
+		Retention: 24 * time.Hour,
+		Retry:     policy,
+	}
+}
+
+// IsTransientIngestionError reports whether a queued message failed for a
+// reason that goes away by itself, a busy database or a full buffer, so that
+// it is worth processing it again later
+func IsTransientIngestionError(err error) bool {
+	return errors.Is(err, domain.ErrBusy) || errors.Is(err, domain.ErrChannelBufferFull) || errors.Is(err, domain.ErrBufferFull)
+}
+
+// queuedMessage is a receipt dispatched to a worker, and how many times its
+// message was attempted before
+type queuedMessage struct {
+	receipt  *domain.Receipt
+	attempts int
+}
+
+type ingestionUsecase struct {
+	receiptRepo          domain.ReceiptRepository
+	rocketMessageUsecase RocketMessageUsecase
+	handlers             *domain.MessageRegistry
+	cfg                  IngestionConfig
+	// Wakes the dispatcher up when a message is queued
+	queued chan struct{}
+}
+
+func NewIngestionUsecase(receiptRepo domain.ReceiptRepository, rocketMessageUsecase RocketMessageUsecase, handlers *domain.MessageRegistry, cfg IngestionConfig) IngestionUsecase {
+	return &ingestionUsecase{
+		receiptRepo:          receiptRepo,
+		rocketMessageUsecase: rocketMessageUsecase,
+		handlers:             handlers,
+		cfg:                  cfg,
+		queued:               make(chan struct{}, 1),
+	}
+}
+
+func (u *ingestionUsecase) EnqueueMessage(ctx context.Context, message *domain.RocketMessage) (*domain.Receipt, error) {
+	// Invalid messages are refused right away rather than failed later
+	if err := u.handlers.Validate(message); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	id, err := newReceiptID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	receipt := &domain.Receipt{
+		ID:            id,
+		Channel:       message.Metadata.Channel,
+		MessageNumber: message.Metadata.MessageNumber,
+		MessageType:   message.Metadata.MessageType,
+		Status:        domain.ReceiptStatusQueued,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Actor:         domain.ActorFromContext(ctx),
+		Message:       data,
+	}
+	if err := u.receiptRepo.Save(ctx, receipt); err != nil {
+		return nil, err
+	}
+
+	select {
+	case u.queued <- struct{}{}:
+	default:
+	}
+
+	log.Printf("Queued message %d for channel %s as %s", receipt.MessageNumber, receipt.Channel, receipt.ID)
+	return receipt, nil
+}
+
+// GetReceipt returns a receipt as last recorded. The receipt of a buffered
+// message is updated when the message leaves the buffer.
+func (u *ingestionUsecase) GetReceipt(ctx context.Context, id string) (*domain.Receipt, error) {
+	return u.receiptRepo.FindByID(ctx, id)
+}
+
+// Start dispatches the queued messages to the workers in queue order. The
+// messages of a channel always go to the same worker, so they are processed
+// in the order they were received, while channels are processed in parallel.
+// A message failing with a transient error is dispatched again after a
+// backoff, behind the messages received since, which may be the ones its
+// channel waits for.
+func (u *ingestionUsecase) Start(ctx context.Context) error {
+	retries := make(chan *queuedMessage)
+	workers := make([]chan *queuedMessage, u.cfg.Workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan *queuedMessage, u.cfg.BatchSize)
+		wg.Add(1)
+		go func(queued <-chan *queuedMessage) {
+			defer wg.Done()
+			for message := range queued {
+				if delay, again := u.process(ctx, message); again {
+					u.requeue(ctx, retries, message, delay)
+				}
+			}
+		}(workers[i])
+	}
+
+	defer func() {
+		for _, receipts := range workers {
+			close(receipts)
+		}
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(u.cfg.Interval)
+	defer ticker.Stop()
+
+	// Receipts up to lastSeq are dispatched, and stay queued until their
+	// worker is done with them
+	var lastSeq int64
+	for {
+		for {
+			receipts, err := u.receiptRepo.FetchQueued(ctx, lastSeq, u.cfg.BatchSize)
+			if err != nil {
+				log.Printf("Ingestion queue failed: %v", err)
+				break
+			}
+
+			for _, receipt := range receipts {
+				select {
+				case workers[workerFor(receipt, len(workers))] <- &queuedMessage{receipt: receipt}:
+					lastSeq = receipt.Seq
+				case <-ctx.Done():
+					return nil
+				}
+			}
+
+			if len(receipts) < u.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			u.cleanup(ctx)
+		case <-u.queued:
+		case message := <-retries:
+			select {
+			case workers[workerFor(message.receipt, len(workers))] <- message:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// requeue hands a message back to the dispatcher once the delay has passed.
+// Its receipt stays queued, so that a stop before then leaves it to the next
+// start.
+func (u *ingestionUsecase) requeue(ctx context.Context, retries chan<- *queuedMessage, message *queuedMessage, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		select {
+		case retries <- message:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// process applies a queued message and records what became of it, and of a
+// buffered message once it leaves the buffer. Messages left queued by a stop
+// are processed again on the next start. It returns
+// true, with the backoff to wait, when the message failed with a transient
+// error and is to be attempted again.
+func (u *ingestionUsecase) process(ctx context.Context, queued *queuedMessage) (time.Duration, bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	receipt := queued.receipt
+	queued.attempts++
+
+	ctx = domain.ContextWithTenant(ctx, receipt.TenantID)
+	if receipt.Actor != "" {
+		ctx = domain.ContextWithIdentity(ctx, &domain.Identity{Subject: receipt.Actor})
+	}
+
+	// A buffered message may be settled by another message filling the gap,
+	// or by an operator, even before it is recorded as buffered below
+	var settleMu sync.Mutex
+	settled := false
+	settleCtx := context.WithoutCancel(ctx)
+	ctx = domain.ContextWithSettle(ctx, func(status string, reason string) {
+		settleMu.Lock()
+		defer settleMu.Unlock()
+		settled = true
+		if err := u.receiptRepo.UpdateStatus(settleCtx, receipt.ID, status, reason); err != nil {
+			log.Printf("Error updating receipt %s: %v", receipt.ID, err)
+		}
+	})
+
+	var status, reason string
+	var message domain.RocketMessage
+	if err := json.Unmarshal(receipt.Message, &message); err != nil {
+		status, reason = domain.ReceiptStatusFailed, fmt.Sprintf("failed to decode message: %v", err)
+	} else if status, err = u.rocketMessageUsecase.ApplyMessage(ctx, &message); err != nil {
+		status, reason = domain.ReceiptStatusFailed, err.Error()
+		// A message that could not be applied waits in the dead-letter store
+		if errors.Is(err, domain.ErrDeadLettered) {
+			status = domain.ReceiptStatusDeadLettered
+		}
+		if errors.Is(err, domain.ErrMessageConflict) {
+			status = domain.ReceiptStatusConflict
+		}
+
+		// The message was accepted, so it is not given up on while the cause
+		// may go away
+		if u.cfg.Retry != nil && u.cfg.Retry.Retryable(err) {
+			if queued.attempts < u.cfg.Retry.MaxAttempts {
+				delay := u.cfg.Retry.Backoff(queued.attempts)
+				log.Printf("Requeuing message %s in %s after attempt %d: %v", receipt.ID, delay, queued.attempts, err)
+				if err := u.receiptRepo.UpdateStatus(ctx, receipt.ID, domain.ReceiptStatusQueued, reason); err != nil {
+					log.Printf("Error updating receipt %s: %v", receipt.ID, err)
+				}
+				return delay, true
+			}
+			reason = fmt.Sprintf("%s after %d attempts", reason, queued.attempts)
+		}
+	}
+
+	if status == domain.ReceiptStatusFailed {
+		log.Printf("Failed to process queued message %s: %s", receipt.ID, reason)
+	}
+
+	settleMu.Lock()
+	defer settleMu.Unlock()
+	if settled {
+		return 0, false
+	}
+	if err := u.receiptRepo.UpdateStatus(ctx, receipt.ID, status, reason); err != nil {
+		log.Printf("Error updating receipt %s: %v", receipt.ID, err)
+	}
+	return 0, false
+}
+
+// cleanup deletes the receipts processed longer ago than the retention
+func (u *ingestionUsecase) cleanup(ctx context.Context) {
+	deleted, err := u.receiptRepo.DeleteProcessedBefore(ctx, time.Now().Add(-u.cfg.Retention))
+	if err != nil {
+		log.Printf("Receipt cleanup failed: %v", err)
+		return
+	}
+
+	if deleted > 0 {
+		log.Printf("Receipt cleanup removed %d processed receipts", deleted)
+	}
+}
+
+// workerFor picks the worker of a receipt's tenant and channel
+func workerFor(receipt *domain.Receipt, workers int) int {
+	h := fnv.New32a()
+	h.Write([]byte(receipt.TenantID + "/" + receipt.Channel))
+	return int(h.Sum32() % uint32(workers))
+}
+
+func newReceiptID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate receipt ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,441 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+	"lunar-rockets/retry"
+	"lunar-rockets/test/helper"
+	"lunar-rockets/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestionUsecase_EnqueueMessage(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		message       *domain.RocketMessage
+		saveError     error
+		expectedError string
+		expectSave    bool
+	}{
+		{
+			name:       "message_queued",
+			message:    helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, now),
+			expectSave: true,
+		},
+		{
+			name:          "invalid_message",
+			message:       &domain.RocketMessage{Metadata: domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1, MessageTime: now}},
+			expectedError: "invalid message",
+		},
+		{
+			name:          "save_error",
+			message:       helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, now),
+			saveError:     errors.New("failed to save receipt: database error"),
+			expectedError: "failed to save receipt: database error",
+			expectSave:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var saved *domain.Receipt
+			mockReceiptRepo := &mocks.MockReceiptRepository{
+				SaveFunc: func(ctx context.Context, receipt *domain.Receipt) error {
+					saved = receipt
+					return tc.saveError
+				},
+			}
+
+			useCase := NewIngestionUsecase(mockReceiptRepo, &mocks.MockRocketMessageUsecase{}, newMessageRegistry(t), DefaultIngestionConfig())
+			ctx := domain.ContextWithIdentity(context.Background(), &domain.Identity{Subject: "ground-station"})
+
+			receipt, err := useCase.EnqueueMessage(ctx, tc.message)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, receipt)
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, receipt.ID, 32)
+				assert.Equal(t, domain.ReceiptStatusQueued, receipt.Status)
+				assert.Equal(t, "channel-1", receipt.Channel)
+				assert.Equal(t, int64(1), receipt.MessageNumber)
+				assert.Equal(t, "ground-station", receipt.Actor)
+				assert.NotEmpty(t, receipt.Message)
+			}
+			assert.Equal(t, tc.expectSave, saved != nil)
+		})
+	}
+}
+
+func TestIngestionUsecase_GetReceipt(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         string
+		findError      error
+		expectedStatus string
+		expectedError  string
+	}{
+		{name: "applied", status: domain.ReceiptStatusApplied, expectedStatus: domain.ReceiptStatusApplied},
+		{name: "buffered", status: domain.ReceiptStatusBuffered, expectedStatus: domain.ReceiptStatusBuffered},
+		{name: "not_found", findError: domain.ErrReceiptNotFound, expectedError: "receipt not found"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockReceiptRepo := &mocks.MockReceiptRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.Receipt, error) {
+					if tc.findError != nil {
+						return nil, tc.findError
+					}
+					return &domain.Receipt{ID: id, Channel: "channel-1", MessageNumber: 5, Status: tc.status}, nil
+				},
+			}
+			useCase := NewIngestionUsecase(mockReceiptRepo, &mocks.MockRocketMessageUsecase{}, newMessageRegistry(t), DefaultIngestionConfig())
+
+			receipt, err := useCase.GetReceipt(context.Background(), "r-1")
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedStatus, receipt.Status)
+			}
+		})
+	}
+}
+
+func TestIngestionUsecase_Start(t *testing.T) {
+	now := time.Now()
+	messages := map[string]*domain.RocketMessage{
+		"r-1": helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, now),
+		"r-2": helper.CreateTestMessage("channel-2", domain.TypeRocketLaunched, 1, now),
+		"r-3": helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now),
+		"r-4": helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now),
+		"r-5": helper.CreateTestMessage("channel-3", domain.TypeRocketLaunched, 1, now),
+		"r-6": helper.CreateTestMessage("channel-4", domain.TypeRocketLaunched, 1, now),
+	}
+
+	var queued []*domain.Receipt
+	for i, id := range []string{"r-1", "r-2", "r-3", "r-4", "r-5", "r-6"} {
+		data, err := json.Marshal(messages[id])
+		require.NoError(t, err)
+		queued = append(queued, &domain.Receipt{
+			ID:            id,
+			Seq:           int64(i + 1),
+			TenantID:      "tenant-a",
+			Channel:       messages[id].Metadata.Channel,
+			MessageNumber: messages[id].Metadata.MessageNumber,
+			Actor:         "ground-station",
+			Message:       data,
+		})
+	}
+
+	var mu sync.Mutex
+	statuses := make(map[string]string)
+	done := make(chan struct{})
+
+	mockReceiptRepo := &mocks.MockReceiptRepository{
+		FetchQueuedFunc: func(ctx context.Context, afterSeq int64, limit int) ([]*domain.Receipt, error) {
+			var receipts []*domain.Receipt
+			for _, receipt := range queued {
+				if receipt.Seq > afterSeq {
+					receipts = append(receipts, receipt)
+				}
+			}
+			return receipts, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id string, status string, reason string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			statuses[id] = status + reason
+			if len(statuses) == len(queued) {
+				close(done)
+			}
+			return nil
+		},
+		DeleteProcessedBeforeFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			return 0, nil
+		},
+	}
+
+	// Messages are applied in the tenant and as the actor that sent them, and
+	// in order within a channel
+	var order []int64
+	inContext := mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.TenantFromContext(ctx) == "tenant-a" && domain.ActorFromContext(ctx) == "ground-station"
+	})
+	mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+	for _, id := range []string{"r-1", "r-2", "r-3", "r-4", "r-5", "r-6"} {
+		message := messages[id]
+		call := mockRocketMessageUsecase.On("ApplyMessage", inContext, mock.MatchedBy(func(m *domain.RocketMessage) bool {
+			return m.Metadata.Channel == message.Metadata.Channel && m.Metadata.MessageNumber == message.Metadata.MessageNumber
+		}))
+		if message.Metadata.Channel == "channel-1" {
+			call.Run(func(args mock.Arguments) {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, args.Get(1).(*domain.RocketMessage).Metadata.MessageNumber)
+			})
+		}
+		switch id {
+		case "r-2":
+			call.Return("", errors.New("database error")).Once()
+		case "r-4":
+			call.Return(domain.ReceiptStatusDuplicate, nil).Once()
+		case "r-5":
+			call.Return("", fmt.Errorf("database is locked (%w)", domain.ErrDeadLettered)).Once()
+		case "r-6":
+			call.Return("", fmt.Errorf("message 1 of channel channel-4: %w", domain.ErrMessageConflict)).Once()
+		default:
+			call.Return(domain.ReceiptStatusApplied, nil).Once()
+		}
+	}
+
+	cfg := DefaultIngestionConfig()
+	cfg.Workers = 2
+	useCase := NewIngestionUsecase(mockReceiptRepo, mockRocketMessageUsecase, newMessageRegistry(t), cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- useCase.Start(ctx) }()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued messages were not processed")
+	}
+	cancel()
+	require.NoError(t, <-stopped)
+
+	assert.Equal(t, map[string]string{
+		"r-1": domain.ReceiptStatusApplied,
+		"r-2": domain.ReceiptStatusFailed + "database error",
+		"r-3": domain.ReceiptStatusApplied,
+		"r-4": domain.ReceiptStatusDuplicate,
+		"r-5": domain.ReceiptStatusDeadLettered + "database is locked (message dead-lettered)",
+		"r-6": domain.ReceiptStatusConflict + "message 1 of channel channel-4: message conflicts with the processed one",
+	}, statuses)
+	assert.Equal(t, []int64{1, 2, 2}, order)
+	mockRocketMessageUsecase.AssertExpectations(t)
+}
+
+func TestIngestionUsecase_Start_RequeuesTransientFailures(t *testing.T) {
+	message := helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, time.Now())
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	busy := fmt.Errorf("failed to apply message: %w", domain.ErrBusy)
+	bufferFull := fmt.Errorf("message 3 of channel channel-1: %w", domain.ErrChannelBufferFull)
+
+	testCases := []struct {
+		name             string
+		results          []error
+		expectedStatuses []string
+	}{
+		{
+			name:    "busy_then_applied",
+			results: []error{busy, nil},
+			expectedStatuses: []string{
+				domain.ReceiptStatusQueued + busy.Error(),
+				domain.ReceiptStatusApplied,
+			},
+		},
+		{
+			name:    "buffer_full_until_last_attempt",
+			results: []error{bufferFull, bufferFull, bufferFull},
+			expectedStatuses: []string{
+				domain.ReceiptStatusQueued + bufferFull.Error(),
+				domain.ReceiptStatusQueued + bufferFull.Error(),
+				domain.ReceiptStatusFailed + bufferFull.Error() + " after 3 attempts",
+			},
+		},
+		{
+			name:    "other_error_not_requeued",
+			results: []error{errors.New("database error")},
+			expectedStatuses: []string{
+				domain.ReceiptStatusFailed + "database error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			receipt := &domain.Receipt{ID: "r-1", Seq: 1, Channel: "channel-1", MessageNumber: 1, Message: data}
+
+			var mu sync.Mutex
+			var statuses []string
+			done := make(chan struct{})
+
+			mockReceiptRepo := &mocks.MockReceiptRepository{
+				FetchQueuedFunc: func(ctx context.Context, afterSeq int64, limit int) ([]*domain.Receipt, error) {
+					if afterSeq < receipt.Seq {
+						return []*domain.Receipt{receipt}, nil
+					}
+					return nil, nil
+				},
+				UpdateStatusFunc: func(ctx context.Context, id string, status string, reason string) error {
+					mu.Lock()
+					defer mu.Unlock()
+					statuses = append(statuses, status+reason)
+					if status != domain.ReceiptStatusQueued {
+						close(done)
+					}
+					return nil
+				},
+				DeleteProcessedBeforeFunc: func(ctx context.Context, before time.Time) (int64, error) {
+					return 0, nil
+				},
+			}
+
+			mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+			for _, result := range tc.results {
+				if result != nil {
+					mockRocketMessageUsecase.On("ApplyMessage", mock.Anything, mock.Anything).Return("", result).Once()
+				} else {
+					mockRocketMessageUsecase.On("ApplyMessage", mock.Anything, mock.Anything).Return(domain.ReceiptStatusApplied, nil).Once()
+				}
+			}
+
+			cfg := DefaultIngestionConfig()
+			cfg.Workers = 1
+			cfg.Retry, err = retry.NewPolicy(3, time.Millisecond, time.Millisecond, IsTransientIngestionError)
+			require.NoError(t, err)
+			useCase := NewIngestionUsecase(mockReceiptRepo, mockRocketMessageUsecase, newMessageRegistry(t), cfg)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan error)
+			go func() { stopped <- useCase.Start(ctx) }()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("queued message was not processed")
+			}
+			cancel()
+			require.NoError(t, <-stopped)
+
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			mockRocketMessageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIngestionUsecase_Start_SettlesBufferedReceipts(t *testing.T) {
+	message := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 3, time.Now())
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name             string
+		status           string
+		reason           string
+		settledFirst     bool // Settled before the worker records the message as buffered
+		expectedStatuses []string
+	}{
+		{
+			name:             "applied",
+			status:           domain.ReceiptStatusApplied,
+			expectedStatuses: []string{domain.ReceiptStatusBuffered, domain.ReceiptStatusApplied},
+		},
+		{
+			name:             "dead_lettered",
+			status:           domain.ReceiptStatusDeadLettered,
+			reason:           "evicted from the full message buffer",
+			expectedStatuses: []string{domain.ReceiptStatusBuffered, domain.ReceiptStatusDeadLettered + "evicted from the full message buffer"},
+		},
+		{
+			name:             "settled_first",
+			status:           domain.ReceiptStatusDiscarded,
+			reason:           "buffer discarded",
+			settledFirst:     true,
+			expectedStatuses: []string{domain.ReceiptStatusDiscarded + "buffer discarded"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			receipt := &domain.Receipt{ID: "r-1", Seq: 1, Channel: "channel-1", MessageNumber: 3, Message: data}
+
+			var mu sync.Mutex
+			var statuses []string
+			updated := make(chan struct{}, 2)
+
+			mockReceiptRepo := &mocks.MockReceiptRepository{
+				FetchQueuedFunc: func(ctx context.Context, afterSeq int64, limit int) ([]*domain.Receipt, error) {
+					if afterSeq < receipt.Seq {
+						return []*domain.Receipt{receipt}, nil
+					}
+					return nil, nil
+				},
+				UpdateStatusFunc: func(ctx context.Context, id string, status string, reason string) error {
+					mu.Lock()
+					defer mu.Unlock()
+					statuses = append(statuses, status+reason)
+					updated <- struct{}{}
+					return nil
+				},
+				DeleteProcessedBeforeFunc: func(ctx context.Context, before time.Time) (int64, error) {
+					return 0, nil
+				},
+			}
+
+			// The channel tells the receipt's settle function what became of
+			// the buffered message
+			var settle domain.SettleFunc
+			mockRocketMessageUsecase := &mocks.MockRocketMessageUsecase{}
+			mockRocketMessageUsecase.On("ApplyMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				settle = domain.SettleFromContext(args.Get(0).(context.Context))
+				if tc.settledFirst {
+					settle(tc.status, tc.reason)
+				}
+			}).Return(domain.ReceiptStatusBuffered, nil).Once()
+
+			cfg := DefaultIngestionConfig()
+			cfg.Workers = 1
+			useCase := NewIngestionUsecase(mockReceiptRepo, mockRocketMessageUsecase, newMessageRegistry(t), cfg)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan error)
+			go func() { stopped <- useCase.Start(ctx) }()
+
+			select {
+			case <-updated:
+			case <-time.After(5 * time.Second):
+				t.Fatal("queued message was not processed")
+			}
+			if !tc.settledFirst {
+				require.NotNil(t, settle)
+				settle(tc.status, tc.reason)
+			}
+			cancel()
+			require.NoError(t, <-stopped)
+
+			assert.Equal(t, tc.expectedStatuses, statuses)
+		})
+	}
+}
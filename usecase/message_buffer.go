@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lunar-rockets/domain"
+)
+
+// BufferPolicy decides what happens to an out-of-order message that does not
+// fit in the buffer
+type BufferPolicy string
+
+const (
+	// BufferPolicyReject refuses the message, for its producer to send it
+	// again later
+	BufferPolicyReject BufferPolicy = "reject"
+	// BufferPolicyEvictOldest dead-letters the messages buffered first until
+	// the message fits
+	BufferPolicyEvictOldest BufferPolicy = "evict-oldest"
+	// BufferPolicyEvictNewest dead-letters the message itself
+	BufferPolicyEvictNewest BufferPolicy = "evict-newest"
+)
+
+// BufferLimits caps the out-of-order messages held in memory, in number and in
+// size, for every channel of a tenant together and for each channel. A zero
+// cap disables it.
+type BufferLimits struct {
+	MaxMessages        int
+	MaxBytes           int64
+	MaxChannelMessages int
+	MaxChannelBytes    int64
+	Policy             BufferPolicy
+}
+
+// DefaultBufferLimits returns limits that keep the buffer within a few tens of
+// megabytes and reject the messages over them
+func DefaultBufferLimits() BufferLimits {
+	return BufferLimits{
+		MaxMessages:        10000,
+		MaxBytes:           64 << 20,
+		MaxChannelMessages: 1000,
+		MaxChannelBytes:    8 << 20,
+		Policy:             BufferPolicyReject,
+	}
+}
+
+// Validate checks that the caps are not negative and the policy is known
+func (l BufferLimits) Validate() error {
+	if l.MaxMessages < 0 || l.MaxBytes < 0 || l.MaxChannelMessages < 0 || l.MaxChannelBytes < 0 {
+		return errors.New("buffer limits must not be negative")
+	}
+
+	switch l.Policy {
+	case BufferPolicyReject, BufferPolicyEvictOldest, BufferPolicyEvictNewest:
+		return nil
+	default:
+		return fmt.Errorf("unknown buffer policy %q", l.Policy)
+	}
+}
+
+// messageBuffer holds the out-of-order messages of every channel, and keeps
+// count of their number and size for each tenant. It is not safe for
+// concurrent use.
+type messageBuffer struct {
+	limits   BufferLimits
+	channels map[bufferKey]*channelBuffer
+	tenants  map[string]*tenantBuffer
+	// Sequence of the last buffered message, to find the oldest ones
+	seq int64
+}
+
+type channelBuffer struct {
+	messages map[int64]*bufferedMessage
+	bytes    int64
+}
+
+// tenantBuffer counts the buffered messages of every channel of a tenant
+type tenantBuffer struct {
+	messages int
+	bytes    int64
+}
+
+type bufferedMessage struct {
+	message    *domain.RocketMessage
+	data       []byte // Message as JSON, kept for dead letters
+	seq        int64
+	receivedAt time.Time
+	// Told what became of the message, for each copy of it that was buffered
+	settle []domain.SettleFunc
+}
+
+// bufferedChannel is a copy of what the buffer of a channel holds
+type bufferedChannel struct {
+	numbers []int64   // Sorted message numbers
+	oldest  time.Time // When the oldest buffered message was received
+	newest  time.Time // When the newest buffered message was received
+}
+
+func newMessageBuffer(limits BufferLimits) *messageBuffer {
+	return &messageBuffer{
+		limits:   limits,
+		channels: make(map[bufferKey]*channelBuffer),
+		tenants:  make(map[string]*tenantBuffer),
+	}
+}
+
+// get returns a buffered message, or nil
+func (b *messageBuffer) get(key bufferKey, number int64) *bufferedMessage {
+	channel, exists := b.channels[key]
+	if !exists {
+		return nil
+	}
+	return channel.messages[number]
+}
+
+// add buffers a message received at receivedAt, whether it fits or not
+func (b *messageBuffer) add(key bufferKey, message *domain.RocketMessage, data []byte, receivedAt time.Time, settle []domain.SettleFunc) {
+	b.seq++
+	b.put(key, &bufferedMessage{message: message, data: data, seq: b.seq, receivedAt: receivedAt, settle: settle})
+}
+
+// put buffers a message as it is, such as one removed before
+func (b *messageBuffer) put(key bufferKey, buffered *bufferedMessage) {
+	channel, exists := b.channels[key]
+	if !exists {
+		channel = &channelBuffer{messages: make(map[int64]*bufferedMessage)}
+		b.channels[key] = channel
+	}
+
+	tenant, exists := b.tenants[key.tenantID]
+	if !exists {
+		tenant = &tenantBuffer{}
+		b.tenants[key.tenantID] = tenant
+	}
+
+	channel.messages[buffered.message.Metadata.MessageNumber] = buffered
+	channel.bytes += int64(len(buffered.data))
+	tenant.messages++
+	tenant.bytes += int64(len(buffered.data))
+}
+
+// remove drops a buffered message, if any
+func (b *messageBuffer) remove(key bufferKey, number int64) {
+	channel, exists := b.channels[key]
+	if !exists {
+		return
+	}
+	buffered, exists := channel.messages[number]
+	if !exists {
+		return
+	}
+
+	delete(channel.messages, number)
+	channel.bytes -= int64(len(buffered.data))
+	tenant := b.tenants[key.tenantID]
+	tenant.messages--
+	tenant.bytes -= int64(len(buffered.data))
+
+	if len(channel.messages) == 0 {
+		delete(b.channels, key)
+	}
+	if tenant.messages == 0 {
+		delete(b.tenants, key.tenantID)
+	}
+}
+
+// tooLarge reports whether a message of size bytes would not fit even in an
+// empty buffer
+func (b *messageBuffer) tooLarge(size int64) bool {
+	return (b.limits.MaxBytes > 0 && size > b.limits.MaxBytes) ||
+		(b.limits.MaxChannelBytes > 0 && size > b.limits.MaxChannelBytes)
+}
+
+// full reports whether a message of size bytes does not fit in the buffer of
+// every channel of a tenant together
+func (b *messageBuffer) full(tenantID string, size int64) bool {
+	tenant, exists := b.tenants[tenantID]
+	if !exists {
+		return false
+	}
+	return (b.limits.MaxMessages > 0 && tenant.messages+1 > b.limits.MaxMessages) ||
+		(b.limits.MaxBytes > 0 && tenant.bytes+size > b.limits.MaxBytes)
+}
+
+// channelFull reports whether a message of size bytes does not fit in the
+// buffer of its channel
+func (b *messageBuffer) channelFull(key bufferKey, size int64) bool {
+	channel, exists := b.channels[key]
+	if !exists {
+		return false
+	}
+	return (b.limits.MaxChannelMessages > 0 && len(channel.messages)+1 > b.limits.MaxChannelMessages) ||
+		(b.limits.MaxChannelBytes > 0 && channel.bytes+size > b.limits.MaxChannelBytes)
+}
+
+// oldest returns the message buffered first in a channel of a tenant, or in
+// every channel of the tenant when channel is empty
+func (b *messageBuffer) oldest(tenantID string, channel string) (bufferKey, *bufferedMessage) {
+	var oldestKey bufferKey
+	var oldest *bufferedMessage
+
+	for key, buffer := range b.channels {
+		if key.tenantID != tenantID || (channel != "" && key.channel != channel) {
+			continue
+		}
+		for _, buffered := range buffer.messages {
+			if oldest == nil || buffered.seq < oldest.seq {
+				oldestKey, oldest = key, buffered
+			}
+		}
+	}
+
+	return oldestKey, oldest
+}
+
+// settled tells the senders of a message that left the buffer what became of
+// it. It must be called with the buffer unlocked, as they may take their time.
+func (m *bufferedMessage) settled(status string, reason string) {
+	for _, settle := range m.settle {
+		settle(status, reason)
+	}
+}
+
+// settlement is what became of a message that left the buffer, for its
+// senders to be told once the buffer is unlocked
+type settlement struct {
+	buffered *bufferedMessage
+	status   string
+	reason   string
+}
+
+// notifySettled tells the senders of the messages that left the buffer what
+// became of them
+func notifySettled(settlements []settlement) {
+	for _, s := range settlements {
+		s.buffered.settled(s.status, s.reason)
+	}
+}
+
+// snapshot returns a copy of what the buffer of a channel holds, or nil when
+// it holds nothing
+func (b *messageBuffer) snapshot(key bufferKey) *bufferedChannel {
+	channel, exists := b.channels[key]
+	if !exists {
+		return nil
+	}
+
+	snapshot := &bufferedChannel{numbers: make([]int64, 0, len(channel.messages))}
+	for number, buffered := range channel.messages {
+		snapshot.numbers = append(snapshot.numbers, number)
+		if snapshot.oldest.IsZero() || buffered.receivedAt.Before(snapshot.oldest) {
+			snapshot.oldest = buffered.receivedAt
+		}
+		if buffered.receivedAt.After(snapshot.newest) {
+			snapshot.newest = buffered.receivedAt
+		}
+	}
+	sort.Slice(snapshot.numbers, func(i, j int) bool { return snapshot.numbers[i] < snapshot.numbers[j] })
+
+	return snapshot
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"lunar-rockets/domain"
+)
+
+// MessageHandlers returns the handlers of the built-in message types
+func MessageHandlers() []domain.MessageHandler {
+	return []domain.MessageHandler{
+		rocketLaunchedHandler{},
+		rocketSpeedIncreasedHandler{},
+		rocketSpeedDecreasedHandler{},
+		rocketExplodedHandler{},
+		rocketMissionChangedHandler{},
+		rocketLandedHandler{},
+		rocketAbortedHandler{},
+		rocketDockedHandler{},
+		rocketTelemetryHandler{},
+	}
+}
+
+// MessageUpcasters returns the upcasters of the built-in message types. Every
+// built-in type is still at its first schema version, so there are none yet;
+// changing the shape of a payload means adding the upcaster from the previous
+// version here.
+func MessageUpcasters() []domain.Upcaster {
+	return nil
+}
+
+type rocketLaunchedHandler struct{}
+
+func (rocketLaunchedHandler) MessageType() string { return domain.TypeRocketLaunched }
+
+func (rocketLaunchedHandler) NewPayload() interface{} { return &domain.RocketLaunchedMessage{} }
+
+func (rocketLaunchedHandler) Validate(payload interface{}) []domain.FieldError {
+	launch := payload.(*domain.RocketLaunchedMessage)
+
+	var fields []domain.FieldError
+	fields = appendIfEmpty(fields, "type", launch.Type)
+	if launch.LaunchSpeed <= 0 {
+		fields = append(fields, domain.FieldError{Field: "launchSpeed", Message: "must be greater than zero"})
+	}
+	return appendIfEmpty(fields, "mission", launch.Mission)
+}
+
+// Apply creates the rocket
+func (rocketLaunchedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	launch := message.Payload.(*domain.RocketLaunchedMessage)
+	return &domain.Rocket{
+		Channel:    message.Metadata.Channel,
+		Type:       launch.Type,
+		Speed:      launch.LaunchSpeed,
+		Mission:    launch.Mission,
+		LaunchTime: message.Metadata.MessageTime,
+	}, nil
+}
+
+type rocketSpeedIncreasedHandler struct{}
+
+func (rocketSpeedIncreasedHandler) MessageType() string { return domain.TypeRocketSpeedIncreased }
+
+func (rocketSpeedIncreasedHandler) NewPayload() interface{} {
+	return &domain.RocketSpeedIncreasedMessage{}
+}
+
+func (rocketSpeedIncreasedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfNotPositive(nil, "by", payload.(*domain.RocketSpeedIncreasedMessage).By)
+}
+
+func (rocketSpeedIncreasedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	rocket.Speed += message.Payload.(*domain.RocketSpeedIncreasedMessage).By
+	if rocket.Speed < 0 {
+		rocket.Speed = 0
+	}
+	return rocket, nil
+}
+
+type rocketSpeedDecreasedHandler struct{}
+
+func (rocketSpeedDecreasedHandler) MessageType() string { return domain.TypeRocketSpeedDecreased }
+
+func (rocketSpeedDecreasedHandler) NewPayload() interface{} {
+	return &domain.RocketSpeedDecreasedMessage{}
+}
+
+func (rocketSpeedDecreasedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfNotPositive(nil, "by", payload.(*domain.RocketSpeedDecreasedMessage).By)
+}
+
+// Apply slows the rocket down, stopping it if the decrease exceeds its speed
+func (rocketSpeedDecreasedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	by := message.Payload.(*domain.RocketSpeedDecreasedMessage).By
+	if by > rocket.Speed {
+		rocket.Speed = 0
+	} else {
+		rocket.Speed -= by
+	}
+	return rocket, nil
+}
+
+type rocketExplodedHandler struct{}
+
+func (rocketExplodedHandler) MessageType() string { return domain.TypeRocketExploded }
+
+func (rocketExplodedHandler) NewPayload() interface{} { return &domain.RocketExplodedMessage{} }
+
+func (rocketExplodedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfEmpty(nil, "reason", payload.(*domain.RocketExplodedMessage).Reason)
+}
+
+func (rocketExplodedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	explodedAt := message.Metadata.MessageTime
+	rocket.Reason = message.Payload.(*domain.RocketExplodedMessage).Reason
+	rocket.ExplodedAt = &explodedAt
+	return rocket, nil
+}
+
+type rocketMissionChangedHandler struct{}
+
+func (rocketMissionChangedHandler) MessageType() string { return domain.TypeRocketMissionChanged }
+
+func (rocketMissionChangedHandler) NewPayload() interface{} {
+	return &domain.RocketMissionChangedMessage{}
+}
+
+func (rocketMissionChangedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfEmpty(nil, "newMission", payload.(*domain.RocketMissionChangedMessage).NewMission)
+}
+
+func (rocketMissionChangedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	rocket.Mission = message.Payload.(*domain.RocketMissionChangedMessage).NewMission
+	return rocket, nil
+}
+
+type rocketLandedHandler struct{}
+
+func (rocketLandedHandler) MessageType() string { return domain.TypeRocketLanded }
+
+func (rocketLandedHandler) NewPayload() interface{} { return &domain.RocketLandedMessage{} }
+
+func (rocketLandedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfEmpty(nil, "site", payload.(*domain.RocketLandedMessage).Site)
+}
+
+// Apply puts the rocket on the ground, stopping it
+func (rocketLandedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	landedAt := message.Metadata.MessageTime
+	rocket.Speed = 0
+	rocket.LandingSite = message.Payload.(*domain.RocketLandedMessage).Site
+	rocket.LandedAt = &landedAt
+	return rocket, nil
+}
+
+type rocketAbortedHandler struct{}
+
+func (rocketAbortedHandler) MessageType() string { return domain.TypeRocketAborted }
+
+func (rocketAbortedHandler) NewPayload() interface{} { return &domain.RocketAbortedMessage{} }
+
+func (rocketAbortedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfEmpty(nil, "reason", payload.(*domain.RocketAbortedMessage).Reason)
+}
+
+func (rocketAbortedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	abortedAt := message.Metadata.MessageTime
+	rocket.Reason = message.Payload.(*domain.RocketAbortedMessage).Reason
+	rocket.AbortedAt = &abortedAt
+	return rocket, nil
+}
+
+type rocketDockedHandler struct{}
+
+func (rocketDockedHandler) MessageType() string { return domain.TypeRocketDocked }
+
+func (rocketDockedHandler) NewPayload() interface{} { return &domain.RocketDockedMessage{} }
+
+func (rocketDockedHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfEmpty(nil, "station", payload.(*domain.RocketDockedMessage).Station)
+}
+
+func (rocketDockedHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	dockedAt := message.Metadata.MessageTime
+	rocket.Station = message.Payload.(*domain.RocketDockedMessage).Station
+	rocket.DockedAt = &dockedAt
+	return rocket, nil
+}
+
+type rocketTelemetryHandler struct{}
+
+func (rocketTelemetryHandler) MessageType() string { return domain.TypeRocketTelemetry }
+
+func (rocketTelemetryHandler) NewPayload() interface{} { return &domain.RocketTelemetryMessage{} }
+
+// Validate rejects the readings no instrument can take
+func (rocketTelemetryHandler) Validate(payload interface{}) []domain.FieldError {
+	telemetry := payload.(*domain.RocketTelemetryMessage)
+
+	var fields []domain.FieldError
+	fields = appendIfOutOfRange(fields, "altitude", telemetry.Altitude, 0, math.Inf(1))
+	fields = appendIfOutOfRange(fields, "latitude", telemetry.Latitude, -90, 90)
+	fields = appendIfOutOfRange(fields, "longitude", telemetry.Longitude, -180, 180)
+	fields = appendIfOutOfRange(fields, "fuel", telemetry.Fuel, 0, 100)
+	return appendIfOutOfRange(fields, "heading", telemetry.Heading, 0, 360)
+}
+
+// Apply stores the reading as the latest telemetry of the rocket
+func (rocketTelemetryHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	telemetry := message.Payload.(*domain.RocketTelemetryMessage)
+	rocket.Telemetry = &domain.Telemetry{
+		Time:      message.Metadata.MessageTime,
+		Altitude:  *telemetry.Altitude,
+		Latitude:  *telemetry.Latitude,
+		Longitude: *telemetry.Longitude,
+		Fuel:      *telemetry.Fuel,
+		Heading:   *telemetry.Heading,
+	}
+	return rocket, nil
+}
+
+func appendIfEmpty(fields []domain.FieldError, name string, value string) []domain.FieldError {
+	if strings.TrimSpace(value) == "" {
+		return append(fields, domain.FieldError{Field: name, Message: "must not be empty"})
+	}
+	return fields
+}
+
+// appendIfOutOfRange reports a missing value, or one outside [min, max]. An
+// infinite max leaves the range open.
+func appendIfOutOfRange(fields []domain.FieldError, name string, value *float64, min float64, max float64) []domain.FieldError {
+	switch {
+	case value == nil:
+		return append(fields, domain.FieldError{Field: name, Message: "is required"})
+	case math.IsInf(max, 1) && *value < min:
+		return append(fields, domain.FieldError{Field: name, Message: fmt.Sprintf("must not be less than %g", min)})
+	case *value < min || *value > max:
+		return append(fields, domain.FieldError{Field: name, Message: fmt.Sprintf("must be between %g and %g", min, max)})
+	}
+	return fields
+}
+
+func appendIfNotPositive(fields []domain.FieldError, name string, value int) []domain.FieldError {
+	if value <= 0 {
+		return append(fields, domain.FieldError{Field: name, Message: "must be greater than zero"})
+	}
+	return fields
+}
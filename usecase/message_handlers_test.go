@@ -0,0 +1,366 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"lunar-rockets/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMessageRegistry creates a registry with the built-in message handlers
+func newMessageRegistry(t *testing.T) *domain.MessageRegistry {
+	t.Helper()
+
+	registry, err := domain.NewMessageRegistry(MessageHandlers()...)
+	require.NoError(t, err)
+	return registry
+}
+
+// rocketRefueledHandler is a message type registered by a test
+type rocketRefueledHandler struct{}
+
+type rocketRefueledMessage struct {
+	Station string `json:"station"`
+	Port    int    `json:"port,omitempty"`
+}
+
+func (rocketRefueledHandler) MessageType() string { return "RocketRefueled" }
+
+func (rocketRefueledHandler) NewPayload() interface{} { return &rocketRefueledMessage{} }
+
+func (rocketRefueledHandler) Validate(payload interface{}) []domain.FieldError {
+	return appendIfEmpty(nil, "station", payload.(*rocketRefueledMessage).Station)
+}
+
+func (rocketRefueledHandler) Apply(rocket *domain.Rocket, message *domain.RocketMessage) (*domain.Rocket, error) {
+	rocket.Mission = "REFUELED_AT_" + message.Payload.(*rocketRefueledMessage).Station
+	return rocket, nil
+}
+
+type invalidPayloadHandler struct{ rocketRefueledHandler }
+
+func (invalidPayloadHandler) MessageType() string { return "Invalid" }
+
+func (invalidPayloadHandler) NewPayload() interface{} { return "not a struct" }
+
+func TestMessageRegistry_Register(t *testing.T) {
+	registry := newMessageRegistry(t)
+
+	require.NoError(t, registry.Register(rocketRefueledHandler{}))
+
+	err := registry.Register(rocketRefueledHandler{})
+	assert.EqualError(t, err, "message type RocketRefueled is already registered")
+
+	err = registry.Register(invalidPayloadHandler{})
+	assert.EqualError(t, err, "invalid payload for message type Invalid: payload must be a pointer to a struct")
+
+	handler, ok := registry.Handler("RocketRefueled")
+	assert.True(t, ok)
+	assert.Equal(t, rocketRefueledHandler{}, handler)
+
+	_, ok = registry.Handler("Invalid")
+	assert.False(t, ok)
+}
+
+func TestMessageRegistry_Types(t *testing.T) {
+	registry := newMessageRegistry(t)
+	require.NoError(t, registry.Register(rocketRefueledHandler{}))
+
+	types := registry.Types()
+
+	names := make([]string, 0, len(types))
+	for _, messageType := range types {
+		names = append(names, messageType.Type)
+	}
+	assert.Equal(t, []string{
+		domain.TypeRocketAborted,
+		domain.TypeRocketDocked,
+		domain.TypeRocketExploded,
+		domain.TypeRocketLanded,
+		domain.TypeRocketLaunched,
+		domain.TypeRocketMissionChanged,
+		"RocketRefueled",
+		domain.TypeRocketSpeedDecreased,
+		domain.TypeRocketSpeedIncreased,
+		domain.TypeRocketTelemetry,
+	}, names)
+
+	assert.Equal(t, domain.PayloadSchema{
+		Type: "object",
+		Properties: map[string]domain.PropertySchema{
+			"station": {Type: "string"},
+			"port":    {Type: "integer"},
+		},
+		Required: []string{"station"},
+	}, types[6].Schema)
+
+	assert.Equal(t, domain.PayloadSchema{
+		Type: "object",
+		Properties: map[string]domain.PropertySchema{
+			"type":        {Type: "string"},
+			"launchSpeed": {Type: "integer"},
+			"mission":     {Type: "string"},
+		},
+		Required: []string{"type", "launchSpeed", "mission"},
+	}, types[4].Schema)
+}
+
+func TestMessageRegistry_Validate(t *testing.T) {
+	registry := newMessageRegistry(t)
+	require.NoError(t, registry.Register(rocketRefueledHandler{}))
+
+	metadata := domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1, MessageTime: time.Now(), MessageType: "RocketRefueled"}
+
+	testCases := []struct {
+		name            string
+		payload         string
+		expectedPayload interface{}
+		expectedFields  []domain.FieldError
+	}{
+		{
+			name:            "valid_payload",
+			payload:         `{"station":"ISS","port":2}`,
+			expectedPayload: &rocketRefueledMessage{Station: "ISS", Port: 2},
+		},
+		{
+			name:            "optional_field_omitted",
+			payload:         `{"station":"ISS"}`,
+			expectedPayload: &rocketRefueledMessage{Station: "ISS"},
+		},
+		{
+			name:    "invalid_value",
+			payload: `{"station":""}`,
+			expectedFields: []domain.FieldError{
+				{Field: "message.station", Message: "must not be empty"},
+			},
+		},
+		{
+			name:    "every_invalid_field",
+			payload: `{"station":"","port":"two","crew":3,"cargo":true}`,
+			expectedFields: []domain.FieldError{
+				{Field: "message.station", Message: "must not be empty"},
+				{Field: "message.port", Message: "must be an integer"},
+				{Field: "message.cargo", Message: "is not a field of RocketRefueled"},
+				{Field: "message.crew", Message: "is not a field of RocketRefueled"},
+			},
+		},
+		{
+			name:    "not_an_object",
+			payload: `["ISS"]`,
+			expectedFields: []domain.FieldError{
+				{Field: "message", Message: "must be a JSON object"},
+			},
+		},
+		{
+			name:    "null_payload",
+			payload: `null`,
+			expectedFields: []domain.FieldError{
+				{Field: "message", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			message := &domain.RocketMessage{Metadata: metadata, Message: json.RawMessage(tc.payload)}
+
+			err := registry.Validate(message)
+
+			if tc.expectedFields == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedPayload, message.Payload)
+				return
+			}
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expectedFields, validationErr.Fields)
+			assert.Nil(t, message.Payload)
+		})
+	}
+}
+
+func TestMessageRegistry_Decode(t *testing.T) {
+	registry := newMessageRegistry(t)
+
+	message := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{MessageType: domain.TypeRocketSpeedIncreased},
+		Message:  json.RawMessage(`{"by":300}`),
+	}
+
+	handler, err := registry.Decode(message)
+	require.NoError(t, err)
+	assert.Equal(t, rocketSpeedIncreasedHandler{}, handler)
+	assert.Equal(t, &domain.RocketSpeedIncreasedMessage{By: 300}, message.Payload)
+
+	// An already decoded payload is kept
+	message.Message = json.RawMessage(`{"by":900}`)
+	_, err = registry.Decode(message)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.RocketSpeedIncreasedMessage{By: 300}, message.Payload)
+
+	_, err = registry.Decode(&domain.RocketMessage{Metadata: domain.MessageMetadata{MessageType: "RocketRefueled"}})
+	assert.EqualError(t, err, "unknown message type: RocketRefueled")
+}
+
+// renameField returns an upcaster renaming a payload field
+func renameField(from string, to string) func(json.RawMessage) (json.RawMessage, error) {
+	return func(payload json.RawMessage) (json.RawMessage, error) {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &values); err != nil {
+			return nil, errors.New("payload must be a JSON object")
+		}
+		if value, ok := values[from]; ok {
+			values[to] = value
+			delete(values, from)
+		}
+		return json.Marshal(values)
+	}
+}
+
+func TestMessageRegistry_RegisterUpcaster(t *testing.T) {
+	registry := newMessageRegistry(t)
+	require.NoError(t, registry.Register(rocketRefueledHandler{}))
+
+	require.NoError(t, registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRefueled", FromVersion: 1, Upcast: renameField("dock", "station")}))
+
+	err := registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRefueled", FromVersion: 1, Upcast: renameField("dock", "station")})
+	assert.EqualError(t, err, "upcaster from schema version 1 of message type RocketRefueled is already registered")
+
+	err = registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRefueled", FromVersion: 0, Upcast: renameField("dock", "station")})
+	assert.EqualError(t, err, "invalid schema version 0 for message type RocketRefueled")
+
+	err = registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRefueled", FromVersion: 2})
+	assert.EqualError(t, err, "upcast function is required")
+
+	err = registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRepaired", FromVersion: 1, Upcast: renameField("dock", "station")})
+	assert.EqualError(t, err, "unknown message type: RocketRepaired")
+
+	// The current version follows the latest upcaster
+	for _, messageType := range registry.Types() {
+		if messageType.Type == "RocketRefueled" {
+			assert.Equal(t, 2, messageType.SchemaVersion)
+		} else {
+			assert.Equal(t, domain.FirstSchemaVersion, messageType.SchemaVersion)
+		}
+	}
+}
+
+func TestMessageRegistry_Upcast(t *testing.T) {
+	registry := newMessageRegistry(t)
+	require.NoError(t, registry.Register(rocketRefueledHandler{}))
+
+	// Version 1 called the station a dock, version 2 called the port a bay
+	require.NoError(t, registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRefueled", FromVersion: 1, Upcast: renameField("dock", "station")}))
+	require.NoError(t, registry.RegisterUpcaster(domain.Upcaster{MessageType: "RocketRefueled", FromVersion: 2, Upcast: renameField("bay", "port")}))
+
+	testCases := []struct {
+		name            string
+		schemaVersion   int
+		payload         string
+		expectedPayload interface{}
+		expectedFields  []domain.FieldError
+	}{
+		{
+			name:            "unset_version_is_the_first",
+			schemaVersion:   0,
+			payload:         `{"dock":"ISS"}`,
+			expectedPayload: &rocketRefueledMessage{Station: "ISS"},
+		},
+		{
+			name:            "upcast_through_every_version",
+			schemaVersion:   1,
+			payload:         `{"dock":"ISS","bay":2}`,
+			expectedPayload: &rocketRefueledMessage{Station: "ISS", Port: 2},
+		},
+		{
+			name:            "upcast_from_intermediate_version",
+			schemaVersion:   2,
+			payload:         `{"station":"ISS","bay":2}`,
+			expectedPayload: &rocketRefueledMessage{Station: "ISS", Port: 2},
+		},
+		{
+			name:            "current_version",
+			schemaVersion:   3,
+			payload:         `{"station":"ISS","port":2}`,
+			expectedPayload: &rocketRefueledMessage{Station: "ISS", Port: 2},
+		},
+		{
+			name:          "old_shape_with_current_version",
+			schemaVersion: 3,
+			payload:       `{"dock":"ISS"}`,
+			expectedFields: []domain.FieldError{
+				{Field: "message.station", Message: "must not be empty"},
+				{Field: "message.dock", Message: "is not a field of RocketRefueled"},
+			},
+		},
+		{
+			name:          "future_version",
+			schemaVersion: 4,
+			payload:       `{"station":"ISS"}`,
+			expectedFields: []domain.FieldError{
+				{Field: "metadata.schemaVersion", Message: "must not be greater than 3, the current version of RocketRefueled"},
+			},
+		},
+		{
+			name:          "negative_version",
+			schemaVersion: -1,
+			payload:       `{"station":"ISS"}`,
+			expectedFields: []domain.FieldError{
+				{Field: "metadata.schemaVersion", Message: "must be greater than zero"},
+			},
+		},
+		{
+			name:          "upcaster_error",
+			schemaVersion: 1,
+			payload:       `["ISS"]`,
+			expectedFields: []domain.FieldError{
+				{Field: "message", Message: "failed to upcast from schema version 1: payload must be a JSON object"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			message := &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1, MessageTime: time.Now(), MessageType: "RocketRefueled", SchemaVersion: tc.schemaVersion},
+				Message:  json.RawMessage(tc.payload),
+			}
+
+			err := registry.Validate(message)
+
+			if tc.expectedFields == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedPayload, message.Payload)
+				// The original payload is kept for the stored event
+				assert.Equal(t, tc.payload, string(message.Message))
+				return
+			}
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expectedFields, validationErr.Fields)
+			assert.Nil(t, message.Payload)
+		})
+	}
+
+	// Decoding a stored event upcasts it the same way
+	message := &domain.RocketMessage{
+		Metadata: domain.MessageMetadata{MessageType: "RocketRefueled", SchemaVersion: 1},
+		Message:  json.RawMessage(`{"dock":"GATEWAY","bay":1}`),
+	}
+	_, err := registry.Decode(message)
+	require.NoError(t, err)
+	assert.Equal(t, &rocketRefueledMessage{Station: "GATEWAY", Port: 1}, message.Payload)
+}
@@ -2,108 +2,731 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"lunar-rockets/domain"
+	"lunar-rockets/metrics"
+	"lunar-rockets/retry"
 )
 
 type RocketMessageUsecase interface {
 	ProcessMessage(ctx context.Context, message *domain.RocketMessage) error
+	// ApplyMessage processes a message like ProcessMessage, and reports what
+	// became of it as a receipt status: applied, buffered, duplicate or
+	// dead-lettered. A buffered message calls the domain.SettleFunc of ctx, if
+	// any, once it leaves the buffer. A message reusing the number of a
+	// processed one with a different content fails with
+	// domain.ErrMessageConflict.
+	ApplyMessage(ctx context.Context, message *domain.RocketMessage) (string, error)
+	// ProcessBatch processes many messages at once, and returns what became of
+	// each of them in batch order
+	ProcessBatch(ctx context.Context, messages []*domain.RocketMessage) []*domain.MessageResult
+	// ListChannels returns the status of the channels with processed or
+	// buffered messages, sorted by channel
+	ListChannels(ctx context.Context) ([]*domain.ChannelStatus, error)
+	// GetChannel returns the status of a channel, or domain.ErrChannelNotFound
+	// when it has no processed or buffered messages
+	GetChannel(ctx context.Context, channel string) (*domain.ChannelStatus, error)
+	// SkipGap marks the messages of a channel up to a number as processed
+	// without applying them, within the transaction of ctx if any, and lists
+	// the buffered ones among them as discarded. A zero number skips the first
+	// gap. It fails with domain.ErrNothingToSkip when the number is already
+	// processed.
+	SkipGap(ctx context.Context, channel string, to int64) (*domain.GapSkip, error)
+	// ApplyAfterSkip drops the buffered messages within a committed skip, and
+	// applies the buffered messages that follow, adding them to skip.Applied
+	ApplyAfterSkip(ctx context.Context, channel string, skip *domain.GapSkip) error
+	// DiscardBuffer drops the buffered messages of a channel, and returns
+	// their numbers
+	DiscardBuffer(ctx context.Context, channel string) []int64
+	// ListConflicts returns the messages that reused the number of a processed
+	// message with a different content, newest first
+	ListConflicts(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error)
 }
 
+// DefaultConflictLimit is the number of conflicts listed when the query sets
+// no limit
+const DefaultConflictLimit = 100
+
 type rocketMessageUsecase struct {
 	rocketRepo         domain.RocketRepository
 	messageRepo        domain.MessageRepository
+	deadLetterRepo     domain.DeadLetterRepository
+	conflictRepo       domain.ConflictRepository
 	rocketStateUsecase RocketStateUsecase
-	messageBuffer      map[string]map[int64]*domain.RocketMessage
+	handlers           *domain.MessageRegistry
+	messageBuffer      *messageBuffer
 	bufferMutex        sync.RWMutex
+	retryPolicy        *retry.Policy
+	conflicts          *metrics.CounterVec // Conflicting messages, by message type
+	now                func() time.Time
+}
+
+// bufferKey identifies the buffer of a channel. Tenants have their own
+// channels, even when they share a name.
+type bufferKey struct {
+	tenantID string
+	channel  string
 }
 
-func NewRocketMessageUsecase(rocketRepo domain.RocketRepository, messageRepo domain.MessageRepository, rocketStateUsecase RocketStateUsecase) RocketMessageUsecase {
+func NewRocketMessageUsecase(rocketRepo domain.RocketRepository, messageRepo domain.MessageRepository, deadLetterRepo domain.DeadLetterRepository, conflictRepo domain.ConflictRepository, rocketStateUsecase RocketStateUsecase, handlers *domain.MessageRegistry, limits BufferLimits, retryPolicy *retry.Policy, conflicts *metrics.CounterVec) RocketMessageUsecase {
 	return &rocketMessageUsecase{
 		rocketRepo:         rocketRepo,
 		messageRepo:        messageRepo,
+		deadLetterRepo:     deadLetterRepo,
+		conflictRepo:       conflictRepo,
 		rocketStateUsecase: rocketStateUsecase,
-		messageBuffer:      make(map[string]map[int64]*domain.RocketMessage),
+		handlers:           handlers,
+		messageBuffer:      newMessageBuffer(limits),
+		retryPolicy:        retryPolicy,
+		conflicts:          conflicts,
+		now:                time.Now,
 	}
 }
 
 func (p *rocketMessageUsecase) ProcessMessage(ctx context.Context, message *domain.RocketMessage) error {
+	_, err := p.ApplyMessage(ctx, message)
+	return err
+}
+
+func (p *rocketMessageUsecase) ApplyMessage(ctx context.Context, message *domain.RocketMessage) (string, error) {
+	if err := p.handlers.Validate(message); err != nil {
+		return "", err
+	}
+
 	lastMessageNumber, err := p.messageRepo.FindLastMessageNumber(ctx, message.Metadata.Channel)
 	if err != nil {
-		return fmt.Errorf("failed to check if message was processed: %w", err)
+		return "", fmt.Errorf("failed to check if message was processed: %w", err)
 	}
 
 	// Skip processed messages
 	if lastMessageNumber >= message.Metadata.MessageNumber {
-		log.Printf("Skipping already processed message %d for channel %s", message.Metadata.MessageNumber, message.Metadata.Channel)
-		return nil
+		return p.checkDuplicate(ctx, message)
 	}
 
 	// Buffer out-of-order messages
 	if lastMessageNumber+1 < message.Metadata.MessageNumber {
 		log.Printf("Buffering out-of-order message %d for channel %s", message.Metadata.MessageNumber, message.Metadata.Channel)
-		p.addToBuffer(message)
-		return nil
+		return p.addToBuffer(ctx, message)
 	}
 
 	// Process message, it's the expected one
 	err = p.rocketStateUsecase.UpdateRocketFromMessage(ctx, message)
 	if err != nil {
-		return fmt.Errorf("failed to execute rocket state usecase: %w", err)
+		// Nothing is wrong with the message itself, so the producer sends it
+		// again rather than having it replayed
+		if errors.Is(err, domain.ErrBusy) {
+			return "", err
+		}
+		// Another delivery of the number was processed since it was checked,
+		// and this one was rolled back
+		if errors.Is(err, domain.ErrAlreadyProcessed) {
+			return p.checkDuplicate(ctx, message)
+		}
+		return "", p.deadLetterFailed(ctx, message, fmt.Errorf("failed to execute rocket state usecase: %w", err))
 	}
 
 	if err := p.processBufferedMessages(ctx, message.Metadata.Channel, message.Metadata.MessageNumber); err != nil {
-		return err
+		return "", err
 	}
 
 	log.Printf("Successfully processed message %d for channel %s", message.Metadata.MessageNumber, message.Metadata.Channel)
+	return domain.ReceiptStatusApplied, nil
+}
+
+// ProcessBatch groups the messages by channel. The messages that follow the
+// last processed one of their channel are applied in one transaction, and the
+// others as if they were received one by one, like all of them when the
+// transaction fails on one of them.
+func (p *rocketMessageUsecase) ProcessBatch(ctx context.Context, messages []*domain.RocketMessage) []*domain.MessageResult {
+	results := make([]*domain.MessageResult, len(messages))
+
+	// Channels are processed in the order they first appear in the batch
+	var channels []string
+	batches := make(map[string][]int)
+	for i, message := range messages {
+		results[i] = &domain.MessageResult{
+			Index:         i,
+			Channel:       message.Metadata.Channel,
+			MessageNumber: message.Metadata.MessageNumber,
+		}
+
+		if err := p.handlers.Validate(message); err != nil {
+			setResultError(results[i], err)
+			continue
+		}
+
+		channel := message.Metadata.Channel
+		if _, exists := batches[channel]; !exists {
+			channels = append(channels, channel)
+		}
+		batches[channel] = append(batches[channel], i)
+	}
+
+	for _, channel := range channels {
+		p.processChannelBatch(ctx, channel, messages, batches[channel], results)
+	}
+
+	return results
+}
+
+// processChannelBatch processes the messages of a batch at the given indexes,
+// all of the same channel, and sets their results
+func (p *rocketMessageUsecase) processChannelBatch(ctx context.Context, channel string, messages []*domain.RocketMessage, indexes []int, results []*domain.MessageResult) {
+	lastMessageNumber, err := p.messageRepo.FindLastMessageNumber(ctx, channel)
+	if err != nil {
+		for _, i := range indexes {
+			setResultError(results[i], fmt.Errorf("failed to check if message was processed: %w", err))
+		}
+		return
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return messages[indexes[a]].Metadata.MessageNumber < messages[indexes[b]].Metadata.MessageNumber
+	})
+
+	// The messages following the last processed one, without a gap, can be
+	// applied together
+	var consecutive, others []int
+	next := lastMessageNumber + 1
+	for _, i := range indexes {
+		switch number := messages[i].Metadata.MessageNumber; {
+		case number < next:
+			status, err := p.checkDuplicate(ctx, messages[i])
+			if err != nil {
+				setResultError(results[i], err)
+				continue
+			}
+			results[i].Status = status
+		case number == next:
+			consecutive = append(consecutive, i)
+			next++
+		default:
+			others = append(others, i)
+		}
+	}
+
+	if len(consecutive) > 0 {
+		if err := p.applyTogether(ctx, channel, messages, consecutive); errors.Is(err, domain.ErrBusy) {
+			// Nothing is wrong with the messages, so the producer sends them
+			// again
+			for _, i := range consecutive {
+				setResultError(results[i], err)
+			}
+		} else if err != nil {
+			// One of the messages failed, which the others are not to blame
+			// for. They are applied one by one, so that only the failing one
+			// is dead-lettered.
+			log.Printf("Applying the messages of channel %s one by one: %v", channel, err)
+			others = append(consecutive, others...)
+		} else {
+			for _, i := range consecutive {
+				results[i].Status = domain.ReceiptStatusApplied
+			}
+			if err := p.processBufferedMessages(ctx, channel, next-1); err != nil {
+				log.Printf("Error processing buffered messages for channel %s: %v", channel, err)
+			}
+		}
+	}
+
+	for _, i := range others {
+		status, err := p.ApplyMessage(ctx, messages[i])
+		if err != nil {
+			setResultError(results[i], err)
+			continue
+		}
+		results[i].Status = status
+	}
+}
+
+// applyTogether applies the messages at the given indexes in one transaction,
+// so that they are all applied or none is. The transaction is attempted again
+// when it fails on a lock held by another connection.
+func (p *rocketMessageUsecase) applyTogether(ctx context.Context, channel string, messages []*domain.RocketMessage, indexes []int) error {
+	return withRetry(ctx, p.retryPolicy, "apply_batch", func() error {
+		return p.applyInTransaction(ctx, channel, messages, indexes)
+	})
+}
+
+// applyInTransaction applies the messages at the given indexes in a
+// transaction of their own
+func (p *rocketMessageUsecase) applyInTransaction(ctx context.Context, channel string, messages []*domain.RocketMessage, indexes []int) error {
+	tx, err := p.rocketRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	ctx = domain.ContextWithTransaction(ctx, tx)
+
+	for _, i := range indexes {
+		if err := p.rocketStateUsecase.UpdateRocketFromMessage(ctx, messages[i]); err != nil {
+			log.Printf("Rolling back batch transaction for channel %s due to error: %v", channel, err)
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply message %d: %w", messages[i].Metadata.MessageNumber, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully processed %d messages for channel %s in one transaction", len(indexes), channel)
 	return nil
 }
 
-// addToBuffer adds a message to the buffer for its channel
-func (p *rocketMessageUsecase) addToBuffer(message *domain.RocketMessage) {
-	p.bufferMutex.Lock()
-	defer p.bufferMutex.Unlock()
+// checkDuplicate skips a message whose number was already processed. It is a
+// duplicate when it has the content of the processed message, or when that
+// content is unknown, and a conflict otherwise, which is recorded.
+func (p *rocketMessageUsecase) checkDuplicate(ctx context.Context, message *domain.RocketMessage) (string, error) {
+	channel, number := message.Metadata.Channel, message.Metadata.MessageNumber
 
-	channel := message.Metadata.Channel
-	if _, exists := p.messageBuffer[channel]; !exists {
-		p.messageBuffer[channel] = make(map[int64]*domain.RocketMessage)
+	processedHash, err := p.messageRepo.FindPayloadHash(ctx, channel, number)
+	if err != nil {
+		return "", fmt.Errorf("failed to find processed message: %w", err)
+	}
+	receivedHash, err := domain.PayloadHash(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash message: %w", err)
 	}
-	p.messageBuffer[channel][message.Metadata.MessageNumber] = message
+
+	if processedHash == "" || processedHash == receivedHash {
+		log.Printf("Skipping already processed message %d for channel %s", number, channel)
+		return domain.ReceiptStatusDuplicate, nil
+	}
+
+	err = p.conflictRepo.Save(ctx, &domain.MessageConflict{
+		Channel:       channel,
+		MessageNumber: number,
+		MessageType:   message.Metadata.MessageType,
+		MessageTime:   message.Metadata.MessageTime,
+		ProcessedHash: processedHash,
+		ReceivedHash:  receivedHash,
+		Payload:       message.Message,
+		DetectedAt:    p.now(),
+		Actor:         domain.ActorFromContext(ctx),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record message conflict: %w", err)
+	}
+	p.conflicts.Inc(message.Metadata.MessageType)
+
+	log.Printf("Skipping message %d for channel %s conflicting with the processed one: hash %s, processed %s", number, channel, receivedHash, processedHash)
+	return "", fmt.Errorf("message %d of channel %s: %w", number, channel, domain.ErrMessageConflict)
 }
 
-// processBufferedMessages processes consecutive messages from the buffer
-func (p *rocketMessageUsecase) processBufferedMessages(ctx context.Context, channel string, lastProcessedNumber int64) error {
+// setResultError marks a batch message as not processed because of err
+func setResultError(result *domain.MessageResult, err error) {
+	result.Status = domain.MessageStatusError
+	result.Error = err.Error()
+
+	if errors.Is(err, domain.ErrDeadLettered) {
+		result.Status = domain.ReceiptStatusDeadLettered
+	}
+	if errors.Is(err, domain.ErrMessageConflict) {
+		result.Status = domain.ReceiptStatusConflict
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		result.Fields = validationErr.Fields
+	}
+}
+
+// addToBuffer adds a message to the buffer for its tenant's channel, along
+// with the SettleFunc of ctx if any. When the message does not fit, it is
+// refused or messages are dead-lettered, as the buffer policy says. It returns
+// whether the message was buffered or dead-lettered.
+func (p *rocketMessageUsecase) addToBuffer(ctx context.Context, message *domain.RocketMessage) (string, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode message: %w", err)
+	}
+	size := int64(len(data))
+
+	var settlements []settlement
+	defer func() { notifySettled(settlements) }() // Once the buffer is unlocked
+
 	p.bufferMutex.Lock()
 	defer p.bufferMutex.Unlock()
 
-	channelBuffer, exists := p.messageBuffer[channel]
-	if !exists {
-		return nil
+	buffer := p.messageBuffer
+	key := bufferKey{tenantID: domain.TenantFromContext(ctx), channel: message.Metadata.Channel}
+
+	// A message sent again replaces the copy buffered before, whose senders
+	// are told what becomes of the new one. The copy stays when the new one is
+	// not buffered.
+	previous := buffer.get(key, message.Metadata.MessageNumber)
+	buffer.remove(key, message.Metadata.MessageNumber)
+	keepPrevious := func() {
+		if previous != nil {
+			buffer.put(key, previous)
+		}
+	}
+
+	if buffer.tooLarge(size) {
+		keepPrevious()
+		if buffer.limits.Policy == BufferPolicyReject {
+			return "", domain.ErrBufferFull
+		}
+		return domain.ReceiptStatusDeadLettered, p.deadLetter(ctx, key, message, data, "message too large to buffer")
 	}
 
-	nextNumber := lastProcessedNumber + 1
 	for {
-		message, exists := channelBuffer[nextNumber]
-		if !exists {
+		channelFull := buffer.channelFull(key, size)
+		if !channelFull && !buffer.full(key.tenantID, size) {
 			break
 		}
 
-		err := p.rocketStateUsecase.UpdateRocketFromMessage(ctx, message)
+		switch buffer.limits.Policy {
+		case BufferPolicyEvictOldest:
+			scope := key.channel
+			reason := "evicted from the full buffer of channel " + key.channel
+			if !channelFull {
+				scope = ""
+				reason = "evicted from the full message buffer"
+			}
+			evictedKey, evicted := buffer.oldest(key.tenantID, scope)
+			if err := p.deadLetter(ctx, evictedKey, evicted.message, evicted.data, reason); err != nil {
+				keepPrevious()
+				return "", err
+			}
+			buffer.remove(evictedKey, evicted.message.Metadata.MessageNumber)
+			settlements = append(settlements, settlement{buffered: evicted, status: domain.ReceiptStatusDeadLettered, reason: reason})
+		case BufferPolicyEvictNewest:
+			keepPrevious()
+			return domain.ReceiptStatusDeadLettered, p.deadLetter(ctx, key, message, data, "message buffer full")
+		default:
+			keepPrevious()
+			if channelFull {
+				return "", domain.ErrChannelBufferFull
+			}
+			return "", domain.ErrBufferFull
+		}
+	}
+
+	var settle []domain.SettleFunc
+	if previous != nil {
+		settle = previous.settle
+	}
+	if s := domain.SettleFromContext(ctx); s != nil {
+		settle = append(settle, s)
+	}
+	buffer.add(key, message, data, p.now(), settle)
+	return domain.ReceiptStatusBuffered, nil
+}
+
+// deadLetter saves a message the service gave up on in the dead-letter store
+// of its tenant
+func (p *rocketMessageUsecase) deadLetter(ctx context.Context, key bufferKey, message *domain.RocketMessage, data []byte, reason string) error {
+	deadLetter := &domain.DeadLetter{
+		Channel:       key.channel,
+		MessageNumber: message.Metadata.MessageNumber,
+		MessageType:   message.Metadata.MessageType,
+		Message:       data,
+		Reason:        reason,
+		CreatedAt:     p.now(),
+	}
+	if err := p.deadLetterRepo.Save(domain.ContextWithTenant(ctx, key.tenantID), deadLetter); err != nil {
+		return fmt.Errorf("failed to dead-letter message %d for channel %s: %w", message.Metadata.MessageNumber, key.channel, err)
+	}
+
+	log.Printf("Dead-lettered message %d for channel %s: %s", message.Metadata.MessageNumber, key.channel, reason)
+	return nil
+}
+
+// deadLetterFailed saves a message that could not be applied because of err
+// in the dead-letter store, and returns err marked with ErrDeadLettered. When
+// the message cannot be saved either, err is returned as is.
+func (p *rocketMessageUsecase) deadLetterFailed(ctx context.Context, message *domain.RocketMessage, err error) error {
+	data, encodeErr := json.Marshal(message)
+	if encodeErr != nil {
+		log.Printf("Error encoding failed message %d for channel %s: %v", message.Metadata.MessageNumber, message.Metadata.Channel, encodeErr)
+		return err
+	}
+
+	key := bufferKey{tenantID: domain.TenantFromContext(ctx), channel: message.Metadata.Channel}
+	if deadLetterErr := p.deadLetter(ctx, key, message, data, err.Error()); deadLetterErr != nil {
+		log.Printf("Error saving failed message: %v", deadLetterErr)
+		return err
+	}
+
+	return fmt.Errorf("%w (%w)", err, domain.ErrDeadLettered)
+}
+
+// processBufferedMessages processes consecutive messages from the buffer of
+// the tenant's channel. A message that fails moves from the buffer to the
+// dead-letter store, and the messages after it wait for it to be replayed.
+// The buffer is only locked to take each message, not while it is applied, as
+// applying may wait out retry backoffs.
+func (p *rocketMessageUsecase) processBufferedMessages(ctx context.Context, channel string, lastProcessedNumber int64) error {
+	key := bufferKey{tenantID: domain.TenantFromContext(ctx), channel: channel}
+
+	for nextNumber := lastProcessedNumber + 1; ; nextNumber++ {
+		buffered := p.takeBuffered(key, nextNumber)
+		if buffered == nil {
+			return nil
+		}
+
+		err := p.rocketStateUsecase.UpdateRocketFromMessage(ctx, buffered.message)
 		if err != nil {
-			return fmt.Errorf("failed to process buffered message %d: %w", nextNumber, err)
+			reason := fmt.Sprintf("failed to process buffered message %d: %v", nextNumber, err)
+			if err := p.deadLetter(ctx, key, buffered.message, buffered.data, reason); err != nil {
+				p.bufferMutex.Lock()
+				p.messageBuffer.put(key, buffered)
+				p.bufferMutex.Unlock()
+				return err
+			}
+			buffered.settled(domain.ReceiptStatusDeadLettered, reason)
+			return nil
+		}
+
+		buffered.settled(domain.ReceiptStatusApplied, "")
+	}
+}
+
+// takeBuffered removes a message from the buffer of a tenant's channel and
+// returns it, or nil. The message leaves the buffer before it is applied, so
+// that no other caller applies it too.
+func (p *rocketMessageUsecase) takeBuffered(key bufferKey, number int64) *bufferedMessage {
+	p.bufferMutex.Lock()
+	defer p.bufferMutex.Unlock()
+
+	buffered := p.messageBuffer.get(key, number)
+	if buffered != nil {
+		p.messageBuffer.remove(key, number)
+	}
+	return buffered
+}
+
+func (p *rocketMessageUsecase) ListChannels(ctx context.Context) ([]*domain.ChannelStatus, error) {
+	processed, err := p.messageRepo.ListChannelProgress(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel progress: %w", err)
+	}
+	buffered := p.bufferedChannels(domain.TenantFromContext(ctx))
+	now := p.now()
+
+	progress := make(map[string]*domain.ChannelProgress, len(processed))
+	channels := make([]string, 0, len(processed)+len(buffered))
+	for _, channelProgress := range processed {
+		progress[channelProgress.Channel] = channelProgress
+		channels = append(channels, channelProgress.Channel)
+	}
+	for channel := range buffered {
+		if _, exists := progress[channel]; !exists {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+
+	statuses := make([]*domain.ChannelStatus, 0, len(channels))
+	for _, channel := range channels {
+		statuses = append(statuses, channelStatus(channel, progress[channel], buffered[channel], now))
+	}
+
+	return statuses, nil
+}
+
+func (p *rocketMessageUsecase) GetChannel(ctx context.Context, channel string) (*domain.ChannelStatus, error) {
+	progress, err := p.messageRepo.FindChannelProgress(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get progress of channel %s: %w", channel, err)
+	}
+
+	p.bufferMutex.RLock()
+	buffered := p.messageBuffer.snapshot(bufferKey{tenantID: domain.TenantFromContext(ctx), channel: channel})
+	p.bufferMutex.RUnlock()
+
+	if progress == nil && buffered == nil {
+		return nil, domain.ErrChannelNotFound
+	}
+
+	return channelStatus(channel, progress, buffered, p.now()), nil
+}
+
+// channelStatus describes a channel at the time now, from its last processed
+// message and its buffer, either of which may be nil
+func channelStatus(channel string, progress *domain.ChannelProgress, buffered *bufferedChannel, now time.Time) *domain.ChannelStatus {
+	status := &domain.ChannelStatus{
+		Channel:  channel,
+		Buffered: []int64{},
+		Missing:  []domain.MessageRange{},
+	}
+
+	var lastMessageAt time.Time
+	if progress != nil {
+		status.LastProcessed = progress.LastProcessed
+		lastMessageAt = progress.LastProcessedAt
+	}
+
+	if buffered != nil {
+		status.Buffered = buffered.numbers
+		status.Missing = append(status.Missing, domain.MissingRanges(status.LastProcessed, buffered.numbers)...)
+
+		oldest := buffered.oldest
+		status.OldestBufferedAt = &oldest
+		status.OldestBufferedAgeSeconds = secondsSince(now, oldest)
+
+		if buffered.newest.After(lastMessageAt) {
+			lastMessageAt = buffered.newest
+		}
+	}
+
+	if !lastMessageAt.IsZero() {
+		status.LastMessageAt = &lastMessageAt
+		status.SinceLastMessageSeconds = secondsSince(now, lastMessageAt)
+	}
+
+	return status
+}
+
+// secondsSince returns the whole seconds from t to now, and 0 for a time after
+// now, such as one stored by a clock running slightly ahead
+func secondsSince(now time.Time, t time.Time) int64 {
+	if !now.After(t) {
+		return 0
+	}
+	return int64(now.Sub(t) / time.Second)
+}
+
+func (p *rocketMessageUsecase) SkipGap(ctx context.Context, channel string, to int64) (*domain.GapSkip, error) {
+	lastMessageNumber, err := p.messageRepo.FindLastMessageNumber(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last message number for channel %s: %w", channel, err)
+	}
+
+	skip := &domain.GapSkip{From: lastMessageNumber, To: to}
+
+	p.bufferMutex.RLock()
+	buffered := p.messageBuffer.snapshot(bufferKey{tenantID: domain.TenantFromContext(ctx), channel: channel})
+	p.bufferMutex.RUnlock()
+
+	if skip.To == 0 && buffered != nil {
+		skip.To = buffered.numbers[0] - 1
+	}
+	if skip.To <= lastMessageNumber {
+		return nil, domain.ErrNothingToSkip
+	}
+
+	// The buffered messages within the gap would never be applied
+	if buffered != nil {
+		for _, number := range buffered.numbers {
+			if number > skip.To {
+				break
+			}
+			skip.Discarded = append(skip.Discarded, number)
 		}
+	}
 
-		delete(channelBuffer, nextNumber)
-		nextNumber++
+	// Skipped messages have no known content, so any message later sent with
+	// their number is taken as a duplicate
+	if err := p.messageRepo.MarkAsProcessed(ctx, channel, skip.To, ""); err != nil {
+		return nil, fmt.Errorf("failed to skip to message %d: %w", skip.To, err)
 	}
 
-	if len(channelBuffer) == 0 {
-		delete(p.messageBuffer, channel)
+	return skip, nil
+}
+
+func (p *rocketMessageUsecase) ApplyAfterSkip(ctx context.Context, channel string, skip *domain.GapSkip) error {
+	key := bufferKey{tenantID: domain.TenantFromContext(ctx), channel: channel}
+	reason := fmt.Sprintf("skipped with the gap up to message %d", skip.To)
+
+	var settlements []settlement
+	var discarded []int64
+	p.bufferMutex.Lock()
+	buffered := p.messageBuffer.snapshot(key)
+	if buffered != nil {
+		for _, number := range buffered.numbers {
+			if number > skip.To {
+				break
+			}
+			settlements = append(settlements, settlement{buffered: p.messageBuffer.get(key, number), status: domain.ReceiptStatusDiscarded, reason: reason})
+			p.messageBuffer.remove(key, number)
+			discarded = append(discarded, number)
+		}
+	}
+	p.bufferMutex.Unlock()
+	notifySettled(settlements)
+
+	if !slices.Equal(discarded, skip.Discarded) {
+		log.Printf("Discarded buffered messages %v of channel %s, received during its skip", discarded, channel)
+	}
+
+	if err := p.processBufferedMessages(ctx, channel, skip.To); err != nil {
+		return err
+	}
+
+	lastMessageNumber, err := p.messageRepo.FindLastMessageNumber(ctx, channel)
+	if err != nil {
+		return fmt.Errorf("failed to get last message number for channel %s: %w", channel, err)
+	}
+	if buffered != nil {
+		for _, number := range buffered.numbers {
+			if number > skip.To && number <= lastMessageNumber {
+				skip.Applied = append(skip.Applied, number)
+			}
+		}
 	}
 
+	log.Printf("Skipped messages %d to %d of channel %s, applied %d buffered messages", skip.From+1, skip.To, channel, len(skip.Applied))
 	return nil
 }
+
+func (p *rocketMessageUsecase) DiscardBuffer(ctx context.Context, channel string) []int64 {
+	var settlements []settlement
+	defer func() { notifySettled(settlements) }() // Once the buffer is unlocked
+
+	p.bufferMutex.Lock()
+	defer p.bufferMutex.Unlock()
+
+	key := bufferKey{tenantID: domain.TenantFromContext(ctx), channel: channel}
+	buffered := p.messageBuffer.snapshot(key)
+	if buffered == nil {
+		return nil
+	}
+
+	for _, number := range buffered.numbers {
+		settlements = append(settlements, settlement{buffered: p.messageBuffer.get(key, number), status: domain.ReceiptStatusDiscarded, reason: "buffer discarded"})
+		p.messageBuffer.remove(key, number)
+	}
+
+	log.Printf("Discarded %d buffered messages of channel %s", len(buffered.numbers), channel)
+	return buffered.numbers
+}
+
+func (p *rocketMessageUsecase) ListConflicts(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultConflictLimit
+	}
+
+	conflicts, err := p.conflictRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message conflicts: %w", err)
+	}
+
+	log.Printf("Successfully listed %d message conflicts", len(conflicts))
+	return conflicts, nil
+}
+
+// bufferedChannels returns a copy of what the buffer of each channel of a
+// tenant holds
+func (p *rocketMessageUsecase) bufferedChannels(tenantID string) map[string]*bufferedChannel {
+	p.bufferMutex.RLock()
+	defer p.bufferMutex.RUnlock()
+
+	buffered := make(map[string]*bufferedChannel)
+	for key := range p.messageBuffer.channels {
+		if key.tenantID != tenantID {
+			continue
+		}
+		buffered[key.channel] = p.messageBuffer.snapshot(key)
+	}
+
+	return buffered
+}
@@ -2,29 +2,63 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"lunar-rockets/domain"
+	"lunar-rockets/metrics"
+	"lunar-rockets/retry"
 	"lunar-rockets/test/helper"
 	"lunar-rockets/test/mocks"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// newConflictCounter returns a counter of conflicting messages for a use case
+// under test
+func newConflictCounter() *metrics.CounterVec {
+	return metrics.NewRegistry().Counter("conflicts_total", "Conflicting messages", "type")
+}
+
+// bufferedNumbers returns a sorted copy of the buffered message numbers of
+// each channel of a tenant
+func (p *rocketMessageUsecase) bufferedNumbers(tenantID string) map[string][]int64 {
+	numbers := make(map[string][]int64)
+	for channel, buffered := range p.bufferedChannels(tenantID) {
+		numbers[channel] = buffered.numbers
+	}
+
+	return numbers
+}
+
 func TestRocketMessageUsecase_ProcessMessage(t *testing.T) {
 	now := time.Now()
 	testMessage := helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, now)
+	testMessageHash, err := domain.PayloadHash(testMessage)
+	require.NoError(t, err)
+
+	// Integers beyond the precision of a float64 differ in their last digit
+	largeNumberMessage := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now)
+	largeNumberMessage.Message = json.RawMessage(`{"by":9007199254740993}`)
+	roundedMessage := *largeNumberMessage
+	roundedMessage.Message = json.RawMessage(`{"by":9007199254740992}`)
+	roundedMessageHash, err := domain.PayloadHash(&roundedMessage)
+	require.NoError(t, err)
 
 	testCases := []struct {
 		name              string
 		message           *domain.RocketMessage
 		lastMessageNumber int64
+		processedHash     string // Hash of the processed message with the same number
 		messageRepoError  error
 		stateUsecaseError error
 		expectedError     string
+		expectedConflict  bool
 		shouldCallState   bool
 		shouldBuffer      bool
 	}{
@@ -52,12 +86,42 @@ func TestRocketMessageUsecase_ProcessMessage(t *testing.T) {
 			name:              "duplicate_message",
 			message:           testMessage,
 			lastMessageNumber: 1,
+			processedHash:     testMessageHash,
 			messageRepoError:  nil,
 			stateUsecaseError: nil,
 			expectedError:     "",
 			shouldCallState:   false,
 			shouldBuffer:      false,
 		},
+		{
+			name:              "duplicate_of_message_processed_before_hashes",
+			message:           testMessage,
+			lastMessageNumber: 1,
+			processedHash:     "",
+			expectedError:     "",
+			shouldCallState:   false,
+			shouldBuffer:      false,
+		},
+		{
+			name:              "conflicting_duplicate_message",
+			message:           testMessage,
+			lastMessageNumber: 1,
+			processedHash:     "other-hash",
+			expectedError:     "message 1 of channel channel-1: message conflicts with the processed one",
+			expectedConflict:  true,
+			shouldCallState:   false,
+			shouldBuffer:      false,
+		},
+		{
+			name:              "conflicting_large_number",
+			message:           largeNumberMessage,
+			lastMessageNumber: 2,
+			processedHash:     roundedMessageHash,
+			expectedError:     "message 2 of channel channel-1: message conflicts with the processed one",
+			expectedConflict:  true,
+			shouldCallState:   false,
+			shouldBuffer:      false,
+		},
 		{
 			name:              "out_of_order_message",
 			message:           helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 3, now),
@@ -84,7 +148,7 @@ func TestRocketMessageUsecase_ProcessMessage(t *testing.T) {
 			lastMessageNumber: 0,
 			messageRepoError:  nil,
 			stateUsecaseError: errors.New("state processing error"),
-			expectedError:     "failed to execute rocket state usecase: state processing error",
+			expectedError:     "failed to execute rocket state usecase: state processing error (message dead-lettered)",
 			shouldCallState:   true,
 			shouldBuffer:      false,
 		},
@@ -101,10 +165,24 @@ func TestRocketMessageUsecase_ProcessMessage(t *testing.T) {
 					assert.Equal(t, tc.message.Metadata.Channel, channel)
 					return tc.lastMessageNumber, tc.messageRepoError
 				},
+				FindPayloadHashFunc: func(ctx context.Context, channel string, messageNumber int64) (string, error) {
+					assert.Equal(t, tc.message.Metadata.MessageNumber, messageNumber)
+					return tc.processedHash, nil
+				},
 			}
 
 			mockRocketRepo := &mocks.MockRocketRepository{}
 
+			// A message reusing a processed number with another content is recorded
+			var conflicts []*domain.MessageConflict
+			mockConflictRepo := &mocks.MockConflictRepository{
+				SaveFunc: func(ctx context.Context, conflict *domain.MessageConflict) error {
+					conflicts = append(conflicts, conflict)
+					return nil
+				},
+			}
+			conflictCounter := newConflictCounter()
+
 			// Create mock rocket state usecase
 			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
 			if tc.shouldCallState {
@@ -115,8 +193,17 @@ func TestRocketMessageUsecase_ProcessMessage(t *testing.T) {
 				}
 			}
 
+			// A message that cannot be applied is dead-lettered
+			var deadLetters []*domain.DeadLetter
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					deadLetters = append(deadLetters, deadLetter)
+					return nil
+				},
+			}
+
 			// Create use case with mock dependencies
-			useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, mockRocketStateUsecase)
+			useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, mockDeadLetterRepo, mockConflictRepo, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, conflictCounter)
 
 			// Execute the method
 			err := useCase.ProcessMessage(context.Background(), tc.message)
@@ -132,12 +219,295 @@ func TestRocketMessageUsecase_ProcessMessage(t *testing.T) {
 			// Verify mock expectations
 			mockRocketStateUsecase.AssertExpectations(t)
 
+			if tc.stateUsecaseError != nil {
+				assert.ErrorIs(t, err, domain.ErrDeadLettered)
+				if assert.Len(t, deadLetters, 1) {
+					assert.Equal(t, tc.message.Metadata.MessageNumber, deadLetters[0].MessageNumber)
+					assert.Equal(t, "failed to execute rocket state usecase: state processing error", deadLetters[0].Reason)
+				}
+			} else {
+				assert.Empty(t, deadLetters)
+			}
+
+			if tc.expectedConflict {
+				assert.ErrorIs(t, err, domain.ErrMessageConflict)
+				if assert.Len(t, conflicts, 1) {
+					assert.Equal(t, tc.processedHash, conflicts[0].ProcessedHash)
+					receivedHash, err := domain.PayloadHash(tc.message)
+					require.NoError(t, err)
+					assert.Equal(t, receivedHash, conflicts[0].ReceivedHash)
+					assert.Equal(t, tc.message.Message, conflicts[0].Payload)
+				}
+				assert.Equal(t, 1.0, conflictCounter.Value(tc.message.Metadata.MessageType))
+			} else {
+				assert.Empty(t, conflicts)
+			}
+
 			// Verify buffer state
 			if tc.shouldBuffer {
-				assert.Contains(t, useCase.(*rocketMessageUsecase).messageBuffer[tc.message.Metadata.Channel], tc.message.Metadata.MessageNumber)
+				assert.Contains(t, defaultBuffer(useCase, tc.message.Metadata.Channel), tc.message.Metadata.MessageNumber)
+			} else {
+				assert.NotContains(t, defaultBuffer(useCase, tc.message.Metadata.Channel), tc.message.Metadata.MessageNumber)
+			}
+		})
+	}
+}
+
+func TestRocketMessageUsecase_ApplyMessage(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name           string
+		messageNumber  int64
+		limits         BufferLimits
+		expectedStatus string
+	}{
+		{name: "applied", messageNumber: 2, limits: DefaultBufferLimits(), expectedStatus: domain.ReceiptStatusApplied},
+		{name: "buffered", messageNumber: 4, limits: DefaultBufferLimits(), expectedStatus: domain.ReceiptStatusBuffered},
+		{name: "duplicate", messageNumber: 1, limits: DefaultBufferLimits(), expectedStatus: domain.ReceiptStatusDuplicate},
+		{
+			name:           "dead_lettered",
+			messageNumber:  4,
+			limits:         BufferLimits{MaxBytes: 10, Policy: BufferPolicyEvictNewest},
+			expectedStatus: domain.ReceiptStatusDeadLettered,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					return 1, nil
+				},
+				FindPayloadHashFunc: func(ctx context.Context, channel string, messageNumber int64) (string, error) {
+					return "", nil
+				},
+			}
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					return nil
+				},
+			}
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), tc.limits, nil, newConflictCounter())
+
+			status, err := useCase.ApplyMessage(context.Background(), helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, tc.messageNumber, now))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
+
+func TestRocketMessageUsecase_ApplyMessage_Busy(t *testing.T) {
+	mockMessageRepo := &mocks.MockMessageRepository{
+		FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+			return 1, nil
+		},
+	}
+	mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+		SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+			t.Error("Messages failing on a busy database must not be dead-lettered")
+			return nil
+		},
+	}
+	mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+	mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).
+		Return(fmt.Errorf("%w: retries exhausted after 5 attempts: database is locked", domain.ErrBusy))
+
+	useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+
+	status, err := useCase.ApplyMessage(context.Background(), helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, time.Now()))
+
+	assert.ErrorIs(t, err, domain.ErrBusy)
+	assert.NotErrorIs(t, err, domain.ErrDeadLettered)
+	assert.Empty(t, status)
+}
+
+func TestRocketMessageUsecase_ApplyMessage_ProcessedConcurrently(t *testing.T) {
+	message := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, time.Now())
+	hash, err := domain.PayloadHash(message)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		processedHash  string
+		expectedStatus string
+		expectedError  error
+	}{
+		{name: "duplicate", processedHash: hash, expectedStatus: domain.ReceiptStatusDuplicate},
+		{name: "conflict", processedHash: "other-hash", expectedError: domain.ErrMessageConflict},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Message 2 is processed by another delivery between the check and
+			// the transaction
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					return 1, nil
+				},
+				FindPayloadHashFunc: func(ctx context.Context, channel string, messageNumber int64) (string, error) {
+					return tc.processedHash, nil
+				},
+			}
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					t.Error("Messages processed concurrently must not be dead-lettered")
+					return nil
+				},
+			}
+			var conflicts []*domain.MessageConflict
+			mockConflictRepo := &mocks.MockConflictRepository{
+				SaveFunc: func(ctx context.Context, conflict *domain.MessageConflict) error {
+					conflicts = append(conflicts, conflict)
+					return nil
+				},
+			}
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).
+				Return(fmt.Errorf("failed to mark message as processed: %w", domain.ErrAlreadyProcessed))
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, mockDeadLetterRepo, mockConflictRepo, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+			detectedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			useCase.(*rocketMessageUsecase).now = func() time.Time { return detectedAt }
+
+			status, err := useCase.ApplyMessage(context.Background(), message)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				if assert.Len(t, conflicts, 1) {
+					assert.Equal(t, detectedAt, conflicts[0].DetectedAt)
+				}
 			} else {
-				assert.NotContains(t, useCase.(*rocketMessageUsecase).messageBuffer[tc.message.Metadata.Channel], tc.message.Metadata.MessageNumber)
+				assert.NoError(t, err)
+				assert.Empty(t, conflicts)
 			}
+			assert.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
+
+func TestRocketMessageUsecase_ProcessMessage_Validation(t *testing.T) {
+	now := time.Now()
+	metadata := func(messageType string) domain.MessageMetadata {
+		return domain.MessageMetadata{Channel: "channel-1", MessageNumber: 1, MessageTime: now, MessageType: messageType}
+	}
+
+	testCases := []struct {
+		name           string
+		message        *domain.RocketMessage
+		expectedFields []domain.FieldError
+	}{
+		{
+			name:    "invalid_metadata",
+			message: &domain.RocketMessage{Metadata: domain.MessageMetadata{MessageNumber: -1}},
+			expectedFields: []domain.FieldError{
+				{Field: "metadata.channel", Message: "is required"},
+				{Field: "metadata.messageNumber", Message: "must be greater than zero"},
+				{Field: "metadata.messageTime", Message: "is required"},
+				{Field: "metadata.messageType", Message: "is required"},
+			},
+		},
+		{
+			name:    "unknown_message_type",
+			message: &domain.RocketMessage{Metadata: metadata("RocketRefueled"), Message: helper.EncodePayload(map[string]interface{}{})},
+			expectedFields: []domain.FieldError{
+				{Field: "metadata.messageType", Message: `unknown message type "RocketRefueled"`},
+			},
+		},
+		{
+			name:    "missing_payload",
+			message: &domain.RocketMessage{Metadata: metadata(domain.TypeRocketExploded)},
+			expectedFields: []domain.FieldError{
+				{Field: "message", Message: "is required"},
+			},
+		},
+		{
+			name: "invalid_launch",
+			message: &domain.RocketMessage{
+				Metadata: metadata(domain.TypeRocketLaunched),
+				Message:  helper.EncodePayload(map[string]interface{}{"type": 9, "launchSpeed": -500, "mission": " ", "crew": 3}),
+			},
+			expectedFields: []domain.FieldError{
+				{Field: "message.type", Message: "must be a string"},
+				{Field: "message.launchSpeed", Message: "must be greater than zero"},
+				{Field: "message.mission", Message: "must not be empty"},
+				{Field: "message.crew", Message: "is not a field of RocketLaunched"},
+			},
+		},
+		{
+			name:    "negative_speed_change",
+			message: &domain.RocketMessage{Metadata: metadata(domain.TypeRocketSpeedDecreased), Message: helper.EncodePayload(domain.RocketSpeedDecreasedMessage{By: -100})},
+			expectedFields: []domain.FieldError{
+				{Field: "message.by", Message: "must be greater than zero"},
+			},
+		},
+		{
+			name:    "fractional_speed_change",
+			message: &domain.RocketMessage{Metadata: metadata(domain.TypeRocketSpeedIncreased), Message: helper.EncodePayload(map[string]interface{}{"by": 1.5})},
+			expectedFields: []domain.FieldError{
+				{Field: "message.by", Message: "must be an integer"},
+			},
+		},
+		{
+			name:    "missing_mission",
+			message: &domain.RocketMessage{Metadata: metadata(domain.TypeRocketMissionChanged), Message: helper.EncodePayload(map[string]interface{}{"mission": "APOLLO"})},
+			expectedFields: []domain.FieldError{
+				{Field: "message.newMission", Message: "must not be empty"},
+				{Field: "message.mission", Message: "is not a field of RocketMissionChanged"},
+			},
+		},
+		{
+			name: "impossible_telemetry",
+			message: &domain.RocketMessage{
+				Metadata: metadata(domain.TypeRocketTelemetry),
+				Message:  helper.EncodePayload(map[string]interface{}{"altitude": -10, "latitude": 91, "longitude": -180.5, "fuel": 120}),
+			},
+			expectedFields: []domain.FieldError{
+				{Field: "message.altitude", Message: "must not be less than 0"},
+				{Field: "message.latitude", Message: "must be between -90 and 90"},
+				{Field: "message.longitude", Message: "must be between -180 and 180"},
+				{Field: "message.fuel", Message: "must be between 0 and 100"},
+				{Field: "message.heading", Message: "is required"},
+			},
+		},
+		{
+			name: "non_numeric_telemetry",
+			message: &domain.RocketMessage{
+				Metadata: metadata(domain.TypeRocketTelemetry),
+				Message:  helper.EncodePayload(map[string]interface{}{"altitude": "high", "latitude": 0, "longitude": 0, "fuel": 50, "heading": 361}),
+			},
+			expectedFields: []domain.FieldError{
+				{Field: "message.altitude", Message: "must be a number"},
+				{Field: "message.heading", Message: "must be between 0 and 360"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Invalid messages are rejected before any repository is used
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, &mocks.MockMessageRepository{}, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+
+			err := useCase.ProcessMessage(context.Background(), tc.message)
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expectedFields, validationErr.Fields)
+			mockRocketStateUsecase.AssertExpectations(t)
 		})
 	}
 }
@@ -157,8 +527,11 @@ func TestRocketMessageUsecase_ProcessBufferedMessages(t *testing.T) {
 		name                string
 		lastProcessedNumber int64
 		stateUsecaseError   error
+		deadLetterError     error
 		expectedError       string
 		expectedProcessed   []int64 // Message numbers that should be processed
+		expectedBuffered    []int64 // Message numbers left in the buffer
+		expectedDeadLetters []int64
 	}{
 		{
 			name:                "process_all_buffered_messages",
@@ -173,13 +546,24 @@ func TestRocketMessageUsecase_ProcessBufferedMessages(t *testing.T) {
 			stateUsecaseError:   nil,
 			expectedError:       "",
 			expectedProcessed:   []int64{2, 3}, // Should process both 2 and 3 since they're consecutive
+			expectedBuffered:    []int64{1},
 		},
 		{
 			name:                "error_processing_buffered_message",
 			lastProcessedNumber: 0,
 			stateUsecaseError:   errors.New("state processing error"),
-			expectedError:       "failed to process buffered message 1: state processing error",
 			expectedProcessed:   []int64{1},
+			expectedBuffered:    []int64{2, 3}, // Waiting for the dead letter to be replayed
+			expectedDeadLetters: []int64{1},
+		},
+		{
+			name:                "error_dead_lettering_buffered_message",
+			lastProcessedNumber: 0,
+			stateUsecaseError:   errors.New("state processing error"),
+			deadLetterError:     errors.New("disk full"),
+			expectedError:       "failed to dead-letter message 1 for channel test-channel: disk full",
+			expectedProcessed:   []int64{1},
+			expectedBuffered:    []int64{1, 2, 3},
 		},
 	}
 
@@ -205,12 +589,24 @@ func TestRocketMessageUsecase_ProcessBufferedMessages(t *testing.T) {
 				}
 			}
 
+			var deadLetters []int64
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					if tc.deadLetterError != nil {
+						return tc.deadLetterError
+					}
+					deadLetters = append(deadLetters, deadLetter.MessageNumber)
+					return nil
+				},
+			}
+
 			// Create use case with mock dependencies
-			useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, mockRocketStateUsecase)
+			useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
 
 			// Add messages to buffer
 			for _, msg := range messages {
-				useCase.(*rocketMessageUsecase).addToBuffer(msg)
+				_, err := useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), msg)
+				require.NoError(t, err)
 			}
 
 			// Process buffered messages
@@ -228,23 +624,29 @@ func TestRocketMessageUsecase_ProcessBufferedMessages(t *testing.T) {
 			mockRocketStateUsecase.AssertExpectations(t)
 
 			// Verify buffer state after processing
-			if tc.stateUsecaseError == nil {
-				// Verify that processed messages were removed from buffer
-				for _, msgNum := range tc.expectedProcessed {
-					assert.NotContains(t, useCase.(*rocketMessageUsecase).messageBuffer[channel], msgNum, "Processed message should be removed from buffer")
-				}
-
-				// Verify that unprocessed messages are still in the buffer
-				for _, msg := range messages {
-					if !contains(tc.expectedProcessed, msg.Metadata.MessageNumber) {
-						assert.Contains(t, useCase.(*rocketMessageUsecase).messageBuffer[channel], msg.Metadata.MessageNumber, "Unprocessed message should remain in buffer")
-					}
+			for _, msg := range messages {
+				if contains(tc.expectedBuffered, msg.Metadata.MessageNumber) {
+					assert.Contains(t, defaultBuffer(useCase, channel), msg.Metadata.MessageNumber, "Unprocessed message should remain in buffer")
+				} else {
+					assert.NotContains(t, defaultBuffer(useCase, channel), msg.Metadata.MessageNumber, "Processed message should be removed from buffer")
 				}
 			}
+			assert.Equal(t, tc.expectedDeadLetters, deadLetters)
 		})
 	}
 }
 
+// defaultBuffer returns the buffered messages of a channel of the default tenant
+func defaultBuffer(useCase RocketMessageUsecase, channel string) map[int64]*domain.RocketMessage {
+	buffered := make(map[int64]*domain.RocketMessage)
+	if channelBuffer, exists := useCase.(*rocketMessageUsecase).messageBuffer.channels[bufferKey{tenantID: domain.DefaultTenantID, channel: channel}]; exists {
+		for number, message := range channelBuffer.messages {
+			buffered[number] = message.message
+		}
+	}
+	return buffered
+}
+
 // Helper function to check if a slice contains a value
 func contains(slice []int64, value int64) bool {
 	for _, v := range slice {
@@ -254,3 +656,1059 @@ func contains(slice []int64, value int64) bool {
 	}
 	return false
 }
+
+func TestRocketMessageUsecase_ListChannels(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	processedAt := now.Add(-time.Hour)
+	bufferedAt := now.Add(-2 * time.Minute)
+
+	testCases := []struct {
+		name             string
+		processed        []*domain.ChannelProgress
+		buffered         map[string][]int64
+		repoError        error
+		expectedStatuses []*domain.ChannelStatus
+		expectedError    string
+	}{
+		{
+			name:             "no_channels",
+			expectedStatuses: []*domain.ChannelStatus{},
+		},
+		{
+			name: "processed_and_buffered_channels",
+			processed: []*domain.ChannelProgress{
+				{Channel: "channel-1", LastProcessed: 1, LastProcessedAt: processedAt},
+				{Channel: "channel-3", LastProcessed: 7, LastProcessedAt: processedAt},
+			},
+			buffered: map[string][]int64{
+				"channel-2": {5},
+				"channel-1": {9, 4, 5, 7},
+			},
+			expectedStatuses: []*domain.ChannelStatus{
+				{
+					Channel:                  "channel-1",
+					LastProcessed:            1,
+					Buffered:                 []int64{4, 5, 7, 9},
+					Missing:                  []domain.MessageRange{{From: 2, To: 3}, {From: 6, To: 6}, {From: 8, To: 8}},
+					LastMessageAt:            &bufferedAt,
+					SinceLastMessageSeconds:  120,
+					OldestBufferedAt:         &bufferedAt,
+					OldestBufferedAgeSeconds: 120,
+				},
+				{
+					Channel:                  "channel-2",
+					Buffered:                 []int64{5},
+					Missing:                  []domain.MessageRange{{From: 1, To: 4}},
+					LastMessageAt:            &bufferedAt,
+					SinceLastMessageSeconds:  120,
+					OldestBufferedAt:         &bufferedAt,
+					OldestBufferedAgeSeconds: 120,
+				},
+				{
+					Channel:                 "channel-3",
+					LastProcessed:           7,
+					Buffered:                []int64{},
+					Missing:                 []domain.MessageRange{},
+					LastMessageAt:           &processedAt,
+					SinceLastMessageSeconds: 3600,
+				},
+			},
+		},
+		{
+			name:          "repository_error",
+			buffered:      map[string][]int64{"channel-1": {3}},
+			repoError:     errors.New("database error"),
+			expectedError: "failed to list channel progress: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockMessageRepo := &mocks.MockMessageRepository{
+				ListChannelProgressFunc: func(ctx context.Context) ([]*domain.ChannelProgress, error) {
+					return tc.processed, tc.repoError
+				},
+			}
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, &mocks.MockRocketStateUsecase{}, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+			useCase.(*rocketMessageUsecase).now = func() time.Time { return bufferedAt }
+			for channel, numbers := range tc.buffered {
+				for _, number := range numbers {
+					useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), helper.CreateTestMessage(channel, domain.TypeRocketSpeedIncreased, number, now))
+				}
+			}
+			useCase.(*rocketMessageUsecase).now = func() time.Time { return now }
+
+			statuses, err := useCase.ListChannels(context.Background())
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedStatuses, statuses)
+			}
+		})
+	}
+}
+
+func TestRocketMessageUsecase_GetChannel(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	processedAt := now.Add(-time.Hour)
+	oldestAt := now.Add(-90 * time.Second)
+	newestAt := now.Add(-30 * time.Second)
+
+	type buffered struct {
+		number     int64
+		receivedAt time.Time
+	}
+
+	testCases := []struct {
+		name           string
+		progress       *domain.ChannelProgress
+		buffered       []buffered
+		repoError      error
+		expectedStatus *domain.ChannelStatus
+		expectedError  error
+	}{
+		{
+			name:     "processed_without_gap",
+			progress: &domain.ChannelProgress{Channel: "channel-1", LastProcessed: 3, LastProcessedAt: processedAt},
+			expectedStatus: &domain.ChannelStatus{
+				Channel:                 "channel-1",
+				LastProcessed:           3,
+				Buffered:                []int64{},
+				Missing:                 []domain.MessageRange{},
+				LastMessageAt:           &processedAt,
+				SinceLastMessageSeconds: 3600,
+			},
+		},
+		{
+			name:     "waiting_for_first_messages",
+			buffered: []buffered{{number: 5, receivedAt: newestAt}, {number: 3, receivedAt: oldestAt}},
+			expectedStatus: &domain.ChannelStatus{
+				Channel:                  "channel-1",
+				Buffered:                 []int64{3, 5},
+				Missing:                  []domain.MessageRange{{From: 1, To: 2}, {From: 4, To: 4}},
+				LastMessageAt:            &newestAt,
+				SinceLastMessageSeconds:  30,
+				OldestBufferedAt:         &oldestAt,
+				OldestBufferedAgeSeconds: 90,
+			},
+		},
+		{
+			name:          "not_found",
+			expectedError: domain.ErrChannelNotFound,
+		},
+		{
+			name:          "repository_error",
+			repoError:     errors.New("database error"),
+			expectedError: errors.New("failed to get progress of channel channel-1: database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindChannelProgressFunc: func(ctx context.Context, channel string) (*domain.ChannelProgress, error) {
+					assert.Equal(t, "channel-1", channel)
+					return tc.progress, tc.repoError
+				},
+			}
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, &mocks.MockRocketStateUsecase{}, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+			for _, message := range tc.buffered {
+				receivedAt := message.receivedAt
+				useCase.(*rocketMessageUsecase).now = func() time.Time { return receivedAt }
+				useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, message.number, now))
+			}
+			useCase.(*rocketMessageUsecase).now = func() time.Time { return now }
+
+			status, err := useCase.GetChannel(context.Background(), "channel-1")
+
+			if tc.expectedError != nil {
+				assert.EqualError(t, err, tc.expectedError.Error())
+				assert.Nil(t, status)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestRocketMessageUsecase_SkipGap(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name              string
+		lastProcessed     int64
+		buffered          []int64
+		to                int64
+		applied           []int64 // Buffered messages the state use case applies
+		markError         error
+		expectedSkip      *domain.GapSkip
+		expectedMarked    int64
+		expectedBuffered  map[string][]int64
+		expectedError     string
+		expectedErrorType error
+	}{
+		{
+			name:             "first_gap",
+			lastProcessed:    1,
+			buffered:         []int64{4, 5, 7},
+			applied:          []int64{4, 5},
+			expectedSkip:     &domain.GapSkip{From: 1, To: 3, Applied: []int64{4, 5}},
+			expectedMarked:   3,
+			expectedBuffered: map[string][]int64{"channel-1": {7}},
+		},
+		{
+			name:             "past_buffered_messages",
+			lastProcessed:    1,
+			buffered:         []int64{4, 5, 7},
+			to:               5,
+			expectedSkip:     &domain.GapSkip{From: 1, To: 5, Discarded: []int64{4, 5}},
+			expectedMarked:   5,
+			expectedBuffered: map[string][]int64{"channel-1": {7}},
+		},
+		{
+			name:             "without_buffered_messages",
+			lastProcessed:    1,
+			to:               4,
+			expectedSkip:     &domain.GapSkip{From: 1, To: 4},
+			expectedMarked:   4,
+			expectedBuffered: map[string][]int64{},
+		},
+		{
+			name:              "no_gap",
+			lastProcessed:     1,
+			expectedErrorType: domain.ErrNothingToSkip,
+			expectedBuffered:  map[string][]int64{},
+		},
+		{
+			name:              "already_processed",
+			lastProcessed:     3,
+			buffered:          []int64{5},
+			to:                2,
+			expectedErrorType: domain.ErrNothingToSkip,
+			expectedBuffered:  map[string][]int64{"channel-1": {5}},
+		},
+		{
+			name:             "mark_error",
+			lastProcessed:    1,
+			buffered:         []int64{3},
+			markError:        errors.New("database error"),
+			expectedError:    "failed to skip to message 2: database error",
+			expectedBuffered: map[string][]int64{"channel-1": {3}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The last processed message moves with the skip and each applied
+			// message
+			last, marked := tc.lastProcessed, int64(0)
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					return last, nil
+				},
+				MarkAsProcessedFunc: func(ctx context.Context, channel string, messageNumber int64, payloadHash string) error {
+					assert.Empty(t, payloadHash)
+					if tc.markError != nil {
+						return tc.markError
+					}
+					last, marked = messageNumber, messageNumber
+					return nil
+				},
+			}
+
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			for _, number := range tc.applied {
+				number := number
+				mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.MatchedBy(func(message *domain.RocketMessage) bool {
+					return message.Metadata.MessageNumber == number
+				})).Run(func(mock.Arguments) { last = number }).Return(nil).Once()
+			}
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+			settled := make(map[int64]string)
+			for _, number := range tc.buffered {
+				number := number
+				ctx := domain.ContextWithSettle(context.Background(), func(status string, reason string) {
+					settled[number] = status
+				})
+				useCase.(*rocketMessageUsecase).addToBuffer(ctx, helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, number, now))
+			}
+
+			skip, err := useCase.SkipGap(context.Background(), "channel-1", tc.to)
+			if err == nil {
+				// Nothing leaves the buffer until the skip is committed
+				if len(tc.buffered) > 0 {
+					assert.Equal(t, map[string][]int64{"channel-1": tc.buffered}, useCase.(*rocketMessageUsecase).bufferedNumbers(domain.DefaultTenantID))
+				}
+				err = useCase.ApplyAfterSkip(context.Background(), "channel-1", skip)
+			}
+
+			switch {
+			case tc.expectedErrorType != nil:
+				assert.ErrorIs(t, err, tc.expectedErrorType)
+				assert.Nil(t, skip)
+			case tc.expectedError != "":
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, skip)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedSkip, skip)
+				assert.Equal(t, tc.expectedMarked, marked)
+			}
+			assert.Equal(t, tc.expectedBuffered, useCase.(*rocketMessageUsecase).bufferedNumbers(domain.DefaultTenantID))
+
+			// The receipts of the messages leaving the buffer are settled
+			expectedSettled := make(map[int64]string)
+			if tc.expectedSkip != nil {
+				for _, number := range tc.expectedSkip.Discarded {
+					expectedSettled[number] = domain.ReceiptStatusDiscarded
+				}
+				for _, number := range tc.expectedSkip.Applied {
+					expectedSettled[number] = domain.ReceiptStatusApplied
+				}
+			}
+			assert.Equal(t, expectedSettled, settled)
+			mockRocketStateUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRocketMessageUsecase_DiscardBuffer(t *testing.T) {
+	now := time.Now()
+	tenantB := domain.ContextWithTenant(context.Background(), "tenant-b")
+
+	useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, &mocks.MockMessageRepository{}, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, &mocks.MockRocketStateUsecase{}, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+	for _, number := range []int64{7, 3, 5} {
+		useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, number, now))
+	}
+	useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), helper.CreateTestMessage("channel-2", domain.TypeRocketSpeedIncreased, 4, now))
+	useCase.(*rocketMessageUsecase).addToBuffer(tenantB, helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 9, now))
+
+	assert.Equal(t, []int64{3, 5, 7}, useCase.DiscardBuffer(context.Background(), "channel-1"))
+	assert.Empty(t, useCase.DiscardBuffer(context.Background(), "channel-1"))
+
+	// Other channels and tenants keep their buffered messages
+	assert.Equal(t, map[string][]int64{"channel-2": {4}}, useCase.(*rocketMessageUsecase).bufferedNumbers(domain.DefaultTenantID))
+	assert.Equal(t, map[string][]int64{"channel-1": {9}}, useCase.(*rocketMessageUsecase).bufferedNumbers("tenant-b"))
+}
+
+func TestRocketMessageUsecase_ProcessBufferedMessages_UnlocksBuffer(t *testing.T) {
+	now := time.Now()
+	buffered := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now)
+
+	// Other channels are buffered while a buffered message is being applied
+	var useCase RocketMessageUsecase
+	mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+	mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, buffered).Run(func(args mock.Arguments) {
+		added := make(chan struct{})
+		go func() {
+			useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), helper.CreateTestMessage("channel-2", domain.TypeRocketSpeedIncreased, 5, now))
+			close(added)
+		}()
+		select {
+		case <-added:
+		case <-time.After(5 * time.Second):
+			t.Error("buffer locked while a buffered message is applied")
+		}
+	}).Return(nil).Once()
+
+	useCase = NewRocketMessageUsecase(&mocks.MockRocketRepository{}, &mocks.MockMessageRepository{}, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+	_, err := useCase.(*rocketMessageUsecase).addToBuffer(context.Background(), buffered)
+	require.NoError(t, err)
+
+	require.NoError(t, useCase.(*rocketMessageUsecase).processBufferedMessages(context.Background(), "channel-1", 1))
+
+	mockRocketStateUsecase.AssertExpectations(t)
+	assert.Equal(t, map[string][]int64{"channel-2": {5}}, useCase.(*rocketMessageUsecase).bufferedNumbers(domain.DefaultTenantID))
+}
+
+func TestRocketMessageUsecase_TenantIsolation(t *testing.T) {
+	now := time.Now()
+	tenantA := domain.ContextWithTenant(context.Background(), "tenant-a")
+	tenantB := domain.ContextWithTenant(context.Background(), "tenant-b")
+
+	// Both tenants have processed message 1 of channel-1
+	mockMessageRepo := &mocks.MockMessageRepository{
+		FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+			return 1, nil
+		},
+		ListChannelProgressFunc: func(ctx context.Context) ([]*domain.ChannelProgress, error) {
+			return []*domain.ChannelProgress{{Channel: "channel-1", LastProcessed: 1, LastProcessedAt: now}}, nil
+		},
+	}
+
+	bufferedA := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 3, now)
+	nextB := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now)
+
+	// Only tenant B's message is applied, and with tenant B's context
+	mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+	mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.TenantFromContext(ctx) == "tenant-b"
+	}), nextB).Return(nil).Once()
+
+	useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+
+	require.NoError(t, useCase.ProcessMessage(tenantA, bufferedA))
+	require.NoError(t, useCase.ProcessMessage(tenantB, nextB))
+
+	// Tenant B filling its own gap does not release tenant A's buffered message
+	mockRocketStateUsecase.AssertExpectations(t)
+
+	channelsA, err := useCase.ListChannels(tenantA)
+	require.NoError(t, err)
+	require.Len(t, channelsA, 1)
+	assert.Equal(t, []int64{3}, channelsA[0].Buffered)
+	assert.Equal(t, []domain.MessageRange{{From: 2, To: 2}}, channelsA[0].Missing)
+
+	channelsB, err := useCase.ListChannels(tenantB)
+	require.NoError(t, err)
+	require.Len(t, channelsB, 1)
+	assert.Empty(t, channelsB[0].Buffered)
+	assert.Nil(t, channelsB[0].OldestBufferedAt)
+}
+
+func TestRocketMessageUsecase_BufferLimits(t *testing.T) {
+	now := time.Now()
+
+	type sent struct {
+		channel string
+		number  int64
+	}
+
+	testCases := []struct {
+		name                string
+		limits              BufferLimits
+		otherTenant         []sent // Sent first by tenant-b, whose buffer is apart
+		messages            []sent
+		saveError           error
+		expectedError       string // Of the last message, the others are buffered
+		expectedBuffered    map[string][]int64
+		expectedDeadLetters []string
+	}{
+		{
+			name:             "reject_over_channel_cap",
+			limits:           BufferLimits{MaxChannelMessages: 2, Policy: BufferPolicyReject},
+			messages:         []sent{{"channel-1", 2}, {"channel-1", 3}, {"channel-1", 4}},
+			expectedError:    "channel message buffer full",
+			expectedBuffered: map[string][]int64{"channel-1": {2, 3}},
+		},
+		{
+			name:             "reject_over_global_cap",
+			limits:           BufferLimits{MaxMessages: 2, Policy: BufferPolicyReject},
+			messages:         []sent{{"channel-1", 2}, {"channel-2", 2}, {"channel-1", 3}},
+			expectedError:    "message buffer full",
+			expectedBuffered: map[string][]int64{"channel-1": {2}, "channel-2": {2}},
+		},
+		{
+			name:             "global_cap_per_tenant",
+			limits:           BufferLimits{MaxMessages: 2, Policy: BufferPolicyReject},
+			otherTenant:      []sent{{"channel-1", 2}, {"channel-1", 3}},
+			messages:         []sent{{"channel-1", 2}, {"channel-2", 2}},
+			expectedBuffered: map[string][]int64{"channel-1": {2}, "channel-2": {2}},
+		},
+		{
+			name:             "reject_too_large",
+			limits:           BufferLimits{MaxBytes: 10, Policy: BufferPolicyReject},
+			messages:         []sent{{"channel-1", 2}},
+			expectedError:    "message buffer full",
+			expectedBuffered: map[string][]int64{},
+		},
+		{
+			name:                "evict_oldest_of_channel",
+			limits:              BufferLimits{MaxMessages: 10, MaxChannelMessages: 2, Policy: BufferPolicyEvictOldest},
+			messages:            []sent{{"channel-2", 2}, {"channel-1", 3}, {"channel-1", 2}, {"channel-1", 4}},
+			expectedBuffered:    map[string][]int64{"channel-1": {2, 4}, "channel-2": {2}},
+			expectedDeadLetters: []string{"channel-1/3"},
+		},
+		{
+			name:                "evict_oldest_of_every_channel",
+			limits:              BufferLimits{MaxMessages: 2, Policy: BufferPolicyEvictOldest},
+			messages:            []sent{{"channel-1", 2}, {"channel-2", 2}, {"channel-1", 3}},
+			expectedBuffered:    map[string][]int64{"channel-1": {3}, "channel-2": {2}},
+			expectedDeadLetters: []string{"channel-1/2"},
+		},
+		{
+			name:                "evict_oldest_of_tenant",
+			limits:              BufferLimits{MaxMessages: 2, Policy: BufferPolicyEvictOldest},
+			otherTenant:         []sent{{"channel-1", 2}},
+			messages:            []sent{{"channel-1", 2}, {"channel-2", 2}, {"channel-1", 3}},
+			expectedBuffered:    map[string][]int64{"channel-1": {3}, "channel-2": {2}},
+			expectedDeadLetters: []string{"channel-1/2"},
+		},
+		{
+			name:                "evict_newest",
+			limits:              BufferLimits{MaxChannelMessages: 2, Policy: BufferPolicyEvictNewest},
+			messages:            []sent{{"channel-1", 2}, {"channel-1", 3}, {"channel-1", 4}},
+			expectedBuffered:    map[string][]int64{"channel-1": {2, 3}},
+			expectedDeadLetters: []string{"channel-1/4"},
+		},
+		{
+			name:                "evict_too_large",
+			limits:              BufferLimits{MaxChannelBytes: 10, Policy: BufferPolicyEvictOldest},
+			messages:            []sent{{"channel-1", 2}},
+			expectedBuffered:    map[string][]int64{},
+			expectedDeadLetters: []string{"channel-1/2"},
+		},
+		{
+			name:             "message_sent_again_replaces_copy",
+			limits:           BufferLimits{MaxChannelMessages: 2, Policy: BufferPolicyReject},
+			messages:         []sent{{"channel-1", 2}, {"channel-1", 2}, {"channel-1", 3}},
+			expectedBuffered: map[string][]int64{"channel-1": {2, 3}},
+		},
+		{
+			name:             "dead_letter_error_keeps_buffer",
+			limits:           BufferLimits{MaxChannelMessages: 1, Policy: BufferPolicyEvictOldest},
+			messages:         []sent{{"channel-1", 2}, {"channel-1", 3}},
+			saveError:        errors.New("database error"),
+			expectedError:    "failed to dead-letter message 2 for channel channel-1: database error",
+			expectedBuffered: map[string][]int64{"channel-1": {2}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Nothing is processed yet, so every message after the first is buffered
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					return 0, nil
+				},
+			}
+
+			var deadLetters []string
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					if tc.saveError != nil {
+						return tc.saveError
+					}
+					assert.Equal(t, "tenant-a", domain.TenantFromContext(ctx))
+					assert.NotEmpty(t, deadLetter.Message)
+					assert.NotEmpty(t, deadLetter.Reason)
+					deadLetters = append(deadLetters, fmt.Sprintf("%s/%d", deadLetter.Channel, deadLetter.MessageNumber))
+					return nil
+				},
+			}
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, &mocks.MockRocketStateUsecase{}, newMessageRegistry(t), tc.limits, nil, newConflictCounter())
+			ctx := domain.ContextWithTenant(context.Background(), "tenant-a")
+
+			otherCtx := domain.ContextWithTenant(context.Background(), "tenant-b")
+			for _, message := range tc.otherTenant {
+				require.NoError(t, useCase.ProcessMessage(otherCtx, helper.CreateTestMessage(message.channel, domain.TypeRocketSpeedIncreased, message.number, now)))
+			}
+
+			for i, message := range tc.messages {
+				err := useCase.ProcessMessage(ctx, helper.CreateTestMessage(message.channel, domain.TypeRocketSpeedIncreased, message.number, now))
+				if i == len(tc.messages)-1 && tc.expectedError != "" {
+					assert.EqualError(t, err, tc.expectedError)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+
+			assert.Equal(t, tc.expectedBuffered, useCase.(*rocketMessageUsecase).bufferedNumbers("tenant-a"))
+			assert.Equal(t, tc.expectedDeadLetters, deadLetters)
+			// The other tenant's messages are never evicted for this one's
+			assert.Len(t, useCase.(*rocketMessageUsecase).bufferedNumbers("tenant-b")["channel-1"], len(tc.otherTenant))
+		})
+	}
+}
+
+func TestRocketMessageUsecase_SettleBufferedMessages(t *testing.T) {
+	now := time.Now()
+
+	type sent struct {
+		sender string // Told what became of the message, empty for none
+		number int64
+	}
+
+	testCases := []struct {
+		name            string
+		limits          BufferLimits
+		failOn          int64 // Message failing to apply
+		messages        []sent
+		discard         bool // Whether the buffer is discarded afterwards
+		expectedSettled map[string]string
+	}{
+		{
+			name:            "applied",
+			limits:          DefaultBufferLimits(),
+			messages:        []sent{{"a", 2}, {"b", 3}, {"", 1}},
+			expectedSettled: map[string]string{"a": domain.ReceiptStatusApplied, "b": domain.ReceiptStatusApplied},
+		},
+		{
+			name:            "copies_settled_together",
+			limits:          DefaultBufferLimits(),
+			messages:        []sent{{"a", 2}, {"b", 2}, {"", 1}},
+			expectedSettled: map[string]string{"a": domain.ReceiptStatusApplied, "b": domain.ReceiptStatusApplied},
+		},
+		{
+			name:            "failed",
+			limits:          DefaultBufferLimits(),
+			failOn:          2,
+			messages:        []sent{{"a", 2}, {"b", 3}, {"", 1}},
+			expectedSettled: map[string]string{"a": domain.ReceiptStatusDeadLettered},
+		},
+		{
+			name:            "evicted",
+			limits:          BufferLimits{MaxChannelMessages: 1, Policy: BufferPolicyEvictOldest},
+			messages:        []sent{{"a", 2}, {"b", 3}},
+			expectedSettled: map[string]string{"a": domain.ReceiptStatusDeadLettered},
+		},
+		{
+			name:            "discarded",
+			limits:          DefaultBufferLimits(),
+			messages:        []sent{{"a", 2}, {"b", 3}},
+			discard:         true,
+			expectedSettled: map[string]string{"a": domain.ReceiptStatusDiscarded, "b": domain.ReceiptStatusDiscarded},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					return 0, nil
+				},
+			}
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					return nil
+				},
+			}
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.MatchedBy(func(message *domain.RocketMessage) bool {
+				return message.Metadata.MessageNumber == tc.failOn
+			})).Return(errors.New("state processing error")).Maybe()
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), tc.limits, nil, newConflictCounter())
+
+			settled := make(map[string]string)
+			for _, message := range tc.messages {
+				ctx := context.Background()
+				if message.sender != "" {
+					sender := message.sender
+					ctx = domain.ContextWithSettle(ctx, func(status string, reason string) {
+						_, exists := settled[sender]
+						assert.False(t, exists, "%s settled twice", sender)
+						settled[sender] = status
+					})
+				}
+				_, _ = useCase.ApplyMessage(ctx, helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, message.number, now))
+			}
+			if tc.discard {
+				useCase.DiscardBuffer(context.Background(), "channel-1")
+			}
+
+			assert.Equal(t, tc.expectedSettled, settled)
+		})
+	}
+}
+
+func TestBufferLimits_Validate(t *testing.T) {
+	assert.NoError(t, DefaultBufferLimits().Validate())
+	assert.EqualError(t, BufferLimits{MaxBytes: -1, Policy: BufferPolicyReject}.Validate(), "buffer limits must not be negative")
+	assert.EqualError(t, BufferLimits{Policy: "drop"}.Validate(), `unknown buffer policy "drop"`)
+}
+
+func TestRocketMessageUsecase_ProcessBatch(t *testing.T) {
+	now := time.Now()
+	invalid := helper.CreateTestMessage("channel-3", domain.TypeRocketSpeedIncreased, 1, now)
+	invalid.Metadata.MessageType = ""
+
+	messages := []*domain.RocketMessage{
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 3, now),
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now),
+		helper.CreateTestMessage("channel-2", domain.TypeRocketLaunched, 1, now),
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 1, now),
+		invalid,
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 5, now),
+		helper.CreateTestMessage("channel-2", domain.TypeRocketSpeedIncreased, 2, now),
+	}
+
+	testCases := []struct {
+		name              string
+		stateError        error
+		expectedStatuses  []string
+		expectedBuffered  map[string][]int64
+		expectedCommits   int
+		expectedRollbacks int
+	}{
+		{
+			name: "applied_by_channel",
+			expectedStatuses: []string{
+				domain.ReceiptStatusApplied,
+				domain.ReceiptStatusApplied,
+				domain.ReceiptStatusApplied,
+				domain.ReceiptStatusDuplicate,
+				domain.MessageStatusError,
+				domain.ReceiptStatusBuffered,
+				domain.ReceiptStatusApplied,
+			},
+			expectedBuffered: map[string][]int64{"channel-1": {5}},
+			expectedCommits:  2,
+		},
+		{
+			// The messages are then applied one by one, the first one of each
+			// channel is dead-lettered and the others wait for it
+			name:       "failed_channel_rolled_back",
+			stateError: errors.New("database error"),
+			expectedStatuses: []string{
+				domain.ReceiptStatusBuffered,
+				domain.ReceiptStatusDeadLettered,
+				domain.ReceiptStatusDeadLettered,
+				domain.ReceiptStatusDuplicate,
+				domain.MessageStatusError,
+				domain.ReceiptStatusBuffered,
+				domain.ReceiptStatusBuffered,
+			},
+			expectedBuffered:  map[string][]int64{"channel-1": {3, 5}, "channel-2": {2}},
+			expectedRollbacks: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var commits, rollbacks int
+			tx := &mocks.MockTransaction{
+				CommitFunc: func() error {
+					commits++
+					return nil
+				},
+				RollbackFunc: func() error {
+					rollbacks++
+					return nil
+				},
+			}
+			mockRocketRepo := &mocks.MockRocketRepository{
+				BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
+					return tx, nil
+				},
+			}
+
+			// Channel 1 has processed message 1, channel 2 nothing yet
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					if channel == "channel-1" {
+						return 1, nil
+					}
+					return 0, nil
+				},
+				FindPayloadHashFunc: func(ctx context.Context, channel string, messageNumber int64) (string, error) {
+					return domain.PayloadHash(messages[3])
+				},
+			}
+
+			// The consecutive messages of a channel share one transaction
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.MatchedBy(func(ctx context.Context) bool {
+				current, ok := domain.TransactionFromContext(ctx)
+				return ok && current == tx
+			}), mock.Anything).Return(tc.stateError)
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).Return(tc.stateError).Maybe()
+
+			mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+				SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+					return nil
+				},
+			}
+
+			useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+
+			results := useCase.ProcessBatch(context.Background(), messages)
+
+			require.Len(t, results, len(messages))
+			for i, result := range results {
+				assert.Equal(t, i, result.Index)
+				assert.Equal(t, messages[i].Metadata.Channel, result.Channel)
+				assert.Equal(t, tc.expectedStatuses[i], result.Status, "message %d", i)
+			}
+			assert.Equal(t, []domain.FieldError{{Field: "metadata.messageType", Message: "is required"}}, results[4].Fields)
+			if tc.stateError != nil {
+				assert.Equal(t, "failed to execute rocket state usecase: database error (message dead-lettered)", results[1].Error)
+			}
+			assert.Equal(t, tc.expectedCommits, commits)
+			assert.Equal(t, tc.expectedRollbacks, rollbacks)
+			assert.Equal(t, tc.expectedBuffered, useCase.(*rocketMessageUsecase).bufferedNumbers(domain.DefaultTenantID))
+		})
+	}
+}
+
+func TestRocketMessageUsecase_ProcessBatch_FailedMessage(t *testing.T) {
+	now := time.Now()
+	messages := []*domain.RocketMessage{
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now),
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 3, now),
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 4, now),
+	}
+
+	var rollbacks int
+	mockRocketRepo := &mocks.MockRocketRepository{
+		BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
+			return &mocks.MockTransaction{
+				CommitFunc: func() error { return nil },
+				RollbackFunc: func() error {
+					rollbacks++
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	// Message 1 is processed, and the applied messages follow it
+	lastMessageNumber := int64(1)
+	mockMessageRepo := &mocks.MockMessageRepository{
+		FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+			return lastMessageNumber, nil
+		},
+	}
+
+	var deadLetters []*domain.DeadLetter
+	mockDeadLetterRepo := &mocks.MockDeadLetterRepository{
+		SaveFunc: func(ctx context.Context, deadLetter *domain.DeadLetter) error {
+			deadLetters = append(deadLetters, deadLetter)
+			return nil
+		},
+	}
+
+	// Message 3 cannot be applied, in the batch transaction or on its own
+	isMessage := func(number int64) any {
+		return mock.MatchedBy(func(m *domain.RocketMessage) bool { return m.Metadata.MessageNumber == number })
+	}
+	mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+	mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, isMessage(3)).Return(errors.New("corrupt payload"))
+	mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		if _, ok := domain.TransactionFromContext(args.Get(0).(context.Context)); !ok {
+			lastMessageNumber = args.Get(1).(*domain.RocketMessage).Metadata.MessageNumber
+		}
+	})
+
+	useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, mockDeadLetterRepo, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+	failedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	useCase.(*rocketMessageUsecase).now = func() time.Time { return failedAt }
+
+	results := useCase.ProcessBatch(context.Background(), messages)
+
+	// Only the failing message is dead-lettered, and the one after it waits
+	// for it to be replayed
+	statuses := make([]string, len(results))
+	for i, result := range results {
+		statuses[i] = result.Status
+	}
+	assert.Equal(t, []string{domain.ReceiptStatusApplied, domain.ReceiptStatusDeadLettered, domain.ReceiptStatusBuffered}, statuses)
+	assert.Empty(t, results[0].Error)
+	assert.Empty(t, results[2].Error)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, int64(3), deadLetters[0].MessageNumber)
+	assert.Equal(t, failedAt, deadLetters[0].CreatedAt)
+	assert.Equal(t, 1, rollbacks)
+	assert.Equal(t, map[string][]int64{"channel-1": {4}}, useCase.(*rocketMessageUsecase).bufferedNumbers(domain.DefaultTenantID))
+}
+
+func TestRocketMessageUsecase_ProcessBatch_RetriesTransientErrors(t *testing.T) {
+	errLocked := errors.New("database is locked")
+	now := time.Now()
+	messages := []*domain.RocketMessage{
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 2, now),
+		helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 3, now),
+	}
+
+	testCases := []struct {
+		name             string
+		lockedBegins     int // Attempts to begin failing before one succeeds
+		expectedBegins   int
+		expectedStatuses []string
+	}{
+		{
+			name:             "applied_after_retries",
+			lockedBegins:     2,
+			expectedBegins:   3,
+			expectedStatuses: []string{domain.ReceiptStatusApplied, domain.ReceiptStatusApplied},
+		},
+		{
+			name:             "busy_after_last_attempt",
+			lockedBegins:     3,
+			expectedBegins:   3,
+			expectedStatuses: []string{domain.MessageStatusError, domain.MessageStatusError},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			begins := 0
+			mockRocketRepo := &mocks.MockRocketRepository{
+				BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
+					begins++
+					if begins <= tc.lockedBegins {
+						return nil, errLocked
+					}
+					return &mocks.MockTransaction{
+						CommitFunc:   func() error { return nil },
+						RollbackFunc: func() error { return nil },
+					}, nil
+				},
+			}
+			mockMessageRepo := &mocks.MockMessageRepository{
+				FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+					return 1, nil
+				},
+			}
+			mockRocketStateUsecase := &mocks.MockRocketStateUsecase{}
+			mockRocketStateUsecase.On("UpdateRocketFromMessage", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			policy, err := retry.NewPolicy(3, 0, 0, func(err error) bool {
+				return errors.Is(err, errLocked)
+			})
+			require.NoError(t, err)
+
+			useCase := NewRocketMessageUsecase(mockRocketRepo, mockMessageRepo, &mocks.MockDeadLetterRepository{}, &mocks.MockConflictRepository{}, mockRocketStateUsecase, newMessageRegistry(t), DefaultBufferLimits(), policy, newConflictCounter())
+
+			results := useCase.ProcessBatch(context.Background(), messages)
+
+			statuses := make([]string, len(results))
+			for i, result := range results {
+				statuses[i] = result.Status
+			}
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			assert.Equal(t, tc.expectedBegins, begins)
+			if tc.expectedStatuses[0] == domain.MessageStatusError {
+				assert.Contains(t, results[0].Error, domain.ErrBusy.Error())
+			}
+		})
+	}
+}
+
+func TestRocketMessageUsecase_ProcessBatch_Conflict(t *testing.T) {
+	now := time.Now()
+	processed := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 1, now)
+	processedHash, err := domain.PayloadHash(processed)
+	require.NoError(t, err)
+
+	// Message 1 is sent again as it was processed, and again with another payload
+	conflicting := helper.CreateTestMessage("channel-1", domain.TypeRocketSpeedIncreased, 1, now)
+	conflicting.Message = helper.EncodePayload(domain.RocketSpeedIncreasedMessage{By: 999})
+	messages := []*domain.RocketMessage{processed, conflicting}
+
+	mockMessageRepo := &mocks.MockMessageRepository{
+		FindLastMessageNumberFunc: func(ctx context.Context, channel string) (int64, error) {
+			return 1, nil
+		},
+		FindPayloadHashFunc: func(ctx context.Context, channel string, messageNumber int64) (string, error) {
+			return processedHash, nil
+		},
+	}
+	var conflicts []*domain.MessageConflict
+	mockConflictRepo := &mocks.MockConflictRepository{
+		SaveFunc: func(ctx context.Context, conflict *domain.MessageConflict) error {
+			conflicts = append(conflicts, conflict)
+			return nil
+		},
+	}
+	conflictCounter := newConflictCounter()
+
+	useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, mockMessageRepo, &mocks.MockDeadLetterRepository{}, mockConflictRepo, &mocks.MockRocketStateUsecase{}, newMessageRegistry(t), DefaultBufferLimits(), nil, conflictCounter)
+
+	ctx := domain.ContextWithIdentity(context.Background(), &domain.Identity{Subject: "mission-control"})
+	results := useCase.ProcessBatch(ctx, messages)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, domain.ReceiptStatusDuplicate, results[0].Status)
+	assert.Equal(t, domain.ReceiptStatusConflict, results[1].Status)
+	assert.Equal(t, "message 1 of channel channel-1: message conflicts with the processed one", results[1].Error)
+	if assert.Len(t, conflicts, 1) {
+		assert.Equal(t, processedHash, conflicts[0].ProcessedHash)
+		assert.NotEqual(t, processedHash, conflicts[0].ReceivedHash)
+		assert.Equal(t, "mission-control", conflicts[0].Actor)
+	}
+	assert.Equal(t, 1.0, conflictCounter.Value(domain.TypeRocketSpeedIncreased))
+}
+
+func TestRocketMessageUsecase_ListConflicts(t *testing.T) {
+	conflicts := []*domain.MessageConflict{
+		{ID: 2, Channel: "channel-1", MessageNumber: 3, MessageType: domain.TypeRocketSpeedIncreased, ProcessedHash: "aaa", ReceivedHash: "bbb"},
+		{ID: 1, Channel: "channel-1", MessageNumber: 1, MessageType: domain.TypeRocketLaunched, ProcessedHash: "ccc", ReceivedHash: "ddd"},
+	}
+
+	testCases := []struct {
+		name              string
+		query             domain.ConflictQuery
+		expectedQuery     domain.ConflictQuery
+		repoError         error
+		expectedConflicts []*domain.MessageConflict
+		expectedError     string
+	}{
+		{
+			name:              "default_limit",
+			query:             domain.ConflictQuery{Channel: "channel-1"},
+			expectedQuery:     domain.ConflictQuery{Channel: "channel-1", Limit: DefaultConflictLimit},
+			expectedConflicts: conflicts,
+		},
+		{
+			name:              "explicit_limit",
+			query:             domain.ConflictQuery{Limit: 2},
+			expectedQuery:     domain.ConflictQuery{Limit: 2},
+			expectedConflicts: conflicts,
+		},
+		{
+			name:          "repository_error",
+			expectedQuery: domain.ConflictQuery{Limit: DefaultConflictLimit},
+			repoError:     errors.New("database error"),
+			expectedError: "failed to list message conflicts: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockConflictRepo := &mocks.MockConflictRepository{
+				ListFunc: func(ctx context.Context, query domain.ConflictQuery) ([]*domain.MessageConflict, error) {
+					assert.Equal(t, tc.expectedQuery, query)
+					if tc.repoError != nil {
+						return nil, tc.repoError
+					}
+					return tc.expectedConflicts, nil
+				},
+			}
+
+			useCase := NewRocketMessageUsecase(&mocks.MockRocketRepository{}, &mocks.MockMessageRepository{}, &mocks.MockDeadLetterRepository{}, mockConflictRepo, &mocks.MockRocketStateUsecase{}, newMessageRegistry(t), DefaultBufferLimits(), nil, newConflictCounter())
+			result, err := useCase.ListConflicts(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedConflicts, result)
+			}
+		})
+	}
+}
@@ -3,11 +3,13 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"lunar-rockets/domain"
+	"lunar-rockets/retry"
 )
 
 type RocketStateUsecase interface {
@@ -15,22 +17,52 @@ type RocketStateUsecase interface {
 }
 
 type rocketStateUsecase struct {
-	rocketRepo  domain.RocketRepository
-	messageRepo domain.MessageRepository
+	rocketRepo    domain.RocketRepository
+	messageRepo   domain.MessageRepository
+	outboxRepo    domain.OutboxRepository
+	telemetryRepo domain.TelemetryRepository
+	rejectionRepo domain.RejectionRepository
+	handlers      *domain.MessageRegistry
+	stateMachine  *domain.StateMachine
+	retryPolicy   *retry.Policy
+	now           func() time.Time
 }
 
-func NewRocketStateUsecase(rocketRepo domain.RocketRepository, messageRepo domain.MessageRepository) RocketStateUsecase {
+func NewRocketStateUsecase(rocketRepo domain.RocketRepository, messageRepo domain.MessageRepository, outboxRepo domain.OutboxRepository, telemetryRepo domain.TelemetryRepository, rejectionRepo domain.RejectionRepository, handlers *domain.MessageRegistry, stateMachine *domain.StateMachine, retryPolicy *retry.Policy) RocketStateUsecase {
 	return &rocketStateUsecase{
-		rocketRepo:  rocketRepo,
-		messageRepo: messageRepo,
+		rocketRepo:    rocketRepo,
+		messageRepo:   messageRepo,
+		outboxRepo:    outboxRepo,
+		telemetryRepo: telemetryRepo,
+		rejectionRepo: rejectionRepo,
+		handlers:      handlers,
+		stateMachine:  stateMachine,
+		retryPolicy:   retryPolicy,
+		now:           time.Now,
 	}
 }
 
+// UpdateRocketFromMessage applies a message in a transaction of its own, or
+// in the transaction of ctx, which the caller then commits. A transaction of
+// its own is attempted again when it fails on a lock held by another
+// connection.
 func (u *rocketStateUsecase) UpdateRocketFromMessage(ctx context.Context, message *domain.RocketMessage) error {
+	if _, ok := domain.TransactionFromContext(ctx); ok {
+		return u.updateRocket(ctx, message)
+	}
+
+	return withRetry(ctx, u.retryPolicy, "apply_message", func() error {
+		return u.updateInTransaction(ctx, message)
+	})
+}
+
+// updateInTransaction applies a message in a transaction of its own
+func (u *rocketStateUsecase) updateInTransaction(ctx context.Context, message *domain.RocketMessage) (err error) {
 	tx, err := u.rocketRepo.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	ctx = domain.ContextWithTransaction(ctx, tx)
 
 	defer func() {
 		if err != nil {
@@ -39,197 +71,154 @@ func (u *rocketStateUsecase) UpdateRocketFromMessage(ctx context.Context, messag
 		}
 	}()
 
-	var processErr error
-	switch message.Metadata.MessageType {
-	case domain.TypeRocketLaunched:
-		processErr = u.handleRocketLaunched(ctx, message)
-	case domain.TypeRocketSpeedIncreased:
-		processErr = u.handleRocketSpeedIncreased(ctx, message)
-	case domain.TypeRocketSpeedDecreased:
-		processErr = u.handleRocketSpeedDecreased(ctx, message)
-	case domain.TypeRocketExploded:
-		processErr = u.handleRocketExploded(ctx, message)
-	case domain.TypeRocketMissionChanged:
-		processErr = u.handleRocketMissionChanged(ctx, message)
-	default:
-		processErr = fmt.Errorf("unknown message type: %s", message.Metadata.MessageType)
-	}
-
-	if processErr != nil {
-		return fmt.Errorf("failed to update rocket state: %w", processErr)
-	}
-
-	if err = u.messageRepo.MarkAsProcessed(ctx, message.Metadata.Channel, message.Metadata.MessageNumber); err != nil {
-		return fmt.Errorf("failed to mark message as processed: %w", err)
+	if err = u.updateRocket(ctx, message); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Successfully updated rocket state for message type %s, channel %s", message.Metadata.MessageType, message.Metadata.Channel)
 	return nil
 }
 
-func (p *rocketStateUsecase) handleRocketLaunched(ctx context.Context, message *domain.RocketMessage) error {
-	var launchMsg domain.RocketLaunchedMessage
-	if err := parseMessagePayload(message.Message, &launchMsg); err != nil {
-		return err
-	}
-
-	rocket, err := p.rocketRepo.GetByChannel(ctx, message.Metadata.Channel)
-	if err != nil {
-		return err
-	}
-
-	if rocket != nil {
-		log.Printf("Rocket already exists for channel %s, skipping launch", message.Metadata.Channel)
-		return nil
+// withRetry runs op with the retry policy, and reports the database as busy
+// when op still failed on a lock after the last attempt
+func withRetry(ctx context.Context, policy *retry.Policy, operation string, op func() error) error {
+	err := policy.Do(ctx, operation, op)
+	if errors.Is(err, retry.ErrExhausted) {
+		return fmt.Errorf("%w: %w", domain.ErrBusy, err)
 	}
+	return err
+}
 
-	newRocket := &domain.Rocket{
-		Channel:     message.Metadata.Channel,
-		Type:        launchMsg.Type,
-		Speed:       launchMsg.LaunchSpeed,
-		Mission:     launchMsg.Mission,
-		LaunchTime:  message.Metadata.MessageTime,
-		Status:      domain.RocketStatusLaunched,
-		LastUpdated: time.Now(),
-		LastMessage: message.Metadata.MessageNumber,
+// updateRocket applies a message within the transaction of ctx
+func (u *rocketStateUsecase) updateRocket(ctx context.Context, message *domain.RocketMessage) error {
+	// A rejected message is processed without changing the rocket, so that it
+	// does not hold back the rest of its channel
+	changed, err := u.applyMessage(ctx, message)
+	var rejected *domain.TransitionError
+	if errors.As(err, &rejected) {
+		if err = u.addRejection(ctx, message, rejected); err != nil {
+			return fmt.Errorf("failed to record rejected transition: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to update rocket state: %w", err)
 	}
 
-	if err := p.rocketRepo.Save(ctx, newRocket); err != nil {
-		return err
+	if changed != nil {
+		if err = u.addOutboxEvent(ctx, message, changed); err != nil {
+			return fmt.Errorf("failed to record state change: %w", err)
+		}
 	}
 
-	log.Printf("Successfully launched rocket for channel %s", message.Metadata.Channel)
-	return nil
-}
-
-func (p *rocketStateUsecase) handleRocketSpeedIncreased(ctx context.Context, message *domain.RocketMessage) error {
-	var speedMsg domain.RocketSpeedIncreasedMessage
-	if err := parseMessagePayload(message.Message, &speedMsg); err != nil {
-		return err
+	// The rocket only keeps the latest reading, the history goes to its own table
+	if changed != nil && message.Metadata.MessageType == domain.TypeRocketTelemetry {
+		if err = u.telemetryRepo.Save(ctx, message.Metadata.Channel, message.Metadata.MessageNumber, changed.Telemetry); err != nil {
+			return fmt.Errorf("failed to record telemetry: %w", err)
+		}
 	}
 
-	rocket, err := p.rocketRepo.GetByChannel(ctx, message.Metadata.Channel)
+	// The hash tells a message delivered again from another one reusing its number
+	payloadHash, err := domain.PayloadHash(message)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to hash message: %w", err)
 	}
 
-	if rocket == nil {
-		return fmt.Errorf("rocket not found: %s", message.Metadata.Channel)
+	if err = u.messageRepo.MarkAsProcessed(ctx, message.Metadata.Channel, message.Metadata.MessageNumber, payloadHash); err != nil {
+		return fmt.Errorf("failed to mark message as processed: %w", err)
 	}
 
-	if rocket.Status == domain.RocketStatusExploded {
+	// Only the applied messages are part of the rocket history
+	if rejected != nil {
+		log.Printf("Rejected message %d of channel %s: %v", message.Metadata.MessageNumber, message.Metadata.Channel, rejected)
 		return nil
 	}
 
-	rocket.Speed += speedMsg.By
-	if rocket.Speed < 0 {
-		rocket.Speed = 0
+	if err = u.messageRepo.SaveEvent(ctx, message); err != nil {
+		return fmt.Errorf("failed to save message event: %w", err)
 	}
-	rocket.LastUpdated = time.Now()
-	rocket.LastMessage = message.Metadata.MessageNumber
 
-	return p.rocketRepo.Update(ctx, rocket)
+	log.Printf("Successfully updated rocket state for message type %s, channel %s", message.Metadata.MessageType, message.Metadata.Channel)
+	return nil
 }
 
-func (p *rocketStateUsecase) handleRocketSpeedDecreased(ctx context.Context, message *domain.RocketMessage) error {
-	var speedMsg domain.RocketSpeedDecreasedMessage
-	if err := parseMessagePayload(message.Message, &speedMsg); err != nil {
-		return err
-	}
+// addRejection records a message the state machine did not allow
+func (u *rocketStateUsecase) addRejection(ctx context.Context, message *domain.RocketMessage, rejected *domain.TransitionError) error {
+	return u.rejectionRepo.Save(ctx, &domain.Rejection{
+		Channel:       message.Metadata.Channel,
+		MessageNumber: message.Metadata.MessageNumber,
+		MessageType:   message.Metadata.MessageType,
+		MessageTime:   message.Metadata.MessageTime,
+		Status:        rejected.Status,
+		Reason:        rejected.Error(),
+		RejectedAt:    u.now(),
+		Actor:         domain.ActorFromContext(ctx),
+	})
+}
 
-	rocket, err := p.rocketRepo.GetByChannel(ctx, message.Metadata.Channel)
+// addOutboxEvent records the new rocket state so the outbox relay can publish it
+func (u *rocketStateUsecase) addOutboxEvent(ctx context.Context, message *domain.RocketMessage, rocket *domain.Rocket) error {
+	payload, err := json.Marshal(rocket)
 	if err != nil {
-		return err
-	}
-
-	if rocket == nil {
-		return fmt.Errorf("rocket not found: %s", message.Metadata.Channel)
-	}
-
-	if rocket.Status == domain.RocketStatusExploded {
-		return nil
-	}
-
-	if speedMsg.By > rocket.Speed {
-		rocket.Speed = 0
-	} else {
-		rocket.Speed -= speedMsg.By
-	}
-	rocket.LastUpdated = time.Now()
-	rocket.LastMessage = message.Metadata.MessageNumber
-
-	return p.rocketRepo.Update(ctx, rocket)
+		return fmt.Errorf("failed to marshal rocket state: %w", err)
+	}
+
+	return u.outboxRepo.Add(ctx, &domain.OutboxEvent{
+		TenantID:      domain.TenantFromContext(ctx),
+		Channel:       message.Metadata.Channel,
+		EventType:     message.Metadata.MessageType,
+		MessageNumber: message.Metadata.MessageNumber,
+		Payload:       payload,
+		CreatedAt:     u.now(),
+		Actor:         domain.ActorFromContext(ctx),
+	})
 }
 
-func (p *rocketStateUsecase) handleRocketExploded(ctx context.Context, message *domain.RocketMessage) error {
-	var explodeMsg domain.RocketExplodedMessage
-	if err := parseMessagePayload(message.Message, &explodeMsg); err != nil {
-		return err
-	}
-
-	rocket, err := p.rocketRepo.GetByChannel(ctx, message.Metadata.Channel)
+// applyMessage checks the transition against the state machine, runs the
+// message type's handler and stores the result. It returns the changed
+// rocket, or nil if nothing changed, and a *domain.TransitionError if the
+// state machine rejects the message.
+func (u *rocketStateUsecase) applyMessage(ctx context.Context, message *domain.RocketMessage) (*domain.Rocket, error) {
+	handler, err := u.handlers.Decode(message)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rocket == nil {
-		return fmt.Errorf("rocket not found: %s", message.Metadata.Channel)
-	}
-
-	if rocket.Status == domain.RocketStatusExploded {
-		return nil
-	}
-
-	rocket.Status = domain.RocketStatusExploded
-	rocket.Reason = explodeMsg.Reason
-	explodeTime := message.Metadata.MessageTime
-	rocket.ExplodedAt = &explodeTime
-	rocket.LastUpdated = time.Now()
-	rocket.LastMessage = message.Metadata.MessageNumber
-
-	return p.rocketRepo.Update(ctx, rocket)
-}
-
-func (p *rocketStateUsecase) handleRocketMissionChanged(ctx context.Context, message *domain.RocketMessage) error {
-	var missionMsg domain.RocketMissionChangedMessage
-	if err := parseMessagePayload(message.Message, &missionMsg); err != nil {
-		return err
+	rocket, err := u.rocketRepo.GetByChannel(ctx, message.Metadata.Channel)
+	if err != nil {
+		return nil, err
 	}
+	exists := rocket != nil
 
-	rocket, err := p.rocketRepo.GetByChannel(ctx, message.Metadata.Channel)
+	status, err := u.stateMachine.Next(rocket, message.Metadata.MessageType)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rocket == nil {
-		return fmt.Errorf("rocket not found: %s", message.Metadata.Channel)
+	changed, err := handler.Apply(rocket, message)
+	if err != nil {
+		return nil, err
 	}
-
-	if rocket.Status == domain.RocketStatusExploded {
-		return nil
+	if changed == nil {
+		log.Printf("Message %d leaves the rocket of channel %s unchanged", message.Metadata.MessageNumber, message.Metadata.Channel)
+		return nil, nil
 	}
 
-	rocket.Mission = missionMsg.NewMission
-	rocket.LastUpdated = time.Now()
-	rocket.LastMessage = message.Metadata.MessageNumber
-
-	return p.rocketRepo.Update(ctx, rocket)
-}
-
-func parseMessagePayload(payload interface{}, dest interface{}) error {
+	changed.Status = status
+	changed.LastUpdated = u.now()
+	changed.LastMessage = message.Metadata.MessageNumber
 
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message payload: %w", err)
+	if !exists {
+		if err := u.rocketRepo.Save(ctx, changed); err != nil {
+			return nil, err
+		}
+		log.Printf("Successfully launched rocket for channel %s", message.Metadata.Channel)
+		return changed, nil
 	}
 
-	if err := json.Unmarshal(payloadBytes, dest); err != nil {
-		return fmt.Errorf("failed to unmarshal message payload: %w", err)
+	if err := u.rocketRepo.Update(ctx, changed); err != nil {
+		return nil, err
 	}
-
-	return nil
+	return changed, nil
 }
@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"lunar-rockets/domain"
+	"lunar-rockets/retry"
 	"lunar-rockets/test/helper"
 	"lunar-rockets/test/mocks"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
@@ -22,7 +24,11 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 		existingRocket      *domain.Rocket
 		rocketRepoError     error
 		messageRepoError    error
+		outboxRepoError     error
+		telemetryRepoError  error
+		rejectionRepoError  error
 		expectedError       string
+		expectedRejection   string // Reason of the rejected transition, if any
 		expectedRocketState *domain.Rocket
 		ignoreLastUpdated   bool // Flag to ignore LastUpdated field comparison
 		ignoreRocketState   bool // Flag to ignore rocket state comparison
@@ -36,11 +42,11 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 1,
 					MessageTime:   now,
 				},
-				Message: domain.RocketLaunchedMessage{
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
 					Type:        "Falcon-9",
 					LaunchSpeed: 1000,
 					Mission:     "ARTEMIS",
-				},
+				}),
 			},
 			existingRocket:   nil,
 			rocketRepoError:  nil,
@@ -66,9 +72,9 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 2,
 					MessageTime:   now,
 				},
-				Message: domain.RocketSpeedIncreasedMessage{
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{
 					By: 500,
-				},
+				}),
 			},
 			existingRocket:   helper.CreateTestRocket("channel-1", "Falcon-9", "ARTEMIS", domain.RocketStatusLaunched, 1000, now.Add(-1*time.Hour)),
 			rocketRepoError:  nil,
@@ -94,9 +100,9 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 3,
 					MessageTime:   now,
 				},
-				Message: domain.RocketSpeedDecreasedMessage{
+				Message: helper.EncodePayload(domain.RocketSpeedDecreasedMessage{
 					By: 300,
-				},
+				}),
 			},
 			existingRocket:   helper.CreateTestRocket("channel-1", "Falcon-9", "ARTEMIS", domain.RocketStatusLaunched, 1500, now.Add(-1*time.Hour)),
 			rocketRepoError:  nil,
@@ -122,9 +128,9 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 4,
 					MessageTime:   now,
 				},
-				Message: domain.RocketMissionChangedMessage{
+				Message: helper.EncodePayload(domain.RocketMissionChangedMessage{
 					NewMission: "MARS",
-				},
+				}),
 			},
 			existingRocket:   helper.CreateTestRocket("channel-1", "Falcon-9", "ARTEMIS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
 			rocketRepoError:  nil,
@@ -150,9 +156,9 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 5,
 					MessageTime:   now,
 				},
-				Message: domain.RocketExplodedMessage{
+				Message: helper.EncodePayload(domain.RocketExplodedMessage{
 					Reason: "PRESSURE_FAILURE",
-				},
+				}),
 			},
 			existingRocket:   helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
 			rocketRepoError:  nil,
@@ -172,7 +178,252 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 			ignoreLastUpdated: true,
 		},
 		{
-			name: "rocket_not_found_for_update",
+			name: "successful_rocket_landing",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketLanded,
+					MessageNumber: 5,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketLandedMessage{
+					Site: "SEA_OF_TRANQUILITY",
+				}),
+			},
+			existingRocket: helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
+			expectedError:  "",
+			expectedRocketState: &domain.Rocket{
+				Channel:     "channel-1",
+				Type:        "Falcon-9",
+				Speed:       0,
+				Mission:     "MARS",
+				LaunchTime:  now.Add(-1 * time.Hour),
+				Status:      domain.RocketStatusLanded,
+				LandedAt:    &now,
+				LandingSite: "SEA_OF_TRANQUILITY",
+				LastMessage: 5,
+			},
+			ignoreLastUpdated: true,
+		},
+		{
+			name: "successful_rocket_abort",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketAborted,
+					MessageNumber: 5,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketAbortedMessage{
+					Reason: "GUIDANCE_FAILURE",
+				}),
+			},
+			existingRocket: helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
+			expectedError:  "",
+			expectedRocketState: &domain.Rocket{
+				Channel:     "channel-1",
+				Type:        "Falcon-9",
+				Speed:       1200,
+				Mission:     "MARS",
+				LaunchTime:  now.Add(-1 * time.Hour),
+				Status:      domain.RocketStatusAborted,
+				Reason:      "GUIDANCE_FAILURE",
+				AbortedAt:   &now,
+				LastMessage: 5,
+			},
+			ignoreLastUpdated: true,
+		},
+		{
+			name: "successful_rocket_docking",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketDocked,
+					MessageNumber: 5,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketDockedMessage{
+					Station: "GATEWAY",
+				}),
+			},
+			existingRocket: helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
+			expectedError:  "",
+			expectedRocketState: &domain.Rocket{
+				Channel:     "channel-1",
+				Type:        "Falcon-9",
+				Speed:       1200,
+				Mission:     "MARS",
+				LaunchTime:  now.Add(-1 * time.Hour),
+				Status:      domain.RocketStatusDocked,
+				DockedAt:    &now,
+				Station:     "GATEWAY",
+				LastMessage: 5,
+			},
+			ignoreLastUpdated: true,
+		},
+		{
+			name: "aborted_rocket_lands",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketLanded,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketLandedMessage{
+					Site: "CAPE_CANAVERAL",
+				}),
+			},
+			existingRocket: helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusAborted, 1200, now.Add(-1*time.Hour)),
+			expectedError:  "",
+			expectedRocketState: &domain.Rocket{
+				Channel:     "channel-1",
+				Type:        "Falcon-9",
+				Speed:       0,
+				Mission:     "MARS",
+				LaunchTime:  now.Add(-1 * time.Hour),
+				Status:      domain.RocketStatusLanded,
+				LandedAt:    &now,
+				LandingSite: "CAPE_CANAVERAL",
+				LastMessage: 6,
+			},
+			ignoreLastUpdated: true,
+		},
+		{
+			name: "landed_rocket_changes_mission",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketMissionChanged,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketMissionChangedMessage{
+					NewMission: "ARTEMIS",
+				}),
+			},
+			existingRocket: helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLanded, 0, now.Add(-1*time.Hour)),
+			expectedError:  "",
+			expectedRocketState: &domain.Rocket{
+				Channel:     "channel-1",
+				Type:        "Falcon-9",
+				Speed:       0,
+				Mission:     "ARTEMIS",
+				LaunchTime:  now.Add(-1 * time.Hour),
+				Status:      domain.RocketStatusLanded,
+				LastMessage: 6,
+			},
+			ignoreLastUpdated: true,
+		},
+		{
+			name: "landed_rocket_rejects_speed_increase",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{
+					By: 500,
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLanded, 0, now.Add(-1*time.Hour)),
+			expectedError:       "",
+			expectedRocketState: nil,
+			expectedRejection:   "RocketSpeedIncreased not allowed in status Landed",
+		},
+		{
+			name: "docked_rocket_rejects_abort",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketAborted,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketAbortedMessage{
+					Reason: "GUIDANCE_FAILURE",
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusDocked, 1200, now.Add(-1*time.Hour)),
+			expectedError:       "",
+			expectedRocketState: nil,
+			expectedRejection:   "RocketAborted not allowed in status Docked",
+		},
+		{
+			name: "successful_telemetry",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketTelemetry,
+					MessageNumber: 5,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(map[string]float64{
+					"altitude": 120000, "latitude": 28.5, "longitude": -80.6, "fuel": 42.5, "heading": 90,
+				}),
+			},
+			existingRocket: helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
+			expectedError:  "",
+			expectedRocketState: &domain.Rocket{
+				Channel:    "channel-1",
+				Type:       "Falcon-9",
+				Speed:      1200,
+				Mission:    "MARS",
+				LaunchTime: now.Add(-1 * time.Hour),
+				Status:     domain.RocketStatusLaunched,
+				Telemetry: &domain.Telemetry{
+					Time:      now,
+					Altitude:  120000,
+					Latitude:  28.5,
+					Longitude: -80.6,
+					Fuel:      42.5,
+					Heading:   90,
+				},
+				LastMessage: 5,
+			},
+			ignoreLastUpdated: true,
+		},
+		{
+			name: "exploded_rocket_rejects_telemetry",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketTelemetry,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(map[string]float64{
+					"altitude": 0, "latitude": 0, "longitude": 0, "fuel": 0, "heading": 0,
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusExploded, 0, now.Add(-1*time.Hour)),
+			expectedError:       "",
+			expectedRocketState: nil,
+			expectedRejection:   "RocketTelemetry not allowed in status Exploded",
+		},
+		{
+			name: "telemetry_repo_error",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketTelemetry,
+					MessageNumber: 5,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(map[string]float64{
+					"altitude": 100, "latitude": 0, "longitude": 0, "fuel": 50, "heading": 0,
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusLaunched, 1200, now.Add(-1*time.Hour)),
+			telemetryRepoError:  errors.New("database error"),
+			expectedError:       "failed to record telemetry: database error",
+			expectedRocketState: nil,
+			ignoreRocketState:   true, // We don't care about the rocket state in this case
+		},
+		{
+			name: "speed_increase_before_launch",
 			message: &domain.RocketMessage{
 				Metadata: domain.MessageMetadata{
 					Channel:       "channel-1",
@@ -180,15 +431,16 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 2,
 					MessageTime:   now,
 				},
-				Message: domain.RocketSpeedIncreasedMessage{
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{
 					By: 500,
-				},
+				}),
 			},
 			existingRocket:      nil,
 			rocketRepoError:     nil,
 			messageRepoError:    nil,
-			expectedError:       "failed to update rocket state: rocket not found: channel-1",
+			expectedError:       "",
 			expectedRocketState: nil,
+			expectedRejection:   "RocketSpeedIncreased received before the rocket was launched",
 		},
 		{
 			name: "rocket_repo_error",
@@ -199,11 +451,11 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 1,
 					MessageTime:   now,
 				},
-				Message: domain.RocketLaunchedMessage{
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
 					Type:        "Falcon-9",
 					LaunchSpeed: 1000,
 					Mission:     "ARTEMIS",
-				},
+				}),
 			},
 			existingRocket:      nil,
 			rocketRepoError:     errors.New("database error"),
@@ -220,11 +472,11 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 					MessageNumber: 1,
 					MessageTime:   now,
 				},
-				Message: domain.RocketLaunchedMessage{
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
 					Type:        "Falcon-9",
 					LaunchSpeed: 1000,
 					Mission:     "ARTEMIS",
-				},
+				}),
 			},
 			existingRocket:      nil,
 			rocketRepoError:     nil,
@@ -250,6 +502,84 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 			expectedError:       "failed to update rocket state: unknown message type: UnknownType",
 			expectedRocketState: nil,
 		},
+		{
+			name: "exploded_rocket_rejects_message",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{
+					By: 500,
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusExploded, 0, now.Add(-1*time.Hour)),
+			expectedError:       "",
+			expectedRocketState: nil,
+			expectedRejection:   "RocketSpeedIncreased not allowed in status Exploded",
+		},
+		{
+			name: "repeated_launch",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketLaunched,
+					MessageNumber: 2,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
+					Type:        "Falcon-9",
+					LaunchSpeed: 1000,
+					Mission:     "ARTEMIS",
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "ARTEMIS", domain.RocketStatusLaunched, 1000, now.Add(-1*time.Hour)),
+			expectedError:       "",
+			expectedRocketState: nil,
+			expectedRejection:   "rocket already launched",
+		},
+		{
+			name: "rejection_repo_error",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketSpeedIncreased,
+					MessageNumber: 6,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketSpeedIncreasedMessage{
+					By: 500,
+				}),
+			},
+			existingRocket:      helper.CreateTestRocket("channel-1", "Falcon-9", "MARS", domain.RocketStatusExploded, 0, now.Add(-1*time.Hour)),
+			rejectionRepoError:  errors.New("database error"),
+			expectedError:       "failed to record rejected transition: database error",
+			expectedRocketState: nil,
+			expectedRejection:   "RocketSpeedIncreased not allowed in status Exploded",
+		},
+		{
+			name: "outbox_repo_error",
+			message: &domain.RocketMessage{
+				Metadata: domain.MessageMetadata{
+					Channel:       "channel-1",
+					MessageType:   domain.TypeRocketLaunched,
+					MessageNumber: 1,
+					MessageTime:   now,
+				},
+				Message: helper.EncodePayload(domain.RocketLaunchedMessage{
+					Type:        "Falcon-9",
+					LaunchSpeed: 1000,
+					Mission:     "ARTEMIS",
+				}),
+			},
+			existingRocket:      nil,
+			outboxRepoError:     errors.New("database error"),
+			expectedError:       "failed to record state change: database error",
+			expectedRocketState: nil,
+			ignoreRocketState:   true, // We don't care about the rocket state in this case
+		},
 	}
 
 	for _, tc := range testCases {
@@ -257,6 +587,12 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
+			var committed, rolledBack bool
+			var outboxEvents []*domain.OutboxEvent
+			var readings []*domain.Telemetry
+			var rejections []*domain.Rejection
+			var savedEvent bool
+
 			// Create mock repositories
 			mockRocketRepo := &mocks.MockRocketRepository{
 				GetByChannelFunc: func(ctx context.Context, channel string) (*domain.Rocket, error) {
@@ -294,9 +630,11 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 				BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
 					return &mocks.MockTransaction{
 						CommitFunc: func() error {
+							committed = true
 							return nil
 						},
 						RollbackFunc: func() error {
+							rolledBack = true
 							return nil
 						},
 					}, nil
@@ -304,18 +642,62 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 			}
 
 			mockMessageRepo := &mocks.MockMessageRepository{
-				MarkAsProcessedFunc: func(ctx context.Context, channel string, messageNumber int64) error {
+				MarkAsProcessedFunc: func(ctx context.Context, channel string, messageNumber int64, payloadHash string) error {
 					assert.Equal(t, tc.message.Metadata.Channel, channel)
 					assert.Equal(t, tc.message.Metadata.MessageNumber, messageNumber)
+					expectedHash, err := domain.PayloadHash(tc.message)
+					require.NoError(t, err)
+					assert.Equal(t, expectedHash, payloadHash)
 					return tc.messageRepoError
 				},
+				SaveEventFunc: func(ctx context.Context, message *domain.RocketMessage) error {
+					_, inTx := domain.TransactionFromContext(ctx)
+					assert.True(t, inTx, "Message event must be saved in the rocket transaction")
+					assert.Equal(t, tc.message, message)
+					savedEvent = true
+					return nil
+				},
+			}
+
+			mockOutboxRepo := &mocks.MockOutboxRepository{
+				AddFunc: func(ctx context.Context, event *domain.OutboxEvent) error {
+					_, inTx := domain.TransactionFromContext(ctx)
+					assert.True(t, inTx, "Outbox event must be written in the rocket transaction")
+					outboxEvents = append(outboxEvents, event)
+					return tc.outboxRepoError
+				},
+			}
+
+			mockTelemetryRepo := &mocks.MockTelemetryRepository{
+				SaveFunc: func(ctx context.Context, channel string, messageNumber int64, reading *domain.Telemetry) error {
+					_, inTx := domain.TransactionFromContext(ctx)
+					assert.True(t, inTx, "Telemetry must be saved in the rocket transaction")
+					assert.Equal(t, tc.message.Metadata.Channel, channel)
+					assert.Equal(t, tc.message.Metadata.MessageNumber, messageNumber)
+					readings = append(readings, reading)
+					return tc.telemetryRepoError
+				},
+			}
+
+			mockRejectionRepo := &mocks.MockRejectionRepository{
+				SaveFunc: func(ctx context.Context, rejection *domain.Rejection) error {
+					_, inTx := domain.TransactionFromContext(ctx)
+					assert.True(t, inTx, "Rejection must be saved in the rocket transaction")
+					rejections = append(rejections, rejection)
+					return tc.rejectionRepoError
+				},
 			}
 
 			// Create use case with mock dependencies
-			useCase := NewRocketStateUsecase(mockRocketRepo, mockMessageRepo)
+			stateMachine := domain.NewStateMachine(domain.RocketTransitions...)
+			useCase := NewRocketStateUsecase(mockRocketRepo, mockMessageRepo, mockOutboxRepo, mockTelemetryRepo, mockRejectionRepo, newMessageRegistry(t), stateMachine, nil)
+			appliedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+			useCase.(*rocketStateUsecase).now = func() time.Time { return appliedAt }
 
 			// Execute the method
-			err := useCase.UpdateRocketFromMessage(context.Background(), tc.message)
+			ctx := domain.ContextWithTenant(context.Background(), "tenant-a")
+			ctx = domain.ContextWithIdentity(ctx, &domain.Identity{Subject: "mission-control", Scopes: []string{domain.ScopeMessagesWrite}})
+			err := useCase.UpdateRocketFromMessage(ctx, tc.message)
 
 			// Check results
 			if tc.expectedError != "" {
@@ -324,6 +706,202 @@ func TestRocketStateUsecase_UpdateRocketFromMessage(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 			}
+
+			// Verify the transaction outcome
+			assert.Equal(t, tc.expectedError == "", committed)
+			assert.Equal(t, tc.expectedError != "", rolledBack)
+
+			// Verify a state change event was recorded for every change
+			if tc.expectedError == "" && tc.expectedRocketState != nil {
+				if assert.Len(t, outboxEvents, 1) {
+					assert.Equal(t, "tenant-a", outboxEvents[0].TenantID)
+					assert.Equal(t, "mission-control", outboxEvents[0].Actor)
+					assert.Equal(t, tc.message.Metadata.Channel, outboxEvents[0].Channel)
+					assert.Equal(t, tc.message.Metadata.MessageType, outboxEvents[0].EventType)
+					assert.Equal(t, tc.message.Metadata.MessageNumber, outboxEvents[0].MessageNumber)
+					assert.Equal(t, appliedAt, outboxEvents[0].CreatedAt)
+				}
+			} else if !tc.ignoreRocketState {
+				assert.Empty(t, outboxEvents)
+			}
+
+			// Verify rejected transitions are recorded instead of applied
+			if tc.expectedRejection != "" {
+				if assert.Len(t, rejections, 1) {
+					assert.Equal(t, tc.message.Metadata.Channel, rejections[0].Channel)
+					assert.Equal(t, tc.message.Metadata.MessageNumber, rejections[0].MessageNumber)
+					assert.Equal(t, tc.message.Metadata.MessageType, rejections[0].MessageType)
+					assert.Equal(t, tc.expectedRejection, rejections[0].Reason)
+					assert.Equal(t, "mission-control", rejections[0].Actor)
+					assert.Equal(t, appliedAt, rejections[0].RejectedAt)
+					if tc.existingRocket != nil {
+						assert.Equal(t, tc.existingRocket.Status, rejections[0].Status)
+					} else {
+						assert.Equal(t, domain.RocketStatusNotLaunched, rejections[0].Status)
+					}
+				}
+				assert.False(t, savedEvent, "Rejected messages must not be saved as rocket events")
+			} else {
+				assert.Empty(t, rejections)
+			}
+
+			// Verify the telemetry history only records applied readings
+			if tc.expectedError == "" && tc.expectedRocketState != nil && tc.expectedRocketState.Telemetry != nil {
+				assert.Equal(t, []*domain.Telemetry{tc.expectedRocketState.Telemetry}, readings)
+			} else if !tc.ignoreRocketState && tc.telemetryRepoError == nil {
+				assert.Empty(t, readings)
+			}
+		})
+	}
+}
+
+func TestRocketStateUsecase_JoinsCallerTransaction(t *testing.T) {
+	message := helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, time.Now())
+
+	// The caller's transaction is used, and left for the caller to commit
+	tx := &mocks.MockTransaction{
+		CommitFunc: func() error {
+			t.Error("Caller transaction must not be committed")
+			return nil
+		},
+		RollbackFunc: func() error {
+			t.Error("Caller transaction must not be rolled back")
+			return nil
+		},
+	}
+	inCallerTx := func(ctx context.Context) {
+		current, _ := domain.TransactionFromContext(ctx)
+		assert.Same(t, tx, current)
+	}
+
+	mockRocketRepo := &mocks.MockRocketRepository{
+		GetByChannelFunc: func(ctx context.Context, channel string) (*domain.Rocket, error) {
+			return nil, nil
+		},
+		SaveFunc: func(ctx context.Context, rocket *domain.Rocket) error {
+			inCallerTx(ctx)
+			return nil
+		},
+	}
+	mockMessageRepo := &mocks.MockMessageRepository{
+		MarkAsProcessedFunc: func(ctx context.Context, channel string, messageNumber int64, payloadHash string) error {
+			inCallerTx(ctx)
+			return nil
+		},
+		SaveEventFunc: func(ctx context.Context, message *domain.RocketMessage) error {
+			inCallerTx(ctx)
+			return nil
+		},
+	}
+	mockOutboxRepo := &mocks.MockOutboxRepository{
+		AddFunc: func(ctx context.Context, event *domain.OutboxEvent) error {
+			inCallerTx(ctx)
+			return nil
+		},
+	}
+
+	stateMachine := domain.NewStateMachine(domain.RocketTransitions...)
+	useCase := NewRocketStateUsecase(mockRocketRepo, mockMessageRepo, mockOutboxRepo, &mocks.MockTelemetryRepository{}, &mocks.MockRejectionRepository{}, newMessageRegistry(t), stateMachine, nil)
+
+	err := useCase.UpdateRocketFromMessage(domain.ContextWithTransaction(context.Background(), tx), message)
+
+	assert.NoError(t, err)
+}
+
+func TestRocketStateUsecase_RetriesTransientErrors(t *testing.T) {
+	errLocked := errors.New("database is locked")
+	errOther := errors.New("disk I/O error")
+
+	testCases := []struct {
+		name             string
+		beginErrors      []error // Error of each attempt to begin, nil once it succeeds
+		expectedBegins   int
+		expectedCommits  int
+		expectedError    error
+		expectedNotError error
+	}{
+		{
+			name:            "succeeds_after_retries",
+			beginErrors:     []error{errLocked, errLocked, nil},
+			expectedBegins:  3,
+			expectedCommits: 1,
+		},
+		{
+			name:           "busy_after_last_attempt",
+			beginErrors:    []error{errLocked, errLocked, errLocked},
+			expectedBegins: 3,
+			expectedError:  domain.ErrBusy,
+		},
+		{
+			name:             "other_errors_are_not_retried",
+			beginErrors:      []error{errOther},
+			expectedBegins:   1,
+			expectedError:    errOther,
+			expectedNotError: domain.ErrBusy,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			begins, commits := 0, 0
+			mockRocketRepo := &mocks.MockRocketRepository{
+				BeginTxFunc: func(ctx context.Context) (domain.Transaction, error) {
+					begins++
+					if err := tc.beginErrors[begins-1]; err != nil {
+						return nil, err
+					}
+					return &mocks.MockTransaction{
+						CommitFunc: func() error {
+							commits++
+							return nil
+						},
+						RollbackFunc: func() error { return nil },
+					}, nil
+				},
+				GetByChannelFunc: func(ctx context.Context, channel string) (*domain.Rocket, error) {
+					return nil, nil
+				},
+				SaveFunc: func(ctx context.Context, rocket *domain.Rocket) error {
+					return nil
+				},
+			}
+			mockMessageRepo := &mocks.MockMessageRepository{
+				MarkAsProcessedFunc: func(ctx context.Context, channel string, messageNumber int64, payloadHash string) error {
+					return nil
+				},
+				SaveEventFunc: func(ctx context.Context, message *domain.RocketMessage) error {
+					return nil
+				},
+			}
+			mockOutboxRepo := &mocks.MockOutboxRepository{
+				AddFunc: func(ctx context.Context, event *domain.OutboxEvent) error {
+					return nil
+				},
+			}
+
+			policy, err := retry.NewPolicy(3, 0, 0, func(err error) bool {
+				return errors.Is(err, errLocked)
+			})
+			require.NoError(t, err)
+
+			stateMachine := domain.NewStateMachine(domain.RocketTransitions...)
+			useCase := NewRocketStateUsecase(mockRocketRepo, mockMessageRepo, mockOutboxRepo, &mocks.MockTelemetryRepository{}, &mocks.MockRejectionRepository{}, newMessageRegistry(t), stateMachine, policy)
+
+			err = useCase.UpdateRocketFromMessage(context.Background(), helper.CreateTestMessage("channel-1", domain.TypeRocketLaunched, 1, time.Now()))
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.expectedNotError != nil {
+				assert.NotErrorIs(t, err, tc.expectedNotError)
+			}
+			assert.Equal(t, tc.expectedBegins, begins)
+			assert.Equal(t, tc.expectedCommits, commits)
 		})
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"lunar-rockets/domain"
 )
@@ -11,14 +12,24 @@ import (
 type RocketUseCase interface {
 	GetRocket(ctx context.Context, channel string) (*domain.Rocket, error)
 	ListRockets(ctx context.Context, sortBy string, order string) ([]*domain.Rocket, error)
+	SearchRockets(ctx context.Context, query domain.RocketQuery) (*domain.RocketPage, error)
+	GetStats(ctx context.Context) (*domain.FleetStats, error)
+	GetTelemetry(ctx context.Context, channel string, query domain.TelemetryQuery) ([]*domain.Telemetry, error)
+	ListRejections(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error)
 }
 
+// DefaultRejectionLimit is the number of rejections listed when the query
+// sets no limit
+const DefaultRejectionLimit = 100
+
 type rocketUseCase struct {
-	rocketRepo domain.RocketRepository
+	rocketRepo    domain.RocketRepository
+	telemetryRepo domain.TelemetryRepository
+	rejectionRepo domain.RejectionRepository
 }
 
-func NewRocketUseCase(rocketRepo domain.RocketRepository) RocketUseCase {
-	return &rocketUseCase{rocketRepo: rocketRepo}
+func NewRocketUseCase(rocketRepo domain.RocketRepository, telemetryRepo domain.TelemetryRepository, rejectionRepo domain.RejectionRepository) RocketUseCase {
+	return &rocketUseCase{rocketRepo: rocketRepo, telemetryRepo: telemetryRepo, rejectionRepo: rejectionRepo}
 }
 
 func (u *rocketUseCase) GetRocket(ctx context.Context, channel string) (*domain.Rocket, error) {
@@ -52,3 +63,91 @@ func (u *rocketUseCase) ListRockets(ctx context.Context, sortBy string, order st
 	log.Printf("Successfully listed %d rockets", len(rockets))
 	return rockets, nil
 }
+
+func (u *rocketUseCase) SearchRockets(ctx context.Context, query domain.RocketQuery) (*domain.RocketPage, error) {
+	if query.SortBy == "" {
+		query.SortBy = "type"
+	}
+
+	if query.Order != "ASC" && query.Order != "DESC" {
+		query.Order = "DESC"
+	}
+
+	if query.Limit < 0 {
+		query.Limit = 0
+	}
+
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	rockets, total, err := u.rocketRepo.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search rockets: %w", err)
+	}
+
+	log.Printf("Successfully found %d of %d rockets", len(rockets), total)
+	return &domain.RocketPage{Rockets: rockets, Total: total}, nil
+}
+
+func (u *rocketUseCase) GetStats(ctx context.Context) (*domain.FleetStats, error) {
+	stats, err := u.rocketRepo.Stats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fleet stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (u *rocketUseCase) GetTelemetry(ctx context.Context, channel string, query domain.TelemetryQuery) ([]*domain.Telemetry, error) {
+	rocket, err := u.rocketRepo.GetByChannel(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rocket: %w", err)
+	}
+
+	if rocket == nil {
+		return nil, domain.ErrRocketNotFound
+	}
+
+	readings, err := u.telemetryRepo.List(ctx, channel, query.From, query.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telemetry: %w", err)
+	}
+
+	if query.Interval > 0 {
+		readings = downsample(readings, query.Interval)
+	}
+
+	log.Printf("Successfully retrieved %d telemetry readings for channel %s", len(readings), channel)
+	return readings, nil
+}
+
+// ListRejections returns the messages rejected by the state machine, newest
+// first
+func (u *rocketUseCase) ListRejections(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultRejectionLimit
+	}
+
+	rejections, err := u.rejectionRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rejections: %w", err)
+	}
+
+	log.Printf("Successfully listed %d rejections", len(rejections))
+	return rejections, nil
+}
+
+// downsample keeps the last of the time-ordered readings in each interval.
+// Intervals are aligned on the Unix epoch, so that they do not depend on the
+// requested range.
+func downsample(readings []*domain.Telemetry, interval time.Duration) []*domain.Telemetry {
+	var sampled []*domain.Telemetry
+	for i, reading := range readings {
+		last := i == len(readings)-1
+		if last || !readings[i+1].Time.Truncate(interval).Equal(reading.Time.Truncate(interval)) {
+			sampled = append(sampled, reading)
+		}
+	}
+	return sampled
+}
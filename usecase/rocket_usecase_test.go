@@ -76,7 +76,7 @@ func TestRocketUseCase_GetRocket(t *testing.T) {
 				},
 			}
 
-			useCase := NewRocketUseCase(mockRepo)
+			useCase := NewRocketUseCase(mockRepo, &mocks.MockTelemetryRepository{}, &mocks.MockRejectionRepository{})
 			rocket, err := useCase.GetRocket(context.Background(), tc.channel)
 
 			if tc.expectedError != "" {
@@ -187,7 +187,7 @@ func TestRocketUseCase_ListRockets(t *testing.T) {
 				},
 			}
 
-			useCase := NewRocketUseCase(mockRepo)
+			useCase := NewRocketUseCase(mockRepo, &mocks.MockTelemetryRepository{}, &mocks.MockRejectionRepository{})
 			rockets, err := useCase.ListRockets(context.Background(), tc.sortBy, tc.order)
 
 			if tc.expectedError != "" {
@@ -200,3 +200,284 @@ func TestRocketUseCase_ListRockets(t *testing.T) {
 		})
 	}
 }
+
+func TestRocketUseCase_SearchRockets(t *testing.T) {
+	fixedTime := time.Date(2025, 5, 20, 9, 39, 15, 0, time.UTC)
+	rockets := []*domain.Rocket{
+		{
+			Channel:     "test-channel-1",
+			Type:        "Falcon-9",
+			Speed:       100,
+			Mission:     "ARTEMIS",
+			LaunchTime:  fixedTime,
+			Status:      domain.RocketStatusLaunched,
+			LastUpdated: fixedTime,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         domain.RocketQuery
+		repoError     error
+		expectedQuery domain.RocketQuery
+		expectedPage  *domain.RocketPage
+		expectedError string
+	}{
+		{
+			name:          "default_sorting",
+			query:         domain.RocketQuery{Status: domain.RocketStatusLaunched, Limit: 10},
+			expectedQuery: domain.RocketQuery{Status: domain.RocketStatusLaunched, SortBy: "type", Order: "DESC", Limit: 10},
+			expectedPage:  &domain.RocketPage{Rockets: rockets, Total: 3},
+		},
+		{
+			name:          "normalize_pagination",
+			query:         domain.RocketQuery{SortBy: "speed", Order: "ASC", Limit: -1, Offset: -5},
+			expectedQuery: domain.RocketQuery{SortBy: "speed", Order: "ASC"},
+			expectedPage:  &domain.RocketPage{Rockets: rockets, Total: 3},
+		},
+		{
+			name:          "repository_error",
+			query:         domain.RocketQuery{},
+			repoError:     errors.New("database error"),
+			expectedQuery: domain.RocketQuery{SortBy: "type", Order: "DESC"},
+			expectedError: "failed to search rockets: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := &mocks.MockRocketRepository{
+				SearchFunc: func(ctx context.Context, query domain.RocketQuery) ([]*domain.Rocket, int, error) {
+					assert.Equal(t, tc.expectedQuery, query)
+					if tc.repoError != nil {
+						return nil, 0, tc.repoError
+					}
+					return rockets, 3, nil
+				},
+			}
+
+			useCase := NewRocketUseCase(mockRepo, &mocks.MockTelemetryRepository{}, &mocks.MockRejectionRepository{})
+			page, err := useCase.SearchRockets(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedPage, page)
+			}
+		})
+	}
+}
+
+func TestRocketUseCase_GetStats(t *testing.T) {
+	stats := &domain.FleetStats{
+		Total:        2,
+		AverageSpeed: 150,
+		MaxSpeed:     200,
+		ByStatus:     map[string]int{domain.RocketStatusLaunched: 2},
+		ByType:       map[string]int{"Falcon-9": 2},
+	}
+
+	testCases := []struct {
+		name          string
+		repoError     error
+		expectedStats *domain.FleetStats
+		expectedError string
+	}{
+		{
+			name:          "successful_stats",
+			expectedStats: stats,
+		},
+		{
+			name:          "repository_error",
+			repoError:     errors.New("database error"),
+			expectedError: "failed to get fleet stats: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := &mocks.MockRocketRepository{
+				StatsFunc: func(ctx context.Context) (*domain.FleetStats, error) {
+					if tc.repoError != nil {
+						return nil, tc.repoError
+					}
+					return stats, nil
+				},
+			}
+
+			useCase := NewRocketUseCase(mockRepo, &mocks.MockTelemetryRepository{}, &mocks.MockRejectionRepository{})
+			result, err := useCase.GetStats(context.Background())
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedStats, result)
+			}
+		})
+	}
+}
+
+func TestRocketUseCase_GetTelemetry(t *testing.T) {
+	start := time.Date(2025, 5, 20, 9, 0, 0, 0, time.UTC)
+	reading := func(offset time.Duration, altitude float64) *domain.Telemetry {
+		return &domain.Telemetry{Time: start.Add(offset), Altitude: altitude}
+	}
+	readings := []*domain.Telemetry{
+		reading(0, 100),
+		reading(20*time.Second, 200),
+		reading(50*time.Second, 300),
+		reading(70*time.Second, 400),
+		reading(3*time.Minute, 500),
+	}
+	rocket := &domain.Rocket{Channel: "channel-1", Status: domain.RocketStatusLaunched}
+
+	testCases := []struct {
+		name              string
+		query             domain.TelemetryQuery
+		rocket            *domain.Rocket
+		rocketRepoError   error
+		telemetryRepoErr  error
+		expectedReadings  []*domain.Telemetry
+		expectedError     string
+		expectedErrorType error
+	}{
+		{
+			name:             "every_reading",
+			query:            domain.TelemetryQuery{From: start, To: start.Add(time.Hour)},
+			rocket:           rocket,
+			expectedReadings: readings,
+		},
+		{
+			name:             "downsampled",
+			query:            domain.TelemetryQuery{Interval: time.Minute},
+			rocket:           rocket,
+			expectedReadings: []*domain.Telemetry{readings[2], readings[3], readings[4]},
+		},
+		{
+			name:              "rocket_not_found",
+			rocket:            nil,
+			expectedErrorType: domain.ErrRocketNotFound,
+		},
+		{
+			name:            "rocket_repository_error",
+			rocketRepoError: errors.New("database error"),
+			expectedError:   "failed to get rocket: database error",
+		},
+		{
+			name:             "telemetry_repository_error",
+			rocket:           rocket,
+			telemetryRepoErr: errors.New("database error"),
+			expectedError:    "failed to get telemetry: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := &mocks.MockRocketRepository{
+				GetByChannelFunc: func(ctx context.Context, channel string) (*domain.Rocket, error) {
+					return tc.rocket, tc.rocketRepoError
+				},
+			}
+			mockTelemetryRepo := &mocks.MockTelemetryRepository{
+				ListFunc: func(ctx context.Context, channel string, from time.Time, to time.Time) ([]*domain.Telemetry, error) {
+					assert.Equal(t, "channel-1", channel)
+					assert.Equal(t, tc.query.From, from)
+					assert.Equal(t, tc.query.To, to)
+					if tc.telemetryRepoErr != nil {
+						return nil, tc.telemetryRepoErr
+					}
+					return readings, nil
+				},
+			}
+
+			useCase := NewRocketUseCase(mockRepo, mockTelemetryRepo, &mocks.MockRejectionRepository{})
+			result, err := useCase.GetTelemetry(context.Background(), "channel-1", tc.query)
+
+			switch {
+			case tc.expectedErrorType != nil:
+				assert.ErrorIs(t, err, tc.expectedErrorType)
+			case tc.expectedError != "":
+				assert.EqualError(t, err, tc.expectedError)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReadings, result)
+			}
+		})
+	}
+}
+
+func TestRocketUseCase_ListRejections(t *testing.T) {
+	rejections := []*domain.Rejection{
+		{ID: 2, Channel: "channel-1", MessageNumber: 3, MessageType: domain.TypeRocketSpeedIncreased, Status: domain.RocketStatusExploded},
+		{ID: 1, Channel: "channel-1", MessageNumber: 1, MessageType: domain.TypeRocketSpeedIncreased, Status: domain.RocketStatusNotLaunched},
+	}
+
+	testCases := []struct {
+		name               string
+		query              domain.RejectionQuery
+		expectedQuery      domain.RejectionQuery
+		repoError          error
+		expectedRejections []*domain.Rejection
+		expectedError      string
+	}{
+		{
+			name:               "default_limit",
+			query:              domain.RejectionQuery{Channel: "channel-1"},
+			expectedQuery:      domain.RejectionQuery{Channel: "channel-1", Limit: DefaultRejectionLimit},
+			expectedRejections: rejections,
+		},
+		{
+			name:               "explicit_limit",
+			query:              domain.RejectionQuery{MessageType: domain.TypeRocketSpeedIncreased, Limit: 2},
+			expectedQuery:      domain.RejectionQuery{MessageType: domain.TypeRocketSpeedIncreased, Limit: 2},
+			expectedRejections: rejections,
+		},
+		{
+			name:          "repository_error",
+			expectedQuery: domain.RejectionQuery{Limit: DefaultRejectionLimit},
+			repoError:     errors.New("database error"),
+			expectedError: "failed to list rejections: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // Capture range variable for parallel execution
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRejectionRepo := &mocks.MockRejectionRepository{
+				ListFunc: func(ctx context.Context, query domain.RejectionQuery) ([]*domain.Rejection, error) {
+					assert.Equal(t, tc.expectedQuery, query)
+					if tc.repoError != nil {
+						return nil, tc.repoError
+					}
+					return rejections, nil
+				},
+			}
+
+			useCase := NewRocketUseCase(&mocks.MockRocketRepository{}, &mocks.MockTelemetryRepository{}, mockRejectionRepo)
+			result, err := useCase.ListRejections(context.Background(), tc.query)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRejections, result)
+			}
+		})
+	}
+}